@@ -0,0 +1,155 @@
+package proton
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// alarmPrefix is the keyspace holding the active alarms
+	alarmPrefix = systemPrefix + "alarm/"
+
+	// AuditAlarmDisarm is recorded when an alarm is disarmed by an operator
+	AuditAlarmDisarm = "alarm-disarm"
+)
+
+var (
+	// ErrCorrupt is thrown when a member detected a corruption and the cluster refuses writes
+	ErrCorrupt = errors.New("corruption detected, writes are refused")
+	// ErrInvalidAlarm is thrown when trying to raise or disarm an alarm without a type
+	ErrInvalidAlarm = errors.New("invalid alarm type")
+)
+
+// alarmKey returns the key of an alarm in the reserved keyspace
+func alarmKey(alarm *protonpb.Alarm) string {
+	return fmt.Sprintf("%s%s/%x", alarmPrefix, alarm.Type, alarm.Member)
+}
+
+// proposeAlarm replicates the activation or the removal of an alarm
+func (n *Node) proposeAlarm(ctx context.Context, alarm *protonpb.Alarm, active bool) error {
+	if alarm.Type == protonpb.AlarmType_NONE {
+		return ErrInvalidAlarm
+	}
+
+	var value []byte
+	if active {
+		data, err := proto.Marshal(alarm)
+		if err != nil {
+			return err
+		}
+		value = data
+	}
+
+	pair, err := EncodePair(alarmKey(alarm), value)
+	if err != nil {
+		return err
+	}
+	return n.proposeRaft(withInternalPriority(ctx), n.stampProposal(pair))
+}
+
+// RaiseAlarm activates an alarm for this member on every node of the
+// cluster. The cluster stays in a protective mode until it is disarmed
+func (n *Node) RaiseAlarm(ctx context.Context, t protonpb.AlarmType) error {
+	return n.proposeAlarm(ctx, &protonpb.Alarm{Member: n.ID, Type: t}, true)
+}
+
+// raiseAlarm raises an alarm without blocking the caller
+func (n *Node) raiseAlarm(t protonpb.AlarmType) {
+	if n.HasAlarm(t) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(n.Ctx, auditTimeout)
+		defer cancel()
+		err := n.RaiseAlarm(ctx, t)
+		if err != nil {
+			log.Println("raft: can't raise alarm", t, ":", err)
+		}
+	}()
+}
+
+// Disarm deactivates an alarm on every node of the cluster,
+// to be used once an operator fixed the cause of the alarm
+func (n *Node) Disarm(ctx context.Context, alarm *protonpb.Alarm) error {
+	return n.proposeAlarm(ctx, alarm, false)
+}
+
+// applyAlarm activates or removes a committed alarm
+func (n *Node) applyAlarm(pair *LogPair) {
+	n.alarmLock.Lock()
+	defer n.alarmLock.Unlock()
+
+	if len(pair.Value) == 0 {
+		delete(n.alarms, pair.Key)
+		return
+	}
+
+	alarm := &protonpb.Alarm{}
+	err := proto.Unmarshal(pair.Value, alarm)
+	if err != nil {
+		log.Println("raft: can't decode alarm:", err)
+		return
+	}
+	n.alarms[pair.Key] = alarm
+}
+
+// Alarms returns the alarms that are active in the cluster
+func (n *Node) Alarms() []*protonpb.Alarm {
+	n.alarmLock.RLock()
+	defer n.alarmLock.RUnlock()
+	var alarms []*protonpb.Alarm
+	for _, alarm := range n.alarms {
+		alarms = append(alarms, alarm)
+	}
+	return alarms
+}
+
+// HasAlarm checks if an alarm of the given type is active on any member
+func (n *Node) HasAlarm(t protonpb.AlarmType) bool {
+	n.alarmLock.RLock()
+	defer n.alarmLock.RUnlock()
+	for key := range n.alarms {
+		if strings.HasPrefix(key, alarmPrefix+t.String()+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAlarms returns the error matching the protective
+// mode the cluster is in, if any
+func (n *Node) checkAlarms() error {
+	switch {
+	case n.HasAlarm(protonpb.AlarmType_CORRUPT):
+		return ErrCorrupt
+	case n.HasAlarm(protonpb.AlarmType_NOSPACE), n.isDiskLow():
+		return ErrNoSpace
+	}
+	return nil
+}
+
+// ListAlarms lists the alarms active in the raft cluster
+func (n *Node) ListAlarms(ctx context.Context, req *protonpb.ListAlarmsRequest) (*protonpb.ListAlarmsResponse, error) {
+	return &protonpb.ListAlarmsResponse{Alarms: n.Alarms()}, nil
+}
+
+// DisarmAlarm disarms an alarm in the raft cluster
+func (n *Node) DisarmAlarm(ctx context.Context, alarm *protonpb.Alarm) (*protonpb.DisarmAlarmResponse, error) {
+	err := n.Disarm(n.Ctx, alarm)
+	n.recordAudit(ctx, AuditAlarmDisarm, alarm.Member, err)
+	if err != nil {
+		return &protonpb.DisarmAlarmResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.DisarmAlarmResponse{Success: true}, nil
+}
@@ -0,0 +1,71 @@
+package proton
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+const (
+	// applyQueueSize is the number of entries queued
+	// for a worker before the main loop waits for it
+	applyQueueSize = 256
+)
+
+// applier runs the apply handler on several workers. The entries
+// writing a key always go to the same worker, so that the entries
+// of a key are handled in order while those of other keys are
+// handled in parallel
+type applier struct {
+	queues  []chan interface{}
+	pending sync.WaitGroup
+}
+
+func newApplier(workers int, apply ApplyCommand) *applier {
+	a := &applier{queues: make([]chan interface{}, workers)}
+	for i := range a.queues {
+		a.queues[i] = make(chan interface{}, applyQueueSize)
+		go a.run(a.queues[i], apply)
+	}
+	return a
+}
+
+// run hands the entries of a queue to the apply handler
+func (a *applier) run(queue <-chan interface{}, apply ApplyCommand) {
+	for data := range queue {
+		apply(data)
+		a.pending.Done()
+	}
+}
+
+// worker returns the worker handling the entries of a key
+func (a *applier) worker(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(a.queues)))
+}
+
+// dispatch queues an entry writing a key to the worker of the key
+func (a *applier) dispatch(key string, data interface{}) {
+	a.pending.Add(1)
+	a.queues[a.worker(key)] <- data
+}
+
+// wait returns once every entry dispatched was handled
+func (a *applier) wait() {
+	a.pending.Wait()
+}
+
+// stop ends the workers once they handled their entries
+func (a *applier) stop() {
+	for _, queue := range a.queues {
+		close(queue)
+	}
+}
+
+// waitApply returns once the apply handler returned for every
+// entry applied to the store. Called from the main loop
+func (n *Node) waitApply() {
+	if n.applier != nil {
+		n.applier.wait()
+	}
+}
@@ -0,0 +1,62 @@
+package proton
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplier(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		handled = make(map[string][]int)
+		release = make(chan struct{})
+	)
+	a := newApplier(4, func(data interface{}) {
+		w := data.([2]interface{})
+		key, seq := w[0].(string), w[1].(int)
+		if key == "slow" && seq == 0 {
+			<-release
+		}
+		lock.Lock()
+		handled[key] = append(handled[key], seq)
+		lock.Unlock()
+	})
+	defer a.stop()
+
+	// Find a key that another worker than the one of slow handles
+	other := "a"
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		if a.worker(key) != a.worker("slow") {
+			other = key
+			break
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		a.dispatch("slow", [2]interface{}{"slow", i})
+		a.dispatch(other, [2]interface{}{other, i})
+	}
+
+	// The other key is not held up by the slow handler
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		lock.Lock()
+		done := len(handled[other]) == 3
+		lock.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	lock.Lock()
+	assert.Equal(t, handled[other], []int{0, 1, 2})
+	assert.Empty(t, handled["slow"])
+	lock.Unlock()
+
+	close(release)
+	a.wait()
+	assert.Equal(t, handled["slow"], []int{0, 1, 2})
+}
@@ -0,0 +1,137 @@
+package proton
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// systemPrefix is the reserved keyspace used to replicate
+	// internal cluster state through the raft log
+	systemPrefix = "__proton/"
+
+	// auditPrefix is the keyspace holding audit events
+	auditPrefix = systemPrefix + "audit/"
+)
+
+const (
+	// AuditMemberAdd is recorded when a node is added to the raft
+	AuditMemberAdd = "member-add"
+	// AuditMemberRemove is recorded when a node is removed from the raft
+	AuditMemberRemove = "member-remove"
+	// AuditMemberUpdate is recorded when the address of a member is updated
+	AuditMemberUpdate = "member-update"
+
+	// auditTimeout bounds the time spent proposing an audit
+	// event so that a missing leader does not block the caller
+	auditTimeout = 2 * time.Second
+
+	// localInitiator is used when an action was not triggered
+	// by a remote RPC call
+	localInitiator = "local"
+
+	// DefaultAuditLimit is the number of audit events kept
+	DefaultAuditLimit = 1000
+)
+
+// isSystemKey checks if a key belongs to the reserved keyspace
+func isSystemKey(key string) bool {
+	return strings.HasPrefix(key, systemPrefix)
+}
+
+// initiator returns the address of the caller of an RPC
+// or 'local' if the action was triggered locally
+func initiator(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return localInitiator
+}
+
+// recordAudit proposes an audit event to the raft so that
+// every member keeps the same log of administrative actions
+func (n *Node) recordAudit(ctx context.Context, action string, target uint64, result error) {
+	event := &protonpb.AuditEvent{
+		Action:    action,
+		Target:    target,
+		Initiator: initiator(ctx),
+		Recorder:  n.ID,
+		Timestamp: time.Now().UnixNano(),
+		Success:   result == nil,
+	}
+	if result != nil {
+		event.Error = result.Error()
+	}
+
+	data, err := proto.Marshal(event)
+	if err != nil {
+		log.Println("raft: can't encode audit event:", err)
+		return
+	}
+
+	key := fmt.Sprintf("%s%020d-%x", auditPrefix, event.Timestamp, n.ID)
+	pair, err := EncodePair(key, data)
+	if err != nil {
+		log.Println("raft: can't encode audit event:", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(n.Ctx, auditTimeout)
+	defer cancel()
+
+	err = n.proposeRaft(withInternalPriority(ctx), n.stampProposal(pair))
+	if err != nil {
+		log.Println("raft: can't propose audit event:", err)
+	}
+}
+
+// applyAudit appends a committed audit event to the local audit log
+func (n *Node) applyAudit(pair *LogPair) {
+	event := &protonpb.AuditEvent{}
+	err := proto.Unmarshal(pair.Value, event)
+	if err != nil {
+		log.Println("raft: can't decode audit event:", err)
+		return
+	}
+
+	n.auditLock.Lock()
+	n.audit = n.trimAudit(append(n.audit, event))
+	n.auditLock.Unlock()
+}
+
+// trimAudit drops the oldest events above AuditLimit. The events
+// are trimmed as they are applied, so that the members with the
+// same limit keep the same events and snapshot them
+func (n *Node) trimAudit(events []*protonpb.AuditEvent) []*protonpb.AuditEvent {
+	if n.AuditLimit <= 0 || len(events) <= n.AuditLimit {
+		return events
+	}
+	return append(events[:0:0], events[len(events)-n.AuditLimit:]...)
+}
+
+// AuditEvents returns the administrative actions recorded
+// in the cluster, oldest first. A limit of 0 returns all
+// the events, otherwise only the most recent ones
+func (n *Node) AuditEvents(limit int) []*protonpb.AuditEvent {
+	n.auditLock.RLock()
+	defer n.auditLock.RUnlock()
+
+	events := n.audit
+	if limit > 0 && limit < len(events) {
+		events = events[len(events)-limit:]
+	}
+	return append([]*protonpb.AuditEvent(nil), events...)
+}
+
+// ListAuditEvents lists the administrative actions recorded in the cluster
+func (n *Node) ListAuditEvents(ctx context.Context, req *protonpb.ListAuditEventsRequest) (*protonpb.ListAuditEventsResponse, error) {
+	return &protonpb.ListAuditEventsResponse{Events: n.AuditEvents(int(req.Limit))}, nil
+}
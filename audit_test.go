@@ -0,0 +1,37 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLimit(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	assert.Equal(t, n.AuditLimit, DefaultAuditLimit)
+	n.AuditLimit = 3
+
+	for i := 1; i <= 5; i++ {
+		data, err := proto.Marshal(&protonpb.AuditEvent{Action: AuditMemberAdd, Target: uint64(i)})
+		assert.NoError(t, err)
+		n.applyAudit(&LogPair{Value: data})
+	}
+
+	// Only the most recent events are kept
+	events := n.AuditEvents(0)
+	assert.Len(t, events, 3)
+	assert.Equal(t, events[0].Target, uint64(3))
+	assert.Equal(t, events[2].Target, uint64(5))
+
+	// A snapshot of more events is trimmed as it is restored
+	state := n.snapshotState()
+	assert.Len(t, state.Events, 3)
+	n.AuditLimit = 2
+	n.restore(state)
+	events = n.AuditEvents(0)
+	assert.Len(t, events, 2)
+	assert.Equal(t, events[0].Target, uint64(4))
+}
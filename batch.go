@@ -0,0 +1,168 @@
+package proton
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// batchKey is the key of the batches of proposals in the reserved keyspace
+	batchKey = systemPrefix + "batch"
+
+	// batchOverhead is the room left in a batch for its encoding
+	batchOverhead = 64
+)
+
+// batcher groups the proposals made during the batch
+// window of the node into a single entry
+type batcher struct {
+	lock      sync.Mutex
+	proposals [][]byte
+	done      []chan error
+	size      int
+	// keys are the keys written by the proposals of the batch
+	keys map[string]bool
+	// generation tells the timer of a batch apart
+	// from the one of a batch flushed since
+	generation uint64
+}
+
+// proposeBatched adds prepared data to the current batch, which is
+// proposed once the batch window elapsed or once it is as large as
+// an entry. A proposal writing a key the batch already writes starts
+// a new batch, the writes of a batch share the revision of its entry
+// and two writes of a key could not be told apart by a transaction.
+// It returns once the batch is handed over to the raft
+func (n *Node) proposeBatched(ctx context.Context, data []byte, limit int) error {
+	b := n.batch
+	done := make(chan error, 1)
+	keys := proposalKeys(data)
+
+	b.lock.Lock()
+	if limit > 0 && len(b.proposals) > 0 && b.size+len(data)+batchOverhead > limit {
+		n.flushBatch(b.generation)
+	}
+	for _, key := range keys {
+		if b.keys[key] {
+			n.flushBatch(b.generation)
+			break
+		}
+	}
+	if b.keys == nil {
+		b.keys = make(map[string]bool)
+	}
+	for _, key := range keys {
+		b.keys[key] = true
+	}
+	b.proposals = append(b.proposals, data)
+	b.done = append(b.done, done)
+	b.size += len(data)
+	if len(b.proposals) == 1 {
+		generation := b.generation
+		time.AfterFunc(n.BatchWindow, func() {
+			b.lock.Lock()
+			n.flushBatch(generation)
+			b.lock.Unlock()
+		})
+	}
+	b.lock.Unlock()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flushBatch proposes the current batch if it is still the one of
+// the generation, a batch of a single proposal is proposed as is.
+// Must be called with the batcher lock held
+func (n *Node) flushBatch(generation uint64) {
+	b := n.batch
+	if b.generation != generation || len(b.proposals) == 0 {
+		return
+	}
+	proposals, done := b.proposals, b.done
+	b.proposals, b.done, b.size, b.keys = nil, nil, 0, nil
+	b.generation++
+
+	go func() {
+		data := proposals[0]
+		if len(proposals) > 1 {
+			var err error
+			data, err = encodeBatch(proposals)
+			if err != nil {
+				for _, ch := range done {
+					ch <- err
+				}
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(n.Ctx, proposeTimeout)
+		defer cancel()
+		err := n.proposeEntry(ctx, data)
+		for _, ch := range done {
+			ch <- err
+		}
+	}()
+}
+
+// proposalKeys returns the keys of the store written by prepared
+// data, the keys of its writes for a transaction
+func proposalKeys(data []byte) []string {
+	pair := &LogPair{}
+	if proto.Unmarshal(data, pair) != nil {
+		return nil
+	}
+	if pair.Key != txnKey {
+		return []string{pair.Key}
+	}
+
+	if pair.Compressed && decompressPair(pair) != nil {
+		return nil
+	}
+	txn := &Txn{}
+	if proto.Unmarshal(pair.Value, txn) != nil {
+		return nil
+	}
+	keys := make([]string, len(txn.Writes))
+	for i, write := range txn.Writes {
+		keys[i] = write.Key
+	}
+	return keys
+}
+
+// encodeBatch encodes proposals into the data of a single entry
+func encodeBatch(proposals [][]byte) ([]byte, error) {
+	value, err := proto.Marshal(&ProposalBatch{Proposals: proposals})
+	if err != nil {
+		return nil, err
+	}
+	return EncodePair(batchKey, value)
+}
+
+// applyBatch applies the proposals of a batch in order, as if
+// each was its own entry. They share the index of the batch
+func (n *Node) applyBatch(entry raftpb.Entry, pair *LogPair) {
+	batch := &ProposalBatch{}
+	err := proto.Unmarshal(pair.Value, batch)
+	if err != nil {
+		log.Println("raft: can't decode batch of proposals:", err)
+		return
+	}
+
+	for _, data := range batch.Proposals {
+		e := entry
+		e.Data = data
+		n.process(e)
+		n.waiters.trigger(e, n.takeRejection(), n.HLC())
+	}
+}
@@ -0,0 +1,107 @@
+package proton
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestApplyBatch(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	foo, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	baz, err := EncodePair("baz", []byte("qux"))
+	assert.NoError(t, err)
+	ch := n.waiters.register(baz)
+
+	data, err := encodeBatch([][]byte{foo, baz})
+	assert.NoError(t, err)
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: 3, Term: 1, Data: data})
+
+	assert.Equal(t, n.Get("foo"), "bar")
+	assert.Equal(t, n.Get("baz"), "qux")
+	select {
+	case c := <-ch:
+		assert.Equal(t, c.index, uint64(3))
+	default:
+		t.Fatal("The proposals of a batch should be matched with their waiters")
+	}
+}
+
+func TestBatchWindow(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	n.BatchWindow = 50 * time.Millisecond
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	time.Sleep(2 * time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	before := n.AppliedIndex()
+	writes := 20
+	var wg sync.WaitGroup
+	for i := 0; i < writes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, err := EncodePair(fmt.Sprintf("key%d", i), []byte("value"))
+			assert.NoError(t, err)
+			_, _, err = n.ProposeWait(ctx, data)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, n.StoreLength(), writes)
+	assert.True(t, n.AppliedIndex()-before < uint64(writes), "Concurrent writes should be grouped into fewer entries")
+}
+
+func TestBatchSameKey(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	n.BatchWindow = time.Hour
+
+	foo, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	baz, err := EncodePair("baz", []byte("qux"))
+	assert.NoError(t, err)
+	txn, err := proto.Marshal(&Txn{Writes: []*LogPair{{Key: "foo"}, {Key: "quux"}}})
+	assert.NoError(t, err)
+	write, err := EncodePair(txnKey, txn)
+	assert.NoError(t, err)
+
+	assert.Equal(t, proposalKeys(foo), []string{"foo"})
+	assert.Equal(t, proposalKeys(write), []string{"foo", "quux"})
+
+	propose := func(data []byte) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		n.proposeBatched(ctx, data, 0)
+	}
+
+	propose(foo)
+	propose(baz)
+	assert.Len(t, n.batch.proposals, 2)
+
+	// A second write of a key starts a new batch
+	propose(write)
+	assert.Len(t, n.batch.proposals, 1)
+	assert.Equal(t, n.batch.generation, uint64(1))
+	propose(baz)
+	assert.Len(t, n.batch.proposals, 2)
+}
@@ -0,0 +1,49 @@
+package proton
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+var (
+	// ErrClusterMismatch is thrown when a node that bootstrapped its own
+	// cluster, or was a member of another one, asks to join the cluster.
+	// Both have committed logs of their own that raft can't reconcile.
+	// The node has to be started again without its state through
+	// NewJoinNode, its keys are then replaced by the ones of the cluster
+	ErrClusterMismatch = errors.New("node belongs to another cluster, start it without its state as a joining node")
+)
+
+// ClusterID returns the id of the cluster of the node, which is the
+// id of the member that bootstrapped it. It is 0 for a node started
+// through NewJoinNode until it receives the log of its cluster
+func (n *Node) ClusterID() uint64 {
+	return atomic.LoadUint64(&n.clusterID)
+}
+
+// setClusterID sets the cluster of the node once known
+func (n *Node) setClusterID(id uint64) {
+	if id != 0 {
+		atomic.StoreUint64(&n.clusterID, id)
+	}
+}
+
+// learnClusterID records the cluster of a joining node from the
+// entry adding the first member, which bootstrapped the cluster
+func (n *Node) learnClusterID(entry raftpb.Entry, cc raftpb.ConfChange) {
+	if entry.Index == 1 && cc.Type == raftpb.ConfChangeAddNode {
+		n.setClusterID(cc.NodeID)
+	}
+}
+
+// checkJoin refuses a node that already belongs to another
+// cluster, such as a node bootstrapped on its own by mistake
+func (n *Node) checkJoin(info *protonpb.NodeInfo) error {
+	if info.ClusterID != 0 && info.ClusterID != n.ClusterID() {
+		return ErrClusterMismatch
+	}
+	return nil
+}
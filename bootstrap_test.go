@@ -0,0 +1,68 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterID(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Stop()
+	assert.Equal(t, n.ClusterID(), uint64(1))
+
+	// A joining node learns the cluster from its log or a snapshot
+	j, err := NewJoinNode(3, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer j.Stop()
+	assert.Equal(t, j.ClusterID(), uint64(0))
+
+	j.learnClusterID(raftpb.Entry{Index: 2}, raftpb.ConfChange{Type: raftpb.ConfChangeAddNode, NodeID: 5})
+	assert.Equal(t, j.ClusterID(), uint64(0))
+	j.learnClusterID(raftpb.Entry{Index: 1}, raftpb.ConfChange{Type: raftpb.ConfChangeAddNode, NodeID: 1})
+	assert.Equal(t, j.ClusterID(), uint64(1))
+
+	s, err := NewJoinNode(4, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer s.Stop()
+	s.restore(n.snapshotState())
+	assert.Equal(t, s.ClusterID(), uint64(1))
+	assert.Equal(t, s.Info().ClusterID, uint64(1))
+}
+
+func TestJoinOtherCluster(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	// A node bootstrapped on its own is refused
+	other, err := NewNode(2, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer other.Stop()
+
+	resp, err := n.JoinRaft(n.Ctx, other.Info())
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Equal(t, resp.Error, ErrClusterMismatch.Error())
+	assert.Len(t, n.Cluster.Members(), 1)
+
+	assert.NoError(t, n.checkJoin(&protonpb.NodeInfo{ID: 3}))
+	assert.NoError(t, n.checkJoin(&protonpb.NodeInfo{ID: 3, ClusterID: 1}))
+}
@@ -0,0 +1,52 @@
+package proton
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+// queueApply appends committed entries to the entries waiting to
+// be applied. The raft is told they are applied right away, so
+// that it keeps handing out messages while they are applied
+func (n *Node) queueApply(entries []raftpb.Entry, ready time.Time) {
+	if len(entries) == 0 {
+		return
+	}
+	if len(n.applyQueue) == 0 {
+		n.applyReady = ready
+	}
+	n.applyQueue = append(n.applyQueue, entries...)
+}
+
+// applyQueued applies the queued entries until the budget is
+// spent, the main loop is then woken up to apply the others once
+// it handled what waits for it. A budget of 0 applies them all
+func (n *Node) applyQueued(budget time.Duration) {
+	apply := time.Now()
+	applied := 0
+	for len(n.applyQueue) > 0 {
+		n.applyEntry(n.applyQueue[0], n.applyReady)
+		n.applyQueue = n.applyQueue[1:]
+		applied++
+		if budget > 0 && time.Since(apply) >= budget {
+			break
+		}
+	}
+
+	n.waitApply()
+	n.indexWaiters.trigger(atomic.LoadUint64(&n.appliedIndex))
+	n.observeApply(time.Since(apply), applied)
+	n.saveApplied()
+	n.maybeSnapshot()
+
+	if len(n.applyQueue) == 0 {
+		n.applyQueue = nil
+		return
+	}
+	select {
+	case n.applyc <- struct{}{}:
+	default:
+	}
+}
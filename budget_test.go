@@ -0,0 +1,62 @@
+package proton
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyBudget(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	var entries []raftpb.Entry
+	for i := 1; i <= 10; i++ {
+		data, err := proto.Marshal(&protonpb.Pair{Key: fmt.Sprintf("key/%d", i), Value: []byte("value")})
+		assert.NoError(t, err)
+		entries = append(entries, raftpb.Entry{Type: raftpb.EntryNormal, Index: uint64(i + 2), Term: 1, Data: data})
+	}
+	n.queueApply(entries[:5], time.Now())
+	n.queueApply(entries[5:], time.Now())
+	assert.Len(t, n.applyQueue, 10)
+
+	// A spent budget applies one entry at a time and wakes up the main loop
+	n.applyQueued(time.Nanosecond)
+	assert.Equal(t, n.AppliedIndex(), uint64(3))
+	assert.Equal(t, n.StoreLength(), 1)
+	assert.Len(t, n.applyc, 1)
+	<-n.applyc
+
+	n.applyQueued(0)
+	assert.Equal(t, n.AppliedIndex(), uint64(12))
+	assert.Equal(t, n.StoreLength(), 10)
+	assert.Empty(t, n.applyQueue)
+	assert.Len(t, n.applyc, 0)
+
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+	leader, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer leader.Shutdown()
+	leader.ApplyBudget = time.Nanosecond
+
+	leader.Campaign(leader.Ctx)
+	go leader.Start()
+	for i := 0; i < 100 && !leader.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, leader.IsLeader())
+
+	for i := 0; i < 10; i++ {
+		data, err := EncodePair(fmt.Sprintf("key/%d", i), []byte("value"))
+		assert.NoError(t, err)
+		_, _, err = leader.ProposeWait(leader.Ctx, data)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, leader.StoreLength(), 10)
+}
@@ -0,0 +1,222 @@
+package proton
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// DefaultBulkBatchSize is the size in bytes of the entries
+	// a bulk load groups the pairs into, when the raft config
+	// does not bound the size of the messages
+	DefaultBulkBatchSize = 1 << 20
+)
+
+var (
+	// ErrUnsortedKeys is thrown when the keys of a bulk load are not in ascending order
+	ErrUnsortedKeys = errors.New("keys of a bulk load must be in ascending order")
+)
+
+// BulkLoadOptions tune a bulk load
+type BulkLoadOptions struct {
+	// BatchSize is the size in bytes of the entries the pairs are
+	// grouped into, 0 uses the maximum size of an entry
+	BatchSize int
+	// Rate is the number of bytes loaded per second, to leave
+	// room for the other proposals. 0 does not throttle the load
+	Rate int
+	// Progress is called once each batch is applied, an error
+	// stops the load
+	Progress func(*protonpb.BulkLoadProgress) error
+}
+
+// LoadPairs proposes the pairs returned by next until it returns
+// io.EOF, grouped into large entries to save the cost of an entry
+// per key when seeding a store with many keys. A batch is proposed
+// once the previous one is applied. The keys must be in ascending
+// order so that an interrupted load resumes after the last key of
+// the progress. Only the leader accepts a bulk load
+func (n *Node) LoadPairs(ctx context.Context, next func() (*protonpb.Pair, error), opts BulkLoadOptions) (*protonpb.BulkLoadProgress, error) {
+	if !n.IsLeader() {
+		return nil, n.notLeader()
+	}
+
+	size := opts.BatchSize
+	if size <= 0 {
+		size = n.maxEntrySize()
+	}
+	if size <= 0 {
+		size = DefaultBulkBatchSize
+	}
+
+	var (
+		progress  = &protonpb.BulkLoadProgress{}
+		proposals [][]byte
+		batched   int
+		keys      uint64
+		lastKey   string
+		start     = time.Now()
+	)
+
+	flush := func() error {
+		if len(proposals) == 0 {
+			return nil
+		}
+		data := proposals[0]
+		if len(proposals) > 1 {
+			var err error
+			data, err = encodeBatch(proposals)
+			if err != nil {
+				return err
+			}
+		}
+		c, err := n.proposePrepared(ctx, data)
+		if err != nil {
+			return err
+		}
+
+		progress.Keys += keys
+		progress.Bytes += uint64(batched)
+		progress.LastKey = lastKey
+		progress.Index = c.index
+		proposals, batched, keys = nil, 0, 0
+
+		if opts.Progress != nil {
+			err = opts.Progress(progress)
+			if err != nil {
+				return err
+			}
+		}
+		return throttleLoad(ctx, start, progress.Bytes, opts.Rate)
+	}
+
+	for {
+		pair, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return progress, err
+		}
+		if progress.Keys+keys > 0 && pair.Key <= lastKey {
+			return progress, ErrUnsortedKeys
+		}
+
+		data, err := proto.Marshal(&LogPair{
+			Key:     pair.Key,
+			Value:   pair.Value,
+			Origin:  pair.Origin,
+			Session: pair.Session,
+		})
+		if err != nil {
+			return progress, err
+		}
+		data, err = n.prepareProposal(data)
+		if err != nil {
+			return progress, err
+		}
+
+		if batched > 0 && batched+len(data) > size-batchOverhead {
+			err = flush()
+			if err != nil {
+				return progress, err
+			}
+		}
+		proposals = append(proposals, data)
+		batched += len(data)
+		keys++
+		lastKey = pair.Key
+	}
+
+	return progress, flush()
+}
+
+// throttleLoad waits until loading bytes since start
+// fits in rate bytes per second
+func throttleLoad(ctx context.Context, start time.Time, bytes uint64, rate int) error {
+	if rate <= 0 {
+		return nil
+	}
+	wait := time.Duration(float64(bytes)/float64(rate)*float64(time.Second)) - time.Since(start)
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BulkLoad loads the pairs streamed by a client into the store, the
+// progress is sent back once each batch is applied. The keys are
+// loaded in the namespace of the first request
+func (n *Node) BulkLoad(stream KV_BulkLoadServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = validateNamespace(req.Namespace)
+	if err != nil {
+		return err
+	}
+	namespace := req.Namespace
+
+	// Each request counts as a write of the client
+	err = n.checkRate(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	opts := BulkLoadOptions{
+		BatchSize: int(req.BatchSize),
+		Rate:      int(req.Rate),
+		Progress: func(progress *protonpb.BulkLoadProgress) error {
+			p := *progress
+			_, p.LastKey = SplitNamespacedKey(p.LastKey)
+			return stream.Send(&p)
+		},
+	}
+
+	pairs := req.Pairs
+	next := func() (*protonpb.Pair, error) {
+		for len(pairs) == 0 {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			err = n.checkRate(stream.Context())
+			if err != nil {
+				return nil, err
+			}
+			pairs = req.Pairs
+		}
+		pair := pairs[0]
+		pairs = pairs[1:]
+
+		if namespace == "" && isReservedKey(pair.Key) {
+			return nil, ErrReservedKey
+		}
+		// Only the key, value and origin of a pair are loaded
+		return &protonpb.Pair{
+			Key:    NamespacedKey(namespace, pair.Key),
+			Value:  pair.Value,
+			Origin: pair.Origin,
+		}, nil
+	}
+
+	_, err = n.LoadPairs(stream.Context(), next, opts)
+	setLeaderTrailer(stream, err)
+	return err
+}
@@ -0,0 +1,160 @@
+package proton
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// bulkPairs returns the pairs of keys from to to, in order
+func bulkPairs(from, to int) func() (*protonpb.Pair, error) {
+	return func() (*protonpb.Pair, error) {
+		if from >= to {
+			return nil, io.EOF
+		}
+		pair := &protonpb.Pair{Key: fmt.Sprintf("key/%06d", from), Value: []byte("value")}
+		from++
+		return pair, nil
+	}
+}
+
+func TestLoadPairs(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	_, err := n.LoadPairs(context.Background(), bulkPairs(0, 10), BulkLoadOptions{})
+	assert.True(t, IsNotLeader(err))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+	n, err = NewNode(1, l.Addr().String(), cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	n.Server = server
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	Register(server, n)
+	go server.Serve(l)
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var updates []*protonpb.BulkLoadProgress
+	opts := BulkLoadOptions{
+		BatchSize: 4096,
+		Progress: func(p *protonpb.BulkLoadProgress) error {
+			progress := *p
+			updates = append(updates, &progress)
+			return nil
+		},
+	}
+	progress, err := n.LoadPairs(ctx, bulkPairs(0, 1000), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, progress.Keys, uint64(1000))
+	assert.Equal(t, progress.LastKey, "key/000999")
+	assert.Equal(t, n.StoreLength(), 1000)
+	assert.Equal(t, n.Get("key/000500"), "value")
+
+	// The pairs are grouped into far fewer entries than keys
+	assert.True(t, len(updates) > 1)
+	assert.True(t, len(updates) < 100)
+	for i := 1; i < len(updates); i++ {
+		assert.True(t, updates[i].Keys > updates[i-1].Keys)
+		assert.True(t, updates[i].LastKey > updates[i-1].LastKey)
+	}
+
+	// An interrupted load resumes after the last key applied
+	progress, err = n.LoadPairs(ctx, bulkPairs(1000, 1002), BulkLoadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, progress.Keys, uint64(2))
+	assert.Equal(t, n.StoreLength(), 1002)
+
+	unsorted := []*protonpb.Pair{{Key: "b"}, {Key: "a"}}
+	progress, err = n.LoadPairs(ctx, func() (*protonpb.Pair, error) {
+		if len(unsorted) == 0 {
+			return nil, io.EOF
+		}
+		pair := unsorted[0]
+		unsorted = unsorted[1:]
+		return pair, nil
+	}, BulkLoadOptions{})
+	assert.Equal(t, err, ErrUnsortedKeys)
+	assert.Equal(t, progress.Keys, uint64(0))
+
+	// The pairs streamed by a client are loaded in its namespace
+	client, err := GetRaftClient(l.Addr().String(), time.Second)
+	assert.NoError(t, err)
+	stream, err := client.BulkLoad(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&protonpb.BulkLoadRequest{
+		Namespace: "app",
+		Pairs:     []*protonpb.Pair{{Key: "a", Value: []byte("1")}, {Key: "b", Value: []byte("2")}},
+	}))
+	assert.NoError(t, stream.Send(&protonpb.BulkLoadRequest{Pairs: []*protonpb.Pair{{Key: "c", Value: []byte("3")}}}))
+	assert.NoError(t, stream.CloseSend())
+
+	var last *protonpb.BulkLoadProgress
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		last = p
+	}
+	assert.Equal(t, last.Keys, uint64(3))
+	assert.Equal(t, last.LastKey, "c")
+	assert.Equal(t, n.Get(NamespacedKey("app", "b")), "2")
+
+	// Only the key, value and origin of the pairs are loaded
+	stream, err = client.BulkLoad(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&protonpb.BulkLoadRequest{
+		Namespace: "fields",
+		Pairs: []*protonpb.Pair{
+			{Key: "session", Value: []byte("2"), Session: 42},
+		},
+	}))
+	assert.NoError(t, stream.CloseSend())
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, n.Get(NamespacedKey("fields", "session")), "2")
+	n.storeLock.RLock()
+	_, owned := n.owners[NamespacedKey("fields", "session")]
+	n.storeLock.RUnlock()
+	assert.False(t, owned)
+}
+
+func TestThrottleLoad(t *testing.T) {
+	start := time.Now()
+	assert.NoError(t, throttleLoad(context.Background(), start, 1000, 10000))
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+
+	assert.NoError(t, throttleLoad(context.Background(), time.Now(), 1000, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, throttleLoad(ctx, time.Now(), 1000, 1), context.Canceled)
+}
@@ -0,0 +1,221 @@
+package proton
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+const (
+	// DefaultCacheStaleness is the time after which a cached
+	// value is read again from the cluster
+	DefaultCacheStaleness = time.Minute
+
+	// cacheRetryInterval is the time to wait before watching
+	// the changes again once the stream was interrupted
+	cacheRetryInterval = time.Second
+)
+
+// CachedClient serves the reads of the keys of a namespace from a
+// local cache, kept coherent by streaming the changes applied on
+// the member it reads from. A value is read from the cluster the
+// first time, then every write and deletion of its key updates it.
+// The cache is dropped whenever the stream is interrupted or falls
+// behind, the reads go to the cluster until it is watched again
+type CachedClient struct {
+	// MaxStaleness is the time after which a cached value is read
+	// again from the cluster, which bounds how stale it can be if
+	// a change was missed. 0 keeps the values until they change
+	MaxStaleness time.Duration
+
+	client    *Raft
+	namespace string
+	prefix    string
+
+	lock       sync.RWMutex
+	entries    map[string]*cacheEntry
+	watching   bool
+	generation uint64
+	hits       uint64
+	misses     uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// cacheEntry is a cached value, index is the entry that
+// wrote it which orders it with the changes of the key
+type cacheEntry struct {
+	value  []byte
+	found  bool
+	index  uint64
+	cached time.Time
+}
+
+// NewCachedClient returns a client caching the keys of the
+// namespace starting with prefix, it must be started to
+// watch the changes before it serves reads from the cache
+func NewCachedClient(client *Raft, namespace, prefix string) *CachedClient {
+	return &CachedClient{
+		MaxStaleness: DefaultCacheStaleness,
+		client:       client,
+		namespace:    namespace,
+		prefix:       prefix,
+		entries:      make(map[string]*cacheEntry),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start watches the changes in the background until Stop is called
+func (c *CachedClient) Start() {
+	go c.watch()
+}
+
+// Stop stops watching the changes and drops the cache
+func (c *CachedClient) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Get returns the value of a key and whether it was found,
+// from the cache if it holds a value recent enough
+func (c *CachedClient) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.lock.Lock()
+	entry, ok := c.entries[key]
+	if ok && c.fresh(entry) {
+		c.hits++
+		c.lock.Unlock()
+		return entry.value, entry.found, nil
+	}
+	c.misses++
+	generation, watching := c.generation, c.watching
+	c.lock.Unlock()
+
+	resp, err := c.client.GetObjects(ctx, &protonpb.GetObjectsRequest{
+		Keys:      []string{key},
+		Namespace: c.namespace,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	result := resp.Results[0]
+
+	if watching && c.cached(key) {
+		c.store(generation, key, &cacheEntry{value: result.Value, found: result.Found, index: result.Revision})
+	}
+	return result.Value, result.Found, nil
+}
+
+// Stats returns the number of reads served from the
+// cache and the number of reads sent to the cluster
+func (c *CachedClient) Stats() (hits uint64, misses uint64) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.hits, c.misses
+}
+
+// fresh checks if a cached value can be served.
+// Must be called with the lock held
+func (c *CachedClient) fresh(entry *cacheEntry) bool {
+	return c.MaxStaleness <= 0 || time.Since(entry.cached) < c.MaxStaleness
+}
+
+// cached checks if a key is watched by the cache
+func (c *CachedClient) cached(key string) bool {
+	return strings.HasPrefix(key, c.prefix)
+}
+
+// store caches a value read from the cluster
+// unless the cache was dropped since it was read
+func (c *CachedClient) store(generation uint64, key string, entry *cacheEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.watching && c.generation == generation {
+		c.put(key, entry)
+	}
+}
+
+// put caches a value unless a later change of the key
+// is already cached. Must be called with the lock held
+func (c *CachedClient) put(key string, entry *cacheEntry) {
+	if old, ok := c.entries[key]; ok && old.index > entry.index {
+		return
+	}
+	entry.cached = time.Now()
+	c.entries[key] = entry
+}
+
+// watch follows the changes of the keys, starting over
+// whenever the stream is interrupted
+func (c *CachedClient) watch() {
+	for {
+		c.follow()
+		c.drop()
+
+		select {
+		case <-c.stop:
+			return
+		case <-time.After(cacheRetryInterval):
+		}
+	}
+}
+
+// follow applies the changes of the keys to the
+// cache until the stream is interrupted
+func (c *CachedClient) follow() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	stream, err := c.client.StreamChanges(ctx, &protonpb.StreamChangesRequest{
+		Prefix:    NamespacedKey(c.namespace, c.prefix),
+		Resumable: true,
+	})
+	if err != nil {
+		return
+	}
+
+	c.lock.Lock()
+	c.watching = true
+	c.lock.Unlock()
+
+	for {
+		change, err := stream.Recv()
+		if err != nil || change.Lagged {
+			return
+		}
+
+		namespace, key := SplitNamespacedKey(change.Pair.Key)
+		if namespace != c.namespace {
+			continue
+		}
+		entry := &cacheEntry{index: change.Index}
+		if change.Type != protonpb.ChangeType_DELETE {
+			entry.value, entry.found = change.Pair.Value, true
+		}
+		c.lock.Lock()
+		c.put(key, entry)
+		c.lock.Unlock()
+	}
+}
+
+// drop forgets every cached value, the changes
+// are not watched anymore
+func (c *CachedClient) drop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries = make(map[string]*cacheEntry)
+	c.watching = false
+	c.generation++
+}
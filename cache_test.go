@@ -0,0 +1,123 @@
+package proton
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// waitCache waits until the cache watches the changes or not
+func waitCache(t *testing.T, c *CachedClient, watching bool) {
+	for i := 0; i < 100; i++ {
+		c.lock.RLock()
+		done := c.watching == watching
+		c.lock.RUnlock()
+		if done {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("cache did not change its watch")
+}
+
+func TestCachedClient(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+	n, err := NewNode(1, l.Addr().String(), cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	n.Server = server
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	Register(server, n)
+	go server.Serve(l)
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	client, err := GetRaftClient(l.Addr().String(), time.Second)
+	assert.NoError(t, err)
+	cache := NewCachedClient(client, "app", "cfg/")
+	cache.Start()
+	defer cache.Stop()
+	waitCache(t, cache, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	put := func(key, value string) {
+		resp, err := n.PutObject(ctx, &protonpb.PutObjectRequest{Object: &protonpb.Pair{Key: key, Value: []byte(value)}, Namespace: "app"})
+		assert.NoError(t, err)
+		assert.True(t, resp.Success)
+	}
+	get := func(key string) (string, bool) {
+		value, found, err := cache.Get(ctx, key)
+		assert.NoError(t, err)
+		return string(value), found
+	}
+	eventually := func(key, value string, found bool) {
+		for i := 0; i < 100; i++ {
+			v, f := get(key)
+			if v == value && f == found {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("cache did not see the change of %s", key)
+	}
+
+	put("cfg/a", "1")
+	value, found := get("cfg/a")
+	assert.Equal(t, value, "1")
+	assert.True(t, found)
+	get("cfg/a")
+	hits, misses := cache.Stats()
+	assert.Equal(t, hits, uint64(1))
+	assert.Equal(t, misses, uint64(1))
+
+	// The changes update the cache without reading from the cluster
+	put("cfg/a", "2")
+	eventually("cfg/a", "2", true)
+
+	data, err := proto.Marshal(&protonpb.Pair{Key: NamespacedKey("app", "cfg/a"), Delete: true})
+	assert.NoError(t, err)
+	_, _, err = n.ProposeWait(ctx, data)
+	assert.NoError(t, err)
+	eventually("cfg/a", "", false)
+
+	_, misses = cache.Stats()
+	assert.Equal(t, misses, uint64(1))
+
+	// The keys outside of the prefix are always read from the cluster
+	put("other", "1")
+	get("other")
+	get("other")
+	_, misses = cache.Stats()
+	assert.Equal(t, misses, uint64(3))
+
+	// A value is read again once stale
+	cache.MaxStaleness = time.Nanosecond
+	get("cfg/a")
+	_, misses = cache.Stats()
+	assert.Equal(t, misses, uint64(4))
+
+	// The cache is dropped once the changes are not watched
+	cache.Stop()
+	waitCache(t, cache, false)
+	cache.lock.RLock()
+	assert.Empty(t, cache.entries)
+	cache.lock.RUnlock()
+}
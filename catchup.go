@@ -0,0 +1,58 @@
+package proton
+
+import (
+	"log"
+
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	// DefaultJoinSnapshotEntries is the number of applied entries
+	// above which a joining member is sent a snapshot right away
+	DefaultJoinSnapshotEntries = 1000
+)
+
+// sendJoinSnapshot sends a snapshot to a member that just joined.
+// The raft would otherwise learn that the member has no log from a
+// rejected append, then replicate the whole log kept since the
+// last compaction before sending a snapshot, if it ever does. A
+// snapshot is taken first if the last one is too old, unless the
+// snapshots are disabled. Called from the main loop once the
+// member is added
+func (n *Node) sendJoinSnapshot(id uint64) {
+	if n.JoinSnapshotEntries == 0 || n.SnapshotCount == 0 || id == n.ID {
+		return
+	}
+	if n.appliedIndex < n.JoinSnapshotEntries || !n.IsLeader() {
+		return
+	}
+	peer, ok := n.Cluster.Peers()[id]
+	if !ok {
+		return
+	}
+	// A promoted standby already has the state up to its join
+	if n.joinIndex(id, n.appliedIndex) != 0 {
+		return
+	}
+
+	if n.appliedIndex-n.snapshotIndex >= n.JoinSnapshotEntries {
+		err := n.createSnapshot()
+		if err != nil {
+			log.Println("raft: can't create snapshot for joining member:", err)
+			return
+		}
+	}
+	snapshot, err := n.Store.Snapshot()
+	if err != nil {
+		log.Println("raft: can't read snapshot for joining member:", err)
+		return
+	}
+
+	go n.sendSnapshot(peer, raftpb.Message{
+		Type:     raftpb.MsgSnap,
+		To:       id,
+		From:     n.ID,
+		Term:     n.Status().Term,
+		Snapshot: snapshot,
+	})
+}
@@ -0,0 +1,77 @@
+package proton
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+)
+
+// snapshotTransport records the snapshots it sends
+type snapshotTransport struct {
+	RaftTransportClient
+	snapshots chan raftpb.Message
+}
+
+func (s *snapshotTransport) Send(ctx context.Context, m *raftpb.Message, opts ...grpc.CallOption) (*SendResponse, error) {
+	if m.Type == raftpb.MsgSnap {
+		s.snapshots <- *m
+	}
+	return &SendResponse{Success: true}, nil
+}
+
+func TestJoinSnapshot(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+	n.JoinSnapshotEntries = 20
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	transport := &snapshotTransport{snapshots: make(chan raftpb.Message, 2)}
+	join := func(id uint64) {
+		n.Cluster.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: id}, Client: &Raft{RaftTransportClient: transport}})
+		n.sendJoinSnapshot(id)
+	}
+
+	// A short log is replicated by the raft
+	join(2)
+	select {
+	case <-transport.snapshots:
+		t.Fatal("snapshot sent for a short log")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	for i := 0; i < 30; i++ {
+		data, err := EncodePair(fmt.Sprintf("key/%d", i), []byte("value"))
+		assert.NoError(t, err)
+		_, _, err = n.ProposeWait(n.Ctx, data)
+		assert.NoError(t, err)
+	}
+
+	join(3)
+	select {
+	case m := <-transport.snapshots:
+		assert.Equal(t, m.To, uint64(3))
+		assert.Equal(t, m.Term, n.Status().Term)
+		assert.Equal(t, m.Snapshot.Metadata.Index, n.AppliedIndex())
+		state, err := decodeSnapshot(m.Snapshot.Data)
+		assert.NoError(t, err)
+		assert.Len(t, state.Pairs, 30)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no snapshot sent to the joining member")
+	}
+}
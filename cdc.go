@@ -0,0 +1,324 @@
+package proton
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+const (
+	// DefaultSubscriptionBuffer is the number of changes buffered
+	// for a subscriber before it is considered too slow
+	DefaultSubscriptionBuffer = 1024
+
+	// MaxSubscriptionBuffer caps the number of changes buffered
+	// for a client, to bound the memory held for slow clients
+	MaxSubscriptionBuffer = 65536
+
+	// DefaultBatchSize is the maximum number of changes of a batch
+	DefaultBatchSize = 100
+
+	// DefaultBatchWindow is the time to wait for more
+	// changes before a batch is sent
+	DefaultBatchWindow = 10 * time.Millisecond
+)
+
+var (
+	// ErrSubscriptionLagged is thrown when a subscriber did not keep up with the changes and was dropped
+	ErrSubscriptionLagged = errors.New("subscriber fell behind the applied changes")
+)
+
+// ChangeFilter selects the changes sent to a subscriber
+type ChangeFilter struct {
+	// Prefix of the keys to receive
+	Prefix string
+	// Glob is a pattern the keys must match, as used by path.Match
+	Glob string
+	// Types of the changes to receive, all if empty
+	Types []protonpb.ChangeType
+	// ChangedOnly skips the writes leaving the value as it was.
+	// Encrypted values may change on every write
+	ChangedOnly bool
+}
+
+// match checks if a change is selected by the filter
+func (f *ChangeFilter) match(change *protonpb.Change) bool {
+	if !strings.HasPrefix(change.Pair.Key, f.Prefix) {
+		return false
+	}
+	if f.ChangedOnly && !change.ValueChanged {
+		return false
+	}
+	if f.Glob != "" {
+		if ok, _ := path.Match(f.Glob, change.Pair.Key); !ok {
+			return false
+		}
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == change.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// subscription is a consumer of the applied changes
+type subscription struct {
+	filter ChangeFilter
+	ch     chan *protonpb.Change
+}
+
+// subscriptions holds the consumers of the applied changes
+type subscriptions struct {
+	lock    sync.Mutex
+	subs    map[*subscription]struct{}
+	dropped uint64
+}
+
+// SubscriberStatus is the backlog of a consumer of the applied changes
+type SubscriberStatus struct {
+	Prefix string
+	// Pending is the number of changes not received yet
+	Pending int
+	// Buffer is the number of pending changes after which
+	// the subscriber is dropped
+	Buffer int
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{subs: make(map[*subscription]struct{})}
+}
+
+// Subscribe returns a channel receiving every mutation of a key
+// starting with prefix once it is applied on this node, along
+// with a function to stop the subscription. The apply loop never
+// waits for a subscriber: if more than buffer changes are pending
+// the channel is closed and the subscriber has to resubscribe
+func (n *Node) Subscribe(prefix string, buffer int) (<-chan *protonpb.Change, func()) {
+	return n.SubscribeFilter(ChangeFilter{Prefix: prefix}, buffer)
+}
+
+// SubscribeFilter is like Subscribe but only receives
+// the changes selected by the filter
+func (n *Node) SubscribeFilter(filter ChangeFilter, buffer int) (<-chan *protonpb.Change, func()) {
+	if buffer <= 0 {
+		buffer = DefaultSubscriptionBuffer
+	}
+
+	sub := &subscription{filter: filter, ch: make(chan *protonpb.Change, buffer)}
+	s := n.subscriptions
+
+	s.lock.Lock()
+	s.subs[sub] = struct{}{}
+	s.lock.Unlock()
+
+	return sub.ch, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if _, ok := s.subs[sub]; ok {
+			delete(s.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// publish sends an applied change to the subscribers
+func (n *Node) publish(change *protonpb.Change) {
+	s := n.subscriptions
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for sub := range s.subs {
+		if !sub.filter.match(change) {
+			continue
+		}
+		select {
+		case sub.ch <- change:
+		default:
+			delete(s.subs, sub)
+			close(sub.ch)
+			s.dropped++
+		}
+	}
+}
+
+// Subscribers returns the backlog of the consumers of the
+// applied changes, to find the ones that are falling behind
+func (n *Node) Subscribers() []SubscriberStatus {
+	s := n.subscriptions
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var status []SubscriberStatus
+	for sub := range s.subs {
+		status = append(status, SubscriberStatus{
+			Prefix:  sub.filter.Prefix,
+			Pending: len(sub.ch),
+			Buffer:  cap(sub.ch),
+		})
+	}
+	return status
+}
+
+// DroppedSubscribers returns the number of subscribers
+// dropped because they fell behind the applied changes
+func (n *Node) DroppedSubscribers() uint64 {
+	s := n.subscriptions
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.dropped
+}
+
+// closeSubscriptions ends every subscription
+func (n *Node) closeSubscriptions() {
+	s := n.subscriptions
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for sub := range s.subs {
+		delete(s.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// subscribeRequest subscribes to the changes selected by a stream request
+func (n *Node) subscribeRequest(req *protonpb.StreamChangesRequest) (<-chan *protonpb.Change, func(), error) {
+	// Invalid patterns are rejected rather than matching nothing
+	if _, err := path.Match(req.Glob, ""); err != nil {
+		return nil, nil, err
+	}
+
+	buffer := int(req.Buffer)
+	if buffer > MaxSubscriptionBuffer {
+		buffer = MaxSubscriptionBuffer
+	}
+
+	changes, cancel := n.SubscribeFilter(ChangeFilter{
+		Prefix:      req.Prefix,
+		Glob:        req.Glob,
+		Types:       req.Types,
+		ChangedOnly: req.ChangedOnly,
+	}, buffer)
+	return changes, cancel, nil
+}
+
+// lagged returns the change telling a resumable stream that the
+// changes after index were dropped. The client can read the store
+// again and resume from there with a new stream
+func lagged(index uint64) *protonpb.Change {
+	return &protonpb.Change{Index: index, Lagged: true}
+}
+
+// StreamChanges streams the changes applied on a node of the raft cluster
+func (n *Node) StreamChanges(req *protonpb.StreamChangesRequest, stream KV_StreamChangesServer) error {
+	changes, cancel, err := n.subscribeRequest(req)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var last uint64
+	ctx := stream.Context()
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				if req.Resumable {
+					return stream.Send(lagged(last))
+				}
+				return ErrSubscriptionLagged
+			}
+			err := stream.Send(change)
+			if err != nil {
+				return err
+			}
+			last = change.Index
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// StreamChangeBatches streams the changes applied on a node of the
+// raft cluster in batches, which saves the cost of sending every
+// change on its own for busy prefixes
+func (n *Node) StreamChangeBatches(req *protonpb.StreamChangesRequest, stream KV_StreamChangeBatchesServer) error {
+	changes, cancel, err := n.subscribeRequest(req)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	size := int(req.BatchSize)
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	window := time.Duration(req.BatchWindow)
+	if window <= 0 {
+		window = DefaultBatchWindow
+	}
+
+	return batchChanges(stream.Context(), changes, size, window, req.Resumable, stream.Send)
+}
+
+// batchChanges sends the changes in batches of at most size changes,
+// a batch is sent once it is full or window after its first change.
+// The changes keep the order in which they were applied
+func batchChanges(ctx context.Context, changes <-chan *protonpb.Change, size int, window time.Duration, resumable bool, send func(*protonpb.ChangeBatch) error) error {
+	var (
+		batch []*protonpb.Change
+		flush <-chan time.Time
+		last  uint64
+	)
+
+	sendBatch := func() error {
+		flush = nil
+		if len(batch) == 0 {
+			return nil
+		}
+		err := send(&protonpb.ChangeBatch{Changes: batch})
+		batch = nil
+		return err
+	}
+
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				// The changes received before falling behind are still sent
+				if err := sendBatch(); err != nil {
+					return err
+				}
+				if resumable {
+					return send(&protonpb.ChangeBatch{Changes: []*protonpb.Change{lagged(last)}})
+				}
+				return ErrSubscriptionLagged
+			}
+			batch = append(batch, change)
+			last = change.Index
+			if len(batch) == 1 {
+				flush = time.After(window)
+			}
+			if len(batch) >= size {
+				if err := sendBatch(); err != nil {
+					return err
+				}
+			}
+		case <-flush:
+			if err := sendBatch(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
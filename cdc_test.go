@@ -0,0 +1,165 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func applyPair(t *testing.T, n *Node, index uint64, key string, value string) {
+	data, err := EncodePair(key, []byte(value))
+	assert.NoError(t, err)
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: index, Term: 1, Data: data})
+}
+
+func TestSubscribe(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	changes, cancel := n.Subscribe("foo", 1)
+	defer cancel()
+
+	applyPair(t, n, 3, "foo", "bar")
+	applyPair(t, n, 4, "baz", "qux")
+
+	change := <-changes
+	assert.Equal(t, change.Pair.Key, "foo")
+	assert.Equal(t, change.Index, uint64(3))
+	assert.Equal(t, change.Term, uint64(1))
+	assert.Equal(t, change.Revision, uint64(1))
+
+	// A subscriber that does not keep up is dropped
+	applyPair(t, n, 5, "foo", "1")
+	applyPair(t, n, 6, "foo", "2")
+	<-changes
+	_, ok := <-changes
+	assert.False(t, ok)
+}
+
+func TestSubscribeFilter(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	changes, cancel := n.SubscribeFilter(ChangeFilter{
+		Prefix:      "app/",
+		Glob:        "app/*/config",
+		Types:       []protonpb.ChangeType{protonpb.ChangeType_UPDATE},
+		ChangedOnly: true,
+	}, 10)
+	defer cancel()
+
+	applyPair(t, n, 3, "app/web/config", "1")
+	applyPair(t, n, 4, "app/web/state", "1")
+	applyPair(t, n, 5, "app/web/state", "2")
+	applyPair(t, n, 6, "app/web/config", "1")
+	applyPair(t, n, 7, "app/web/config", "2")
+
+	change := <-changes
+	assert.Equal(t, change.Index, uint64(7))
+	assert.Equal(t, change.Type, protonpb.ChangeType_UPDATE)
+	assert.True(t, change.ValueChanged)
+	assert.Len(t, changes, 0)
+}
+
+// changeStream records the changes sent to a client
+type changeStream struct {
+	grpc.ServerStream
+	changes []*protonpb.Change
+}
+
+func (s *changeStream) Context() context.Context { return context.Background() }
+
+func (s *changeStream) Send(change *protonpb.Change) error {
+	s.changes = append(s.changes, change)
+	return nil
+}
+
+func TestSubscribers(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	_, cancel := n.Subscribe("foo", 1)
+	defer cancel()
+
+	applyPair(t, n, 3, "foo", "bar")
+	assert.Equal(t, n.Subscribers(), []SubscriberStatus{{Prefix: "foo", Pending: 1, Buffer: 1}})
+
+	applyPair(t, n, 4, "foo", "baz")
+	assert.Empty(t, n.Subscribers())
+	assert.Equal(t, n.DroppedSubscribers(), uint64(1))
+
+	// A resumable stream ends with the index of its last change
+	stream := &changeStream{}
+	done := make(chan error)
+	go func() {
+		done <- n.StreamChanges(&protonpb.StreamChangesRequest{Buffer: 1, Resumable: true}, stream)
+	}()
+	assert.NoError(t, poll(context.Background(), func() bool { return len(n.Subscribers()) == 1 }))
+
+	// Changes are applied until the stream falls behind
+	for i := uint64(5); len(n.Subscribers()) == 1; i++ {
+		applyPair(t, n, i, "foo", "qux")
+	}
+	assert.NoError(t, <-done)
+
+	marker := stream.changes[len(stream.changes)-1]
+	assert.True(t, marker.Lagged)
+	if len(stream.changes) > 1 {
+		assert.Equal(t, marker.Index, stream.changes[len(stream.changes)-2].Index)
+	} else {
+		assert.Equal(t, marker.Index, uint64(0))
+	}
+}
+
+func TestBatchChanges(t *testing.T) {
+	changes := make(chan *protonpb.Change, 10)
+	for i := 1; i <= 5; i++ {
+		changes <- &protonpb.Change{Index: uint64(i)}
+	}
+
+	var batches []*protonpb.ChangeBatch
+	send := func(batch *protonpb.ChangeBatch) error {
+		batches = append(batches, batch)
+		if len(batches) == 3 {
+			close(changes)
+		}
+		return nil
+	}
+
+	// Full batches are sent right away, the last one after the window
+	err := batchChanges(context.Background(), changes, 2, 10*time.Millisecond, false, send)
+	assert.Equal(t, err, ErrSubscriptionLagged)
+	assert.Len(t, batches, 3)
+
+	var index uint64
+	for i, batch := range batches {
+		if i < 2 {
+			assert.Len(t, batch.Changes, 2)
+		}
+		for _, change := range batch.Changes {
+			index++
+			assert.Equal(t, change.Index, index)
+		}
+	}
+	assert.Equal(t, index, uint64(5))
+}
+
+func TestMirrorTranslate(t *testing.T) {
+	m := &Mirror{Name: "east", DestinationName: "west", Prefix: "app/", DestinationPrefix: NamespacedKey("east", "")}
+
+	ns, key := m.translate("app/foo")
+	assert.Equal(t, ns, "east")
+	assert.Equal(t, key, "foo")
+
+	assert.False(t, m.skip(&protonpb.Change{Pair: &protonpb.Pair{Key: "app/foo"}}))
+	assert.False(t, m.skip(&protonpb.Change{Pair: &protonpb.Pair{Key: "app/foo", Origin: "east"}}))
+
+	// Values mirrored from the destination are not sent back
+	assert.True(t, m.skip(&protonpb.Change{Pair: &protonpb.Pair{Key: "app/foo", Origin: "west"}}))
+}
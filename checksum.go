@@ -0,0 +1,152 @@
+package proton
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+// sealKey is the key of the entry after which every entry
+// carries a checksum, in the reserved keyspace
+const sealKey = systemPrefix + "seal"
+
+// entryChecksumField is the number of the protobuf field holding the
+// checksum of an entry, above the fields of any encoded proposal
+const entryChecksumField = 2047
+
+var (
+	// entryChecksumTag is the key of the checksum field, with the fixed32 wire type
+	entryChecksumTag = proto.EncodeVarint(entryChecksumField<<3 | 5)
+
+	// ErrEntryChecksum is thrown when the data of an entry does not match its checksum
+	ErrEntryChecksum = errors.New("entry checksum mismatch")
+)
+
+// entryTrailerSize is the size of the checksum appended to an entry
+var entryTrailerSize = len(entryChecksumTag) + 4
+
+// sealEntry appends the checksum of the data of an entry. It is
+// encoded as a field the proposals do not know of, so that the data
+// still decodes if the checksum is not stripped
+func sealEntry(data []byte) []byte {
+	sealed := append(data[:len(data):len(data)], entryChecksumTag...)
+	sum := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sum, crc32.Checksum(data, crcTable))
+	return append(sealed, sum...)
+}
+
+// unsealEntry checks the data of an entry against its checksum and
+// returns the data without it. An entry that must be sealed is refused
+// without a checksum, the entries written before the cutover and the
+// empty entries of the new leaders are returned as is
+func unsealEntry(data []byte, sealed bool) ([]byte, error) {
+	size := len(data) - entryTrailerSize
+	if size < 0 || !bytes.Equal(data[size:size+len(entryChecksumTag)], entryChecksumTag) {
+		if sealed && len(data) > 0 {
+			return nil, ErrEntryChecksum
+		}
+		return data, nil
+	}
+	sum := binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.Checksum(data[:size], crcTable) != sum {
+		return nil, ErrEntryChecksum
+	}
+	return data[:size], nil
+}
+
+// sealedAt tells if the entry at index must carry a checksum, which
+// holds for the entries after the cutover once it is known
+func sealedAt(cutover, index uint64) bool {
+	return cutover != 0 && index > cutover
+}
+
+// isSealEntry tells if the data of an entry is the cutover
+func isSealEntry(data []byte) bool {
+	pair := &LogPair{}
+	return proto.Unmarshal(data, pair) == nil && pair.Key == sealKey
+}
+
+// verifyEntries checks the entries received from a member, a
+// message corrupted on its way is refused and sent again later
+func verifyEntries(m *raftpb.Message, cutover uint64) error {
+	for _, entry := range m.Entries {
+		if entry.Type != raftpb.EntryNormal {
+			continue
+		}
+		_, err := unsealEntry(entry.Data, sealedAt(cutover, entry.Index))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unsealCommitted checks the committed entries read back from the
+// log and strips their checksum. The entries are copied, as the
+// ones of the log are shared with the raft. The entries following
+// the cutover in the same batch must be sealed as well, the cutover
+// is returned once known
+func unsealCommitted(entries []raftpb.Entry, cutover uint64) ([]raftpb.Entry, uint64, error) {
+	if len(entries) == 0 {
+		return entries, cutover, nil
+	}
+	unsealed := make([]raftpb.Entry, len(entries))
+	copy(unsealed, entries)
+	for i, entry := range unsealed {
+		if entry.Type != raftpb.EntryNormal {
+			continue
+		}
+		data, err := unsealEntry(entry.Data, sealedAt(cutover, entry.Index))
+		if err != nil {
+			return nil, cutover, fmt.Errorf("committed entry %d is corrupted: %v", entry.Index, err)
+		}
+		unsealed[i].Data = data
+		if cutover == 0 && isSealEntry(data) {
+			cutover = entry.Index
+		}
+	}
+	return unsealed, cutover, nil
+}
+
+// proposeSeal proposes the cutover after which every entry must carry
+// a checksum, once for the cluster. Only the leader proposes it, the
+// entries of the log written before are still read without checksum.
+// Called from the main loop on every tick
+func (n *Node) proposeSeal() {
+	if atomic.LoadUint64(&n.sealIndex) != 0 || !n.IsLeader() || time.Since(n.sealProposed) < expiryRetry {
+		return
+	}
+	n.sealProposed = time.Now()
+
+	// Proposing blocks while there is no leader, which
+	// must not hold up the main loop
+	go func() {
+		data, err := EncodePair(sealKey, nil)
+		if err != nil {
+			log.Println("raft: can't encode checksum cutover:", err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(withInternalPriority(n.Ctx), proposeTimeout)
+		defer cancel()
+		err = n.proposeRaft(ctx, n.stampProposal(data))
+		if err != nil {
+			log.Println("raft: can't propose checksum cutover:", err)
+		}
+	}()
+}
+
+// applySeal sets the cutover after which every entry carries a
+// checksum, the first one committed wins
+func (n *Node) applySeal(entry raftpb.Entry) {
+	atomic.CompareAndSwapUint64(&n.sealIndex, 0, entry.Index)
+}
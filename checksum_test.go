@@ -0,0 +1,148 @@
+package proton
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryChecksum(t *testing.T) {
+	data, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+
+	sealed := sealEntry(data)
+	assert.Len(t, sealed, len(data)+entryTrailerSize)
+
+	// The checksum is skipped by the decoders that don't strip it
+	pair := &protonpb.Pair{}
+	assert.NoError(t, proto.Unmarshal(sealed, pair))
+	assert.Equal(t, pair.Key, "foo")
+
+	unsealed, err := unsealEntry(sealed, true)
+	assert.NoError(t, err)
+	assert.Equal(t, unsealed, data)
+
+	// Entries proposed without a checksum before the cutover are
+	// left as is, like the empty entries of the new leaders
+	unsealed, err = unsealEntry(data, false)
+	assert.NoError(t, err)
+	assert.Equal(t, unsealed, data)
+	unsealed, err = unsealEntry(nil, true)
+	assert.NoError(t, err)
+	assert.Nil(t, unsealed)
+
+	corrupted := append([]byte(nil), sealed...)
+	corrupted[1] ^= 0xff
+	_, err = unsealEntry(corrupted, true)
+	assert.Equal(t, err, ErrEntryChecksum)
+
+	msg := &raftpb.Message{Type: raftpb.MsgApp, Entries: []raftpb.Entry{{Index: 1, Data: sealed}}}
+	assert.NoError(t, verifyEntries(msg, 0))
+	msg.Entries = append(msg.Entries, raftpb.Entry{Index: 2, Data: corrupted})
+	assert.Equal(t, verifyEntries(msg, 0), ErrEntryChecksum)
+
+	// The committed entries are stripped without touching the log
+	entries := []raftpb.Entry{{Index: 1, Data: sealed}, {Index: 2}}
+	committed, cutover, err := unsealCommitted(entries, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, cutover, uint64(0))
+	assert.Equal(t, committed[0].Data, data)
+	assert.Equal(t, entries[0].Data, sealed)
+	assert.Nil(t, committed[1].Data)
+
+	// A corrupted committed entry is reported rather than applied
+	_, _, err = unsealCommitted([]raftpb.Entry{{Index: 1, Data: sealed}, {Index: 2, Data: corrupted}}, 0)
+	assert.EqualError(t, err, "committed entry 2 is corrupted: "+ErrEntryChecksum.Error())
+}
+
+func TestEntryChecksumCutover(t *testing.T) {
+	data, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	sealed := sealEntry(data)
+
+	// A damaged tag hides the checksum, the entry can
+	// only pass through before the cutover
+	damaged := append([]byte(nil), sealed...)
+	damaged[len(data)] ^= 0x01
+	unsealed, err := unsealEntry(damaged, false)
+	assert.NoError(t, err)
+	assert.Equal(t, unsealed, damaged)
+	_, err = unsealEntry(damaged, true)
+	assert.Equal(t, err, ErrEntryChecksum)
+	_, err = unsealEntry(data, true)
+	assert.Equal(t, err, ErrEntryChecksum)
+
+	assert.False(t, sealedAt(0, 5))
+	assert.False(t, sealedAt(5, 5))
+	assert.True(t, sealedAt(5, 6))
+
+	msg := &raftpb.Message{Type: raftpb.MsgApp, Entries: []raftpb.Entry{{Index: 5, Data: data}, {Index: 6, Data: sealed}, {Index: 7}}}
+	assert.NoError(t, verifyEntries(msg, 5))
+	msg.Entries = append(msg.Entries, raftpb.Entry{Index: 8, Data: damaged})
+	assert.Equal(t, verifyEntries(msg, 5), ErrEntryChecksum)
+
+	// The entries following the cutover in the same batch are sealed
+	marker, err := EncodePair(sealKey, nil)
+	assert.NoError(t, err)
+	entries := []raftpb.Entry{{Index: 1, Data: data}, {Index: 2, Data: sealEntry(marker)}, {Index: 3, Data: sealed}}
+	committed, cutover, err := unsealCommitted(entries, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, cutover, uint64(2))
+	assert.Equal(t, committed[2].Data, data)
+
+	entries = append(entries, raftpb.Entry{Index: 4, Data: damaged})
+	_, _, err = unsealCommitted(entries, 0)
+	assert.EqualError(t, err, "committed entry 4 is corrupted: "+ErrEntryChecksum.Error())
+}
+
+func TestApplySeal(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	marker, err := EncodePair(sealKey, nil)
+	assert.NoError(t, err)
+	n.applyEntry(raftpb.Entry{Index: 3, Term: 1, Data: marker}, time.Now())
+	assert.Equal(t, atomic.LoadUint64(&n.sealIndex), uint64(3))
+
+	// The first cutover committed wins
+	n.applyEntry(raftpb.Entry{Index: 4, Term: 1, Data: marker}, time.Now())
+	assert.Equal(t, atomic.LoadUint64(&n.sealIndex), uint64(3))
+	assert.Equal(t, n.snapshotState().SealIndex, uint64(3))
+
+	// The entries that follow are refused without checksum
+	data, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	resp, err := n.Send(n.Ctx, &raftpb.Message{Type: raftpb.MsgApp, From: 2, To: 1, Entries: []raftpb.Entry{{Index: 5, Data: data}}})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Error, ErrEntryChecksum.Error())
+}
+
+func TestSendCorruptedEntries(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	data, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	sealed := sealEntry(data)
+	sealed[0] ^= 0xff
+
+	resp, err := n.Send(n.Ctx, &raftpb.Message{Type: raftpb.MsgApp, From: 2, To: 1, Entries: []raftpb.Entry{{Index: 1, Data: sealed}}})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Error, ErrEntryChecksum.Error())
+}
+
+func TestCorruptedConfChange(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// A configuration change that can't be decoded is skipped
+	members := n.Cluster.Members()
+	n.applyEntry(raftpb.Entry{Index: 3, Term: 1, Type: raftpb.EntryConfChange, Data: []byte{0xff, 0xff}}, time.Now())
+	assert.Equal(t, n.Cluster.Members(), members)
+	assert.Equal(t, n.appliedIndex, uint64(3))
+}
@@ -0,0 +1,69 @@
+package proton
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// pairTimestampTag is the key of the timestamp field of an encoded
+// Pair, the field number 9 with the varint wire type
+const pairTimestampTag = 9<<3 | 0
+
+// leaderClock is the offset of the wall clock of the leader
+// from the one of this node, estimated from the responses of
+// the leader to the messages this node sends it
+type leaderClock struct {
+	lock   sync.Mutex
+	leader uint64
+	offset time.Duration
+}
+
+// observe estimates the offset of the clock of a leader that
+// answered at leaderTime a message sent and answered at the
+// given times, the leader is assumed to answer halfway
+func (c *leaderClock) observe(leader uint64, sent, received time.Time, leaderTime int64) {
+	midpoint := sent.Add(received.Sub(sent) / 2)
+
+	c.lock.Lock()
+	c.leader = leader
+	c.offset = time.Unix(0, leaderTime).Sub(midpoint)
+	c.lock.Unlock()
+}
+
+// LeaderTime returns the time on the wall clock of the leader as
+// estimated by this node, that is given to the entries it proposes.
+// The local clock is used until the current leader answered
+func (n *Node) LeaderTime() time.Time {
+	now := time.Now()
+	leader := n.Cluster.leaderID()
+	if leader == n.ID {
+		return now
+	}
+
+	c := n.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.leader != leader {
+		return now
+	}
+	return now.Add(c.offset)
+}
+
+// stampProposal sets the timestamp of an encoded pair to the time
+// of the leader. The field is appended, the last occurrence of a
+// field wins when the pair is decoded
+func (n *Node) stampProposal(data []byte) []byte {
+	stamped := append(data[:len(data):len(data)], pairTimestampTag)
+	return append(stamped, proto.EncodeVarint(uint64(n.LeaderTime().UnixNano()))...)
+}
+
+// entryTime returns the time of an applied entry, or the time at
+// which it is applied for the entries proposed without one
+func entryTime(timestamp int64) time.Time {
+	if timestamp == 0 {
+		return time.Now()
+	}
+	return time.Unix(0, timestamp)
+}
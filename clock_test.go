@@ -0,0 +1,72 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderTime(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// The local clock is used until the leader answered
+	n.Cluster.setLeader(2)
+	assert.WithinDuration(t, n.LeaderTime(), time.Now(), time.Second)
+
+	sent := time.Now()
+	leaderTime := sent.Add(time.Hour + 5*time.Millisecond)
+	n.clock.observe(2, sent, sent.Add(10*time.Millisecond), leaderTime.UnixNano())
+	assert.WithinDuration(t, n.LeaderTime(), time.Now().Add(time.Hour), time.Second)
+
+	// The offset of a former leader is not used
+	n.Cluster.setLeader(3)
+	assert.WithinDuration(t, n.LeaderTime(), time.Now(), time.Second)
+
+	n.Cluster.setLeader(1)
+	assert.WithinDuration(t, n.LeaderTime(), time.Now(), time.Second)
+}
+
+func TestEntryTimestamp(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	n.HistoryLimit = 1
+
+	changes, cancel := n.Subscribe("", 10)
+	defer cancel()
+
+	n.Cluster.setLeader(2)
+	n.clock.observe(2, time.Now(), time.Now(), time.Now().Add(time.Hour).UnixNano())
+
+	data, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	proposed, err := n.prepareProposal(data)
+	assert.NoError(t, err)
+
+	pair := &LogPair{}
+	assert.NoError(t, proto.Unmarshal(proposed, pair))
+	assert.Equal(t, pair.Key, "foo")
+	assert.WithinDuration(t, time.Unix(0, pair.Timestamp), time.Now().Add(time.Hour), time.Second)
+
+	// Every member applies the time of the entry
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: 1, Term: 1, Data: proposed})
+	change := <-changes
+	assert.Equal(t, change.Timestamp, pair.Timestamp)
+	assert.Equal(t, n.History("foo", 0)[0].Time, time.Unix(0, pair.Timestamp))
+
+	// The writes of a transaction share the time of its entry
+	value, err := proto.Marshal(&Txn{Id: 1, Writes: []*LogPair{{Key: "foo", Value: []byte("baz")}}})
+	assert.NoError(t, err)
+	applyProposal(t, n, 2, &LogPair{Key: txnKey, Value: value, Timestamp: 42})
+	change = <-changes
+	assert.Equal(t, change.Timestamp, int64(42))
+
+	// The entries proposed without a time are applied at the local time
+	applyPair(t, n, 3, "foo", "qux")
+	change = <-changes
+	assert.Equal(t, change.Timestamp, int64(0))
+	assert.WithinDuration(t, n.History("foo", 0)[0].Time, time.Now(), time.Second)
+}
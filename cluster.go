@@ -1,18 +1,47 @@
 package proton
 
-import "sync"
+import (
+	"sort"
+	"sync"
 
-// Cluster represents a set of active
-// raft members
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+// Cluster represents a set of active raft members, it is
+// safe for concurrent use. Peers are never modified once
+// added: an update replaces the peer in the cluster
 type Cluster struct {
 	lock sync.RWMutex
 
-	peers map[uint64]*Peer
+	peers    map[uint64]*Peer
+	leader   uint64
+	handlers []func(MemberChange)
+}
+
+// MemberEvent is the kind of a membership change
+type MemberEvent int
+
+const (
+	// MemberAdded is notified when a member joins the cluster
+	MemberAdded MemberEvent = iota
+	// MemberUpdated is notified when the information of a member changes
+	MemberUpdated
+	// MemberRemoved is notified when a member leaves the cluster
+	MemberRemoved
+	// LeaderChanged is notified when a new leader is known, or
+	// with no member when the cluster has lost its leader
+	LeaderChanged
+)
+
+// MemberChange is a change of the membership of the cluster
+type MemberChange struct {
+	Event  MemberEvent
+	Member *protonpb.NodeInfo
 }
 
 // Peer represents a raft cluster peer
 type Peer struct {
-	*NodeInfo
+	*protonpb.NodeInfo
 
 	Client *Raft
 }
@@ -36,16 +65,113 @@ func (c *Cluster) Peers() map[uint64]*Peer {
 	return peers
 }
 
+// Members returns a copy of the information of
+// the peers in the cluster, ordered by id
+func (c *Cluster) Members() []*protonpb.NodeInfo {
+	c.lock.RLock()
+	members := make([]*protonpb.NodeInfo, 0, len(c.peers))
+	for _, peer := range c.peers {
+		info := *peer.NodeInfo
+		members = append(members, &info)
+	}
+	c.lock.RUnlock()
+
+	sort.Sort(membersByID(members))
+	return members
+}
+
+// Member returns a copy of the information of a member
+func (c *Cluster) Member(id uint64) (*protonpb.NodeInfo, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	peer, ok := c.peers[id]
+	if !ok {
+		return nil, false
+	}
+	info := *peer.NodeInfo
+	return &info, true
+}
+
+// Leader returns a copy of the information of the
+// leader, if there is one and it is a known member
+func (c *Cluster) Leader() (*protonpb.NodeInfo, bool) {
+	c.lock.RLock()
+	leader := c.leader
+	c.lock.RUnlock()
+	return c.Member(leader)
+}
+
+// leaderID returns the id of the leader, 0 if unknown
+func (c *Cluster) leaderID() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.leader
+}
+
+// OnChange registers a function called on every change of
+// the membership or of the leader, in the order they are
+// applied. It is called from the raft loop and must not block
+func (c *Cluster) OnChange(fn func(MemberChange)) {
+	c.lock.Lock()
+	c.handlers = append(c.handlers, fn)
+	c.lock.Unlock()
+}
+
+// notify calls the registered change handlers
+func (c *Cluster) notify(handlers []func(MemberChange), change MemberChange) {
+	for _, fn := range handlers {
+		fn(change)
+	}
+}
+
 // AddPeer adds a node to our neighbors
 func (c *Cluster) AddPeer(peer *Peer) {
 	c.lock.Lock()
+	event := MemberAdded
+	if _, ok := c.peers[peer.ID]; ok {
+		event = MemberUpdated
+	}
 	c.peers[peer.ID] = peer
+	info := *peer.NodeInfo
+	handlers := c.handlers
 	c.lock.Unlock()
+
+	c.notify(handlers, MemberChange{Event: event, Member: &info})
 }
 
 // RemovePeer removes a node from our neighbors
 func (c *Cluster) RemovePeer(id uint64) {
 	c.lock.Lock()
+	peer, ok := c.peers[id]
 	delete(c.peers, id)
+	handlers := c.handlers
 	c.lock.Unlock()
+
+	if ok {
+		info := *peer.NodeInfo
+		c.notify(handlers, MemberChange{Event: MemberRemoved, Member: &info})
+	}
+}
+
+// setLeader records the leader of the cluster
+func (c *Cluster) setLeader(id uint64) {
+	c.lock.Lock()
+	if c.leader == id {
+		c.lock.Unlock()
+		return
+	}
+	c.leader = id
+	handlers := c.handlers
+	c.lock.Unlock()
+
+	member, _ := c.Member(id)
+	c.notify(handlers, MemberChange{Event: LeaderChanged, Member: member})
 }
+
+// membersByID sorts members by id
+type membersByID []*protonpb.NodeInfo
+
+func (m membersByID) Len() int           { return len(m) }
+func (m membersByID) Less(i, j int) bool { return m[i].ID < m[j].ID }
+func (m membersByID) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
@@ -0,0 +1,78 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterMembers(t *testing.T) {
+	c := NewCluster()
+	c.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 3, Addr: "c"}})
+	c.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 1, Addr: "a"}})
+	c.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 2, Addr: "b"}})
+
+	members := c.Members()
+	assert.Equal(t, len(members), 3)
+	for i, member := range members {
+		assert.Equal(t, member.ID, uint64(i+1))
+	}
+
+	// The members are copies of the cluster state
+	members[0].Addr = "changed"
+	assert.Equal(t, c.Peers()[1].Addr, "a")
+}
+
+func TestClusterOnChange(t *testing.T) {
+	c := NewCluster()
+
+	var changes []MemberChange
+	c.OnChange(func(change MemberChange) {
+		changes = append(changes, change)
+	})
+
+	c.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 1, Addr: "a"}})
+	c.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 1, Addr: "b"}})
+	c.setLeader(1)
+	c.setLeader(1)
+	c.RemovePeer(1)
+	c.RemovePeer(1)
+
+	assert.Equal(t, len(changes), 4)
+	assert.Equal(t, changes[0].Event, MemberAdded)
+	assert.Equal(t, changes[1].Event, MemberUpdated)
+	assert.Equal(t, changes[1].Member.Addr, "b")
+	assert.Equal(t, changes[2].Event, LeaderChanged)
+	assert.Equal(t, changes[2].Member.ID, uint64(1))
+	assert.Equal(t, changes[3].Event, MemberRemoved)
+
+	// The leader is no longer a member
+	_, ok := c.Leader()
+	assert.False(t, ok)
+	_, ok = c.Member(1)
+	assert.False(t, ok)
+}
+
+func TestRegisterNodesQuorum(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// A member that can't be reached does not fail the join
+	err := n.RegisterNodes(n.Ctx, []*protonpb.NodeInfo{
+		{ID: 1, Addr: n.Address},
+		{ID: 2, Addr: "127.0.0.1:4242"},
+		{ID: 3, Addr: "unreachable"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, n.Cluster.Peers(), uint64(2))
+
+	// It does without a quorum
+	err = n.RegisterNodes(n.Ctx, []*protonpb.NodeInfo{
+		{ID: 1, Addr: n.Address},
+		{ID: 4, Addr: "unreachable"},
+		{ID: 5, Addr: "unreachable"},
+	})
+	assert.Equal(t, err, ErrConnectionRefused)
+	assert.NotContains(t, n.Cluster.Peers(), uint64(4))
+}
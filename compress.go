@@ -0,0 +1,56 @@
+package proton
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// compressProposal compresses the value of a proposed pair if
+// it is larger than the compression threshold of the node. The
+// value is left as is when compressing does not make it smaller
+func (n *Node) compressProposal(data []byte) []byte {
+	if n.CompressionThreshold <= 0 || len(data) < n.CompressionThreshold {
+		return data
+	}
+
+	pair := &LogPair{}
+	err := proto.Unmarshal(data, pair)
+	if err != nil || pair.Compressed || len(pair.Value) < n.CompressionThreshold {
+		return data
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return data
+	}
+	_, err = w.Write(pair.Value)
+	if err != nil || w.Close() != nil || buf.Len() >= len(pair.Value) {
+		return data
+	}
+
+	pair.Value = buf.Bytes()
+	pair.Compressed = true
+	compressed, err := proto.Marshal(pair)
+	if err != nil {
+		return data
+	}
+	return compressed
+}
+
+// decompressPair restores the value of a compressed pair
+func decompressPair(pair *LogPair) error {
+	r := flate.NewReader(bytes.NewReader(pair.Value))
+	defer r.Close()
+
+	value, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	pair.Value = value
+	pair.Compressed = false
+	return nil
+}
@@ -0,0 +1,64 @@
+package proton
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressProposal(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	value := strings.Repeat("compressible ", 100)
+	data, err := EncodePair("foo", []byte(value))
+	assert.NoError(t, err)
+
+	// Disabled by default
+	assert.Equal(t, n.compressProposal(data), data)
+
+	n.CompressionThreshold = 64
+	compressed := n.compressProposal(data)
+	assert.True(t, len(compressed) < len(data))
+
+	pair := &LogPair{}
+	assert.NoError(t, proto.Unmarshal(compressed, pair))
+	assert.True(t, pair.Compressed)
+
+	// Small values are left as is
+	small, err := EncodePair("bar", []byte("baz"))
+	assert.NoError(t, err)
+	assert.Equal(t, n.compressProposal(small), small)
+
+	// The store and the handler see the original value
+	var applied []byte
+	n.apply = func(data interface{}) { applied = data.([]byte) }
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: 1, Term: 1, Data: compressed})
+	assert.Equal(t, n.Get("foo"), value)
+	assert.Equal(t, applied, data)
+}
+
+func TestProcessMalformed(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	applied := false
+	n.apply = func(data interface{}) { applied = true }
+
+	// Entries that can't be decoded are skipped
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: 1, Term: 1, Data: []byte{0xff, 0xff}})
+
+	data, err := proto.Marshal(&LogPair{Key: "foo", Value: []byte("not deflate"), Compressed: true})
+	assert.NoError(t, err)
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: 2, Term: 1, Data: data})
+
+	data, err = proto.Marshal(&LogPair{Key: txnKey, Value: []byte("not deflate"), Compressed: true})
+	assert.NoError(t, err)
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: 3, Term: 1, Data: data})
+
+	assert.False(t, applied)
+	assert.Equal(t, n.StoreLength(), 0)
+}
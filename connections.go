@@ -0,0 +1,171 @@
+package proton
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultResolveInterval is the time between two
+	// resolutions of the hostnames of the members
+	DefaultResolveInterval = 30 * time.Second
+)
+
+// lookupHost resolves the hostnames of the members
+var lookupHost = net.LookupHost
+
+// connections owns the grpc connections to the raft
+// members, a single connection is kept per remote
+// address and shared by everything talking to it
+type connections struct {
+	lock  sync.Mutex
+	conns map[string]*connection
+
+	// opts returns the options the members are dialed with
+	opts func() []grpc.DialOption
+}
+
+// connection is a shared client along with the number
+// of its users and the IPs of its hostname when dialed
+type connection struct {
+	client *Raft
+	refs   int
+	ips    []string
+}
+
+func newConnections(opts func() []grpc.DialOption) *connections {
+	return &connections{conns: make(map[string]*connection), opts: opts}
+}
+
+// get returns the client connected to an address,
+// dialing it if there is no connection yet
+func (c *connections) get(addr string, timeout time.Duration) (*Raft, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if conn, ok := c.conns[addr]; ok {
+		conn.refs++
+		return conn.client, nil
+	}
+
+	client, err := GetRaftClient(addr, timeout, c.opts()...)
+	if err != nil {
+		return nil, err
+	}
+	ips, _ := resolve(addr)
+	c.conns[addr] = &connection{client: client, refs: 1, ips: ips}
+	return client, nil
+}
+
+// resolve returns the sorted IPs of the hostname of an
+// address, or nothing if the address holds an IP
+func resolve(addr string) ([]string, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return nil, err
+	}
+
+	ips, err := lookupHost(host)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ips)
+	return ips, nil
+}
+
+// resolved returns the addresses whose hostname resolves to
+// other IPs than when they were dialed, along with the new IPs.
+// The addresses that can't be resolved are left as they are
+func (c *connections) resolved() map[string][]string {
+	c.lock.Lock()
+	dialed := make(map[string][]string)
+	for addr, conn := range c.conns {
+		if conn.ips != nil {
+			dialed[addr] = conn.ips
+		}
+	}
+	c.lock.Unlock()
+
+	changed := make(map[string][]string)
+	for addr, ips := range dialed {
+		current, err := resolve(addr)
+		if err != nil || len(current) == 0 || equalStrings(current, ips) {
+			continue
+		}
+		changed[addr] = current
+	}
+	return changed
+}
+
+// replace dials an address again and gives the new client
+// to the next users, the previous connection is closed
+func (c *connections) replace(addr string, ips []string, timeout time.Duration) (*Raft, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	conn, ok := c.conns[addr]
+	if !ok {
+		return nil, nil
+	}
+
+	client, err := GetRaftClient(addr, timeout, c.opts()...)
+	if err != nil {
+		return nil, err
+	}
+	conn.client.Conn.Close()
+	conn.client = client
+	conn.ips = ips
+	return client, nil
+}
+
+// equalStrings checks if two sorted slices hold the same strings
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// release gives back a client, the connection is
+// closed once it has no user left
+func (c *connections) release(addr string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	conn, ok := c.conns[addr]
+	if !ok {
+		return
+	}
+	conn.refs--
+	if conn.refs <= 0 {
+		conn.client.Conn.Close()
+		delete(c.conns, addr)
+	}
+}
+
+// closeAll closes every connection
+func (c *connections) closeAll() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for addr, conn := range c.conns {
+		conn.client.Conn.Close()
+		delete(c.conns, addr)
+	}
+}
+
+// len returns the number of open connections
+func (c *connections) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.conns)
+}
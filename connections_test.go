@@ -0,0 +1,96 @@
+package proton
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconnect(t *testing.T) {
+	ips := []string{"10.0.0.1"}
+	lookupHost = func(host string) ([]string, error) { return ips, nil }
+	defer func() { lookupHost = net.LookupHost }()
+
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	err := n.RegisterNode(context.Background(), &protonpb.NodeInfo{ID: 2, Addr: "proton-2:4242"})
+	assert.NoError(t, err)
+	err = n.RegisterNode(context.Background(), &protonpb.NodeInfo{ID: 3, Addr: "127.0.0.1:4243"})
+	assert.NoError(t, err)
+	client := n.Cluster.Peers()[2].Client
+	assert.Empty(t, n.conns.resolved())
+
+	// Only hostnames are resolved again
+	ips = []string{"10.0.0.2", "10.0.0.3"}
+	changed := n.conns.resolved()
+	assert.Equal(t, changed, map[string][]string{"proton-2:4242": {"10.0.0.2", "10.0.0.3"}})
+
+	n.reconnect(changed)
+	assert.True(t, n.Cluster.Peers()[2].Client != client)
+	assert.Equal(t, n.Cluster.Peers()[2].Addr, "proton-2:4242")
+	assert.Equal(t, n.conns.len(), 2)
+	assert.Empty(t, n.conns.resolved())
+}
+
+func TestDialer(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	dialed := make(chan string, 1)
+	n.Dialer = func(addr string, timeout time.Duration) (net.Conn, error) {
+		select {
+		case dialed <- addr:
+		default:
+		}
+		return nil, errors.New("unreachable")
+	}
+
+	// The members are dialed through the dialer of the node
+	err := n.RegisterNode(context.Background(), &protonpb.NodeInfo{ID: 2, Addr: "proton-2:4242"})
+	assert.NoError(t, err)
+	select {
+	case addr := <-dialed:
+		assert.Equal(t, addr, "proton-2:4242")
+	case <-time.After(5 * time.Second):
+		t.Fatal("member not dialed through the dialer")
+	}
+}
+
+func TestSharedConnection(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := NewServer()
+	Register(server, n)
+	go server.Serve(l)
+	defer server.Stop()
+
+	addr := l.Addr().String()
+	err = n.RegisterNode(context.Background(), &protonpb.NodeInfo{ID: 2, Addr: addr})
+	assert.NoError(t, err)
+	peer := n.Cluster.Peers()[2]
+
+	// The transport, the cluster and the KV services share the connection
+	client, err := n.conns.get(addr, 2*time.Second)
+	assert.NoError(t, err)
+	defer n.conns.release(addr)
+	assert.True(t, client == peer.Client)
+	assert.Equal(t, n.conns.len(), 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = client.ListMembers(ctx, &protonpb.ListMembersRequest{})
+	assert.NoError(t, err)
+	_, err = client.GetObjects(ctx, &protonpb.GetObjectsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, n.conns.len(), 1)
+}
@@ -0,0 +1,138 @@
+package proton
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+// DebugHandler returns an HTTP handler exposing pprof, expvar,
+// the raft status and goroutine dumps of the node. If a token
+// is given, requests must carry it as a bearer token
+func (n *Node) DebugHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/raft", n.serveRaftStatus)
+	mux.HandleFunc("/debug/goroutines", serveGoroutines)
+
+	if token == "" {
+		return mux
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(auth, expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// ServeDebug serves the debug endpoints of the node on
+// a dedicated listener, it blocks until the listener fails
+func (n *Node) ServeDebug(l net.Listener, token string) error {
+	return http.Serve(l, n.DebugHandler(token))
+}
+
+// raftStatus is the raft state of a node as exposed
+// on the debug endpoint and in the status dumps
+type raftStatus struct {
+	ID       uint64               `json:"id"`
+	Time     time.Time            `json:"time"`
+	Leader   uint64               `json:"leader"`
+	Applied  uint64               `json:"applied"`
+	Commit   uint64               `json:"commit"`
+	Quiesced bool                 `json:"quiesced"`
+	Status   interface{}          `json:"raft"`
+	Members  []*protonpb.NodeInfo `json:"members"`
+	Alarms   []*protonpb.Alarm    `json:"alarms"`
+}
+
+// StatusJSON returns the raft status of the node in JSON, along
+// with its members, alarms, applied indexes and quiescence. The
+// progress of every member is part of the raft status of a leader
+func (n *Node) StatusJSON() ([]byte, error) {
+	status := n.Status()
+	return json.Marshal(&raftStatus{
+		ID:       n.ID,
+		Time:     time.Now(),
+		Leader:   status.Lead,
+		Applied:  n.AppliedIndex(),
+		Commit:   atomic.LoadUint64(&n.commitIndex),
+		Quiesced: n.IsQuiesced(),
+		Status:   json.RawMessage(status.String()),
+		Members:  n.Cluster.Members(),
+		Alarms:   n.Alarms(),
+	})
+}
+
+func (n *Node) serveRaftStatus(w http.ResponseWriter, r *http.Request) {
+	data, err := n.StatusJSON()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// dumpStatus periodically writes the raft status of the node to
+// StatusDumpWriter, or to the log if there is none, so that the
+// state of the cluster before a failure can be looked into
+func (n *Node) dumpStatus() {
+	if n.StatusDumpInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(n.StatusDumpInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			data, err := n.StatusJSON()
+			if err != nil {
+				log.Println("raft: can't encode status:", err)
+				continue
+			}
+			writeStatus(n.StatusDumpWriter, data)
+		case <-n.tickStop:
+			return
+		}
+	}
+}
+
+// writeStatus writes a status dump as a line of w, or logs it
+func writeStatus(w io.Writer, data []byte) {
+	if w == nil {
+		log.Printf("raft: status %s", data)
+		return
+	}
+	_, err := w.Write(append(data, '\n'))
+	if err != nil {
+		log.Println("raft: can't write status:", err)
+	}
+}
+
+func serveGoroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
@@ -0,0 +1,72 @@
+package proton
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugHandler(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	server := httptest.NewServer(n.DebugHandler("secret"))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/raft")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusUnauthorized)
+
+	req, err := http.NewRequest("GET", server.URL+"/debug/raft", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	status := &raftStatus{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(status))
+	assert.Equal(t, status.ID, uint64(1))
+}
+
+func TestStatusJSON(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	applyPair(t, n, 3, "foo", "bar")
+	data, err := n.StatusJSON()
+	assert.NoError(t, err)
+
+	status := &raftStatus{}
+	assert.NoError(t, json.Unmarshal(data, status))
+	assert.Equal(t, status.ID, uint64(1))
+	assert.Equal(t, len(status.Members), 1)
+
+	// Every dump is written as a line
+	var buf bytes.Buffer
+	writeStatus(&buf, data)
+	writeStatus(&buf, data)
+	assert.Equal(t, bytes.Count(buf.Bytes(), []byte("\n")), 2)
+}
+
+func TestHealthHandler(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	server := httptest.NewServer(n.HealthHandler())
+	defer server.Close()
+
+	// Without a leader the node is neither a leader nor a healthy follower
+	for _, path := range []string{"/leader", "/follower"} {
+		resp, err := http.Get(server.URL + path)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
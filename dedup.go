@@ -0,0 +1,143 @@
+package proton
+
+import (
+	"crypto/sha256"
+	"log"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// blob is a value stored by content, shared by the keys holding it
+type blob struct {
+	value string
+	refs  int
+}
+
+// dedupProposal stores the value of a proposed pair by content if
+// it is larger than the dedup threshold of the node. The value is
+// left out of the proposal when the store already holds it. Values
+// are hashed as stored, so that encrypted values only match if
+// their encryption is deterministic
+func (n *Node) dedupProposal(data []byte) []byte {
+	if n.DedupThreshold <= 0 || len(data) < n.DedupThreshold {
+		return data
+	}
+
+	pair := &LogPair{}
+	err := proto.Unmarshal(data, pair)
+	if err != nil || isSystemKey(pair.Key) || pair.Delete || len(pair.Value) < n.DedupThreshold {
+		return data
+	}
+
+	digest := sha256.Sum256(pair.Value)
+	pair.Digest = digest[:]
+	if _, ok := n.blobValue(pair.Digest); ok {
+		pair.Value = nil
+	}
+
+	deduped, err := proto.Marshal(pair)
+	if err != nil {
+		return data
+	}
+	return deduped
+}
+
+// blobValue returns the value stored with a digest
+func (n *Node) blobValue(digest []byte) (string, bool) {
+	n.storeLock.RLock()
+	defer n.storeLock.RUnlock()
+
+	b, ok := n.blobs[string(digest)]
+	if !ok {
+		return "", false
+	}
+	return b.value, true
+}
+
+// resolveDigest fills in the value of a pair that only carries
+// its digest, or hashes again a value sent along with its digest
+// so that a value is never stored under another digest. It returns
+// false if the value is no longer stored, which happens when the
+// last key holding it was overwritten between the proposal and
+// its commit
+func (n *Node) resolveDigest(pair *LogPair) bool {
+	if len(pair.Value) > 0 {
+		digest := sha256.Sum256(pair.Value)
+		pair.Digest = digest[:]
+		return true
+	}
+
+	value, ok := n.blobValue(pair.Digest)
+	if !ok {
+		log.Printf("raft: ignoring write of %s, its value %x is no longer stored", pair.Key, pair.Digest)
+		return false
+	}
+	pair.Value = []byte(value)
+	return true
+}
+
+// ref makes a key hold the value stored with a digest, it returns
+// the shared value. Must be called with the store lock held
+func (n *Node) ref(key, digest, value string) string {
+	n.unref(key)
+	b, ok := n.blobs[digest]
+	if !ok {
+		b = &blob{value: value}
+		n.blobs[digest] = b
+	}
+	b.refs++
+	n.digests[key] = digest
+	return b.value
+}
+
+// unref releases the value stored by content held by a key, the
+// value is dropped once no key holds it. Must be called with the
+// store lock held
+func (n *Node) unref(key string) {
+	digest, ok := n.digests[key]
+	if !ok {
+		return
+	}
+	delete(n.digests, key)
+
+	b := n.blobs[digest]
+	b.refs--
+	if b.refs <= 0 {
+		delete(n.blobs, digest)
+	}
+}
+
+// snapshotBlobs returns the values stored by content.
+// Must be called with the store lock held
+func (n *Node) snapshotBlobs() []*Blob {
+	var blobs []*Blob
+	for digest, b := range n.blobs {
+		blobs = append(blobs, &Blob{Digest: []byte(digest), Value: []byte(b.value)})
+	}
+	return blobs
+}
+
+// restoreBlobs counts again the keys holding each value stored by
+// content once a snapshot is restored, the keys then share the
+// same value. Must be called with the store lock held
+func (n *Node) restoreBlobs() {
+	n.blobs = make(map[string]*blob)
+	for key, digest := range n.digests {
+		b, ok := n.blobs[digest]
+		if !ok {
+			b = &blob{value: n.pstore[key]}
+			n.blobs[digest] = b
+		}
+		b.refs++
+		n.pstore[key] = b.value
+	}
+}
+
+// blobsByDigest indexes the values stored by content of a snapshot
+func blobsByDigest(blobs []*Blob) map[string][]byte {
+	values := make(map[string][]byte, len(blobs))
+	for _, b := range blobs {
+		values[string(b.Digest)] = b.Value
+	}
+	return values
+}
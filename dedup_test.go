@@ -0,0 +1,76 @@
+package proton
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func dedupPair(t *testing.T, n *Node, key, value string) *LogPair {
+	data, err := proto.Marshal(&LogPair{Key: key, Value: []byte(value)})
+	assert.NoError(t, err)
+
+	pair := &LogPair{}
+	assert.NoError(t, proto.Unmarshal(n.dedupProposal(data), pair))
+	return pair
+}
+
+func TestDedup(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	n.DedupThreshold = 8
+
+	blob := "a large configuration payload"
+	digest := sha256.Sum256([]byte(blob))
+
+	// Small values are left as is
+	assert.Nil(t, dedupPair(t, n, "small", "tiny").Digest)
+
+	// The first write of a value sends it along with its digest
+	first := dedupPair(t, n, "a", blob)
+	assert.Equal(t, first.Digest, digest[:])
+	assert.Equal(t, string(first.Value), blob)
+	applyProposal(t, n, 3, first)
+
+	// The next ones only send its digest
+	second := dedupPair(t, n, "b", blob)
+	assert.Equal(t, second.Digest, digest[:])
+	assert.Empty(t, second.Value)
+	applyProposal(t, n, 4, second)
+
+	assert.Equal(t, n.Get("a"), blob)
+	assert.Equal(t, n.Get("b"), blob)
+	assert.Len(t, n.blobs, 1)
+	assert.Equal(t, n.blobs[string(digest[:])].refs, 2)
+
+	// Snapshots hold the value once
+	state := n.snapshotState()
+	assert.Len(t, state.Blobs, 1)
+	for _, pair := range state.Pairs {
+		assert.Empty(t, pair.Value)
+	}
+
+	restored := newQuotaNode(t)
+	defer restored.Stop()
+	restored.restore(state)
+	assert.Equal(t, restored.Get("a"), blob)
+	assert.Equal(t, restored.Get("b"), blob)
+	assert.Equal(t, restored.blobs[string(digest[:])].refs, 2)
+	assert.Equal(t, restored.StoreSize(), n.StoreSize())
+
+	// The value is dropped once no key holds it
+	applyPair(t, n, 5, "a", "other")
+	applyProposal(t, n, 6, &LogPair{Key: "b", Delete: true})
+	assert.Empty(t, n.blobs)
+	assert.Empty(t, n.digests)
+
+	// A digest of a value no longer stored is not written
+	applyProposal(t, n, 7, &LogPair{Key: "c", Digest: digest[:]})
+	assert.Equal(t, n.Get("c"), "")
+
+	// A value is stored under its own digest whatever it is sent with
+	applyProposal(t, n, 8, &LogPair{Key: "d", Value: []byte(blob), Digest: []byte("forged")})
+	assert.Equal(t, n.digests["d"], string(digest[:]))
+}
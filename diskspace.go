@@ -0,0 +1,112 @@
+package proton
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+const (
+	// DefaultMinFreeSpace is the free space in bytes under which
+	// the data directory of the node is considered full
+	DefaultMinFreeSpace = 256 << 20
+)
+
+var (
+	// ErrDiskFull is thrown when a file is not written because the disk would run out of space
+	ErrDiskFull = errors.New("not enough free space on disk")
+)
+
+// diskSpace tracks the free space measured on the data directory
+type diskSpace struct {
+	lock sync.RWMutex
+	free uint64
+	low  bool
+	err  error
+}
+
+// DiskSpace returns the free space in bytes last measured on
+// the data directory and whether it is under MinFreeSpace
+func (n *Node) DiskSpace() (uint64, bool, error) {
+	d := n.disk
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.free, d.low, d.err
+}
+
+// isDiskLow checks if the data directory went under MinFreeSpace
+func (n *Node) isDiskLow() bool {
+	d := n.disk
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.low
+}
+
+// checkDiskSpace measures the free space on the data directory,
+// called on every tick. Under MinFreeSpace, the node refuses the
+// writes and raises a NOSPACE alarm so that the whole cluster
+// stops accepting them before a file is left half written
+func (n *Node) checkDiskSpace() {
+	if n.DataDir == "" || n.MinFreeSpace == 0 {
+		return
+	}
+
+	free, err := freeSpace(n.DataDir)
+
+	d := n.disk
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if err != nil {
+		if d.err == nil {
+			n.Cfg.Logger.Warningf("raft: can't measure the free space of %s: %v", n.DataDir, err)
+		}
+		d.err = err
+		return
+	}
+	d.free, d.err = free, nil
+
+	low := free < n.MinFreeSpace
+	if low != d.low {
+		d.low = low
+		if low {
+			n.Cfg.Logger.Warningf("raft: %d bytes left on %s, under %d, writes are refused", free, n.DataDir, n.MinFreeSpace)
+		} else {
+			n.Cfg.Logger.Infof("raft: %d bytes left on %s, writes are accepted once the NOSPACE alarm is disarmed", free, n.DataDir)
+		}
+	}
+	// The alarm is raised again if it is disarmed while the disk is still full
+	if low {
+		n.raiseAlarm(protonpb.AlarmType_NOSPACE)
+	}
+}
+
+// ensureSpace checks that a file of a size can be written
+// to a directory without filling the disk up, a directory
+// whose free space can't be measured is given the benefit
+func ensureSpace(dir string, size uint64) error {
+	free, err := freeSpace(dir)
+	if err != nil {
+		return nil
+	}
+	if free < size {
+		return ErrDiskFull
+	}
+	return nil
+}
+
+// isNoSpaceError checks if a write failed as the disk is full
+func isNoSpaceError(err error) bool {
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *os.LinkError:
+		err = e.Err
+	case *os.SyscallError:
+		err = e.Err
+	}
+	return err == syscall.ENOSPC || err == ErrDiskFull
+}
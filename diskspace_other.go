@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package proton
+
+import "errors"
+
+// freeSpace can't measure the free space on this platform
+func freeSpace(dir string) (uint64, error) {
+	return 0, errors.New("free space can't be measured on this platform")
+}
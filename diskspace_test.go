@@ -0,0 +1,71 @@
+package proton
+
+import (
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskSpace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proton-disk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// Without a data directory nothing is measured
+	n.checkDiskSpace()
+	free, low, err := n.DiskSpace()
+	assert.NoError(t, err)
+	assert.Equal(t, free, uint64(0))
+	assert.False(t, low)
+
+	n.DataDir = dir
+	n.checkDiskSpace()
+	free, low, err = n.DiskSpace()
+	assert.NoError(t, err)
+	assert.True(t, free > 0)
+	assert.False(t, low)
+	assert.NoError(t, n.checkAlarms())
+
+	// Under the threshold the writes are refused
+	n.MinFreeSpace = ^uint64(0)
+	n.checkDiskSpace()
+	_, low, _ = n.DiskSpace()
+	assert.True(t, low)
+	assert.Equal(t, n.checkAlarms(), ErrNoSpace)
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	assert.Equal(t, n.checkQuota(pair), ErrNoSpace)
+
+	n.MinFreeSpace = 1
+	n.checkDiskSpace()
+	_, low, _ = n.DiskSpace()
+	assert.False(t, low)
+	assert.NoError(t, n.checkAlarms())
+}
+
+func TestDirSnapshotStoreFull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proton-disk")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	free, err := freeSpace(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, ensureSpace(dir, free+1), ErrDiskFull)
+	assert.NoError(t, ensureSpace(dir, 1))
+
+	store := NewDirSnapshotStore(dir)
+	_, err = store.Put(context.Background(), "snap", []byte("data"))
+	assert.NoError(t, err)
+
+	assert.True(t, isNoSpaceError(&os.PathError{Op: "write", Path: dir, Err: syscall.ENOSPC}))
+	assert.True(t, isNoSpaceError(ErrDiskFull))
+	assert.False(t, isNoSpaceError(&os.PathError{Op: "write", Path: dir, Err: syscall.EIO}))
+}
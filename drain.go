@@ -0,0 +1,167 @@
+package proton
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+)
+
+const (
+	// AuditDrain is recorded when a node starts draining before leaving the raft
+	AuditDrain = "drain"
+
+	// drainPollInterval is the time between two checks
+	// of the progress of a drain
+	drainPollInterval = 100 * time.Millisecond
+)
+
+var (
+	// ErrNoTransferee is thrown when the leader drains and no other member can take over the leadership
+	ErrNoTransferee = errors.New("no member available to take over the leadership")
+	// ErrNoLeader is thrown when the node drains and there is no leader to remove it from the raft
+	ErrNoLeader = errors.New("no leader in the raft cluster")
+)
+
+// Drain gracefully decommissions the node: it stops accepting
+// proposals, hands over the leadership once the followers caught
+// up, then leaves the raft and shuts down. The node stays in
+// read-only mode if the drain is interrupted
+func (n *Node) Drain(ctx context.Context) error {
+	n.SetReadOnly(true)
+	n.recordAudit(ctx, AuditDrain, n.ID, nil)
+
+	if n.IsLeader() {
+		err := n.waitFollowers(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = n.handOver(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := n.leave(ctx)
+	if err != nil {
+		return err
+	}
+
+	n.Shutdown()
+	return nil
+}
+
+// poll calls done until it returns true or the context is done
+func poll(ctx context.Context, done func() bool) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for !done() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// waitFollowers waits for the active followers
+// to replicate the whole log of the leader
+func (n *Node) waitFollowers(ctx context.Context) error {
+	return poll(ctx, func() bool {
+		status := n.Status()
+		self := status.Progress[n.ID]
+		for id, pr := range status.Progress {
+			if id != n.ID && pr.RecentActive && pr.Match < self.Match {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// handOver transfers the leadership to the member with the
+// highest priority, or else the most up to date one
+func (n *Node) handOver(ctx context.Context) error {
+	status := n.Status()
+
+	eligible := func(id uint64) bool {
+		pr, ok := status.Progress[id]
+		return ok && pr.RecentActive
+	}
+
+	target := n.preferredLeader(eligible)
+	if target == raft.None {
+		var match uint64
+		for id, pr := range status.Progress {
+			if id != n.ID && eligible(id) && pr.Match >= match {
+				target, match = id, pr.Match
+			}
+		}
+	}
+	if target == raft.None {
+		return ErrNoTransferee
+	}
+
+	n.recordAudit(ctx, AuditLeaderTransfer, target, nil)
+	n.TransferLeadership(ctx, n.ID, target)
+
+	return poll(ctx, func() bool {
+		lead := n.Leader()
+		return lead != raft.None && lead != n.ID
+	})
+}
+
+// leave asks the leader to remove the node from the raft and
+// waits for the removal, which a removed member may never see
+// committed in its own log
+func (n *Node) leave(ctx context.Context) error {
+	var leader *Peer
+	err := poll(ctx, func() bool {
+		peer, ok := n.Cluster.Peers()[n.Leader()]
+		leader = peer
+		return ok && peer.ID != n.ID
+	})
+	if err != nil {
+		return ErrNoLeader
+	}
+
+	resp, err := leader.Client.LeaveRaft(ctx, &protonpb.NodeInfo{ID: n.ID})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+
+	return poll(ctx, func() bool {
+		members, err := leader.Client.ListMembers(ctx, &protonpb.ListMembersRequest{})
+		if err != nil {
+			return false
+		}
+		for _, member := range members.Members {
+			if member.ID == n.ID {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// DrainNode drains a node of the raft cluster before it is decommissioned
+func (n *Node) DrainNode(ctx context.Context, req *protonpb.DrainNodeRequest) (*protonpb.DrainNodeResponse, error) {
+	err := n.Drain(ctx)
+	if err != nil {
+		return &protonpb.DrainNodeResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.DrainNodeResponse{Success: true}, nil
+}
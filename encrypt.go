@@ -0,0 +1,90 @@
+package proton
+
+import (
+	"log"
+	"sync"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+)
+
+// Encrypter encrypts the value of a key before it is proposed
+type Encrypter func(key string, value []byte) ([]byte, error)
+
+// Decrypter decrypts the value of a key read from the store
+type Decrypter func(key string, value []byte) ([]byte, error)
+
+// encryption holds the hooks encrypting the values
+type encryption struct {
+	lock    sync.RWMutex
+	encrypt Encrypter
+	decrypt Decrypter
+}
+
+// SetEncryption sets the hooks encrypting the values before they
+// are proposed and decrypting them when they are read, so that the
+// raft log, the messages and the snapshots never hold a plaintext
+// value. Keys are not encrypted as they route the entries. Every
+// member must use the same hooks
+func (n *Node) SetEncryption(encrypt Encrypter, decrypt Decrypter) {
+	n.encryption.lock.Lock()
+	n.encryption.encrypt = encrypt
+	n.encryption.decrypt = decrypt
+	n.encryption.lock.Unlock()
+}
+
+// decrypter returns the hook decrypting the values, if any
+func (n *Node) decrypter() Decrypter {
+	n.encryption.lock.RLock()
+	defer n.encryption.lock.RUnlock()
+	return n.encryption.decrypt
+}
+
+// encryptProposal encrypts the value of a proposed pair,
+// values of the reserved keyspace are left in the clear
+func (n *Node) encryptProposal(data []byte) ([]byte, error) {
+	n.encryption.lock.RLock()
+	encrypt := n.encryption.encrypt
+	n.encryption.lock.RUnlock()
+	if encrypt == nil {
+		return data, nil
+	}
+
+	pair := &LogPair{}
+	err := proto.Unmarshal(data, pair)
+	if err != nil || isSystemKey(pair.Key) {
+		return data, nil
+	}
+
+	pair.Value, err = encrypt(pair.Key, pair.Value)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(pair)
+}
+
+// decryptValue returns the plaintext of a stored value
+func (n *Node) decryptValue(key string, value []byte) []byte {
+	decrypt := n.decrypter()
+	if decrypt == nil || isSystemKey(key) {
+		return value
+	}
+
+	plaintext, err := decrypt(key, value)
+	if err != nil {
+		log.Printf("raft: can't decrypt the value of %s: %v", key, err)
+		return nil
+	}
+	return plaintext
+}
+
+// decryptPairs decrypts the values of pairs read from the store,
+// keys are the keys of the store the pairs were read from
+func (n *Node) decryptPairs(keys []string, pairs []*protonpb.Pair) {
+	if n.decrypter() == nil {
+		return
+	}
+	for i, pair := range pairs {
+		pair.Value = n.decryptValue(keys[i], pair.Value)
+	}
+}
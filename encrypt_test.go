@@ -0,0 +1,44 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// reverse is a toy cipher reversing the values
+func reverse(key string, value []byte) ([]byte, error) {
+	out := make([]byte, len(value))
+	for i, b := range value {
+		out[len(value)-1-i] = b
+	}
+	return out, nil
+}
+
+func TestEncryption(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	n.SetEncryption(reverse, reverse)
+
+	var applied []byte
+	n.apply = func(data interface{}) { applied = data.([]byte) }
+
+	data, err := EncodePair("foo", []byte("secret"))
+	assert.NoError(t, err)
+	proposed, err := n.prepareProposal(data)
+	assert.NoError(t, err)
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: 1, Term: 1, Data: proposed})
+
+	// Only the reads see the plaintext
+	assert.Equal(t, n.pstore["foo"], "terces")
+	assert.Equal(t, n.snapshotState().Pairs[0].Value, []byte("terces"))
+	assert.Equal(t, n.Get("foo"), "secret")
+	assert.Equal(t, n.ListPairs()[0].Value, []byte("secret"))
+	pair := &protonpb.Pair{}
+	assert.NoError(t, proto.Unmarshal(applied, pair))
+	assert.Equal(t, pair.Key, "foo")
+	assert.Equal(t, pair.Value, []byte("secret"))
+}
@@ -0,0 +1,241 @@
+package proton
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync/atomic"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/boltdb/bolt"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+)
+
+var (
+	// The buckets of the etcd v3 backend holding the keys and the metadata
+	etcdKeyBucket  = []byte("key")
+	etcdMetaBucket = []byte("meta")
+
+	etcdConsistentIndexKey = []byte("consistent_index")
+
+	// ErrEtcdSnapshotHash is thrown when the hash appended to an etcd snapshot does not match the database
+	ErrEtcdSnapshotHash = errors.New("etcd snapshot hash mismatch")
+)
+
+const (
+	// AuditEtcdExport is recorded when the store is exported as an etcd snapshot
+	AuditEtcdExport = "etcd-export"
+	// AuditEtcdImport is recorded when the keys of an etcd snapshot are imported
+	AuditEtcdImport = "etcd-import"
+)
+
+const (
+	// etcdRevisionSize is the size of a revision in the key bucket:
+	// the main revision, a separator and the sub revision
+	etcdRevisionSize = 17
+
+	// etcdTombstone marks the revision of a deleted key
+	etcdTombstone = 't'
+)
+
+// etcdRevision encodes a revision as a key of the etcd key bucket
+func etcdRevision(main int64) []byte {
+	b := make([]byte, etcdRevisionSize)
+	binary.BigEndian.PutUint64(b, uint64(main))
+	b[8] = '_'
+	return b
+}
+
+// ExportEtcdSnapshot writes the store in the etcd v3 snapshot
+// format, which can be restored with etcdctl snapshot restore
+func (n *Node) ExportEtcdSnapshot(ctx context.Context, w io.Writer) error {
+	err := n.exportEtcdSnapshot(w)
+	n.recordAudit(ctx, AuditEtcdExport, n.ID, err)
+	return err
+}
+
+// exportEtcdSnapshot writes the etcd v3 database of the store
+func (n *Node) exportEtcdSnapshot(w io.Writer) error {
+	f, err := ioutil.TempFile("", "proton-etcd-snapshot")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return err
+	}
+
+	pairs := n.ListPairs()
+	sort.Sort(pairsByKey(pairs))
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		keys, err := tx.CreateBucket(etcdKeyBucket)
+		if err != nil {
+			return err
+		}
+		for i, pair := range pairs {
+			rev := int64(i + 1)
+			data, err := (&mvccpb.KeyValue{
+				Key:            []byte(pair.Key),
+				Value:          pair.Value,
+				CreateRevision: rev,
+				ModRevision:    rev,
+				Version:        1,
+			}).Marshal()
+			if err != nil {
+				return err
+			}
+			err = keys.Put(etcdRevision(rev), data)
+			if err != nil {
+				return err
+			}
+		}
+
+		meta, err := tx.CreateBucket(etcdMetaBucket)
+		if err != nil {
+			return err
+		}
+		index := make([]byte, 8)
+		binary.BigEndian.PutUint64(index, atomic.LoadUint64(&n.appliedIndex))
+		return meta.Put(etcdConsistentIndexKey, index)
+	})
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	err = db.Close()
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// etcd appends the hash of the database to its snapshots
+	hash := sha256.Sum256(data)
+	_, err = w.Write(append(data, hash[:]...))
+	return err
+}
+
+// ReadEtcdSnapshot reads the latest value of every key
+// of a snapshot in the etcd v3 format
+func ReadEtcdSnapshot(r io.Reader) ([]*protonpb.Pair, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snapshots saved by etcd end with the hash of the database
+	if len(data)%512 == sha256.Size {
+		db := data[:len(data)-sha256.Size]
+		hash := sha256.Sum256(db)
+		if !bytes.Equal(hash[:], data[len(db):]) {
+			return nil, ErrEtcdSnapshotHash
+		}
+		data = db
+	}
+
+	f, err := ioutil.TempFile("", "proton-etcd-snapshot")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(f.Name(), 0400, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	values := make(map[string][]byte)
+	err = db.View(func(tx *bolt.Tx) error {
+		keys := tx.Bucket(etcdKeyBucket)
+		if keys == nil {
+			return nil
+		}
+
+		// Revisions are sorted, the last one of a key holds its value
+		return keys.ForEach(func(rev, data []byte) error {
+			kv := &mvccpb.KeyValue{}
+			err := kv.Unmarshal(data)
+			if err != nil {
+				return err
+			}
+			if len(rev) > etcdRevisionSize && rev[etcdRevisionSize] == etcdTombstone {
+				delete(values, string(kv.Key))
+				return nil
+			}
+			values[string(kv.Key)] = kv.Value
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []*protonpb.Pair
+	for k, v := range values {
+		pairs = append(pairs, &protonpb.Pair{Key: k, Value: v})
+	}
+	sort.Sort(pairsByKey(pairs))
+	return pairs, nil
+}
+
+// ImportEtcdSnapshot proposes every key of a snapshot in
+// the etcd v3 format, keys of the reserved keyspace of
+// the cluster are skipped
+func (n *Node) ImportEtcdSnapshot(ctx context.Context, r io.Reader) error {
+	err := n.importEtcdSnapshot(ctx, r)
+	n.recordAudit(ctx, AuditEtcdImport, n.ID, err)
+	return err
+}
+
+// importEtcdSnapshot proposes the keys of an etcd v3 snapshot
+func (n *Node) importEtcdSnapshot(ctx context.Context, r io.Reader) error {
+	pairs, err := ReadEtcdSnapshot(r)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		if isSystemKey(pair.Key) {
+			continue
+		}
+		data, err := EncodePair(pair.Key, pair.Value)
+		if err != nil {
+			return err
+		}
+		err = n.Propose(ctx, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pairsByKey sorts pairs by key
+type pairsByKey []*protonpb.Pair
+
+func (p pairsByKey) Len() int           { return len(p) }
+func (p pairsByKey) Less(i, j int) bool { return p[i].Key < p[j].Key }
+func (p pairsByKey) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
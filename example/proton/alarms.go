@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func alarms(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.ListAlarms(context.TODO(), &protonpb.ListAlarmsRequest{})
+	if err != nil {
+		log.Fatal("Can't list alarms in the cluster")
+	}
+
+	fmt.Println("Alarms:")
+
+	for _, alarm := range resp.Alarms {
+		fmt.Println(":", alarm.Member, ":", alarm.Type)
+	}
+}
+
+func disarm(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	member, err := strconv.ParseUint(c.String("member"), 10, 64)
+	if err != nil {
+		log.Fatal("member flag must be a valid member id")
+	}
+
+	t, ok := protonpb.AlarmType_value[c.String("alarm")]
+	if !ok {
+		log.Fatal("alarm flag must be a valid alarm type")
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.DisarmAlarm(context.TODO(), &protonpb.Alarm{Member: member, Type: protonpb.AlarmType(t)})
+	if err != nil || !resp.Success {
+		log.Fatal("Can't disarm alarm in the cluster")
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func audit(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.ListAuditEvents(context.TODO(), &protonpb.ListAuditEventsRequest{Limit: uint64(c.Int("limit"))})
+	if err != nil {
+		log.Fatal("Can't list audit events in the cluster")
+	}
+
+	fmt.Println("Events:")
+
+	for _, e := range resp.Events {
+		result := "ok"
+		if !e.Success {
+			result = "failed: " + e.Error
+		}
+		fmt.Println(":", time.Unix(0, e.Timestamp).Format(time.RFC3339), ":", e.Action, e.Target, "by", e.Initiator, ":", result)
+	}
+}
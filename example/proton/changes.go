@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func changes(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	req := &protonpb.StreamChangesRequest{
+		Prefix:      c.String("prefix"),
+		Glob:        c.String("glob"),
+		ChangedOnly: c.Bool("changed-only"),
+		Resumable:   c.Bool("resumable"),
+	}
+	if c.IsSet("type") {
+		t, ok := protonpb.ChangeType_value[strings.ToUpper(c.String("type"))]
+		if !ok {
+			log.Fatal("unknown change type: ", c.String("type"))
+		}
+		req.Types = append(req.Types, protonpb.ChangeType(t))
+	}
+
+	if c.IsSet("batch-size") || c.IsSet("batch-window") {
+		req.BatchSize = uint64(c.Int("batch-size"))
+		req.BatchWindow = int64(c.Duration("batch-window"))
+		batches(client, req)
+		return
+	}
+
+	stream, err := client.StreamChanges(context.TODO(), req)
+	if err != nil {
+		log.Fatal("Can't stream the changes of the cluster")
+	}
+
+	for {
+		change, err := stream.Recv()
+		if err != nil {
+			log.Fatal("Stream of changes interrupted: ", err)
+		}
+		printChange(change)
+	}
+}
+
+func batches(client *proton.Raft, req *protonpb.StreamChangesRequest) {
+	stream, err := client.StreamChangeBatches(context.TODO(), req)
+	if err != nil {
+		log.Fatal("Can't stream the changes of the cluster")
+	}
+
+	for {
+		batch, err := stream.Recv()
+		if err != nil {
+			log.Fatal("Stream of changes interrupted: ", err)
+		}
+		fmt.Printf("batch of %d changes\n", len(batch.Changes))
+		for _, change := range batch.Changes {
+			printChange(change)
+		}
+	}
+}
+
+func printChange(change *protonpb.Change) {
+	if change.Lagged {
+		fmt.Printf("fell behind after index %d, the stream has to be resumed\n", change.Index)
+		return
+	}
+	fmt.Printf("[%d/%d] rev %d: %v = %v\n", change.Term, change.Index, change.Revision, change.Pair.Key, string(change.Pair.Value))
+}
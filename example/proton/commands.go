@@ -7,32 +7,165 @@ var (
 		{
 			Name:   "init",
 			Usage:  "Initialize a single machine raft cluster",
-			Flags:  []cli.Flag{flHosts, flReplication, flHostname, flWithRaftLogs},
+			Flags:  []cli.Flag{flHosts, flReplication, flHostname, flWithRaftLogs, flPriority, flDebugAddr, flDebugToken, flHealthAddr},
 			Action: initcluster,
 		},
 		{
 			Name:   "join",
 			Usage:  "Join an existing raft cluster",
-			Flags:  []cli.Flag{flJoin, flHosts, flHostname, flWithRaftLogs},
+			Flags:  []cli.Flag{flJoin, flHosts, flHostname, flWithRaftLogs, flPriority, flDebugAddr, flDebugToken, flHealthAddr, flJoinToken},
 			Action: join,
 		},
+		{
+			Name:   "standby",
+			Usage:  "Follow an existing raft cluster as a standby, without being a member",
+			Flags:  []cli.Flag{flJoin, flHosts, flHostname, flWithRaftLogs, flPriority, flDebugAddr, flDebugToken, flHealthAddr},
+			Action: standby,
+		},
+		{
+			Name:   "promote",
+			Usage:  "Promote a standby to a member of the raft cluster it follows",
+			Flags:  []cli.Flag{flHosts, flTimeout},
+			Action: promote,
+		},
 		{
 			Name:   "put",
 			Usage:  "Put a value on the raft store",
-			Flags:  []cli.Flag{flHosts, flKey, flValue},
+			Flags:  []cli.Flag{flHosts, flKey, flValue, flNamespace, flTTL},
 			Action: put,
 		},
+		{
+			Name:   "register",
+			Usage:  "Put a key deleted once this command stops, to register a service",
+			Flags:  []cli.Flag{flHosts, flKey, flValue, flNamespace, flSessionTTL},
+			Action: register,
+		},
+		{
+			Name:      "get",
+			Usage:     "Get the values of keys in the raft store",
+			ArgsUsage: "KEY...",
+			Flags:     []cli.Flag{flHosts, flNamespace, flLinearizable},
+			Action:    get,
+		},
 		{
 			Name:   "list",
 			Usage:  "List values in the raft store",
-			Flags:  []cli.Flag{flHosts},
+			Flags:  []cli.Flag{flHosts, flNamespace, flKeysOnly, flCount, flPageSize},
 			Action: list,
 		},
+		{
+			Name:   "export",
+			Usage:  "Export the keys of the raft store as JSON lines or CSV",
+			Flags:  []cli.Flag{flHosts, flNamespace, flPrefix, flFormat, flFile, flPageSize},
+			Action: export,
+		},
+		{
+			Name:   "import",
+			Usage:  "Import keys exported as JSON lines or CSV into the raft store",
+			Flags:  []cli.Flag{flHosts, flNamespace, flPrefix, flFormat, flFile, flBulk},
+			Action: importKeys,
+		},
+		{
+			Name:   "changes",
+			Usage:  "Stream the changes applied on a node",
+			Flags:  []cli.Flag{flHosts, flPrefix, flGlob, flType, flChangedOnly, flBatchSize, flBatchWindow, flResumable},
+			Action: changes,
+		},
+		{
+			Name:   "mirror",
+			Usage:  "Mirror the changes of a cluster into another one",
+			Flags:  []cli.Flag{flHosts, flDestination, flName, flDestinationName, flPrefix, flDestinationPrefix},
+			Action: mirror,
+		},
 		{
 			Name:   "members",
 			Usage:  "List the members of the raft cluster",
 			Flags:  []cli.Flag{flHosts},
 			Action: members,
 		},
+		{
+			Name:   "status",
+			Usage:  "Show the raft status of a node, with the progress of every member on the leader",
+			Flags:  []cli.Flag{flHosts},
+			Action: status,
+		},
+		{
+			Name:   "update-member",
+			Usage:  "Update the address of a member that came back with a new one",
+			Flags:  []cli.Flag{flHosts, flMember, flAddr},
+			Action: updateMember,
+		},
+		{
+			Name:   "label-member",
+			Usage:  "Replace the labels of a member, without --label they are removed",
+			Flags:  []cli.Flag{flHosts, flMember, flLabel},
+			Action: labelMember,
+		},
+		{
+			Name:   "audit",
+			Usage:  "List the administrative actions recorded in the raft cluster",
+			Flags:  []cli.Flag{flHosts, flLimit},
+			Action: audit,
+		},
+		{
+			Name:   "alarms",
+			Usage:  "List the alarms active in the raft cluster",
+			Flags:  []cli.Flag{flHosts},
+			Action: alarms,
+		},
+		{
+			Name:   "disarm",
+			Usage:  "Disarm an alarm once its cause is fixed",
+			Flags:  []cli.Flag{flHosts, flMember, flAlarm},
+			Action: disarm,
+		},
+		{
+			Name:   "rate-limits",
+			Usage:  "List the write rate limits of the clients of the raft cluster",
+			Flags:  []cli.Flag{flHosts},
+			Action: rateLimits,
+		},
+		{
+			Name:   "rate-limit",
+			Usage:  "Limit the write rate of a client, a rate of 0 removes the limit",
+			Flags:  []cli.Flag{flHosts, flIdentity, flRate, flBurst},
+			Action: rateLimit,
+		},
+		{
+			Name:   "readonly",
+			Usage:  "Put a node in read-only mode, or take it out with --off",
+			Flags:  []cli.Flag{flHosts, flOff},
+			Action: readonly,
+		},
+		{
+			Name:   "drain",
+			Usage:  "Drain a node, hand over its leadership and remove it from the raft",
+			Flags:  []cli.Flag{flHosts, flTimeout},
+			Action: drain,
+		},
+		{
+			Name:   "force-new-cluster",
+			Usage:  "Make a node the single member of a new cluster after the quorum is lost",
+			Flags:  []cli.Flag{flHosts, flDryRun, flTimeout},
+			Action: forceNewCluster,
+		},
+		{
+			Name:   "log-level",
+			Usage:  "Change the log level of a node and trace its raft messages with --trace",
+			Flags:  []cli.Flag{flHosts, flLevel, flTrace, flTracePeer, flTraceType},
+			Action: logLevel,
+		},
+		{
+			Name:  "token",
+			Usage: "Manage the tokens the nodes join the cluster with",
+			Subcommands: []cli.Command{
+				{
+					Name:   "create",
+					Usage:  "Create a single use join token on the leader",
+					Flags:  []cli.Flag{flHosts, flTokenTTL},
+					Action: createToken,
+				},
+			},
+		},
 	}
 )
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func drain(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	ctx := context.TODO()
+	if timeout := c.Duration("timeout"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	resp, err := client.DrainNode(ctx, &protonpb.DrainNodeRequest{})
+	if err != nil {
+		log.Fatal("Can't drain the node: ", err)
+	}
+	if !resp.Success {
+		log.Fatal("Can't drain the node: ", resp.Error)
+	}
+
+	log.Println("Node drained and removed from the raft")
+}
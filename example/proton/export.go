@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+)
+
+// record is a key and its value as exported, a value
+// that is not valid UTF-8 is encoded in base64
+type record struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+var csvHeader = []string{"key", "value", "encoding"}
+
+func newRecord(pair *protonpb.Pair) record {
+	if utf8.Valid(pair.Value) {
+		return record{Key: pair.Key, Value: string(pair.Value)}
+	}
+	return record{Key: pair.Key, Value: base64.StdEncoding.EncodeToString(pair.Value), Encoding: "base64"}
+}
+
+// pair decodes the value of the record
+func (r record) pair() (*protonpb.Pair, error) {
+	switch r.Encoding {
+	case "":
+		return &protonpb.Pair{Key: r.Key, Value: []byte(r.Value)}, nil
+	case "base64":
+		value, err := base64.StdEncoding.DecodeString(r.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &protonpb.Pair{Key: r.Key, Value: value}, nil
+	}
+	return nil, fmt.Errorf("unknown encoding %q", r.Encoding)
+}
+
+func export(c *cli.Context) {
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	format := c.String("format")
+	if format != "json" && format != "csv" {
+		log.Fatal("unknown format: ", format)
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	out := os.Stdout
+	if file := c.String("file"); file != "-" {
+		out, err = os.Create(file)
+		if err != nil {
+			log.Fatal("Can't create the export file: ", err)
+		}
+	}
+	w := bufio.NewWriter(out)
+
+	var encode func(record) error
+	if format == "csv" {
+		cw := csv.NewWriter(w)
+		cw.Write(csvHeader)
+		encode = func(r record) error {
+			cw.Write([]string{r.Key, r.Value, r.Encoding})
+			cw.Flush()
+			return cw.Error()
+		}
+	} else {
+		enc := json.NewEncoder(w)
+		encode = func(r record) error { return enc.Encode(r) }
+	}
+
+	req := &protonpb.ListObjectsRequest{
+		Namespace: c.String("namespace"),
+		Limit:     uint64(c.Int("page-size")),
+	}
+	prefix := c.String("prefix")
+
+	count := 0
+	for {
+		resp, err := client.ListObjects(context.TODO(), req)
+		if err != nil {
+			log.Fatal("Can't list objects in the cluster")
+		}
+
+		for _, obj := range resp.Objects {
+			if !strings.HasPrefix(obj.Key, prefix) {
+				continue
+			}
+			err = encode(newRecord(obj))
+			if err != nil {
+				log.Fatal("Can't write the export: ", err)
+			}
+			count++
+		}
+		if resp.NextToken == "" {
+			break
+		}
+		req.Token = resp.NextToken
+	}
+
+	err = w.Flush()
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		log.Fatal("Can't write the export: ", err)
+	}
+	fmt.Fprintln(os.Stderr, "Exported", count, "keys")
+}
+
+func importKeys(c *cli.Context) {
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	format := c.String("format")
+	if format != "json" && format != "csv" {
+		log.Fatal("unknown format: ", format)
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	in := os.Stdin
+	if file := c.String("file"); file != "-" {
+		in, err = os.Open(file)
+		if err != nil {
+			log.Fatal("Can't open the import file: ", err)
+		}
+		defer in.Close()
+	}
+
+	var decode func() (record, error)
+	if format == "csv" {
+		cr := csv.NewReader(bufio.NewReader(in))
+		cr.FieldsPerRecord = -1
+		header, err := cr.Read()
+		if err != nil || len(header) < 2 || header[0] != csvHeader[0] || header[1] != csvHeader[1] {
+			log.Fatal("Can't read the import: expected a key,value header")
+		}
+		decode = func() (record, error) {
+			fields, err := cr.Read()
+			if err != nil {
+				return record{}, err
+			}
+			if len(fields) < 2 {
+				return record{}, fmt.Errorf("expected a key and a value, got %d fields", len(fields))
+			}
+			r := record{Key: fields[0], Value: fields[1]}
+			if len(fields) > 2 {
+				r.Encoding = fields[2]
+			}
+			return r, nil
+		}
+	} else {
+		dec := json.NewDecoder(bufio.NewReader(in))
+		decode = func() (record, error) {
+			var r record
+			err := dec.Decode(&r)
+			return r, err
+		}
+	}
+
+	namespace := c.String("namespace")
+	put := func(pair *protonpb.Pair) {
+		req := &protonpb.PutObjectRequest{
+			Object:    pair,
+			Namespace: namespace,
+		}
+		resp, err := client.PutObject(context.TODO(), req)
+		if resp == nil || err != nil {
+			log.Fatal("Can't put object in the cluster")
+		}
+		if !resp.Success {
+			log.Fatalf("Can't put %s in the cluster: %s", pair.Key, resp.Error)
+		}
+	}
+	finish := func() {}
+	if c.Bool("bulk") {
+		put, finish = bulkLoad(client, namespace)
+	}
+
+	prefix := c.String("prefix")
+
+	count := 0
+	for {
+		r, err := decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal("Can't read the import: ", err)
+		}
+		if !strings.HasPrefix(r.Key, prefix) {
+			continue
+		}
+
+		pair, err := r.pair()
+		if err != nil {
+			log.Fatalf("Can't decode the value of %s: %v", r.Key, err)
+		}
+		put(pair)
+		count++
+	}
+	finish()
+
+	fmt.Println("Imported", count, "keys")
+}
+
+// bulkRequestSize is the number of pairs sent per bulk load request
+const bulkRequestSize = 1000
+
+// bulkLoad streams the pairs to the leader in a bulk load, which
+// needs the keys in ascending order as exported. The progress is
+// printed as the batches are applied
+func bulkLoad(client *proton.Raft, namespace string) (func(*protonpb.Pair), func()) {
+	stream, err := client.BulkLoad(context.TODO())
+	if err != nil {
+		log.Fatal("Can't start the bulk load: ", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for {
+			progress, err := stream.Recv()
+			if err != nil {
+				done <- err
+				return
+			}
+			fmt.Fprintln(os.Stderr, "Loaded", progress.Keys, "keys up to", progress.LastKey)
+		}
+	}()
+
+	req := &protonpb.BulkLoadRequest{Namespace: namespace}
+	send := func() {
+		err := stream.Send(req)
+		if err != nil {
+			log.Fatal("Can't send the bulk load: ", <-done)
+		}
+		req = &protonpb.BulkLoadRequest{}
+	}
+
+	put := func(pair *protonpb.Pair) {
+		req.Pairs = append(req.Pairs, pair)
+		if len(req.Pairs) >= bulkRequestSize {
+			send()
+		}
+	}
+	finish := func() {
+		send()
+		stream.CloseSend()
+		if err := <-done; err != io.EOF {
+			log.Fatal("Bulk load interrupted: ", err)
+		}
+	}
+	return put, finish
+}
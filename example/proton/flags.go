@@ -1,6 +1,10 @@
 package main
 
-import "github.com/codegangsta/cli"
+import (
+	"time"
+
+	"github.com/codegangsta/cli"
+)
 
 var (
 	flHostsValue = cli.StringSlice([]string{"127.0.0.1:6744"})
@@ -8,7 +12,7 @@ var (
 	flHosts = cli.StringSliceFlag{
 		Name:   "host, H",
 		Value:  &flHostsValue,
-		Usage:  "ip/socket to listen on",
+		Usage:  "ip/socket to listen on, the first one is advertised to the members",
 		EnvVar: "PROTON_HOST",
 	}
 
@@ -36,12 +40,42 @@ var (
 		EnvVar: "PROTON_JOIN",
 	}
 
+	flJoinToken = cli.StringFlag{
+		Name:   "token",
+		Usage:  "join token created on the leader with token create",
+		EnvVar: "PROTON_JOIN_TOKEN",
+	}
+
 	flHostname = cli.StringFlag{
 		Name:   "hostname",
 		Usage:  "hostname of the raft node",
 		EnvVar: "PROTON_HOSTNAME",
 	}
 
+	flPriority = cli.IntFlag{
+		Name:   "priority",
+		Usage:  "election priority, the healthy node with the highest priority is the leader",
+		EnvVar: "PROTON_PRIORITY",
+	}
+
+	flDebugAddr = cli.StringFlag{
+		Name:   "debug-addr",
+		Usage:  "ip/socket to serve the debug endpoints on (pprof, expvar, raft status), disabled if empty",
+		EnvVar: "PROTON_DEBUG_ADDR",
+	}
+
+	flDebugToken = cli.StringFlag{
+		Name:   "debug-token",
+		Usage:  "bearer token required to access the debug endpoints",
+		EnvVar: "PROTON_DEBUG_TOKEN",
+	}
+
+	flHealthAddr = cli.StringFlag{
+		Name:   "health-addr",
+		Usage:  "ip/socket to serve the leader and follower checks of load balancers on, disabled if empty",
+		EnvVar: "PROTON_HEALTH_ADDR",
+	}
+
 	flKey = cli.StringFlag{
 		Name:  "key",
 		Usage: "key to put in the store",
@@ -51,4 +85,201 @@ var (
 		Name:  "value",
 		Usage: "value to put in the store",
 	}
+
+	flNamespace = cli.StringFlag{
+		Name:   "namespace, n",
+		Usage:  "namespace of the keys",
+		EnvVar: "PROTON_NAMESPACE",
+	}
+
+	flPrefix = cli.StringFlag{
+		Name:  "prefix",
+		Usage: "only show the keys starting with the prefix",
+	}
+
+	flDestination = cli.StringFlag{
+		Name:  "destination",
+		Usage: "ip/socket of a member of the destination cluster",
+	}
+
+	flName = cli.StringFlag{
+		Name:  "name",
+		Usage: "name of the source cluster, recorded as the origin of mirrored values",
+	}
+
+	flDestinationName = cli.StringFlag{
+		Name:  "destination-name",
+		Usage: "name of the destination cluster, its values are not mirrored back",
+	}
+
+	flDestinationPrefix = cli.StringFlag{
+		Name:  "destination-prefix",
+		Usage: "prefix replacing the source prefix in the mirrored keys",
+	}
+
+	flMember = cli.StringFlag{
+		Name:  "member",
+		Usage: "id of the raft member",
+	}
+
+	flAddr = cli.StringFlag{
+		Name:  "addr",
+		Usage: "address of the raft member",
+	}
+
+	flAlarm = cli.StringFlag{
+		Name:  "alarm",
+		Usage: "type of the alarm (NOSPACE, CORRUPT)",
+	}
+
+	flLabel = cli.StringSliceFlag{
+		Name:  "label",
+		Value: &cli.StringSlice{},
+		Usage: "label of the member, as key=value",
+	}
+
+	flLinearizable = cli.BoolFlag{
+		Name:  "linearizable",
+		Usage: "wait for the writes committed before the read to be applied",
+	}
+
+	flOff = cli.BoolFlag{
+		Name:  "off",
+		Usage: "leave the read-only mode",
+	}
+
+	flGlob = cli.StringFlag{
+		Name:  "glob",
+		Usage: "only stream the keys matching this pattern",
+	}
+
+	flType = cli.StringFlag{
+		Name:  "type",
+		Usage: "only stream the changes of this type: create or update",
+	}
+
+	flChangedOnly = cli.BoolFlag{
+		Name:  "changed-only",
+		Usage: "skip the writes leaving the value as it was",
+	}
+
+	flBatchSize = cli.IntFlag{
+		Name:  "batch-size",
+		Usage: "receive the changes in batches of at most this size",
+	}
+
+	flBatchWindow = cli.DurationFlag{
+		Name:  "batch-window",
+		Usage: "time to wait for more changes before a batch is sent",
+	}
+
+	flResumable = cli.BoolFlag{
+		Name:  "resumable",
+		Usage: "end the stream with the last index received instead of an error when falling behind",
+	}
+
+	flTTL = cli.DurationFlag{
+		Name:  "ttl",
+		Usage: "time after which the key is deleted",
+	}
+
+	flSessionTTL = cli.DurationFlag{
+		Name:  "ttl",
+		Value: 10 * time.Second,
+		Usage: "time without keepalive after which the key is deleted",
+	}
+
+	flKeysOnly = cli.BoolFlag{
+		Name:  "keys-only",
+		Usage: "only list the keys, without their values",
+	}
+
+	flCount = cli.BoolFlag{
+		Name:  "count",
+		Usage: "only print the number of keys",
+	}
+
+	flFormat = cli.StringFlag{
+		Name:  "format",
+		Usage: "format of the keys exported or imported (options: json, csv)",
+		Value: "json",
+	}
+
+	flFile = cli.StringFlag{
+		Name:  "file, f",
+		Usage: "file the keys are exported to or imported from, - for the standard output or input",
+		Value: "-",
+	}
+
+	flBulk = cli.BoolFlag{
+		Name:  "bulk",
+		Usage: "load the keys in large batches through the leader, they must be in ascending order",
+	}
+
+	flPageSize = cli.IntFlag{
+		Name:  "page-size",
+		Usage: "number of keys fetched per request, 0 fetches all the keys at once",
+		Value: 1000,
+	}
+
+	flDryRun = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "only print the changes that would be made",
+	}
+
+	flTokenTTL = cli.DurationFlag{
+		Name:  "ttl",
+		Value: 24 * time.Hour,
+		Usage: "time the token can be used for",
+	}
+
+	flTimeout = cli.DurationFlag{
+		Name:  "timeout",
+		Value: time.Minute,
+		Usage: "maximum time to wait for the operation",
+	}
+
+	flLimit = cli.IntFlag{
+		Name:  "limit",
+		Usage: "maximum number of entries to display",
+	}
+
+	flIdentity = cli.StringFlag{
+		Name:  "identity",
+		Usage: "certificate common name or host of the client, * for every client without its own limit",
+	}
+
+	flRate = cli.Float64Flag{
+		Name:  "rate",
+		Usage: "writes per second allowed to the client",
+	}
+
+	flBurst = cli.IntFlag{
+		Name:  "burst",
+		Value: 1,
+		Usage: "writes the client can make at once",
+	}
+
+	flLevel = cli.StringFlag{
+		Name:  "level",
+		Value: "INFO",
+		Usage: "level under which the messages are dropped (DEBUG, INFO, WARNING, ERROR)",
+	}
+
+	flTrace = cli.BoolFlag{
+		Name:  "trace",
+		Usage: "log every raft message sent and received",
+	}
+
+	flTracePeer = cli.StringSliceFlag{
+		Name:  "trace-peer",
+		Value: &cli.StringSlice{},
+		Usage: "only trace the messages exchanged with this member id",
+	}
+
+	flTraceType = cli.StringSliceFlag{
+		Name:  "trace-type",
+		Value: &cli.StringSlice{},
+		Usage: "only trace the messages of this type (MsgVote, MsgSnap...)",
+	}
 )
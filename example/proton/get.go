@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+)
+
+func get(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	if len(c.Args()) == 0 {
+		log.Fatal("get requires at least one key")
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.GetObjects(context.TODO(), &protonpb.GetObjectsRequest{
+		Keys:         c.Args(),
+		Namespace:    c.String("namespace"),
+		Linearizable: c.Bool("linearizable"),
+	})
+	if err != nil {
+		log.Fatal("Can't get objects in the cluster")
+	}
+
+	for _, result := range resp.Results {
+		if !result.Found {
+			fmt.Println(":", result.Key, ": not found")
+			continue
+		}
+		fmt.Println(":", result.Key, ":", string(result.Value))
+	}
+}
@@ -4,12 +4,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
-	"net"
 	"strconv"
 	"time"
 
-	"google.golang.org/grpc"
-
 	"github.com/abronan/proton"
 	"github.com/codegangsta/cli"
 	"github.com/coreos/etcd/raft"
@@ -21,11 +18,11 @@ func initcluster(c *cli.Context) {
 		hosts = hosts[1:]
 	}
 
-	lis, err := net.Listen("tcp", hosts[0])
+	listeners, err := proton.Listen(hosts...)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	server := grpc.NewServer()
+	server := proton.NewServer()
 
 	hostname := c.String("hostname")
 
@@ -42,6 +39,7 @@ func initcluster(c *cli.Context) {
 	if err != nil {
 		log.Fatal("Can't initialize raft node")
 	}
+	node.SetPriority(uint64(c.Int("priority")))
 
 	node.Campaign(node.Ctx)
 	go node.Start()
@@ -49,7 +47,11 @@ func initcluster(c *cli.Context) {
 	log.Println("Starting raft transport layer..")
 	proton.Register(server, node)
 
-	go server.Serve(lis)
+	for _, lis := range listeners {
+		go server.Serve(lis)
+	}
+	serveDebug(c, node)
+	serveHealth(c, node)
 
 	ticker := time.NewTicker(time.Second * 10)
 	go func() {
@@ -4,11 +4,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
-	"net"
 	"time"
 
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
 
 	"github.com/abronan/proton"
 	"github.com/codegangsta/cli"
@@ -25,11 +23,11 @@ func join(c *cli.Context) {
 		hosts = hosts[1:]
 	}
 
-	lis, err := net.Listen("tcp", hosts[0])
+	listeners, err := proton.Listen(hosts...)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	server := grpc.NewServer()
+	server := proton.NewServer()
 
 	joinAddr := c.String("join")
 	hostname := c.String("hostname")
@@ -42,10 +40,11 @@ func join(c *cli.Context) {
 	cfg := proton.DefaultNodeConfig()
 	cfg.Logger = raftLogger
 
-	node, err := proton.NewNode(id, hosts[0], cfg, handler)
+	node, err := proton.NewJoinNode(id, hosts[0], cfg, handler)
 	if err != nil {
 		log.Fatal("Can't initialize raft node")
 	}
+	node.SetPriority(uint64(c.Int("priority")))
 
 	proton.Register(server, node)
 
@@ -56,19 +55,30 @@ func join(c *cli.Context) {
 
 	// Start raft
 	go node.Start()
-	go server.Serve(lis)
-
-	info := &proton.NodeInfo{
-		ID:   id,
-		Addr: hosts[0],
+	for _, lis := range listeners {
+		go server.Serve(lis)
+	}
+	serveDebug(c, node)
+	serveHealth(c, node)
+
+	info := node.Info()
+	if c.String("token") != "" {
+		_, err = proton.ParseJoinToken(c.String("token"))
+		if err != nil {
+			log.Fatalf("could not join: %v", err)
+		}
+		info.Token = c.String("token")
 	}
 
 	resp, err := client.JoinRaft(context.Background(), info)
 	if err != nil {
 		log.Fatalf("could not join: %v", err)
 	}
+	if !resp.Success {
+		log.Fatalf("could not join: %s", resp.Error)
+	}
 
-	err = node.RegisterNodes(resp.GetNodes())
+	err = node.RegisterNodes(node.Ctx, resp.GetNodes())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -8,6 +8,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
 	"github.com/codegangsta/cli"
 )
 
@@ -26,14 +27,41 @@ func list(c *cli.Context) {
 		log.Fatal("couldn't initialize client connection")
 	}
 
-	resp, err := client.ListObjects(context.TODO(), &proton.ListObjectsRequest{})
+	req := &protonpb.ListObjectsRequest{
+		Namespace: c.String("namespace"),
+		KeysOnly:  c.Bool("keys-only"),
+		CountOnly: c.Bool("count"),
+		Limit:     uint64(c.Int("page-size")),
+	}
+
+	resp, err := client.ListObjects(context.TODO(), req)
 	if err != nil {
 		log.Fatal("Can't list objects in the cluster")
 	}
 
+	if c.Bool("count") {
+		fmt.Println("Count:", resp.Count)
+		return
+	}
+
 	fmt.Println("Keys:")
 
-	for _, obj := range resp.Objects {
-		fmt.Println(":", obj.Key, ":", string(obj.Value))
+	for {
+		for _, obj := range resp.Objects {
+			if c.Bool("keys-only") {
+				fmt.Println(":", obj.Key)
+				continue
+			}
+			fmt.Println(":", obj.Key, ":", string(obj.Value))
+		}
+		if resp.NextToken == "" {
+			return
+		}
+
+		req.Token = resp.NextToken
+		resp, err = client.ListObjects(context.TODO(), req)
+		if err != nil {
+			log.Fatal("Can't list objects in the cluster")
+		}
 	}
 }
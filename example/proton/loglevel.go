@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func logLevel(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	level, ok := protonpb.LogLevel_value[strings.ToUpper(c.String("level"))]
+	if !ok {
+		log.Fatal("level flag must be a valid log level")
+	}
+
+	var peers []uint64
+	for _, p := range c.StringSlice("trace-peer") {
+		id, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			log.Fatal("trace-peer flag must be a valid member id")
+		}
+		peers = append(peers, id)
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.ChangeLogLevel(context.TODO(), &protonpb.ChangeLogLevelRequest{
+		Level:      protonpb.LogLevel(level),
+		Trace:      c.Bool("trace"),
+		TracePeers: peers,
+		TraceTypes: c.StringSlice("trace-type"),
+	})
+	if err != nil || !resp.Success {
+		log.Fatal("Can't change the log level of the node")
+	}
+}
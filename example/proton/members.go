@@ -3,9 +3,13 @@ package main
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
 	"github.com/codegangsta/cli"
 	"golang.org/x/net/context"
 )
@@ -25,14 +29,118 @@ func members(c *cli.Context) {
 		log.Fatal("couldn't initialize client connection")
 	}
 
-	resp, err := client.ListMembers(context.TODO(), &proton.ListMembersRequest{})
+	resp, err := client.ListMembers(context.TODO(), &protonpb.ListMembersRequest{})
 	if err != nil {
 		log.Fatal("Can't list members in the cluster")
 	}
 
 	fmt.Println("Nodes:")
 
-	for _, node := range resp.Members {
-		fmt.Println(":", node.ID)
+	// Older members only list the information of the nodes
+	if len(resp.Statuses) == 0 {
+		for _, node := range resp.Members {
+			fmt.Println(":", node.ID)
+		}
+		return
 	}
+
+	for _, status := range resp.Statuses {
+		node := status.Info
+		role := strings.ToLower(status.Role.String())
+		if status.Leader {
+			role += " (leader)"
+		}
+		liveness := "never contacted"
+		if status.LastContact != 0 {
+			liveness = "last contact " + time.Since(time.Unix(0, status.LastContact)).Truncate(time.Millisecond).String() + " ago"
+		}
+		active := "inactive"
+		if status.Active {
+			active = "active"
+		}
+		version := node.Version
+		if version == "" {
+			version = "unknown"
+		}
+		var labels []string
+		for k, v := range node.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		fmt.Println(":", node.ID, ":", node.Addr, ":", role, ":", active, ":", liveness, ": version", version, ": labels", strings.Join(labels, ","))
+	}
+}
+
+func updateMember(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	member, err := strconv.ParseUint(c.String("member"), 10, 64)
+	if err != nil {
+		log.Fatal("member flag must be a valid member id")
+	}
+	if c.String("addr") == "" {
+		log.Fatal("addr flag must be set")
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.UpdateMember(context.TODO(), &protonpb.NodeInfo{ID: member, Addr: c.String("addr")})
+	if err != nil {
+		log.Fatal("Can't update the member: ", err)
+	}
+	if !resp.Success {
+		log.Fatal("Can't update the member: ", resp.Error)
+	}
+
+	log.Println("Member address updated")
+}
+
+func labelMember(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	member, err := strconv.ParseUint(c.String("member"), 10, 64)
+	if err != nil {
+		log.Fatal("member flag must be a valid member id")
+	}
+
+	labels := make(map[string]string)
+	for _, label := range c.StringSlice("label") {
+		kv := strings.SplitN(label, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			log.Fatal("label flag must be of the form key=value")
+		}
+		labels[kv[0]] = kv[1]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.LabelMember(context.TODO(), &protonpb.LabelMemberRequest{Id: member, Labels: labels})
+	if err != nil {
+		log.Fatal("Can't label the member: ", err)
+	}
+	if !resp.Success {
+		log.Fatal("Can't label the member: ", resp.Error)
+	}
+
+	log.Println("Member labels replaced")
 }
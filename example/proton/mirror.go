@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func mirror(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	if c.String("destination") == "" {
+		log.Fatal("destination flag must be set")
+	}
+
+	source, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize source connection")
+	}
+
+	destination, err := proton.GetRaftClient(c.String("destination"), 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize destination connection")
+	}
+
+	m := &proton.Mirror{
+		Source:            source,
+		Destination:       destination,
+		Name:              c.String("name"),
+		DestinationName:   c.String("destination-name"),
+		Prefix:            c.String("prefix"),
+		DestinationPrefix: c.String("destination-prefix"),
+	}
+
+	for {
+		err = m.Run(context.TODO())
+		log.Println("Mirror interrupted, restarting:", err)
+		time.Sleep(time.Second)
+	}
+}
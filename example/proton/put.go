@@ -1,10 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
 	"github.com/codegangsta/cli"
 	"golang.org/x/net/context"
 )
@@ -34,8 +36,19 @@ func put(c *cli.Context) {
 		log.Fatal("couldn't initialize client connection")
 	}
 
-	resp, err := client.PutObject(context.TODO(), &proton.PutObjectRequest{Object: &proton.Pair{Key: key, Value: value}})
+	req := &protonpb.PutObjectRequest{
+		Object:    &protonpb.Pair{Key: key, Value: value},
+		Namespace: c.String("namespace"),
+		Ttl:       int64(c.Duration("ttl")),
+	}
+
+	resp, err := client.PutObject(context.TODO(), req)
 	if resp == nil || err != nil {
 		log.Fatal("Can't put object in the cluster")
 	}
+	if !resp.Success {
+		log.Fatal("Can't put object in the cluster: ", resp.Error)
+	}
+
+	fmt.Println("Committed at index", resp.Index, "term", resp.Term)
 }
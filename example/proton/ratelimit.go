@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func rateLimits(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.ListRateLimits(context.TODO(), &protonpb.ListRateLimitsRequest{})
+	if err != nil {
+		log.Fatal("Can't list rate limits in the cluster")
+	}
+
+	fmt.Println("Rate limits:")
+
+	for _, limit := range resp.Limits {
+		fmt.Println(":", limit.Identity, ":", limit.Rate, "/s :", limit.Burst)
+	}
+}
+
+func rateLimit(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	identity := c.String("identity")
+	if identity == "" {
+		log.Fatal("identity flag must be set")
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.SetRateLimit(context.TODO(), &protonpb.RateLimit{
+		Identity: identity,
+		Rate:     c.Float64("rate"),
+		Burst:    uint64(c.Int("burst")),
+	})
+	if err != nil || !resp.Success {
+		log.Fatal("Can't set rate limit in the cluster")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func forceNewCluster(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	ctx := context.TODO()
+	if timeout := c.Duration("timeout"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	dryRun := c.Bool("dry-run")
+	if !dryRun {
+		log.Println("WARNING: the other members are removed without their agreement, wipe them before they rejoin")
+	}
+
+	resp, err := client.RecoverCluster(ctx, &protonpb.RecoverClusterRequest{DryRun: dryRun})
+	if err != nil {
+		log.Fatal("Can't force a new cluster: ", err)
+	}
+
+	for _, member := range resp.Removed {
+		fmt.Printf("%x\t%s\n", member.ID, member.Addr)
+	}
+	if !resp.Success {
+		log.Fatal("Can't force a new cluster: ", resp.Error)
+	}
+
+	if dryRun {
+		log.Println("Dry run: the members above would be removed")
+		return
+	}
+	log.Println("The node is now the single member of a new cluster")
+}
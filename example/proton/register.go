@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func register(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	key := c.String("key")
+	if key == "" {
+		log.Fatal("key flag must be set")
+	}
+
+	ttl := c.Duration("ttl")
+	if ttl <= 0 {
+		log.Fatal("ttl flag must be set")
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	session, err := client.GrantSession(context.TODO(), &protonpb.GrantSessionRequest{Ttl: int64(ttl)})
+	if session == nil || err != nil {
+		log.Fatal("Can't start a session")
+	}
+	if !session.Success {
+		log.Fatal("Can't start a session: ", session.Error)
+	}
+
+	resp, err := client.PutObject(context.TODO(), &protonpb.PutObjectRequest{
+		Object:    &protonpb.Pair{Key: key, Value: []byte(c.String("value")), Session: session.Id},
+		Namespace: c.String("namespace"),
+	})
+	if resp == nil || err != nil {
+		log.Fatal("Can't put object in the cluster")
+	}
+	if !resp.Success {
+		log.Fatal("Can't put object in the cluster: ", resp.Error)
+	}
+
+	fmt.Printf("Registered %s with session %x, the key is deleted %v after this command stops\n", key, session.Id, ttl)
+
+	// Keep the session alive until the command is stopped
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for range ticker.C {
+		alive, err := client.KeepAliveSession(context.TODO(), &protonpb.KeepAliveSessionRequest{Id: session.Id})
+		if alive == nil || err != nil {
+			log.Println("Can't keep the session alive:", err)
+		} else if !alive.Success {
+			log.Println("Can't keep the session alive:", alive.Error)
+		}
+	}
+}
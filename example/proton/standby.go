@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"github.com/coreos/etcd/raft"
+)
+
+func standby(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	listeners, err := proton.Listen(hosts...)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	server := proton.NewServer()
+
+	hostname := c.String("hostname")
+
+	if c.Bool("withRaftLogs") {
+		raftLogger = &raft.DefaultLogger{Logger: log.New(ioutil.Discard, "", 0)}
+	}
+
+	id := proton.GenID(hostname)
+	cfg := proton.DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	node, err := proton.NewJoinNode(id, hosts[0], cfg, handler)
+	if err != nil {
+		log.Fatal("Can't initialize raft node")
+	}
+	node.SetPriority(uint64(c.Int("priority")))
+
+	proton.Register(server, node)
+
+	// Start raft
+	go node.Start()
+	for _, lis := range listeners {
+		go server.Serve(lis)
+	}
+	serveDebug(c, node)
+	serveHealth(c, node)
+
+	node.StartStandby(c.String("join"))
+	log.Println("Following the cluster as a standby, promote it with the promote command")
+
+	select {}
+}
+
+func promote(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	ctx := context.TODO()
+	if timeout := c.Duration("timeout"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	resp, err := client.PromoteStandby(ctx, &protonpb.PromoteStandbyRequest{})
+	if err != nil {
+		log.Fatal("Can't promote the standby: ", err)
+	}
+	if !resp.Success {
+		log.Fatal("Can't promote the standby: ", resp.Error)
+	}
+
+	log.Println("Standby promoted to a member of the raft")
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func status(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.RaftStatus(context.TODO(), &protonpb.RaftStatusRequest{})
+	if err != nil {
+		log.Fatal("Can't get the raft status of the node")
+	}
+
+	fmt.Println("Node:", resp.Id, "Leader:", resp.Leader, "Term:", resp.Term)
+	fmt.Println("Commit:", resp.Commit, "Applied:", resp.Applied)
+	if resp.TickMultiplier > 1 {
+		fmt.Println("Overloaded: election timeout widened", resp.TickMultiplier, "times")
+	}
+
+	if len(resp.Progress) == 0 {
+		return
+	}
+	fmt.Println("Progress:")
+
+	for _, pr := range resp.Progress {
+		fmt.Println(":", pr.Id, ":", pr.State, ": match", pr.Match, ": next", pr.Next, ": active", pr.RecentActive)
+	}
+}
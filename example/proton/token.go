@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
+	"golang.org/x/net/context"
+)
+
+func createToken(c *cli.Context) {
+	var (
+		err error
+	)
+
+	hosts := c.StringSlice("host")
+	if c.IsSet("host") || c.IsSet("H") {
+		hosts = hosts[1:]
+	}
+
+	client, err := proton.GetRaftClient(hosts[0], 2*time.Second)
+	if err != nil {
+		log.Fatal("couldn't initialize client connection")
+	}
+
+	resp, err := client.CreateJoinToken(context.TODO(), &protonpb.CreateJoinTokenRequest{Ttl: int64(c.Duration("ttl"))})
+	if err != nil {
+		log.Fatal("Can't create a join token: ", err)
+	}
+	if !resp.Success {
+		log.Fatal("Can't create a join token: ", resp.Error)
+	}
+
+	fmt.Println(resp.Token)
+}
@@ -3,17 +3,53 @@ package main
 import (
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 
 	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/codegangsta/cli"
 	"github.com/gogo/protobuf/proto"
 )
 
 func handler(msg interface{}) {
 	// Here: can be a protobuf 'oneof' message
-	pair := &proton.Pair{}
+	pair := &protonpb.Pair{}
 	err := proto.Unmarshal(msg.([]byte), pair)
 	if err != nil {
 		log.Fatal("Can't decode key and value sent through raft")
 	}
 	fmt.Printf("New entry added to logs: [%v = %v]\n", pair.Key, string(pair.Value))
 }
+
+// serveDebug starts the debug listener of the node if enabled
+func serveDebug(c *cli.Context, node *proton.Node) {
+	addr := c.String("debug-addr")
+	if addr == "" {
+		return
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("failed to listen on debug address: %v", err)
+	}
+
+	log.Println("Serving debug endpoints on", addr)
+	go node.ServeDebug(lis, c.String("debug-token"))
+}
+
+// serveHealth starts the health check listener of the node if enabled
+func serveHealth(c *cli.Context, node *proton.Node) {
+	addr := c.String("health-addr")
+	if addr == "" {
+		return
+	}
+
+	log.Println("Serving health checks on", addr)
+	go func() {
+		err := http.ListenAndServe(addr, node.HealthHandler())
+		if err != nil {
+			log.Fatalf("failed to serve health checks: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,23 @@
+package proton
+
+const (
+	// FailpointBeforeSend is reached before a message is sent to a member
+	FailpointBeforeSend = "before-send"
+	// FailpointAfterPersist is reached once the entries and the
+	// hard state of a ready are saved, before they are sent
+	FailpointAfterPersist = "after-persist"
+	// FailpointBeforeApply is reached before a committed entry is applied
+	FailpointBeforeApply = "before-apply"
+	// FailpointDuringSnapshot is reached once the state of a
+	// snapshot is encoded, before it is saved and the log compacted
+	FailpointDuringSnapshot = "during-snapshot"
+)
+
+// isFailpoint checks if a failpoint exists
+func isFailpoint(name string) bool {
+	switch name {
+	case FailpointBeforeSend, FailpointAfterPersist, FailpointBeforeApply, FailpointDuringSnapshot:
+		return true
+	}
+	return false
+}
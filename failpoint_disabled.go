@@ -0,0 +1,21 @@
+//go:build !failpoints
+// +build !failpoints
+
+package proton
+
+import "errors"
+
+// ErrInvalidFailpoint is thrown when enabling a failpoint
+// in a build without the failpoints build tag
+var ErrInvalidFailpoint = errors.New("failpoints are not compiled in, build with the failpoints tag")
+
+// EnableFailpoint does nothing without the failpoints build tag
+func EnableFailpoint(name, action string) error {
+	return ErrInvalidFailpoint
+}
+
+// DisableFailpoint does nothing without the failpoints build tag
+func DisableFailpoint(name string) {}
+
+// failpoint does nothing without the failpoints build tag
+func failpoint(name string) {}
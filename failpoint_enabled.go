@@ -0,0 +1,132 @@
+//go:build failpoints
+// +build failpoints
+
+package proton
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// failpointsEnv holds the failpoints enabled when the
+// process starts, such as "before-apply=panic;after-persist=2*sleep(1s)"
+const failpointsEnv = "PROTON_FAILPOINTS"
+
+// ErrInvalidFailpoint is thrown when enabling an unknown failpoint or an unknown action
+var ErrInvalidFailpoint = errors.New("unknown failpoint or action")
+
+// failpointAction is the action taken when a failpoint is reached,
+// count is the number of times left to take it, -1 for always
+type failpointAction struct {
+	action string
+	sleep  time.Duration
+	count  int
+	resume chan struct{}
+}
+
+var failpoints = struct {
+	lock    sync.Mutex
+	actions map[string]*failpointAction
+}{actions: make(map[string]*failpointAction)}
+
+func init() {
+	for _, term := range strings.Split(os.Getenv(failpointsEnv), ";") {
+		if term == "" {
+			continue
+		}
+		kv := strings.SplitN(term, "=", 2)
+		if len(kv) != 2 {
+			log.Fatalf("raft: invalid failpoint %q in %s", term, failpointsEnv)
+		}
+		err := EnableFailpoint(kv[0], kv[1])
+		if err != nil {
+			log.Fatalf("raft: invalid failpoint %q in %s: %v", term, failpointsEnv, err)
+		}
+	}
+}
+
+// EnableFailpoint sets the action taken when a failpoint is
+// reached: panic, exit the process, sleep(duration) or pause until
+// the failpoint is disabled. A count such as 3*panic only takes the
+// action the first 3 times. Failpoints are only compiled in with
+// the failpoints build tag
+func EnableFailpoint(name, action string) error {
+	if !isFailpoint(name) {
+		return ErrInvalidFailpoint
+	}
+
+	fp := &failpointAction{count: -1, resume: make(chan struct{})}
+	if i := strings.Index(action, "*"); i >= 0 {
+		count, err := strconv.Atoi(action[:i])
+		if err != nil || count < 1 {
+			return ErrInvalidFailpoint
+		}
+		fp.count = count
+		action = action[i+1:]
+	}
+
+	switch {
+	case action == "panic" || action == "exit" || action == "pause":
+	case strings.HasPrefix(action, "sleep(") && strings.HasSuffix(action, ")"):
+		d, err := time.ParseDuration(action[len("sleep(") : len(action)-1])
+		if err != nil {
+			return ErrInvalidFailpoint
+		}
+		fp.sleep = d
+		action = "sleep"
+	default:
+		return ErrInvalidFailpoint
+	}
+	fp.action = action
+
+	failpoints.lock.Lock()
+	defer failpoints.lock.Unlock()
+	if old, ok := failpoints.actions[name]; ok {
+		close(old.resume)
+	}
+	failpoints.actions[name] = fp
+	return nil
+}
+
+// DisableFailpoint stops taking the action of a failpoint,
+// the callers paused on it resume
+func DisableFailpoint(name string) {
+	failpoints.lock.Lock()
+	defer failpoints.lock.Unlock()
+	if fp, ok := failpoints.actions[name]; ok {
+		close(fp.resume)
+		delete(failpoints.actions, name)
+	}
+}
+
+// failpoint takes the action enabled on a failpoint, if any
+func failpoint(name string) {
+	failpoints.lock.Lock()
+	fp, ok := failpoints.actions[name]
+	if !ok || fp.count == 0 {
+		failpoints.lock.Unlock()
+		return
+	}
+	if fp.count > 0 {
+		fp.count--
+	}
+	failpoints.lock.Unlock()
+
+	switch fp.action {
+	case "panic":
+		panic(fmt.Sprintf("raft: failpoint %s reached", name))
+	case "exit":
+		log.Printf("raft: failpoint %s reached, exiting", name)
+		os.Exit(1)
+	case "sleep":
+		time.Sleep(fp.sleep)
+	case "pause":
+		<-fp.resume
+	}
+}
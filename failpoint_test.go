@@ -0,0 +1,72 @@
+//go:build failpoints
+// +build failpoints
+
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableFailpoint(t *testing.T) {
+	defer DisableFailpoint(FailpointBeforeApply)
+
+	for _, action := range []string{"panic", "exit", "pause", "sleep(10ms)", "2*panic"} {
+		assert.NoError(t, EnableFailpoint(FailpointBeforeApply, action))
+	}
+	for _, action := range []string{"crash", "sleep(forever)", "0*panic", "x*panic", ""} {
+		assert.Equal(t, EnableFailpoint(FailpointBeforeApply, action), ErrInvalidFailpoint)
+	}
+	assert.Equal(t, EnableFailpoint("unknown", "panic"), ErrInvalidFailpoint)
+}
+
+func TestFailpointCount(t *testing.T) {
+	defer DisableFailpoint(FailpointBeforeSend)
+
+	assert.NoError(t, EnableFailpoint(FailpointBeforeSend, "2*panic"))
+	assert.Panics(t, func() { failpoint(FailpointBeforeSend) })
+	assert.Panics(t, func() { failpoint(FailpointBeforeSend) })
+	assert.NotPanics(t, func() { failpoint(FailpointBeforeSend) })
+
+	// Other failpoints are not reached
+	assert.NotPanics(t, func() { failpoint(FailpointAfterPersist) })
+}
+
+func TestFailpointSleep(t *testing.T) {
+	defer DisableFailpoint(FailpointDuringSnapshot)
+
+	assert.NoError(t, EnableFailpoint(FailpointDuringSnapshot, "sleep(50ms)"))
+	start := time.Now()
+	failpoint(FailpointDuringSnapshot)
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+
+	DisableFailpoint(FailpointDuringSnapshot)
+	start = time.Now()
+	failpoint(FailpointDuringSnapshot)
+	assert.True(t, time.Since(start) < 50*time.Millisecond)
+}
+
+func TestFailpointPause(t *testing.T) {
+	assert.NoError(t, EnableFailpoint(FailpointAfterPersist, "pause"))
+
+	done := make(chan struct{})
+	go func() {
+		failpoint(FailpointAfterPersist)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("failpoint did not pause")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	DisableFailpoint(FailpointAfterPersist)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("failpoint did not resume once disabled")
+	}
+}
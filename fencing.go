@@ -0,0 +1,55 @@
+package proton
+
+import (
+	"sync/atomic"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+// FencingToken returns the epoch of the leadership of the node, made
+// of its term and its applied index. A leader deposed without knowing
+// it hands out tokens of an older term, which the resources guarded
+// by the token refuse once they accepted one of the new leader
+func (n *Node) FencingToken() (*protonpb.FencingToken, error) {
+	status := n.Node.Status()
+	if status.Lead != n.ID {
+		return nil, n.notLeader()
+	}
+	return &protonpb.FencingToken{
+		Term:  status.Term,
+		Index: atomic.LoadUint64(&n.appliedIndex),
+	}, nil
+}
+
+// SemaphoreToken returns the fencing token of the permits taken
+// with a ticket, it increases every time the permits are granted
+func (n *Node) SemaphoreToken(name string, ticket uint64) (*protonpb.FencingToken, bool) {
+	n.semaphoreLock.RLock()
+	defer n.semaphoreLock.RUnlock()
+
+	semaphore, ok := n.semaphores[name]
+	if !ok {
+		return nil, false
+	}
+	i := holderIndex(semaphore, ticket)
+	if i < 0 {
+		return nil, false
+	}
+	return semaphore.Holders[i].Token, true
+}
+
+// CompareFencingTokens returns -1 if a was handed out before b,
+// 1 if it was handed out after and 0 if they are the same token
+func CompareFencingTokens(a, b *protonpb.FencingToken) int {
+	switch {
+	case a.Term < b.Term:
+		return -1
+	case a.Term > b.Term:
+		return 1
+	case a.Index < b.Index:
+		return -1
+	case a.Index > b.Index:
+		return 1
+	}
+	return 0
+}
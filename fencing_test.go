@@ -0,0 +1,78 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFencingToken(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	_, err := n.FencingToken()
+	assert.True(t, IsNotLeader(err))
+
+	value, err := proto.Marshal(&protonpb.Session{Id: 1, Ttl: 1000})
+	assert.NoError(t, err)
+	applyProposal(t, n, 3, &LogPair{Key: sessionKey(1), Value: value})
+
+	acquire := func(index, ticket uint64) {
+		applySemaphoreRequest(t, n, index, &protonpb.SemaphoreRequest{
+			Name:   "lock",
+			Limit:  1,
+			Holder: &protonpb.SemaphoreHolder{Ticket: ticket, Session: 1, Permits: 1},
+		})
+	}
+	release := func(index, ticket uint64) {
+		applySemaphoreRequest(t, n, index, &protonpb.SemaphoreRequest{
+			Name:    "lock",
+			Holder:  &protonpb.SemaphoreHolder{Ticket: ticket},
+			Release: true,
+		})
+	}
+
+	// The token of a lock taken again is higher than the previous one
+	acquire(4, 10)
+	first, ok := n.SemaphoreToken("lock", 10)
+	assert.True(t, ok)
+	assert.Equal(t, first, &protonpb.FencingToken{Term: 1, Index: 4})
+	release(5, 10)
+	_, ok = n.SemaphoreToken("lock", 10)
+	assert.False(t, ok)
+
+	acquire(6, 11)
+	second, ok := n.SemaphoreToken("lock", 11)
+	assert.True(t, ok)
+	assert.Equal(t, CompareFencingTokens(first, second), -1)
+	assert.Equal(t, CompareFencingTokens(second, first), 1)
+	assert.Equal(t, CompareFencingTokens(second, second), 0)
+
+	// A later term wins over a higher index
+	assert.Equal(t, CompareFencingTokens(&protonpb.FencingToken{Term: 1, Index: 100}, &protonpb.FencingToken{Term: 2, Index: 1}), -1)
+
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+	leader, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer leader.Shutdown()
+
+	leader.Campaign(leader.Ctx)
+	go leader.Start()
+	for i := 0; i < 100 && !leader.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, leader.IsLeader())
+
+	token, err := leader.FencingToken()
+	assert.NoError(t, err)
+	assert.Equal(t, token.Term, leader.Node.Status().Term)
+
+	resp, err := leader.CheckLeader(leader.Ctx, &protonpb.CheckLeaderRequest{})
+	assert.NoError(t, err)
+	assert.True(t, resp.Leader)
+	assert.Equal(t, resp.Token.Term, token.Term)
+}
@@ -0,0 +1,127 @@
+//go:build gofuzz
+// +build gofuzz
+
+package proton
+
+import (
+	"hash/crc32"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+// The fuzz targets are built with go-fuzz, such as with
+// go-fuzz-build -func FuzzEntry. They return 1 when the input
+// was decoded, for the fuzzer to favor it, and 0 otherwise
+
+// fuzzNode returns a node applying entries without raft
+func fuzzNode() *Node {
+	n, err := newNodeState(1, "127.0.0.1:4000", nil, func(interface{}) {}, raft.NewMemoryStorage())
+	if err != nil {
+		panic(err)
+	}
+	n.ticker.Stop()
+	return n
+}
+
+// FuzzEntry applies a committed entry holding data
+func FuzzEntry(data []byte) int {
+	n := fuzzNode()
+	n.process(raftpb.Entry{Term: 1, Index: 1, Type: raftpb.EntryNormal, Data: data})
+
+	if proto.Unmarshal(data, &LogPair{}) != nil {
+		return 0
+	}
+	return 1
+}
+
+// FuzzConfChange applies a committed configuration change holding
+// data, on a node running the raft that the change is handed to
+func FuzzConfChange(data []byte) int {
+	n, err := NewNode(1, "127.0.0.1:4000", nil, func(interface{}) {})
+	if err != nil {
+		panic(err)
+	}
+	n.ticker.Stop()
+	defer n.Stop()
+	n.applyEntry(raftpb.Entry{Term: 1, Index: 1, Type: raftpb.EntryConfChange, Data: data}, time.Now())
+
+	var cc raftpb.ConfChange
+	if cc.Unmarshal(data) != nil {
+		return 0
+	}
+	return 1
+}
+
+// FuzzSnapshot restores a snapshot of the store encoded in data,
+// it is sent with a valid checksum to get past the verification
+func FuzzSnapshot(data []byte) int {
+	envelope, err := proto.Marshal(&SnapshotData{
+		State:    data,
+		Checksum: crc32.Checksum(data, crcTable),
+	})
+	if err != nil {
+		return 0
+	}
+
+	n := fuzzNode()
+	msg := &raftpb.Message{
+		Type:     raftpb.MsgSnap,
+		Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 1, Term: 1}, Data: envelope},
+	}
+	if n.verifySnapshot(msg) != nil {
+		return 0
+	}
+	n.processSnapshot(msg.Snapshot)
+	n.restoreBlobs()
+
+	_, err = n.snapshotData()
+	if err != nil {
+		panic(err)
+	}
+	return 1
+}
+
+// fuzzRequests are the payloads of the RPCs, the first
+// byte of the input picks the one data is decoded into
+var fuzzRequests = []func() proto.Message{
+	func() proto.Message { return &raftpb.Message{} },
+	func() proto.Message { return &protonpb.NodeInfo{} },
+	func() proto.Message { return &protonpb.PutObjectRequest{} },
+	func() proto.Message { return &protonpb.ListObjectsRequest{} },
+	func() proto.Message { return &protonpb.GetObjectsRequest{} },
+	func() proto.Message { return &protonpb.TxnRequest{} },
+	func() proto.Message { return &protonpb.StreamChangesRequest{} },
+	func() proto.Message { return &protonpb.GrantSessionRequest{} },
+	func() proto.Message { return &protonpb.AcquireSemaphoreRequest{} },
+	func() proto.Message { return &protonpb.RateLimit{} },
+	func() proto.Message { return &protonpb.RecoverClusterRequest{} },
+	func() proto.Message { return &FetchEntriesRequest{} },
+	func() proto.Message { return &FetchEntriesResponse{} },
+}
+
+// FuzzRequest decodes the payload of an RPC, a raft message
+// carrying a snapshot is verified as the Send RPC does
+func FuzzRequest(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	req := fuzzRequests[int(data[0])%len(fuzzRequests)]()
+	err := proto.Unmarshal(data[1:], req)
+	if err != nil {
+		return 0
+	}
+
+	if msg, ok := req.(*raftpb.Message); ok {
+		fuzzNode().verifySnapshot(msg)
+	}
+
+	_, err = proto.Marshal(req)
+	if err != nil {
+		panic(err)
+	}
+	return 1
+}
@@ -0,0 +1,54 @@
+package proton
+
+import (
+	"net/http"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+)
+
+// IsHealthy checks if the node takes part in a raft
+// cluster with a known leader
+func (n *Node) IsHealthy() bool {
+	return !n.IsPaused() && n.Leader() != raft.None
+}
+
+// HealthHandler returns an HTTP handler for load balancers:
+// /leader answers 200 only on the leader, /follower answers
+// 200 only on a healthy follower, 503 otherwise
+func (n *Node) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/leader", func(w http.ResponseWriter, r *http.Request) {
+		check(w, n.IsHealthy() && n.IsLeader())
+	})
+	mux.HandleFunc("/follower", func(w http.ResponseWriter, r *http.Request) {
+		check(w, n.IsHealthy() && !n.IsLeader())
+	})
+	return mux
+}
+
+func check(w http.ResponseWriter, ok bool) {
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// CheckLeader returns the leadership and the health of a node
+func (n *Node) CheckLeader(ctx context.Context, req *protonpb.CheckLeaderRequest) (*protonpb.CheckLeaderResponse, error) {
+	token, err := n.FencingToken()
+	resp := &protonpb.CheckLeaderResponse{
+		Leader:   err == nil,
+		LeaderId: n.Leader(),
+		Healthy:  n.IsHealthy(),
+		Token:    token,
+	}
+	// A follower tells where to send the requests of the leader
+	if leader, ok := n.Cluster.Leader(); ok {
+		resp.LeaderId, resp.LeaderAddr = leader.ID, leader.Addr
+	}
+	return resp, nil
+}
@@ -0,0 +1,201 @@
+package proton
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+const (
+	// DefaultHedgePercentile is the percentile of the read latency
+	// after which a read is sent to a second member
+	DefaultHedgePercentile = 0.95
+
+	// DefaultHedgeDelay is the delay before hedging a read
+	// until enough latencies have been observed
+	DefaultHedgeDelay = 10 * time.Millisecond
+
+	// DefaultHedgeBudget is the fraction of the reads that can be hedged
+	DefaultHedgeBudget = 0.1
+
+	// hedgeMinSamples is the number of reads to observe before
+	// the hedging delay is computed from their latency
+	hedgeMinSamples = 20
+
+	// hedgeMaxTokens caps the hedges saved up while the
+	// members are fast, to bound a burst of hedged reads
+	hedgeMaxTokens = 10
+)
+
+var (
+	// ErrNoReplica is thrown when a hedged client has no member to read from
+	ErrNoReplica = errors.New("no member to send the request to")
+)
+
+// HedgedClient reads from a set of members of the cluster. A read
+// that takes longer than usual is also sent to a second member and
+// the first response is used, which cuts the tail latency when a
+// member is slow. The hedges are limited to a fraction of the reads
+// so that a slow cluster is not overloaded by twice the requests
+type HedgedClient struct {
+	// Percentile of the observed latency after which a read is hedged
+	Percentile float64
+	// Budget is the fraction of the reads that can be hedged
+	Budget float64
+	// Timeout bounds each read, 0 means no timeout
+	Timeout time.Duration
+
+	replicas []*Raft
+	latency  *Histogram
+
+	lock   sync.Mutex
+	next   int
+	tokens float64
+}
+
+// NewHedgedClient returns a client hedging its reads
+// between the given members of the cluster
+func NewHedgedClient(replicas []*Raft) *HedgedClient {
+	return &HedgedClient{
+		Percentile: DefaultHedgePercentile,
+		Budget:     DefaultHedgeBudget,
+		replicas:   replicas,
+		latency:    newHistogram(),
+		tokens:     hedgeMaxTokens,
+	}
+}
+
+// delay returns the time to wait for a response before hedging
+func (h *HedgedClient) delay() time.Duration {
+	if h.latency.Count() < hedgeMinSamples {
+		return DefaultHedgeDelay
+	}
+	return h.latency.Quantile(h.Percentile)
+}
+
+// pick returns the member receiving the next read, the
+// reads are spread over the members in turn
+func (h *HedgedClient) pick() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	i := h.next
+	h.next = (h.next + 1) % len(h.replicas)
+
+	h.tokens += h.Budget
+	if h.tokens > hedgeMaxTokens {
+		h.tokens = hedgeMaxTokens
+	}
+	return i
+}
+
+// spend takes a hedge from the budget
+func (h *HedgedClient) spend() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+// hedgeResult is the response of a member to a hedged read
+type hedgeResult struct {
+	resp interface{}
+	err  error
+}
+
+// hedge sends a read to a member, and to a second one if there
+// is no response after the hedging delay or if the first fails
+// with a retryable error
+func (h *HedgedClient) hedge(ctx context.Context, call func(context.Context, *Raft) (interface{}, error)) (interface{}, error) {
+	if len(h.replicas) == 0 {
+		return nil, ErrNoReplica
+	}
+
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	// Cancels the read still in flight once one succeeds
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	send := func(replica *Raft) {
+		start := time.Now()
+		resp, err := call(ctx, replica)
+		if err == nil {
+			h.latency.Observe(time.Since(start))
+		}
+		results <- hedgeResult{resp: resp, err: err}
+	}
+
+	first := h.pick()
+	go send(h.replicas[first])
+	pending, hedged := 1, len(h.replicas) == 1
+
+	timer := time.NewTimer(h.delay())
+	defer timer.Stop()
+
+	var err error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if !hedged && h.spend() {
+				hedged = true
+				pending++
+				go send(h.replicas[(first+1)%len(h.replicas)])
+			}
+
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return r.resp, nil
+			}
+			err = r.err
+
+			// A read failing with a retryable error is retried once
+			// on the other member, within the budget of the hedges
+			if !hedged && IsRetryable(r.err) && h.spend() {
+				hedged = true
+				pending++
+				go send(h.replicas[(first+1)%len(h.replicas)])
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, err
+}
+
+// ListObjects lists the keys of a namespace from the first member to respond
+func (h *HedgedClient) ListObjects(ctx context.Context, req *protonpb.ListObjectsRequest) (*protonpb.ListObjectsResponse, error) {
+	resp, err := h.hedge(ctx, func(ctx context.Context, replica *Raft) (interface{}, error) {
+		return replica.ListObjects(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*protonpb.ListObjectsResponse), nil
+}
+
+// ListMembers lists the members of the cluster from the first member to respond
+func (h *HedgedClient) ListMembers(ctx context.Context, req *protonpb.ListMembersRequest) (*protonpb.ListMembersResponse, error) {
+	resp, err := h.hedge(ctx, func(ctx context.Context, replica *Raft) (interface{}, error) {
+		return replica.ListMembers(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*protonpb.ListMembersResponse), nil
+}
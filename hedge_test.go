@@ -0,0 +1,76 @@
+package proton
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// replicaKV answers the reads after a delay
+type replicaKV struct {
+	KVClient
+	name  string
+	delay time.Duration
+	err   error
+}
+
+func (r *replicaKV) ListObjects(ctx context.Context, req *protonpb.ListObjectsRequest, opts ...grpc.CallOption) (*protonpb.ListObjectsResponse, error) {
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &protonpb.ListObjectsResponse{Objects: []*protonpb.Pair{{Key: r.name}}}, nil
+}
+
+func TestHedgedClient(t *testing.T) {
+	slow := &Raft{KVClient: &replicaKV{name: "slow", delay: time.Second}}
+	fast := &Raft{KVClient: &replicaKV{name: "fast"}}
+	failing := &Raft{KVClient: &replicaKV{name: "failing", err: grpc.Errorf(codes.Unavailable, "unavailable")}}
+	invalid := &Raft{KVClient: &replicaKV{name: "invalid", err: errors.New("invalid request")}}
+
+	// The read is hedged to the fast member
+	h := NewHedgedClient([]*Raft{slow, fast})
+	start := time.Now()
+	resp, err := h.ListObjects(context.Background(), &protonpb.ListObjectsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Objects[0].Key, "fast")
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+
+	// Without budget the read waits for the slow member
+	h = NewHedgedClient([]*Raft{slow, fast})
+	h.tokens, h.Budget = 0, 0
+	h.Timeout = 100 * time.Millisecond
+	_, err = h.ListObjects(context.Background(), &protonpb.ListObjectsRequest{})
+	assert.Equal(t, err, context.DeadlineExceeded)
+
+	// A failed read is retried on the other member
+	h = NewHedgedClient([]*Raft{failing, fast})
+	resp, err = h.ListObjects(context.Background(), &protonpb.ListObjectsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Objects[0].Key, "fast")
+
+	// A read failing with a permanent error is not retried
+	h = NewHedgedClient([]*Raft{invalid, fast})
+	_, err = h.ListObjects(context.Background(), &protonpb.ListObjectsRequest{})
+	assert.EqualError(t, err, "invalid request")
+
+	// Nor is a failed read without budget
+	h = NewHedgedClient([]*Raft{failing, fast})
+	h.tokens, h.Budget = 0, 0
+	_, err = h.ListObjects(context.Background(), &protonpb.ListObjectsRequest{})
+	assert.Equal(t, grpc.Code(err), codes.Unavailable)
+
+	_, err = NewHedgedClient(nil).ListObjects(context.Background(), &protonpb.ListObjectsRequest{})
+	assert.Equal(t, err, ErrNoReplica)
+}
@@ -0,0 +1,121 @@
+package proton
+
+import (
+	"sync"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+// KeyRevision is a mutation of a key kept in its history
+type KeyRevision struct {
+	// Index and Term of the entry that wrote the key
+	Index uint64
+	Term  uint64
+	// Revision of the store once the mutation was applied
+	Revision uint64
+	Type     protonpb.ChangeType
+	// Value written, nil for a deletion
+	Value []byte
+	// Time is when the mutation was proposed, on the
+	// clock of the leader and the same on every member
+	Time time.Time
+}
+
+// history holds the recent revisions of the keys,
+// the newest last
+type history struct {
+	lock sync.RWMutex
+	keys map[string][]KeyRevision
+	// deleted are the keys whose last revision is a
+	// deletion, forgotten on the next snapshot
+	deleted map[string]struct{}
+}
+
+func newHistory() *history {
+	return &history{
+		keys:    make(map[string][]KeyRevision),
+		deleted: make(map[string]struct{}),
+	}
+}
+
+// History returns the recent revisions of a key applied on this
+// node, the newest first and at most limit of them, 0 returning
+// all the revisions kept. Only HistoryLimit revisions are kept per
+// key and the history starts over when a snapshot is restored, the
+// history of a deleted key is kept until the next snapshot is taken
+func (n *Node) History(key string, limit int) []KeyRevision {
+	h := n.history
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	revisions := h.keys[key]
+	if limit <= 0 || limit > len(revisions) {
+		limit = len(revisions)
+	}
+
+	result := make([]KeyRevision, 0, limit)
+	for i := len(revisions) - 1; i >= len(revisions)-limit; i-- {
+		result = append(result, revisions[i])
+	}
+	return result
+}
+
+// recordHistory adds an applied change to the history of its
+// key. Called from the main loop
+func (n *Node) recordHistory(change *protonpb.Change) {
+	if n.HistoryLimit <= 0 {
+		return
+	}
+
+	revision := KeyRevision{
+		Index:    change.Index,
+		Term:     change.Term,
+		Revision: change.Revision,
+		Type:     change.Type,
+		Time:     entryTime(change.Timestamp),
+	}
+	if change.Type != protonpb.ChangeType_DELETE {
+		revision.Value = change.Pair.Value
+	}
+
+	h := n.history
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	revisions := append(h.keys[change.Pair.Key], revision)
+	if len(revisions) > n.HistoryLimit {
+		revisions = append(revisions[:0:0], revisions[len(revisions)-n.HistoryLimit:]...)
+	}
+	h.keys[change.Pair.Key] = revisions
+
+	if change.Type == protonpb.ChangeType_DELETE {
+		h.deleted[change.Pair.Key] = struct{}{}
+	} else {
+		delete(h.deleted, change.Pair.Key)
+	}
+}
+
+// compactHistory forgets the history of the deleted keys,
+// once a snapshot is taken. Called from the main loop
+func (n *Node) compactHistory() {
+	h := n.history
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for key := range h.deleted {
+		delete(h.keys, key)
+	}
+	h.deleted = make(map[string]struct{})
+}
+
+// resetHistory forgets every revision, the store was replaced
+// by a snapshot. Called from the main loop
+func (n *Node) resetHistory() {
+	h := n.history
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.keys = make(map[string][]KeyRevision)
+	h.deleted = make(map[string]struct{})
+}
@@ -0,0 +1,53 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// The history is disabled by default
+	applyPair(t, n, 1, "foo", "v1")
+	assert.Empty(t, n.History("foo", 0))
+
+	n.HistoryLimit = 3
+	for i, value := range []string{"v2", "v3", "v4", "v5"} {
+		applyPair(t, n, uint64(i+2), "foo", value)
+	}
+	applyPair(t, n, 6, "bar", "baz")
+
+	// Only the last revisions are kept, the newest first
+	revisions := n.History("foo", 0)
+	assert.Len(t, revisions, 3)
+	for i, value := range []string{"v5", "v4", "v3"} {
+		assert.Equal(t, string(revisions[i].Value), value)
+		assert.Equal(t, revisions[i].Index, uint64(5-i))
+		assert.Equal(t, revisions[i].Term, uint64(1))
+		assert.Equal(t, revisions[i].Type, protonpb.ChangeType_UPDATE)
+		assert.False(t, revisions[i].Time.IsZero())
+	}
+	assert.True(t, revisions[0].Revision > revisions[1].Revision)
+
+	revisions = n.History("foo", 1)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, string(revisions[0].Value), "v5")
+
+	applyProposal(t, n, 7, &LogPair{Key: "foo", Delete: true})
+	revisions = n.History("foo", 2)
+	assert.Equal(t, revisions[0].Type, protonpb.ChangeType_DELETE)
+	assert.Nil(t, revisions[0].Value)
+	assert.Equal(t, string(revisions[1].Value), "v5")
+
+	// The history of the deleted keys is forgotten on snapshots
+	n.compactHistory()
+	assert.Empty(t, n.History("foo", 0))
+	assert.Len(t, n.History("bar", 0), 1)
+
+	n.resetHistory()
+	assert.Empty(t, n.History("bar", 0))
+}
@@ -0,0 +1,93 @@
+package proton
+
+import (
+	"sync"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+// hybridClock is the hybrid logical clock of the cluster. It only
+// moves with the applied entries, from the time the leader gave
+// them, so that every member reads the same time for an entry
+type hybridClock struct {
+	lock sync.RWMutex
+	now  protonpb.HybridTime
+}
+
+// HLC returns the hybrid logical time of the last entry applied on
+// this node. Applications spanning several clusters or systems pass
+// it along with their writes, as the After time of the writes that
+// depend on it, so that the times they read respect causality
+func (n *Node) HLC() *protonpb.HybridTime {
+	n.hlc.lock.RLock()
+	defer n.hlc.lock.RUnlock()
+	now := n.hlc.now
+	return &now
+}
+
+// tickHLC moves the clock past the time of an applied entry and
+// the time the entry depends on, if any. Called from the main loop
+func (n *Node) tickHLC(pair *LogPair) {
+	n.hlc.lock.Lock()
+	defer n.hlc.lock.Unlock()
+
+	after := protonpb.HybridTime{}
+	if pair.After != nil {
+		after = *pair.After
+	}
+	n.hlc.now = nextHybridTime(n.hlc.now, pair.Timestamp, after)
+}
+
+// setHLC sets the clock restored from a snapshot
+func (n *Node) setHLC(now *protonpb.HybridTime) {
+	n.hlc.lock.Lock()
+	defer n.hlc.lock.Unlock()
+
+	n.hlc.now = protonpb.HybridTime{}
+	if now != nil {
+		n.hlc.now = *now
+	}
+}
+
+// nextHybridTime returns the time of an event at wall time physical,
+// following the last event and an event it depends on
+func nextHybridTime(last protonpb.HybridTime, physical int64, after protonpb.HybridTime) protonpb.HybridTime {
+	wall := last.WallTime
+	if after.WallTime > wall {
+		wall = after.WallTime
+	}
+	if physical > wall {
+		return protonpb.HybridTime{WallTime: physical}
+	}
+
+	next := protonpb.HybridTime{WallTime: wall}
+	switch {
+	case wall == last.WallTime && wall == after.WallTime:
+		next.Logical = last.Logical
+		if after.Logical > next.Logical {
+			next.Logical = after.Logical
+		}
+		next.Logical++
+	case wall == last.WallTime:
+		next.Logical = last.Logical + 1
+	default:
+		next.Logical = after.Logical + 1
+	}
+	return next
+}
+
+// CompareHybridTime returns -1 if a happened before b, 1
+// if it happened after and 0 if they are the same time
+func CompareHybridTime(a, b *protonpb.HybridTime) int {
+	switch {
+	case a.WallTime < b.WallTime:
+		return -1
+	case a.WallTime > b.WallTime:
+		return 1
+	case a.Logical < b.Logical:
+		return -1
+	case a.Logical > b.Logical:
+		return 1
+	}
+	return 0
+}
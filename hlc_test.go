@@ -0,0 +1,118 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextHybridTime(t *testing.T) {
+	last := protonpb.HybridTime{WallTime: 100, Logical: 2}
+
+	// The wall time moves forward with the entries
+	assert.Equal(t, nextHybridTime(last, 200, protonpb.HybridTime{}), protonpb.HybridTime{WallTime: 200})
+
+	// A wall time behind the clock only moves the counter
+	assert.Equal(t, nextHybridTime(last, 50, protonpb.HybridTime{}), protonpb.HybridTime{WallTime: 100, Logical: 3})
+	assert.Equal(t, nextHybridTime(last, 0, protonpb.HybridTime{}), protonpb.HybridTime{WallTime: 100, Logical: 3})
+
+	// The clock moves past the time the entry depends on
+	assert.Equal(t, nextHybridTime(last, 50, protonpb.HybridTime{WallTime: 300, Logical: 7}), protonpb.HybridTime{WallTime: 300, Logical: 8})
+	assert.Equal(t, nextHybridTime(last, 50, protonpb.HybridTime{WallTime: 100, Logical: 7}), protonpb.HybridTime{WallTime: 100, Logical: 8})
+	assert.Equal(t, nextHybridTime(last, 400, protonpb.HybridTime{WallTime: 300, Logical: 7}), protonpb.HybridTime{WallTime: 400})
+
+	assert.Equal(t, CompareHybridTime(&last, &protonpb.HybridTime{WallTime: 100, Logical: 3}), -1)
+	assert.Equal(t, CompareHybridTime(&last, &protonpb.HybridTime{WallTime: 99, Logical: 9}), 1)
+	assert.Equal(t, CompareHybridTime(&last, &last), 0)
+}
+
+func TestHLC(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	changes, cancel := n.Subscribe("", 10)
+	defer cancel()
+
+	applyProposal(t, n, 1, &LogPair{Key: "foo", Value: []byte("bar"), Timestamp: 100})
+	change := <-changes
+	assert.Equal(t, change.Hlc, &protonpb.HybridTime{WallTime: 100})
+
+	// The entries keep their order when the time of the leader goes back
+	applyProposal(t, n, 2, &LogPair{Key: "foo", Value: []byte("baz"), Timestamp: 90})
+	change = <-changes
+	assert.Equal(t, change.Hlc, &protonpb.HybridTime{WallTime: 100, Logical: 1})
+
+	after := &protonpb.HybridTime{WallTime: 500, Logical: 4}
+	applyProposal(t, n, 3, &LogPair{Key: "foo", Delete: true, Timestamp: 110, After: after})
+	change = <-changes
+	assert.Equal(t, CompareHybridTime(change.Hlc, after), 1)
+	assert.Equal(t, n.HLC(), change.Hlc)
+
+	// The clock is kept in the snapshots
+	state := n.snapshotState()
+	m := newQuotaNode(t)
+	defer m.Stop()
+	m.restore(state)
+	assert.Equal(t, m.HLC(), n.HLC())
+}
+
+func TestPutObjectHLC(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := n.PutObject(ctx, &protonpb.PutObjectRequest{Object: &protonpb.Pair{Key: "foo", Value: []byte("bar")}})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.WithinDuration(t, time.Unix(0, resp.Hlc.WallTime), time.Now(), time.Second)
+
+	// A write depending on a later event of another system is ordered after it
+	after := &protonpb.HybridTime{WallTime: time.Now().Add(time.Hour).UnixNano()}
+	resp, err = n.PutObject(ctx, &protonpb.PutObjectRequest{Object: &protonpb.Pair{Key: "foo", Value: []byte("baz")}, After: after})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, resp.Hlc, &protonpb.HybridTime{WallTime: after.WallTime, Logical: 1})
+}
+
+func TestTxnHLC(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The writes of a transaction are ordered after the event of the request
+	after := &protonpb.HybridTime{WallTime: time.Now().Add(time.Hour).UnixNano()}
+	resp, err := n.Txn(ctx, &protonpb.TxnRequest{Writes: []*protonpb.Pair{{Key: "foo", Value: []byte("bar")}}, After: after})
+	assert.NoError(t, err)
+	assert.True(t, resp.Committed)
+	assert.Equal(t, CompareHybridTime(n.HLC(), after), 1)
+}
@@ -0,0 +1,92 @@
+package proton
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// nodeURIScheme is the scheme of the URI naming a node
+	// in the subject alternative names of its certificate
+	nodeURIScheme = "proton"
+	nodeURIHost   = "node"
+)
+
+var (
+	// ErrPeerIdentity is thrown when the certificate of a peer does not identify the member it claims to be
+	ErrPeerIdentity = errors.New("peer certificate does not identify the member")
+)
+
+// NodeURI returns the URI to give as a subject alternative name
+// to the certificate of a node, so that the members can check
+// which node a peer is when VerifyPeerIdentity is set
+func NodeURI(id uint64) *url.URL {
+	return &url.URL{Scheme: nodeURIScheme, Host: nodeURIHost, Path: fmt.Sprintf("/%x", id)}
+}
+
+// certIdentifies checks if a certificate names a node
+func certIdentifies(cert *x509.Certificate, id uint64) bool {
+	for _, uri := range cert.URIs {
+		if uri.Scheme != nodeURIScheme || uri.Host != nodeURIHost {
+			continue
+		}
+		named, err := strconv.ParseUint(strings.TrimPrefix(uri.Path, "/"), 16, 64)
+		if err == nil && named == id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPeerIdentity checks that the client of an RPC presented a
+// certificate naming the node it claims to be and valid for the
+// address it is known at, so that the key of a member can't be
+// used to impersonate another one
+func (n *Node) checkPeerIdentity(ctx context.Context, id uint64, addr string) error {
+	if !n.VerifyPeerIdentity {
+		return nil
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ErrPeerIdentity
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return ErrPeerIdentity
+	}
+	cert := info.State.PeerCertificates[0]
+	if !certIdentifies(cert, id) {
+		return ErrPeerIdentity
+	}
+
+	if addr == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || cert.VerifyHostname(host) != nil {
+		return ErrPeerIdentity
+	}
+	return nil
+}
+
+// checkSender checks the identity of the member a raft message is from
+func (n *Node) checkSender(ctx context.Context, from uint64) error {
+	if !n.VerifyPeerIdentity {
+		return nil
+	}
+	var addr string
+	if member, ok := n.Cluster.Member(from); ok {
+		addr = member.Addr
+	}
+	return n.checkPeerIdentity(ctx, from, addr)
+}
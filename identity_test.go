@@ -0,0 +1,99 @@
+package proton
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// peerContext returns the context of an RPC from a client
+// presenting a certificate issued for a node at an address
+func peerContext(t *testing.T, id uint64, ip string) context.Context {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{NodeURI(id)},
+		IPAddresses:  []net.IP{net.ParseIP(ip)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.ParseIP(ip)},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}},
+	})
+}
+
+func TestPeerIdentity(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	ctx := peerContext(t, 0x2a, "10.0.0.2")
+	assert.Equal(t, NodeURI(0x2a).String(), "proton://node/2a")
+
+	// Nothing is checked unless the option is set
+	assert.NoError(t, n.checkPeerIdentity(context.Background(), 0x2b, ""))
+	n.VerifyPeerIdentity = true
+
+	assert.NoError(t, n.checkPeerIdentity(ctx, 0x2a, ""))
+	assert.NoError(t, n.checkPeerIdentity(ctx, 0x2a, "10.0.0.2:4242"))
+	assert.Equal(t, n.checkPeerIdentity(ctx, 0x2b, "10.0.0.2:4242"), ErrPeerIdentity)
+	assert.Equal(t, n.checkPeerIdentity(ctx, 0x2a, "10.0.0.3:4242"), ErrPeerIdentity)
+
+	// A client without a certificate is refused
+	assert.Equal(t, n.checkPeerIdentity(context.Background(), 0x2a, ""), ErrPeerIdentity)
+	plain := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2")}})
+	assert.Equal(t, n.checkPeerIdentity(plain, 0x2a, ""), ErrPeerIdentity)
+
+	// A message from a member is checked against its address
+	n.Cluster.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 0x2a, Addr: "10.0.0.3:4242"}})
+	assert.Equal(t, n.checkSender(ctx, 0x2a), ErrPeerIdentity)
+}
+
+func TestPeerIdentityRPCs(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.VerifyPeerIdentity = true
+	n.Cluster.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 0x2a, Addr: "10.0.0.3:4242"}})
+	ctx := peerContext(t, 0x2a, "10.0.0.2")
+
+	// A member can't move another one, nor move to an address
+	// its certificate is not valid for
+	resp, err := n.UpdateMember(ctx, &protonpb.NodeInfo{ID: 0x2b, Addr: "10.0.0.2:4242"})
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Equal(t, resp.Error, ErrPeerIdentity.Error())
+	resp, err = n.UpdateMember(ctx, &protonpb.NodeInfo{ID: 0x2a, Addr: "10.0.0.4:4242"})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Error, ErrPeerIdentity.Error())
+	member, _ := n.Cluster.Member(0x2a)
+	assert.Equal(t, member.Addr, "10.0.0.3:4242")
+
+	leave, err := n.LeaveRaft(ctx, &protonpb.NodeInfo{ID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, leave.Error, ErrPeerIdentity.Error())
+
+	fetch, err := n.FetchEntries(ctx, &FetchEntriesRequest{Id: 0x2b})
+	assert.NoError(t, err)
+	assert.Equal(t, fetch.Error, ErrPeerIdentity.Error())
+}
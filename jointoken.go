@@ -0,0 +1,289 @@
+package proton
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// tokenPrefix is the keyspace holding the join tokens
+	tokenPrefix = systemPrefix + "jointoken/"
+
+	// joinTokenVersion prefixes the join tokens
+	joinTokenVersion = "proton1"
+
+	// DefaultJoinTokenTTL is the lifetime of a join token
+	DefaultJoinTokenTTL = 24 * time.Hour
+
+	// AuditJoinTokenCreate is recorded when a join token is created
+	AuditJoinTokenCreate = "join-token-create"
+)
+
+var (
+	// ErrJoinTokenRequired is thrown when a node joins without a join token while the cluster requires one
+	ErrJoinTokenRequired = errors.New("a join token is required to join the cluster")
+	// ErrInvalidJoinToken is thrown when a join token is malformed, unknown, expired or already used
+	ErrInvalidJoinToken = errors.New("join token is invalid, expired or already used")
+	// ErrCAMismatch is thrown when the CA certificate of a cluster does not match the hash of a join token
+	ErrCAMismatch = errors.New("CA certificate does not match the join token")
+)
+
+// JoinTokenClaims is what a join token tells the node joining
+// with it: the cluster it joins, the hash of the CA certificate
+// the members are signed with, and the secret the leader checks
+type JoinTokenClaims struct {
+	ClusterID uint64
+	// CAHash is the SHA-256 of the CA certificate in DER
+	// form, empty if the cluster was given none
+	CAHash []byte
+	ID     string
+	Secret string
+}
+
+// String encodes the claims as a join token
+func (c *JoinTokenClaims) String() string {
+	return fmt.Sprintf("%s.%x.%s.%s.%s", joinTokenVersion, c.ClusterID, hex.EncodeToString(c.CAHash), c.ID, c.Secret)
+}
+
+// VerifyCA checks a CA certificate in DER form against the hash
+// of the token, so that a node joining trusts the members of the
+// cluster it was given the token for only
+func (c *JoinTokenClaims) VerifyCA(der []byte) error {
+	sum := sha256.Sum256(der)
+	if subtle.ConstantTimeCompare(sum[:], c.CAHash) != 1 {
+		return ErrCAMismatch
+	}
+	return nil
+}
+
+// ParseJoinToken decodes a join token
+func ParseJoinToken(token string) (*JoinTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 || parts[0] != joinTokenVersion || parts[3] == "" || parts[4] == "" {
+		return nil, ErrInvalidJoinToken
+	}
+	clusterID, err := strconv.ParseUint(parts[1], 16, 64)
+	if err != nil {
+		return nil, ErrInvalidJoinToken
+	}
+	caHash, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidJoinToken
+	}
+	return &JoinTokenClaims{ClusterID: clusterID, CAHash: caHash, ID: parts[3], Secret: parts[4]}, nil
+}
+
+// joinTokens holds the join tokens replicated in the cluster,
+// along with the ones being used by a join on the leader
+type joinTokens struct {
+	lock    sync.Mutex
+	tokens  map[string]*JoinToken
+	pending map[string]bool
+}
+
+func newJoinTokens() *joinTokens {
+	return &joinTokens{
+		tokens:  make(map[string]*JoinToken),
+		pending: make(map[string]bool),
+	}
+}
+
+// randomHex returns n random bytes in hexadecimal
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewJoinToken creates a join token valid for ttl, or for
+// DefaultJoinTokenTTL if it is 0, that a single node can join
+// the cluster with. Only the hash of its secret is replicated.
+// The expired tokens are removed along the way
+func (n *Node) NewJoinToken(ctx context.Context, ttl time.Duration) (string, error) {
+	if !n.IsLeader() {
+		return "", n.notLeader()
+	}
+	if ttl <= 0 {
+		ttl = DefaultJoinTokenTTL
+	}
+	n.removeExpiredJoinTokens(ctx)
+
+	id, err := randomHex(6)
+	if err != nil {
+		return "", err
+	}
+	secret, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(secret))
+	data, err := proto.Marshal(&JoinToken{
+		Id:         id,
+		SecretHash: hash[:],
+		Expires:    n.LeaderTime().Add(ttl).UnixNano(),
+	})
+	if err != nil {
+		return "", err
+	}
+	err = n.proposeJoinToken(ctx, id, data)
+	if err != nil {
+		return "", err
+	}
+
+	claims := &JoinTokenClaims{ClusterID: n.ClusterID(), ID: id, Secret: secret}
+	if len(n.CACert) > 0 {
+		sum := sha256.Sum256(n.CACert)
+		claims.CAHash = sum[:]
+	}
+	return claims.String(), nil
+}
+
+// proposeJoinToken proposes a join token, or its removal without data
+func (n *Node) proposeJoinToken(ctx context.Context, id string, data []byte) error {
+	pair, err := EncodePair(tokenPrefix+id, data)
+	if err != nil {
+		return err
+	}
+	_, _, err = n.ProposeWait(withInternalPriority(ctx), pair)
+	return err
+}
+
+// removeExpiredJoinTokens proposes the removal of the expired tokens
+func (n *Node) removeExpiredJoinTokens(ctx context.Context) {
+	now := n.LeaderTime().UnixNano()
+	for _, token := range n.JoinTokens() {
+		if token.Expires > now {
+			continue
+		}
+		err := n.proposeJoinToken(ctx, token.Id, nil)
+		if err != nil {
+			log.Println("raft: can't remove expired join token:", err)
+		}
+	}
+}
+
+// JoinTokens returns the join tokens that were not used yet
+func (n *Node) JoinTokens() []*JoinToken {
+	t := n.joinTokens
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var tokens []*JoinToken
+	for _, token := range t.tokens {
+		tokens = append(tokens, token)
+	}
+	sort.Sort(joinTokensByID(tokens))
+	return tokens
+}
+
+// useJoinToken checks the token presented by a joining node and
+// removes it, so that no other node joins with it. Tokens are only
+// required if the node requires them, but are always checked
+func (n *Node) useJoinToken(ctx context.Context, token string) error {
+	if token == "" {
+		if n.RequireJoinToken {
+			return ErrJoinTokenRequired
+		}
+		return nil
+	}
+
+	claims, err := ParseJoinToken(token)
+	if err != nil {
+		return err
+	}
+	if claims.ClusterID != n.ClusterID() {
+		return ErrClusterMismatch
+	}
+
+	t := n.joinTokens
+	t.lock.Lock()
+	stored, ok := t.tokens[claims.ID]
+	hash := sha256.Sum256([]byte(claims.Secret))
+	if !ok || t.pending[claims.ID] || stored.Expires <= n.LeaderTime().UnixNano() ||
+		subtle.ConstantTimeCompare(hash[:], stored.SecretHash) != 1 {
+		t.lock.Unlock()
+		return ErrInvalidJoinToken
+	}
+	t.pending[claims.ID] = true
+	t.lock.Unlock()
+
+	err = n.proposeJoinToken(ctx, claims.ID, nil)
+
+	t.lock.Lock()
+	delete(t.pending, claims.ID)
+	t.lock.Unlock()
+	return err
+}
+
+// applyJoinToken adds or removes a committed join token
+func (n *Node) applyJoinToken(pair *LogPair) {
+	id := strings.TrimPrefix(pair.Key, tokenPrefix)
+
+	t := n.joinTokens
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(pair.Value) == 0 {
+		delete(t.tokens, id)
+		return
+	}
+
+	token := &JoinToken{}
+	err := proto.Unmarshal(pair.Value, token)
+	if err != nil {
+		log.Println("raft: can't decode join token:", err)
+		return
+	}
+	t.tokens[id] = token
+}
+
+// restoreJoinTokens replaces the join tokens with those of a snapshot
+func (n *Node) restoreJoinTokens(tokens []*JoinToken) {
+	t := n.joinTokens
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.tokens = make(map[string]*JoinToken)
+	for _, token := range tokens {
+		t.tokens[token.Id] = token
+	}
+}
+
+// CreateJoinToken creates a join token on the leader of the raft cluster
+func (n *Node) CreateJoinToken(ctx context.Context, req *protonpb.CreateJoinTokenRequest) (*protonpb.CreateJoinTokenResponse, error) {
+	token, err := n.NewJoinToken(ctx, time.Duration(req.Ttl))
+	n.recordAudit(ctx, AuditJoinTokenCreate, n.ID, err)
+	if err != nil {
+		return &protonpb.CreateJoinTokenResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.CreateJoinTokenResponse{Success: true, Token: token}, nil
+}
+
+// joinTokensByID sorts join tokens by id
+type joinTokensByID []*JoinToken
+
+func (t joinTokensByID) Len() int           { return len(t) }
+func (t joinTokensByID) Less(i, j int) bool { return t[i].Id < t[j].Id }
+func (t joinTokensByID) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
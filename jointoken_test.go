@@ -0,0 +1,101 @@
+package proton
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJoinToken(t *testing.T) {
+	ca := []byte("ca certificate")
+	sum := sha256.Sum256(ca)
+	claims := &JoinTokenClaims{ClusterID: 42, CAHash: sum[:], ID: "abc", Secret: "s3cr3t"}
+
+	parsed, err := ParseJoinToken(claims.String())
+	assert.NoError(t, err)
+	assert.Equal(t, parsed, claims)
+	assert.NoError(t, parsed.VerifyCA(ca))
+	assert.Equal(t, parsed.VerifyCA([]byte("other")), ErrCAMismatch)
+
+	for _, token := range []string{"", "proton1.2a..abc", "proton2.2a..abc.s", "proton1.zz..abc.s", "proton1.2a.zz.abc.s", "proton1.2a...s"} {
+		_, err = ParseJoinToken(token)
+		assert.Equal(t, err, ErrInvalidJoinToken, token)
+	}
+
+	n := newQuotaNode(t)
+	defer n.Stop()
+	_, err = n.NewJoinToken(n.Ctx, 0)
+	assert.True(t, IsNotLeader(err))
+}
+
+func TestJoinToken(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+	n.RequireJoinToken = true
+	n.CACert = []byte("ca certificate")
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	resp, err := n.JoinRaft(n.Ctx, &protonpb.NodeInfo{ID: 2, Addr: "127.0.0.1:4242"})
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Equal(t, resp.Error, ErrJoinTokenRequired.Error())
+
+	token, err := n.NewJoinToken(n.Ctx, time.Minute)
+	assert.NoError(t, err)
+	claims, err := ParseJoinToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, claims.ClusterID, n.ClusterID())
+	assert.NoError(t, claims.VerifyCA(n.CACert))
+	assert.Len(t, n.JoinTokens(), 1)
+	assert.Len(t, n.snapshotState().JoinTokens, 1)
+
+	// Only the holder of the secret joins with the token
+	forged := *claims
+	forged.Secret = "guess"
+	assert.Equal(t, n.useJoinToken(n.Ctx, forged.String()), ErrInvalidJoinToken)
+	other := *claims
+	other.ClusterID++
+	assert.Equal(t, n.useJoinToken(n.Ctx, other.String()), ErrClusterMismatch)
+
+	// A token is used once
+	assert.NoError(t, n.useJoinToken(n.Ctx, token))
+	assert.Equal(t, n.useJoinToken(n.Ctx, token), ErrInvalidJoinToken)
+	assert.Empty(t, n.JoinTokens())
+
+	// The expired tokens are refused, then removed
+	expired, err := n.NewJoinToken(n.Ctx, time.Nanosecond)
+	assert.NoError(t, err)
+	assert.Equal(t, n.useJoinToken(n.Ctx, expired), ErrInvalidJoinToken)
+	token, err = n.NewJoinToken(n.Ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, n.JoinTokens(), 1)
+
+	// The token is not replicated with the member
+	resp, err = n.JoinRaft(n.Ctx, &protonpb.NodeInfo{ID: 2, Addr: "127.0.0.1:4242", Token: token})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	var member *protonpb.NodeInfo
+	for i := 0; i < 100; i++ {
+		if m, ok := n.Cluster.Member(2); ok {
+			member = m
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if assert.NotNil(t, member) {
+		assert.Equal(t, member.Token, "")
+	}
+}
@@ -0,0 +1,193 @@
+package kvstore
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/docker/libkv/store"
+)
+
+const (
+	// defaultLockTTL is the time after which a lock whose
+	// holder stopped keeping it alive is released
+	defaultLockTTL = 20 * time.Second
+)
+
+// lock is a lock held by a key written with a session, the key
+// is deleted on every member when the session ends
+type lock struct {
+	store *Proton
+	key   string
+	value []byte
+	ttl   time.Duration
+	renew chan struct{}
+
+	mu      sync.Mutex
+	session uint64
+	stop    chan struct{}
+}
+
+// NewLock returns a lock on a key. The lock is released if its
+// holder does not keep it alive for the TTL of the options, or
+// once their RenewLock channel is closed
+func (s *Proton) NewLock(key string, options *store.LockOptions) (store.Locker, error) {
+	l := &lock{store: s, key: normalize(key), ttl: defaultLockTTL}
+	if options != nil {
+		l.value = options.Value
+		l.renew = options.RenewLock
+		if options.TTL > 0 {
+			l.ttl = options.TTL
+		}
+	}
+	return l, nil
+}
+
+// Lock takes the lock, waiting for it to be released if it is
+// held elsewhere. The channel returned is closed if the lock is
+// lost, once the session holding it ended
+func (l *lock) Lock(stopChan chan struct{}) (<-chan struct{}, error) {
+	session, err := l.grant()
+	if err != nil {
+		return nil, err
+	}
+	stop := make(chan struct{})
+	lost := make(chan struct{})
+	go l.keepAlive(session, stop, lost)
+
+	for {
+		locked, err := l.tryLock(session, stopChan, lost)
+		if err == nil && locked {
+			l.mu.Lock()
+			l.session, l.stop = session, stop
+			l.mu.Unlock()
+			return lost, nil
+		}
+		if err != nil {
+			close(stop)
+			l.revoke(session)
+			return nil, err
+		}
+	}
+}
+
+// tryLock writes the key of the lock if it does not exist, or
+// else waits for it to be deleted. The stream may start after
+// the deletion, it is tried again after the ttl of the lock
+func (l *lock) tryLock(session uint64, stopChan, lost chan struct{}) (bool, error) {
+	// Watched before trying so that a release is not missed
+	done := make(chan struct{})
+	defer close(done)
+	changes, err := l.store.stream(l.key, done)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	compares := []*protonpb.Compare{{Key: l.key}}
+	write := &protonpb.Pair{Key: l.key, Value: l.value, Session: session}
+	locked, _, err := l.store.txn(ctx, compares, 0, write)
+	if err != nil || locked {
+		return locked, err
+	}
+
+	retry := time.After(l.ttl)
+	for {
+		select {
+		case <-retry:
+			return false, nil
+		case change, ok := <-changes:
+			if !ok {
+				return false, nil
+			}
+			if change.Pair.Key == l.key && change.Type == protonpb.ChangeType_DELETE {
+				return false, nil
+			}
+		case <-stopChan:
+			return false, store.ErrCannotLock
+		case <-lost:
+			return false, store.ErrCannotLock
+		}
+	}
+}
+
+// Unlock releases the lock by ending its session
+func (l *lock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stop == nil {
+		return nil
+	}
+	close(l.stop)
+	l.stop = nil
+	return l.revoke(l.session)
+}
+
+// grant starts the session holding the lock
+func (l *lock) grant() (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := l.store.client.GrantSession(ctx, &protonpb.GrantSessionRequest{Ttl: int64(l.ttl)})
+	if err != nil {
+		return 0, err
+	}
+	if !resp.Success {
+		return 0, errors.New(resp.Error)
+	}
+	return resp.Id, nil
+}
+
+// revoke ends the session holding the lock, which deletes its key
+func (l *lock) revoke(session uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := l.store.client.RevokeSession(ctx, &protonpb.RevokeSessionRequest{Id: session})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// keepAlive keeps the session alive until stop or the renew
+// channel of the lock is closed, lost is closed once the session
+// ended or could not be kept alive for its ttl
+func (l *lock) keepAlive(session uint64, stop chan struct{}, lost chan struct{}) {
+	defer close(lost)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	alive := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		case <-l.renew:
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+		resp, err := l.store.client.KeepAliveSession(ctx, &protonpb.KeepAliveSessionRequest{Id: session})
+		cancel()
+		switch {
+		case err == nil && resp.Success:
+			alive = time.Now()
+		case err == nil:
+			return
+		case time.Since(alive) > l.ttl:
+			return
+		}
+	}
+}
@@ -0,0 +1,333 @@
+// Package kvstore exposes a proton cluster through the Store
+// interface of libkv, so that software written against consul,
+// etcd or zookeeper through libkv can use a proton cluster
+package kvstore
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/docker/libkv"
+	"github.com/docker/libkv/store"
+)
+
+const (
+	// PROTON is the libkv backend of proton clusters
+	PROTON store.Backend = "proton"
+
+	// defaultTimeout is the time given to connect to a member
+	defaultTimeout = 2 * time.Second
+
+	// requestTimeout is the time given to a call to the cluster
+	requestTimeout = 10 * time.Second
+
+	// listPageSize is the number of keys listed at once
+	listPageSize = 1000
+)
+
+// Register adds proton to the backends of libkv
+func Register() {
+	libkv.AddStore(PROTON, New)
+}
+
+// Proton is a libkv store backed by a proton cluster. The keys
+// are stored without their leading and trailing slashes, and
+// the directories are the keys sharing a prefix up to a slash
+type Proton struct {
+	client    *proton.Raft
+	namespace string
+}
+
+// New connects to the first member of a proton cluster that
+// answers. The Bucket of the config is the namespace holding
+// the keys, the default namespace is used if it is empty
+func New(addrs []string, options *store.Config) (store.Store, error) {
+	timeout := defaultTimeout
+	namespace := ""
+	var opts []grpc.DialOption
+	if options != nil {
+		if options.ConnectionTimeout > 0 {
+			timeout = options.ConnectionTimeout
+		}
+		if options.TLS != nil {
+			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(options.TLS)))
+		}
+		namespace = options.Bucket
+	}
+
+	err := store.ErrNotReachable
+	for _, addr := range addrs {
+		var client *proton.Raft
+		client, err = connect(addr, timeout, opts)
+		if err == nil {
+			return &Proton{client: client, namespace: namespace}, nil
+		}
+	}
+	return nil, err
+}
+
+// connect dials a member and checks that it answers
+func connect(addr string, timeout time.Duration, opts []grpc.DialOption) (*proton.Raft, error) {
+	client, err := proton.GetRaftClient(addr, timeout, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err = client.ListMembers(ctx, &protonpb.ListMembersRequest{})
+	if err != nil {
+		client.Conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// normalize returns a key without its leading and trailing slashes
+func normalize(key string) string {
+	return strings.Trim(key, "/")
+}
+
+// directory returns the prefix of the keys of a directory
+func directory(dir string) string {
+	dir = normalize(dir)
+	if dir == "" {
+		return ""
+	}
+	return dir + "/"
+}
+
+// Put writes a value, the TTL of the options deletes it once elapsed
+func (s *Proton) Put(key string, value []byte, options *store.WriteOptions) error {
+	req := &protonpb.PutObjectRequest{
+		Object:    &protonpb.Pair{Key: normalize(key), Value: value},
+		Namespace: s.namespace,
+	}
+	if options != nil {
+		req.Ttl = int64(options.TTL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := s.client.PutObject(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Get returns the value of a key, its LastIndex is the
+// index of the raft entry which last wrote it
+func (s *Proton) Get(key string) (*store.KVPair, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	pairs, err := s.get(ctx, normalize(key))
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+	return pairs[0], nil
+}
+
+// get returns the pairs of the keys found
+func (s *Proton) get(ctx context.Context, keys ...string) ([]*store.KVPair, error) {
+	resp, err := s.client.GetObjects(ctx, &protonpb.GetObjectsRequest{
+		Keys:      keys,
+		Namespace: s.namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []*store.KVPair{}
+	for _, result := range resp.Results {
+		if result.Found {
+			pairs = append(pairs, &store.KVPair{
+				Key:       result.Key,
+				Value:     result.Value,
+				LastIndex: result.Revision,
+			})
+		}
+	}
+	return pairs, nil
+}
+
+// Delete deletes a key
+func (s *Proton) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	_, _, err := s.txn(ctx, nil, 0, &protonpb.Pair{Key: normalize(key), Delete: true})
+	return err
+}
+
+// Exists checks if a key exists
+func (s *Proton) Exists(key string) (bool, error) {
+	_, err := s.Get(key)
+	if err == store.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List returns the pairs of the keys of a directory
+func (s *Proton) List(dir string) ([]*store.KVPair, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	pairs, err := s.list(ctx, directory(dir))
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+	return pairs, nil
+}
+
+// list returns the pairs of the keys starting with a prefix
+func (s *Proton) list(ctx context.Context, prefix string) ([]*store.KVPair, error) {
+	keys, err := s.keys(ctx, prefix)
+	if err != nil || len(keys) == 0 {
+		return []*store.KVPair{}, err
+	}
+	return s.get(ctx, keys...)
+}
+
+// keys returns the keys starting with a prefix. The keys are
+// listed in order, from the first one after the prefix
+func (s *Proton) keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := prefix
+	for {
+		resp, err := s.client.ListObjects(ctx, &protonpb.ListObjectsRequest{
+			Namespace: s.namespace,
+			KeysOnly:  true,
+			Limit:     listPageSize,
+			Token:     token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range resp.Objects {
+			if !strings.HasPrefix(object.Key, prefix) {
+				return keys, nil
+			}
+			keys = append(keys, object.Key)
+		}
+		if resp.NextToken == "" {
+			return keys, nil
+		}
+		token = resp.NextToken
+	}
+}
+
+// DeleteTree deletes the keys of a directory at once
+func (s *Proton) DeleteTree(dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	keys, err := s.keys(ctx, directory(dir))
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	var writes []*protonpb.Pair
+	for _, key := range keys {
+		writes = append(writes, &protonpb.Pair{Key: key, Delete: true})
+	}
+	_, _, err = s.txn(ctx, nil, 0, writes...)
+	return err
+}
+
+// AtomicPut writes a value if the key was not written since
+// previous was read, or if it does not exist when previous is
+// nil. The TTL of the options deletes it once elapsed
+func (s *Proton) AtomicPut(key string, value []byte, previous *store.KVPair, options *store.WriteOptions) (bool, *store.KVPair, error) {
+	key = normalize(key)
+	compare := &protonpb.Compare{Key: key}
+	if previous != nil {
+		compare.Revision = previous.LastIndex
+	}
+	write := &protonpb.Pair{Key: key, Value: value}
+	var ttl time.Duration
+	if options != nil && options.TTL > 0 {
+		ttl = options.TTL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	committed, index, err := s.txn(ctx, []*protonpb.Compare{compare}, ttl, write)
+	if err != nil {
+		return false, nil, err
+	}
+	if !committed {
+		if previous == nil {
+			return false, nil, store.ErrKeyExists
+		}
+		return false, nil, store.ErrKeyModified
+	}
+	return true, &store.KVPair{Key: key, Value: value, LastIndex: index}, nil
+}
+
+// AtomicDelete deletes a key if it was not written since previous was read
+func (s *Proton) AtomicDelete(key string, previous *store.KVPair) (bool, error) {
+	if previous == nil {
+		return false, store.ErrPreviousNotSpecified
+	}
+	key = normalize(key)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	compares := []*protonpb.Compare{{Key: key, Revision: previous.LastIndex}}
+	committed, _, err := s.txn(ctx, compares, 0, &protonpb.Pair{Key: key, Delete: true})
+	if err != nil {
+		return false, err
+	}
+	if !committed {
+		return false, store.ErrKeyModified
+	}
+	return true, nil
+}
+
+// txn applies writes if the compares hold, the keys written are
+// deleted after ttl unless it is 0. It returns if the writes were
+// applied along with the index of the transaction
+func (s *Proton) txn(ctx context.Context, compares []*protonpb.Compare, ttl time.Duration, writes ...*protonpb.Pair) (bool, uint64, error) {
+	resp, err := s.client.Txn(ctx, &protonpb.TxnRequest{
+		Compares:  compares,
+		Writes:    writes,
+		Namespace: s.namespace,
+		Ttl:       int64(ttl),
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if !resp.Success {
+		return false, 0, errors.New(resp.Error)
+	}
+	return resp.Committed, resp.Index, nil
+}
+
+// Close closes the connection to the cluster
+func (s *Proton) Close() {
+	s.client.Conn.Close()
+}
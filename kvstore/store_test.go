@@ -0,0 +1,182 @@
+package kvstore
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/grpclog"
+
+	"github.com/abronan/proton"
+	"github.com/coreos/etcd/raft"
+	"github.com/docker/libkv"
+	"github.com/docker/libkv/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStore(t *testing.T) (store.Store, func()) {
+	grpclog.SetLogger(log.New(ioutil.Discard, "", log.LstdFlags))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+
+	cfg := proton.DefaultNodeConfig()
+	cfg.Logger = &raft.DefaultLogger{Logger: log.New(ioutil.Discard, "", 0)}
+	node, err := proton.NewNode(1, l.Addr().String(), cfg, nil)
+	assert.NoError(t, err)
+	node.Server = server
+
+	node.Campaign(node.Ctx)
+	go node.Start()
+	proton.Register(server, node)
+	go server.Serve(l)
+
+	for !node.IsLeader() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	Register()
+	kv, err := libkv.NewStore(PROTON, []string{l.Addr().String()}, &store.Config{Bucket: "test"})
+	assert.NoError(t, err)
+	return kv, func() {
+		kv.Close()
+		node.GracefulShutdown(time.Second)
+	}
+}
+
+func TestStore(t *testing.T) {
+	kv, stop := newStore(t)
+	defer stop()
+
+	_, err := kv.Get("foo")
+	assert.Equal(t, err, store.ErrKeyNotFound)
+
+	assert.NoError(t, kv.Put("/foo/", []byte("bar"), nil))
+	pair, err := kv.Get("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, pair.Value, []byte("bar"))
+	exists, err := kv.Exists("foo")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// Atomic operations compare the index of the last write
+	_, _, err = kv.AtomicPut("foo", []byte("baz"), nil, nil)
+	assert.Equal(t, err, store.ErrKeyExists)
+	ok, updated, err := kv.AtomicPut("foo", []byte("baz"), pair, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, err = kv.AtomicDelete("foo", pair)
+	assert.Equal(t, err, store.ErrKeyModified)
+	ok, err = kv.AtomicDelete("foo", updated)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// The TTL of an atomic put deletes the key once elapsed
+	ok, _, err = kv.AtomicPut("ttl", []byte("bar"), nil, &store.WriteOptions{TTL: 500 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	exists, err = kv.Exists("ttl")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	for i := 0; i < 100 && exists; i++ {
+		time.Sleep(50 * time.Millisecond)
+		exists, err = kv.Exists("ttl")
+		assert.NoError(t, err)
+	}
+	assert.False(t, exists)
+
+	// Directories hold the keys under their prefix
+	for _, key := range []string{"dir/a", "dir/b", "dirty"} {
+		assert.NoError(t, kv.Put(key, []byte(key), nil))
+	}
+	pairs, err := kv.List("dir")
+	assert.NoError(t, err)
+	assert.Equal(t, len(pairs), 2)
+	assert.NoError(t, kv.DeleteTree("dir"))
+	_, err = kv.List("dir")
+	assert.Equal(t, err, store.ErrKeyNotFound)
+	assert.NoError(t, kv.Delete("dirty"))
+	exists, err = kv.Exists("dirty")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestWatch(t *testing.T) {
+	kv, stop := newStore(t)
+	defer stop()
+
+	assert.NoError(t, kv.Put("foo", []byte("bar"), nil))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	watch, err := kv.Watch("foo", stopCh)
+	assert.NoError(t, err)
+	tree, err := kv.WatchTree("dir", stopCh)
+	assert.NoError(t, err)
+
+	assert.Equal(t, (<-watch).Value, []byte("bar"))
+	assert.Empty(t, <-tree)
+
+	// The streams may start after the first writes
+	var pair *store.KVPair
+	for pair == nil {
+		assert.NoError(t, kv.Put("foo", []byte("baz"), nil))
+		select {
+		case pair = <-watch:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	assert.Equal(t, pair.Value, []byte("baz"))
+
+	var pairs []*store.KVPair
+	for pairs == nil {
+		assert.NoError(t, kv.Put("dir/a", []byte("a"), nil))
+		select {
+		case pairs = <-tree:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	assert.Equal(t, len(pairs), 1)
+	assert.Equal(t, pairs[0].Key, "dir/a")
+}
+
+func TestLock(t *testing.T) {
+	kv, stop := newStore(t)
+	defer stop()
+
+	lock, err := kv.NewLock("lock", &store.LockOptions{Value: []byte("first"), TTL: 2 * time.Second})
+	assert.NoError(t, err)
+	lost, err := lock.Lock(nil)
+	assert.NoError(t, err)
+
+	pair, err := kv.Get("lock")
+	assert.NoError(t, err)
+	assert.Equal(t, pair.Value, []byte("first"))
+
+	// The lock is taken elsewhere once released
+	other, err := kv.NewLock("lock", &store.LockOptions{Value: []byte("second"), TTL: 2 * time.Second})
+	assert.NoError(t, err)
+	locked := make(chan error)
+	go func() {
+		_, err := other.Lock(nil)
+		locked <- err
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock taken while held")
+	case <-time.After(time.Second):
+	}
+
+	assert.NoError(t, lock.Unlock())
+	<-lost
+	assert.NoError(t, <-locked)
+	pair, err = kv.Get("lock")
+	assert.NoError(t, err)
+	assert.Equal(t, pair.Value, []byte("second"))
+	assert.NoError(t, other.Unlock())
+}
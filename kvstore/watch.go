@@ -0,0 +1,132 @@
+package kvstore
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton"
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/docker/libkv/store"
+)
+
+// stream returns the changes of the keys starting with a prefix,
+// with the keys of the namespace. The channel is closed once stop
+// is closed or the stream ends, a client falling behind included
+func (s *Proton) stream(prefix string, stop <-chan struct{}) (<-chan *protonpb.Change, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := s.client.StreamChanges(ctx, &protonpb.StreamChangesRequest{
+		Prefix: proton.NamespacedKey(s.namespace, prefix),
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	changes := make(chan *protonpb.Change)
+	go func() {
+		<-stop
+		cancel()
+	}()
+	go func() {
+		defer close(changes)
+		defer cancel()
+		for {
+			change, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			// The default namespace holds the prefixes of the others
+			namespace, key := proton.SplitNamespacedKey(change.Pair.Key)
+			if namespace != s.namespace {
+				continue
+			}
+			change.Pair.Key = key
+
+			select {
+			case changes <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// Watch sends the value of a key, then its value every time it
+// is written until stopCh is closed. Deletions are not sent
+func (s *Proton) Watch(key string, stopCh <-chan struct{}) (<-chan *store.KVPair, error) {
+	key = normalize(key)
+
+	// Watched first so that a write is not missed
+	changes, err := s.stream(key, stopCh)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.Get(key)
+	if err != nil && err != store.ErrKeyNotFound {
+		return nil, err
+	}
+
+	watch := make(chan *store.KVPair)
+	go func() {
+		defer close(watch)
+
+		if current != nil {
+			select {
+			case watch <- current:
+			case <-stopCh:
+				return
+			}
+		}
+
+		for change := range changes {
+			if change.Pair.Key != key || change.Type == protonpb.ChangeType_DELETE {
+				continue
+			}
+			pair := &store.KVPair{Key: key, Value: change.Pair.Value, LastIndex: change.Index}
+			select {
+			case watch <- pair:
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	return watch, nil
+}
+
+// WatchTree sends the pairs of a directory, then all of them
+// again every time one of its keys changes until stopCh is closed
+func (s *Proton) WatchTree(dir string, stopCh <-chan struct{}) (<-chan []*store.KVPair, error) {
+	prefix := directory(dir)
+
+	changes, err := s.stream(prefix, stopCh)
+	if err != nil {
+		return nil, err
+	}
+
+	watch := make(chan []*store.KVPair)
+	go func() {
+		defer close(watch)
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			pairs, err := s.list(ctx, prefix)
+			cancel()
+			if err != nil {
+				return
+			}
+
+			select {
+			case watch <- pairs:
+			case <-stopCh:
+				return
+			}
+
+			_, ok := <-changes
+			if !ok {
+				return
+			}
+		}
+	}()
+	return watch, nil
+}
@@ -0,0 +1,89 @@
+package proton
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// AuditMemberLabels is recorded when the labels of a member are replaced
+	AuditMemberLabels = "member-labels"
+)
+
+// SetLabels sets the labels the node advertises when it joins a
+// raft cluster, the labels of a member are then changed through
+// LabelMember so that every member records them through the raft
+func (n *Node) SetLabels(labels map[string]string) {
+	if peer, ok := n.Cluster.Peers()[n.ID]; ok {
+		info := *peer.NodeInfo
+		info.Labels = copyLabels(labels)
+		n.Cluster.AddPeer(&Peer{NodeInfo: &info, Client: peer.Client})
+	}
+}
+
+// Labels returns the labels of the node
+func (n *Node) Labels() map[string]string {
+	member, ok := n.Cluster.Member(n.ID)
+	if !ok {
+		return nil
+	}
+	return copyLabels(member.Labels)
+}
+
+// LabelMember replaces the labels of a member. They are proposed
+// along with the address of the member as a configuration change,
+// so that every member records them in the same order and those
+// joining later receive them from the log or the snapshots
+func (n *Node) LabelMember(ctx context.Context, req *protonpb.LabelMemberRequest) (*protonpb.LabelMemberResponse, error) {
+	err := n.labelMember(ctx, req.Id, req.Labels)
+	if err != nil {
+		return &protonpb.LabelMemberResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.LabelMemberResponse{Success: true}, nil
+}
+
+// labelMember proposes the new labels of a member
+func (n *Node) labelMember(ctx context.Context, id uint64, labels map[string]string) error {
+	member, ok := n.Cluster.Member(id)
+	if !ok {
+		return ErrMemberNotFound
+	}
+
+	update := *member
+	update.Labels = copyLabels(labels)
+	meta, err := proto.Marshal(&update)
+	if err != nil {
+		return err
+	}
+
+	err = n.ProposeConfChange(ctx, raftpb.ConfChange{
+		ID:      id,
+		Type:    raftpb.ConfChangeUpdateNode,
+		NodeID:  id,
+		Context: meta,
+	})
+	n.recordAudit(ctx, AuditMemberLabels, id, err)
+	if err != nil {
+		return ErrConfChangeRefused
+	}
+	return nil
+}
+
+// copyLabels returns a copy of labels, nil if there are none
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	return copied
+}
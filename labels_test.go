@@ -0,0 +1,69 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabels(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	assert.Nil(t, n.Labels())
+	n.SetLabels(map[string]string{"zone": "a"})
+	assert.Equal(t, n.Labels(), map[string]string{"zone": "a"})
+	assert.Equal(t, n.Info().Labels, map[string]string{"zone": "a"})
+
+	// The labels of the members are applied from the configuration changes
+	err := n.RegisterNode(n.Ctx, &protonpb.NodeInfo{ID: 2, Addr: "127.0.0.1:4242"})
+	assert.NoError(t, err)
+	client := n.Cluster.Peers()[2].Client
+
+	meta, err := proto.Marshal(&protonpb.NodeInfo{ID: 2, Addr: "127.0.0.1:4242", Labels: map[string]string{"zone": "b"}})
+	assert.NoError(t, err)
+	assert.NoError(t, n.applyUpdateNode(raftpb.ConfChange{Type: raftpb.ConfChangeUpdateNode, NodeID: 2, Context: meta}))
+	member, ok := n.Cluster.Member(2)
+	assert.True(t, ok)
+	assert.Equal(t, member.Labels, map[string]string{"zone": "b"})
+	assert.True(t, n.Cluster.Peers()[2].Client == client)
+
+	meta, err = proto.Marshal(&protonpb.NodeInfo{ID: 1, Addr: "10.0.0.1:4242", Labels: map[string]string{"zone": "c"}})
+	assert.NoError(t, err)
+	assert.NoError(t, n.applyUpdateNode(raftpb.ConfChange{Type: raftpb.ConfChangeUpdateNode, NodeID: 1, Context: meta}))
+	assert.Equal(t, n.Labels(), map[string]string{"zone": "c"})
+	assert.Equal(t, n.Info().Addr, n.Address)
+
+	assert.Equal(t, n.labelMember(n.Ctx, 5, nil), ErrMemberNotFound)
+}
+
+func TestLabelMember(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	resp, err := n.LabelMember(n.Ctx, &protonpb.LabelMemberRequest{Id: 1, Labels: map[string]string{"rack": "r1"}})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	for i := 0; i < 100 && n.Labels() == nil; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Equal(t, n.Labels(), map[string]string{"rack": "r1"})
+
+	// The snapshots carry the labels to the members joining later
+	assert.Equal(t, n.snapshotState().Members[0].Labels, map[string]string{"rack": "r1"})
+}
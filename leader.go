@@ -0,0 +1,87 @@
+package proton
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// leaderIDKey and leaderAddrKey are the keys of the trailer
+	// metadata of a request refused because it was not sent to
+	// the leader, which tell where the leader is
+	leaderIDKey   = "proton-leader-id"
+	leaderAddrKey = "proton-leader-addr"
+)
+
+var (
+	// ErrNotLeader is thrown when a request only accepted by the leader is sent to a follower
+	ErrNotLeader = errors.New("node is not the leader of the raft cluster")
+)
+
+// NotLeaderError is the ErrNotLeader thrown by a follower, along
+// with the leader it knows of so that the request is sent to it
+// without probing the members. The leader is 0 if there is none
+type NotLeaderError struct {
+	LeaderID   uint64
+	LeaderAddr string
+}
+
+func (e *NotLeaderError) Error() string {
+	if e.LeaderID == 0 {
+		return ErrNotLeader.Error() + ", the leader is unknown"
+	}
+	return fmt.Sprintf("%v, the leader is %d at %s", ErrNotLeader, e.LeaderID, e.LeaderAddr)
+}
+
+// IsNotLeader checks if a request was refused because it was not
+// sent to the leader of the raft cluster, locally or through gRPC
+func IsNotLeader(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := err.(*NotLeaderError); ok {
+		return true
+	}
+	return strings.HasPrefix(grpc.ErrorDesc(err), ErrNotLeader.Error())
+}
+
+// notLeader returns the error thrown by a follower
+func (n *Node) notLeader() error {
+	e := &NotLeaderError{}
+	if leader, ok := n.Cluster.Leader(); ok {
+		e.LeaderID, e.LeaderAddr = leader.ID, leader.Addr
+	}
+	return e
+}
+
+// setLeaderTrailer adds the leader of a NotLeaderError to the
+// trailer of a stream, for the client to redirect the request
+func setLeaderTrailer(stream grpc.ServerStream, err error) {
+	e, ok := err.(*NotLeaderError)
+	if !ok || e.LeaderID == 0 {
+		return
+	}
+	stream.SetTrailer(metadata.Pairs(
+		leaderIDKey, strconv.FormatUint(e.LeaderID, 10),
+		leaderAddrKey, e.LeaderAddr,
+	))
+}
+
+// LeaderHint returns the leader a request was redirected to
+// in the trailer metadata of its response, if there is one
+func LeaderHint(trailer metadata.MD) (*NotLeaderError, bool) {
+	ids, addrs := trailer[leaderIDKey], trailer[leaderAddrKey]
+	if len(ids) == 0 || len(addrs) == 0 {
+		return nil, false
+	}
+	id, err := strconv.ParseUint(ids[0], 10, 64)
+	if err != nil || id == 0 {
+		return nil, false
+	}
+	return &NotLeaderError{LeaderID: id, LeaderAddr: addrs[0]}, true
+}
@@ -0,0 +1,64 @@
+package proton
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotLeader(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+	n, err := NewNode(1, l.Addr().String(), cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Stop()
+	n.Server = server
+	Register(server, n)
+	go server.Serve(l)
+
+	err = n.notLeader()
+	assert.True(t, IsNotLeader(err))
+	assert.Equal(t, err.Error(), "node is not the leader of the raft cluster, the leader is unknown")
+
+	n.Cluster.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 2, Addr: "10.0.0.2:4242"}})
+	n.Cluster.setLeader(2)
+	err = n.notLeader()
+	assert.Equal(t, err, &NotLeaderError{LeaderID: 2, LeaderAddr: "10.0.0.2:4242"})
+	assert.Equal(t, err.Error(), "node is not the leader of the raft cluster, the leader is 2 at 10.0.0.2:4242")
+	assert.False(t, IsNotLeader(nil))
+	assert.False(t, IsNotLeader(ErrNoLeader))
+
+	resp, err := n.CheckLeader(context.Background(), &protonpb.CheckLeaderRequest{})
+	assert.NoError(t, err)
+	assert.False(t, resp.Leader)
+	assert.Equal(t, resp.LeaderId, uint64(2))
+	assert.Equal(t, resp.LeaderAddr, "10.0.0.2:4242")
+
+	// A client is told where the leader is along with the error
+	client, err := GetRaftClient(l.Addr().String(), time.Second)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.BulkLoad(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&protonpb.BulkLoadRequest{Pairs: []*protonpb.Pair{{Key: "a"}}}))
+	assert.NoError(t, stream.CloseSend())
+
+	_, err = stream.Recv()
+	assert.NotEqual(t, err, io.EOF)
+	assert.True(t, IsNotLeader(err))
+	hint, ok := LeaderHint(stream.Trailer())
+	assert.True(t, ok)
+	assert.Equal(t, hint, &NotLeaderError{LeaderID: 2, LeaderAddr: "10.0.0.2:4242"})
+}
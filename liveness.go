@@ -0,0 +1,65 @@
+package proton
+
+import (
+	"sync"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+// Version is the version of proton the node advertises to the members
+const Version = "0.1.0"
+
+// contacts holds the last time a message was exchanged with each
+// member, successfully sent to it or received from it
+type contacts struct {
+	lock sync.RWMutex
+	last map[uint64]time.Time
+}
+
+func newContacts() *contacts {
+	return &contacts{last: make(map[uint64]time.Time)}
+}
+
+// touch records a message exchanged with a member
+func (c *contacts) touch(id uint64) {
+	c.lock.Lock()
+	c.last[id] = time.Now()
+	c.lock.Unlock()
+}
+
+// lastContact returns the last message exchanged with a member
+func (c *contacts) lastContact(id uint64) (time.Time, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	last, ok := c.last[id]
+	return last, ok
+}
+
+// MemberStatuses returns the role and the liveness of the members,
+// ordered by id. A member is active if the node exchanged a message
+// with it within an election timeout, the raft ticking every second
+func (n *Node) MemberStatuses() []*protonpb.MemberStatus {
+	now := time.Now()
+	timeout := time.Duration(n.Cfg.ElectionTick) * time.Second
+	leader := n.Cluster.leaderID()
+
+	var statuses []*protonpb.MemberStatus
+	for _, member := range n.Cluster.Members() {
+		status := &protonpb.MemberStatus{
+			Info:   member,
+			Role:   protonpb.MemberRole_VOTER,
+			Leader: member.ID == leader,
+		}
+		last, ok := n.contacts.lastContact(member.ID)
+		if member.ID == n.ID {
+			last, ok = now, true
+		}
+		if ok {
+			status.LastContact = last.UnixNano()
+			status.Active = now.Sub(last) < timeout
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
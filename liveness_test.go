@@ -0,0 +1,51 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemberStatuses(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.Cluster.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 2, Addr: "b", Version: Version}})
+	n.Cluster.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 3, Addr: "c"}})
+	n.Cluster.setLeader(2)
+	n.contacts.touch(2)
+
+	// A member is inactive once it has not been heard of for an election timeout
+	n.contacts.lock.Lock()
+	n.contacts.last[3] = time.Now().Add(-time.Hour)
+	n.contacts.lock.Unlock()
+
+	resp, err := n.ListMembers(context.Background(), &protonpb.ListMembersRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Members, 3)
+	statuses := resp.Statuses
+	assert.Len(t, statuses, 3)
+
+	assert.Equal(t, statuses[0].Info.ID, uint64(1))
+	assert.Equal(t, statuses[0].Info.Version, Version)
+	assert.Equal(t, statuses[0].Role, protonpb.MemberRole_VOTER)
+	assert.True(t, statuses[0].Active)
+	assert.False(t, statuses[0].Leader)
+
+	assert.True(t, statuses[1].Leader)
+	assert.True(t, statuses[1].Active)
+	assert.WithinDuration(t, time.Unix(0, statuses[1].LastContact), time.Now(), time.Second)
+
+	assert.False(t, statuses[2].Active)
+	assert.NotZero(t, statuses[2].LastContact)
+
+	n.Cluster.AddPeer(&Peer{NodeInfo: &protonpb.NodeInfo{ID: 4, Addr: "d"}})
+	statuses = n.MemberStatuses()
+	assert.False(t, statuses[3].Active)
+	assert.Zero(t, statuses[3].LastContact)
+	assert.Equal(t, n.Info().Version, Version)
+}
@@ -0,0 +1,126 @@
+package proton
+
+import (
+	"log"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	// logKey is the key of the commands of the replicated log
+	logKey = systemPrefix + "log"
+)
+
+// LogEntry is a command of the replicated log, along with the
+// index and the term of the raft entry holding it
+type LogEntry struct {
+	Index uint64
+	Term  uint64
+	Data  []byte
+}
+
+// ReplicatedLog orders arbitrary commands through raft, without
+// the key-value store. The commands are neither written to the
+// store nor given to the apply handler of the node, and they are
+// not part of the snapshots: a state built from them has to be
+// restored with the snapshots of the application
+type ReplicatedLog struct {
+	node *Node
+
+	lock sync.Mutex
+	subs map[chan *LogEntry]struct{}
+}
+
+func newReplicatedLog(n *Node) *ReplicatedLog {
+	return &ReplicatedLog{node: n, subs: make(map[chan *LogEntry]struct{})}
+}
+
+// ReplicatedLog returns the replicated log of the raft cluster
+func (n *Node) ReplicatedLog() *ReplicatedLog {
+	return n.replicated
+}
+
+// Propose appends a command to the log and waits for it to be
+// applied on this node. It returns the index and the term of the
+// entry holding the command, which order it among the others
+func (l *ReplicatedLog) Propose(ctx context.Context, data []byte) (uint64, uint64, error) {
+	if l.node.IsReadOnly() {
+		return 0, 0, ErrReadOnly
+	}
+
+	pair, err := EncodePair(logKey, data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return l.node.ProposeWait(ctx, pair)
+}
+
+// Subscribe returns a channel receiving the commands in the order
+// they are applied on this node, along with a function to stop
+// the subscription. As for the changes of the store, the channel
+// is closed if more than buffer commands are pending
+func (l *ReplicatedLog) Subscribe(buffer int) (<-chan *LogEntry, func()) {
+	if buffer <= 0 {
+		buffer = DefaultSubscriptionBuffer
+	}
+
+	ch := make(chan *LogEntry, buffer)
+	l.lock.Lock()
+	l.subs[ch] = struct{}{}
+	l.lock.Unlock()
+
+	return ch, func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		if _, ok := l.subs[ch]; ok {
+			delete(l.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish sends an applied command to the subscribers
+func (l *ReplicatedLog) publish(entry *LogEntry) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for ch := range l.subs {
+		select {
+		case ch <- entry:
+		default:
+			delete(l.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// close ends the subscriptions once the node stopped
+func (l *ReplicatedLog) close() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for ch := range l.subs {
+		delete(l.subs, ch)
+		close(ch)
+	}
+}
+
+// applyLog hands over a command of the log to the subscribers
+func (n *Node) applyLog(entry raftpb.Entry, pair *LogPair) {
+	if pair.Compressed {
+		err := decompressPair(pair)
+		if err != nil {
+			log.Println("raft: ignoring command that can't be decompressed:", err)
+			return
+		}
+	}
+
+	n.replicated.publish(&LogEntry{
+		Index: entry.Index,
+		Term:  entry.Term,
+		Data:  pair.Value,
+	})
+}
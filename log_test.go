@@ -0,0 +1,32 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicatedLog(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	applied := false
+	n.apply = func(data interface{}) { applied = true }
+
+	entries, cancel := n.ReplicatedLog().Subscribe(1)
+	defer cancel()
+
+	// Commands bypass the store and the apply handler
+	applyProposal(t, n, 3, &LogPair{Key: logKey, Value: []byte("cmd")})
+	entry := <-entries
+	assert.Equal(t, entry, &LogEntry{Index: 3, Term: 1, Data: []byte("cmd")})
+	assert.False(t, applied)
+	assert.Empty(t, n.ListPairs())
+
+	// A subscriber that does not keep up is dropped
+	applyProposal(t, n, 4, &LogPair{Key: logKey, Value: []byte("1")})
+	applyProposal(t, n, 5, &LogPair{Key: logKey, Value: []byte("2")})
+	<-entries
+	_, ok := <-entries
+	assert.False(t, ok)
+}
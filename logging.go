@@ -0,0 +1,243 @@
+package proton
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	// AuditLogLevel is recorded when the log level of a node is changed
+	AuditLogLevel = "log-level"
+)
+
+// logRanks orders the log levels by verbosity
+var logRanks = map[protonpb.LogLevel]int{
+	protonpb.LogLevel_DEBUG:   0,
+	protonpb.LogLevel_INFO:    1,
+	protonpb.LogLevel_WARNING: 2,
+	protonpb.LogLevel_ERROR:   3,
+}
+
+// levelLogger drops the messages below a level that can be
+// changed while the node runs, and traces the raft messages
+// exchanged with the members when tracing is on
+type levelLogger struct {
+	raft.Logger
+
+	lock  sync.RWMutex
+	level protonpb.LogLevel
+	trace bool
+	// filter restricts the messages traced, nil traces them all
+	filter *TraceFilter
+
+	// debug writes a debug message even if the logger
+	// wrapped was not built with the debug messages on
+	debug func(msg string)
+}
+
+// newLevelLogger wraps a logger at the INFO level
+func newLevelLogger(logger raft.Logger) *levelLogger {
+	l := &levelLogger{Logger: logger}
+	l.debug = func(msg string) { logger.Debug(msg) }
+	if d, ok := logger.(*raft.DefaultLogger); ok && d.Logger != nil {
+		l.debug = func(msg string) { d.Output(3, "DEBUG: "+msg) }
+	}
+	return l
+}
+
+// enabled checks if the messages of a level are logged
+func (l *levelLogger) enabled(level protonpb.LogLevel) bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return logRanks[level] >= logRanks[l.level]
+}
+
+func (l *levelLogger) Debug(v ...interface{}) {
+	if l.enabled(protonpb.LogLevel_DEBUG) {
+		l.debug(fmt.Sprint(v...))
+	}
+}
+
+func (l *levelLogger) Debugf(format string, v ...interface{}) {
+	if l.enabled(protonpb.LogLevel_DEBUG) {
+		l.debug(fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *levelLogger) Info(v ...interface{}) {
+	if l.enabled(protonpb.LogLevel_INFO) {
+		l.Logger.Info(v...)
+	}
+}
+
+func (l *levelLogger) Infof(format string, v ...interface{}) {
+	if l.enabled(protonpb.LogLevel_INFO) {
+		l.Logger.Infof(format, v...)
+	}
+}
+
+func (l *levelLogger) Warning(v ...interface{}) {
+	if l.enabled(protonpb.LogLevel_WARNING) {
+		l.Logger.Warning(v...)
+	}
+}
+
+func (l *levelLogger) Warningf(format string, v ...interface{}) {
+	if l.enabled(protonpb.LogLevel_WARNING) {
+		l.Logger.Warningf(format, v...)
+	}
+}
+
+// SetLogLevel sets the level under which the messages of
+// the node and of its raft are dropped, while it runs
+func (n *Node) SetLogLevel(level protonpb.LogLevel) {
+	n.logger.lock.Lock()
+	n.logger.level = level
+	n.logger.lock.Unlock()
+}
+
+// LogLevel returns the level under which the messages are dropped
+func (n *Node) LogLevel() protonpb.LogLevel {
+	n.logger.lock.RLock()
+	defer n.logger.lock.RUnlock()
+	return n.logger.level
+}
+
+// SetTracing toggles the logging of every raft
+// message the node sends to and receives from the members
+func (n *Node) SetTracing(trace bool) {
+	n.logger.lock.Lock()
+	n.logger.trace = trace
+	n.logger.lock.Unlock()
+}
+
+// IsTracing checks if the raft messages are logged
+func (n *Node) IsTracing() bool {
+	n.logger.lock.RLock()
+	defer n.logger.lock.RUnlock()
+	return n.logger.trace
+}
+
+// TraceFilter restricts the raft messages traced to the ones
+// exchanged with some members or of some types, an empty list
+// does not restrict the messages
+type TraceFilter struct {
+	Peers []uint64
+	Types []raftpb.MessageType
+}
+
+// matches checks if a message passes the filter, the peer
+// of a message is the member it is sent to or received from
+func (f *TraceFilter) matches(self uint64, m raftpb.Message) bool {
+	if f == nil {
+		return true
+	}
+	peer := m.To
+	if peer == self {
+		peer = m.From
+	}
+	return (len(f.Peers) == 0 || containsID(f.Peers, peer)) &&
+		(len(f.Types) == 0 || containsType(f.Types, m.Type))
+}
+
+func containsID(ids []uint64, id uint64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(types []raftpb.MessageType, t raftpb.MessageType) bool {
+	for _, i := range types {
+		if i == t {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTraceFilter restricts the raft messages traced, so that
+// the trace of a busy cluster stays readable. A nil filter
+// traces every message
+func (n *Node) SetTraceFilter(filter *TraceFilter) {
+	n.logger.lock.Lock()
+	n.logger.filter = filter
+	n.logger.lock.Unlock()
+}
+
+// SetDebug turns the debug messages and the tracing of
+// the raft messages on, or back to the INFO level
+func (n *Node) SetDebug(debug bool) {
+	level := protonpb.LogLevel_INFO
+	if debug {
+		level = protonpb.LogLevel_DEBUG
+	}
+	n.SetLogLevel(level)
+	n.SetTracing(debug)
+}
+
+// traceMessage logs a raft message sent or received when tracing
+// is on, whatever the level. The data of the entries is not logged
+func (n *Node) traceMessage(direction string, m raftpb.Message) {
+	n.logger.lock.RLock()
+	traced := n.logger.trace && n.logger.filter.matches(n.ID, m)
+	n.logger.lock.RUnlock()
+	if !traced {
+		return
+	}
+	n.logger.Logger.Infof("raft: %s %s", direction, raft.DescribeMessage(m, describeData))
+}
+
+// describeData describes the data of an entry by its size
+func describeData(data []byte) string {
+	return fmt.Sprintf("%d bytes", len(data))
+}
+
+// ChangeLogLevel changes the log level and the tracing of a
+// node in the raft cluster
+func (n *Node) ChangeLogLevel(ctx context.Context, req *protonpb.ChangeLogLevelRequest) (*protonpb.ChangeLogLevelResponse, error) {
+	if _, ok := logRanks[req.Level]; !ok {
+		return &protonpb.ChangeLogLevelResponse{
+			Success: false,
+			Error:   fmt.Sprintf("unknown log level %d", req.Level),
+		}, nil
+	}
+	filter, err := traceFilter(req)
+	if err != nil {
+		return &protonpb.ChangeLogLevelResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+	n.SetLogLevel(req.Level)
+	n.SetTraceFilter(filter)
+	n.SetTracing(req.Trace)
+	n.recordAudit(ctx, AuditLogLevel, n.ID, nil)
+
+	return &protonpb.ChangeLogLevelResponse{Success: true}, nil
+}
+
+// traceFilter returns the filter of the messages traced
+// requested, nil if the messages are not restricted
+func traceFilter(req *protonpb.ChangeLogLevelRequest) (*TraceFilter, error) {
+	if len(req.TracePeers) == 0 && len(req.TraceTypes) == 0 {
+		return nil, nil
+	}
+	filter := &TraceFilter{Peers: req.TracePeers}
+	for _, name := range req.TraceTypes {
+		t, ok := raftpb.MessageType_value[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown message type %s", name)
+		}
+		filter.Types = append(filter.Types, raftpb.MessageType(t))
+	}
+	return filter, nil
+}
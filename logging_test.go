@@ -0,0 +1,101 @@
+package proton
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := DefaultNodeConfig()
+	cfg.Logger = &raft.DefaultLogger{Logger: log.New(&buf, "", 0)}
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Stop()
+
+	assert.Equal(t, n.LogLevel(), protonpb.LogLevel_INFO)
+	assert.False(t, n.IsTracing())
+
+	buf.Reset()
+	n.Cfg.Logger.Debugf("hidden")
+	n.Cfg.Logger.Infof("shown")
+	assert.False(t, strings.Contains(buf.String(), "hidden"))
+	assert.True(t, strings.Contains(buf.String(), "shown"))
+
+	// The debug messages are written even though the logger dropped them
+	n.SetDebug(true)
+	assert.Equal(t, n.LogLevel(), protonpb.LogLevel_DEBUG)
+	assert.True(t, n.IsTracing())
+	n.Cfg.Logger.Debugf("verbose")
+	assert.True(t, strings.Contains(buf.String(), "DEBUG: verbose"))
+
+	n.traceMessage("sending", raftpb.Message{Type: raftpb.MsgVote, From: 1, To: 2, Term: 3})
+	assert.True(t, strings.Contains(buf.String(), "sending 1->2 MsgVote Term:3"))
+
+	buf.Reset()
+	resp, err := n.ChangeLogLevel(n.Ctx, &protonpb.ChangeLogLevelRequest{Level: protonpb.LogLevel_WARNING})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.False(t, n.IsTracing())
+	n.Cfg.Logger.Infof("quiet")
+	n.Cfg.Logger.Warningf("loud")
+	n.traceMessage("sending", raftpb.Message{Type: raftpb.MsgVote, From: 1, To: 2})
+	assert.False(t, strings.Contains(buf.String(), "quiet"))
+	assert.False(t, strings.Contains(buf.String(), "MsgVote"))
+	assert.True(t, strings.Contains(buf.String(), "loud"))
+
+	resp, err = n.ChangeLogLevel(n.Ctx, &protonpb.ChangeLogLevelRequest{Level: protonpb.LogLevel(42)})
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Equal(t, n.LogLevel(), protonpb.LogLevel_WARNING)
+}
+
+func TestTraceFilter(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := DefaultNodeConfig()
+	cfg.Logger = &raft.DefaultLogger{Logger: log.New(&buf, "", 0)}
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Stop()
+
+	filter, err := traceFilter(&protonpb.ChangeLogLevelRequest{
+		TracePeers: []uint64{2},
+		TraceTypes: []string{"MsgVote", "MsgSnap"},
+	})
+	assert.NoError(t, err)
+	n.SetTraceFilter(filter)
+	n.SetTracing(true)
+
+	buf.Reset()
+
+	n.traceMessage("sending", raftpb.Message{Type: raftpb.MsgVote, From: 1, To: 2})
+	n.traceMessage("received", raftpb.Message{Type: raftpb.MsgSnap, From: 2, To: 1})
+	n.traceMessage("sending", raftpb.Message{Type: raftpb.MsgVote, From: 1, To: 3})
+	n.traceMessage("sending", raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 2})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.Contains(lines[0], "1->2 MsgVote"))
+	assert.True(t, strings.Contains(lines[1], "2->1 MsgSnap"))
+
+	// An empty filter traces every message again
+	buf.Reset()
+	filter, err = traceFilter(&protonpb.ChangeLogLevelRequest{})
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+	n.SetTraceFilter(filter)
+	n.traceMessage("sending", raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 3})
+	assert.True(t, strings.Contains(buf.String(), "MsgHeartbeat"))
+
+	resp, err := n.ChangeLogLevel(n.Ctx, &protonpb.ChangeLogLevelRequest{Trace: true, TraceTypes: []string{"MsgBogus"}})
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+}
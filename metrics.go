@@ -0,0 +1,204 @@
+package proton
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSlowApplyThreshold is the duration above which
+	// applying committed entries is reported in the logs
+	DefaultSlowApplyThreshold = 100 * time.Millisecond
+
+	// proposalExpiry is the time after which a proposal that
+	// was never committed (dropped by raft) is forgotten
+	proposalExpiry = time.Minute
+)
+
+// latencyBuckets are the upper bounds of the histogram
+// buckets, from 1ms to about 8s
+var latencyBuckets = func() []time.Duration {
+	var buckets []time.Duration
+	for b := time.Millisecond; b <= 8*time.Second; b *= 2 {
+		buckets = append(buckets, b)
+	}
+	return buckets
+}()
+
+// Histogram is a latency histogram with exponential buckets
+type Histogram struct {
+	lock   sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    time.Duration
+}
+
+// HistogramBucket is the number of observations
+// lower or equal to an upper bound
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+func newHistogram() *Histogram {
+	return &Histogram{counts: make([]uint64, len(latencyBuckets)+1)}
+}
+
+// Observe adds a latency to the histogram
+func (h *Histogram) Observe(d time.Duration) {
+	i := 0
+	for i < len(latencyBuckets) && d > latencyBuckets[i] {
+		i++
+	}
+
+	h.lock.Lock()
+	h.counts[i]++
+	h.count++
+	h.sum += d
+	h.lock.Unlock()
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.count
+}
+
+// Sum returns the sum of the observed latencies
+func (h *Histogram) Sum() time.Duration {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.sum
+}
+
+// Buckets returns the cumulative counts of the histogram,
+// observations above the last bound are only in Count
+func (h *Histogram) Buckets() []HistogramBucket {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	buckets := make([]HistogramBucket, len(latencyBuckets))
+	var cumulative uint64
+	for i, bound := range latencyBuckets {
+		cumulative += h.counts[i]
+		buckets[i] = HistogramBucket{UpperBound: bound, Count: cumulative}
+	}
+	return buckets
+}
+
+// Quantile returns the upper bound of the bucket holding
+// the q quantile of the observations, 0 if there is none
+func (h *Histogram) Quantile(q float64) time.Duration {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := uint64(q * float64(h.count))
+	var cumulative uint64
+	for i, bound := range latencyBuckets {
+		cumulative += h.counts[i]
+		if cumulative > rank {
+			return bound
+		}
+	}
+	return latencyBuckets[len(latencyBuckets)-1]
+}
+
+// LatencyMetrics holds the latency of each stage
+// an entry goes through on a node
+type LatencyMetrics struct {
+	// ProposeCommit is the time between a local proposal and its commit
+	ProposeCommit *Histogram
+	// CommitApply is the time between receiving a committed entry and applying it
+	CommitApply *Histogram
+	// Persist is the time spent saving entries to the storage
+	Persist *Histogram
+}
+
+func newLatencyMetrics() *LatencyMetrics {
+	return &LatencyMetrics{
+		ProposeCommit: newHistogram(),
+		CommitApply:   newHistogram(),
+		Persist:       newHistogram(),
+	}
+}
+
+// proposals keeps track of the time local
+// proposals were made until they are committed
+type proposals struct {
+	lock    sync.Mutex
+	pending map[uint64][]time.Time
+}
+
+func proposalHash(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
+
+// start records the time a proposal was made
+func (p *proposals) start(data []byte) {
+	h := proposalHash(data)
+	p.lock.Lock()
+	p.pending[h] = append(p.pending[h], time.Now())
+	p.lock.Unlock()
+}
+
+// cancel forgets a proposal that was not accepted
+func (p *proposals) cancel(data []byte) {
+	p.done(data)
+}
+
+// expire forgets the proposals made before a given
+// time, it returns the number of proposals forgotten
+func (p *proposals) expire(before time.Time) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	expired := 0
+	for h, times := range p.pending {
+		for len(times) > 0 && times[0].Before(before) {
+			times = times[1:]
+			expired++
+		}
+		if len(times) == 0 {
+			delete(p.pending, h)
+		} else {
+			p.pending[h] = times
+		}
+	}
+	return expired
+}
+
+// done returns the time a committed entry was proposed
+// on this node, if it was
+func (p *proposals) done(data []byte) (time.Time, bool) {
+	h := proposalHash(data)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	times, ok := p.pending[h]
+	if !ok {
+		return time.Time{}, false
+	}
+	if len(times) == 1 {
+		delete(p.pending, h)
+	} else {
+		p.pending[h] = times[1:]
+	}
+	return times[0], true
+}
+
+// observeApply reports the time spent applying a batch of
+// committed entries, logging it if it went above the threshold
+func (n *Node) observeApply(took time.Duration, entries int) {
+	if n.SlowApplyThreshold > 0 && took > n.SlowApplyThreshold {
+		n.Cfg.Logger.Warningf("raft: apply of %d entries took too long (%v), expected less than %v", entries, took, n.SlowApplyThreshold)
+	}
+}
+
+// Latency returns the latency histograms of the node
+func (n *Node) Latency() *LatencyMetrics {
+	return n.latency
+}
@@ -0,0 +1,51 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram()
+	h.Observe(500 * time.Microsecond)
+	h.Observe(3 * time.Millisecond)
+	h.Observe(time.Minute)
+
+	assert.Equal(t, h.Count(), uint64(3))
+	assert.Equal(t, h.Sum(), time.Minute+3500*time.Microsecond)
+
+	buckets := h.Buckets()
+	assert.Equal(t, buckets[0], HistogramBucket{UpperBound: time.Millisecond, Count: 1})
+	assert.Equal(t, buckets[2], HistogramBucket{UpperBound: 4 * time.Millisecond, Count: 2})
+	assert.Equal(t, buckets[len(buckets)-1].Count, uint64(2))
+}
+
+func TestProposalsLatency(t *testing.T) {
+	p := &proposals{pending: make(map[uint64][]time.Time)}
+	p.start([]byte("foo"))
+	p.start([]byte("foo"))
+
+	_, ok := p.done([]byte("bar"))
+	assert.False(t, ok)
+	_, ok = p.done([]byte("foo"))
+	assert.True(t, ok)
+
+	p.expire(time.Now().Add(time.Second))
+	_, ok = p.done([]byte("foo"))
+	assert.False(t, ok)
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := newHistogram()
+	assert.Equal(t, h.Quantile(0.5), time.Duration(0))
+
+	for i := 0; i < 9; i++ {
+		h.Observe(500 * time.Microsecond)
+	}
+	h.Observe(3 * time.Millisecond)
+
+	assert.Equal(t, h.Quantile(0.5), time.Millisecond)
+	assert.Equal(t, h.Quantile(0.95), 4*time.Millisecond)
+}
@@ -0,0 +1,95 @@
+package proton
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+// Mirror asynchronously replicates the changes applied in a
+// source cluster to a destination cluster. Only the changes
+// applied once the mirror is running are replicated
+type Mirror struct {
+	// Source is a member of the cluster to mirror
+	Source *Raft
+	// Destination is a member of the cluster receiving the changes
+	Destination *Raft
+
+	// Name identifies the source cluster, it is recorded as the
+	// origin of the mirrored values
+	Name string
+	// DestinationName identifies the destination cluster, values
+	// coming from it are not mirrored back to prevent loops
+	DestinationName string
+
+	// Prefix selects the keys to mirror
+	Prefix string
+	// DestinationPrefix replaces Prefix in the mirrored keys
+	DestinationPrefix string
+}
+
+// translate returns the key of a mirrored change in the
+// destination, split into its namespace and its key
+func (m *Mirror) translate(key string) (string, string) {
+	return SplitNamespacedKey(m.DestinationPrefix + strings.TrimPrefix(key, m.Prefix))
+}
+
+// skip checks if a change must not be mirrored
+func (m *Mirror) skip(change *protonpb.Change) bool {
+	if change.Pair == nil || isSystemKey(change.Pair.Key) {
+		return true
+	}
+	return m.DestinationName != "" && change.Pair.Origin == m.DestinationName
+}
+
+// apply writes a change of the source in the destination
+func (m *Mirror) apply(ctx context.Context, change *protonpb.Change) error {
+	origin := change.Pair.Origin
+	if origin == "" {
+		origin = m.Name
+	}
+
+	namespace, key := m.translate(change.Pair.Key)
+	resp, err := m.Destination.PutObject(ctx, &protonpb.PutObjectRequest{
+		Object: &protonpb.Pair{
+			Key:    key,
+			Value:  change.Pair.Value,
+			Origin: origin,
+		},
+		Namespace: namespace,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Run mirrors the changes until the context is done or one of
+// the clusters fails, in which case it has to be run again
+func (m *Mirror) Run(ctx context.Context) error {
+	stream, err := m.Source.StreamChanges(ctx, &protonpb.StreamChangesRequest{Prefix: m.Prefix})
+	if err != nil {
+		return err
+	}
+
+	for {
+		change, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if m.skip(change) {
+			continue
+		}
+
+		err = m.apply(ctx, change)
+		if err != nil {
+			return err
+		}
+	}
+}
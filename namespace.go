@@ -0,0 +1,128 @@
+package proton
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+const (
+	// namespacePrefix is the keyspace under which the
+	// keys of every namespace are stored
+	namespacePrefix = "__ns/"
+
+	// NamespaceSeparator separates the namespace
+	// from the key in the store
+	NamespaceSeparator = "/"
+)
+
+var (
+	// ErrInvalidNamespace is thrown when a namespace is malformed
+	ErrInvalidNamespace = errors.New("namespace must not contain a separator")
+	// ErrReservedKey is thrown when a client tries to write in a reserved keyspace
+	ErrReservedKey = errors.New("key belongs to a reserved keyspace")
+)
+
+// validateNamespace checks if a namespace can be used
+// to prefix keys in the store
+func validateNamespace(namespace string) error {
+	if strings.Contains(namespace, NamespaceSeparator) {
+		return ErrInvalidNamespace
+	}
+	return nil
+}
+
+// isReservedKey checks if a key is part of a keyspace
+// that clients can't write to directly
+func isReservedKey(key string) bool {
+	return isSystemKey(key) || strings.HasPrefix(key, namespacePrefix)
+}
+
+// NamespacedKey returns the key under which a key of a
+// namespace is stored. The empty namespace is the default
+// namespace and leaves the key untouched
+func NamespacedKey(namespace, key string) string {
+	if namespace == "" {
+		return key
+	}
+	return namespacePrefix + namespace + NamespaceSeparator + key
+}
+
+// SplitNamespacedKey returns the namespace and the key
+// of a key stored with NamespacedKey
+func SplitNamespacedKey(key string) (string, string) {
+	if !strings.HasPrefix(key, namespacePrefix) {
+		return "", key
+	}
+	parts := strings.SplitN(key[len(namespacePrefix):], NamespaceSeparator, 2)
+	if len(parts) != 2 {
+		return "", key
+	}
+	return parts[0], parts[1]
+}
+
+// ListNamespace lists the pairs of a namespace, keys
+// are returned without the namespace prefix
+func (n *Node) ListNamespace(namespace string) []*protonpb.Pair {
+	var (
+		keys  []string
+		pairs []*protonpb.Pair
+	)
+
+	n.storeLock.RLock()
+	for k, v := range n.pstore {
+		ns, key := SplitNamespacedKey(k)
+		if ns != namespace || (ns == "" && isReservedKey(k)) {
+			continue
+		}
+		keys = append(keys, k)
+		pairs = append(pairs, &protonpb.Pair{Key: key, Value: []byte(v)})
+	}
+	n.storeLock.RUnlock()
+
+	n.decryptPairs(keys, pairs)
+	return pairs
+}
+
+// ListNamespaceKeys lists the keys of a namespace without
+// their values, keys are returned without the namespace prefix
+func (n *Node) ListNamespaceKeys(namespace string) []string {
+	var keys []string
+
+	n.storeLock.RLock()
+	for k := range n.pstore {
+		ns, key := SplitNamespacedKey(k)
+		if ns != namespace || (ns == "" && isReservedKey(k)) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	n.storeLock.RUnlock()
+
+	return keys
+}
+
+// pageKeys sorts keys and returns at most limit of them after
+// token, along with the token of the next page if any is left
+func pageKeys(keys []string, token string, limit uint64) ([]string, string) {
+	sort.Strings(keys)
+
+	start := sort.SearchStrings(keys, token)
+	if start < len(keys) && keys[start] == token {
+		start++
+	}
+	keys = keys[start:]
+
+	if limit == 0 || uint64(len(keys)) <= limit {
+		return keys, ""
+	}
+	keys = keys[:limit]
+	return keys, keys[len(keys)-1]
+}
+
+// GetNamespaced returns a value of a namespace from the store
+func (n *Node) GetNamespaced(namespace, key string) string {
+	return n.Get(NamespacedKey(namespace, key))
+}
@@ -0,0 +1,127 @@
+package proton
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespacedKey(t *testing.T) {
+	assert.Equal(t, NamespacedKey("", "foo"), "foo")
+	assert.Equal(t, NamespacedKey("team", "foo/bar"), "__ns/team/foo/bar")
+
+	ns, key := SplitNamespacedKey(NamespacedKey("team", "foo/bar"))
+	assert.Equal(t, ns, "team")
+	assert.Equal(t, key, "foo/bar")
+
+	ns, key = SplitNamespacedKey("foo/bar")
+	assert.Equal(t, ns, "")
+	assert.Equal(t, key, "foo/bar")
+
+	assert.Equal(t, validateNamespace("team/a"), ErrInvalidNamespace)
+	assert.NoError(t, validateNamespace("team"))
+
+	assert.True(t, isReservedKey(auditPrefix+"1"))
+	assert.True(t, isReservedKey(NamespacedKey("team", "foo")))
+	assert.False(t, isReservedKey("foo"))
+}
+
+func TestGetObjects(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	applyPair(t, n, 3, "foo", "bar")
+	applyPair(t, n, 4, NamespacedKey("team", "foo"), "baz")
+	applyPair(t, n, 5, auditPrefix+"1", "audit")
+
+	results, err := n.MultiGet(context.Background(), []string{"foo", "missing"})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, results[0], &protonpb.GetResult{Key: "foo", Value: []byte("bar"), Found: true, Revision: 3})
+	assert.Equal(t, results[1], &protonpb.GetResult{Key: "missing"})
+
+	resp, err := n.GetObjects(context.Background(), &protonpb.GetObjectsRequest{
+		Keys:      []string{"foo", "missing"},
+		Namespace: "team",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Results[0], &protonpb.GetResult{Key: "foo", Value: []byte("baz"), Found: true, Revision: 4})
+	assert.False(t, resp.Results[1].Found)
+
+	// The reserved keyspace is hidden from the default namespace
+	resp, err = n.GetObjects(context.Background(), &protonpb.GetObjectsRequest{
+		Keys: []string{auditPrefix + "1", NamespacedKey("team", "foo")},
+	})
+	assert.NoError(t, err)
+	assert.False(t, resp.Results[0].Found)
+	assert.False(t, resp.Results[1].Found)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = n.MultiGet(ctx, []string{"foo"})
+	assert.Equal(t, err, context.Canceled)
+}
+
+func TestListObjectsKeysOnly(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	applyPair(t, n, 3, NamespacedKey("team", "foo"), "bar")
+	applyPair(t, n, 4, NamespacedKey("team", "baz"), "qux")
+	applyPair(t, n, 5, "foo", "bar")
+
+	resp, err := n.ListObjects(context.Background(), &protonpb.ListObjectsRequest{Namespace: "team", KeysOnly: true})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Count, int64(2))
+	assert.Len(t, resp.Objects, 2)
+	for _, obj := range resp.Objects {
+		assert.Nil(t, obj.Value)
+	}
+
+	resp, err = n.ListObjects(context.Background(), &protonpb.ListObjectsRequest{Namespace: "team", CountOnly: true})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Count, int64(2))
+	assert.Empty(t, resp.Objects)
+
+	resp, err = n.ListObjects(context.Background(), &protonpb.ListObjectsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Count, int64(1))
+	assert.Equal(t, resp.Objects[0].Value, []byte("bar"))
+}
+
+func TestListObjectsPages(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	for i, key := range []string{"d", "b", "e", "a", "c"} {
+		applyPair(t, n, uint64(i+3), NamespacedKey("team", key), key)
+	}
+
+	var keys []string
+	req := &protonpb.ListObjectsRequest{Namespace: "team", Limit: 2}
+	for pages := 1; ; pages++ {
+		resp, err := n.ListObjects(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.Count, int64(5))
+		for _, obj := range resp.Objects {
+			assert.Equal(t, string(obj.Value), obj.Key)
+			keys = append(keys, obj.Key)
+		}
+		if resp.NextToken == "" {
+			assert.Equal(t, pages, 3)
+			break
+		}
+		req.Token = resp.NextToken
+	}
+	assert.Equal(t, keys, []string{"a", "b", "c", "d", "e"})
+
+	// A token that is not a key starts after it
+	resp, err := n.ListObjects(context.Background(), &protonpb.ListObjectsRequest{Namespace: "team", KeysOnly: true, Token: "bb"})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Objects, 3)
+	assert.Equal(t, resp.Objects[0].Key, "c")
+	assert.Empty(t, resp.NextToken)
+}
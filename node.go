@@ -2,19 +2,22 @@ package proton
 
 import (
 	"errors"
-	"fmt"
 	"hash/fnv"
+	"io"
 	"log"
 	"math"
 	"net"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
 
 	"golang.org/x/net/context"
 
+	"github.com/abronan/proton/protonpb/v1"
 	"github.com/coreos/etcd/raft"
 	"github.com/coreos/etcd/raft/raftpb"
 	"github.com/gogo/protobuf/proto"
@@ -27,8 +30,12 @@ var (
 	ErrConnectionRefused = errors.New("connection refused to the node")
 	// ErrConfChangeRefused is thrown when there is an issue with the configuration change
 	ErrConfChangeRefused = errors.New("propose configuration change refused")
+	// ErrMemberNotFound is thrown when updating a node that is not a member of the raft cluster
+	ErrMemberNotFound = errors.New("member not found in the raft cluster")
 	// ErrApplyNotSpecified is thrown during the creation of a raft node when no apply method was provided
 	ErrApplyNotSpecified = errors.New("apply method was not specified")
+	// ErrInvalidAddress is thrown when an address is not a host and a port
+	ErrInvalidAddress = errors.New("address must be host:port, with IPv6 literals between brackets")
 )
 
 // ApplyCommand function can be used and triggered
@@ -42,6 +49,8 @@ type Node struct {
 
 	Client   *Raft
 	Cluster  *Cluster
+	conns    *connections
+	senders  *senders
 	Server   *grpc.Server
 	Listener net.Listener
 	Ctx      context.Context
@@ -52,11 +61,250 @@ type Node struct {
 	Error   error
 
 	storeLock sync.RWMutex
-	PStore    map[string]string
+	pstore    map[string]string
+	revisions map[string]uint64
+	revision  uint64
+	expiries  map[string]int64
+	expiring  map[string]time.Time
+	owners    map[string]uint64
+	blobs     map[string]*blob
+	digests   map[string]string
 	Store     *raft.MemoryStorage
 	Cfg       *raft.Config
 
+	auditLock sync.RWMutex
+	audit     []*protonpb.AuditEvent
+
+	quotaLock    sync.RWMutex
+	quota        Quota
+	nsQuotas     map[string]Quota
+	backendQuota int64
+	usage        usage
+	nsUsage      map[string]usage
+
+	alarmLock sync.RWMutex
+	alarms    map[string]*protonpb.Alarm
+
+	sessionLock sync.RWMutex
+	sessions    map[uint64]*protonpb.Session
+	keepalives  map[uint64]time.Time
+
+	semaphoreLock sync.RWMutex
+	semaphores    map[string]*protonpb.Semaphore
+	released      chan struct{}
+
+	standbyLock sync.RWMutex
+	standby     *standby
+	standbyc    chan *standbyBatch
+	viewc       chan chan *SnapshotView
+
+	partsLock sync.Mutex
+	parts     map[uint64][]*EntryPart
+
+	readOnlyLock sync.RWMutex
+	readOnly     bool
+
+	// sealIndex is the index after which every entry carries a
+	// checksum, zero until the cutover is committed
+	sealIndex    uint64
+	sealProposed time.Time
+
+	priorityLock       sync.RWMutex
+	priority           uint64
+	lastPriorityChange time.Time
+
+	// ApplyWorkers is the number of goroutines running the apply
+	// handler, the entries writing a key are always handled by the
+	// same one so that they keep their order. The handler returned
+	// for every entry committed in a batch before the next batch is
+	// applied, but ProposeWait may return before the handler of its
+	// entry. Must be set before the node is started, 0 or 1 runs
+	// the handler from the main loop
+	ApplyWorkers int
+	applier      *applier
+
+	// SlowApplyThreshold is the duration above which applying
+	// committed entries is logged, 0 disables the logging
+	SlowApplyThreshold time.Duration
+
+	// OverloadThreshold is the duration above which persisting and
+	// applying a batch of entries counts as an overload. A member
+	// that is not the leader and stays overloaded ticks its raft
+	// less often, up to MaxTickMultiplier times, which widens its
+	// election timeout so that it does not campaign while it falls
+	// behind on the heartbeats. 0 disables the adaptation
+	OverloadThreshold time.Duration
+	MaxTickMultiplier int
+	overload          *overload
+
+	// ApplyBudget is the time the main loop spends applying the
+	// entries of a batch before handling the ticks, reads and
+	// other requests waiting for it, the rest of the batch is
+	// applied in slices of the same budget. 0 applies the whole
+	// batch at once
+	ApplyBudget time.Duration
+	applyQueue  []raftpb.Entry
+	applyReady  time.Time
+	applyc      chan struct{}
+
+	// WarmupReads makes a started node refuse the client reads
+	// until it applied the entries committed before it started,
+	// as saved in its AppliedStore or told by the leader, so that
+	// a restarted member does not serve an older state than the
+	// one it served before
+	WarmupReads bool
+	warmup      *warmup
+
+	contacts     *contacts
+	proposeQueue *proposalQueue
+	reads        *readIndexes
+
+	latency      *LatencyMetrics
+	proposals    *proposals
+	waiters      *waiters
+	indexWaiters *indexWaiters
+	txns         *outcomes
+
+	subscriptions *subscriptions
+	history       *history
+	clock         *leaderClock
+	// entryTime is the timestamp of the entry being applied
+	entryTime  int64
+	hlc        hybridClock
+	clusterID  uint64
+	replicated *ReplicatedLog
+	observers  *observers
+	limiter    *rateLimiter
+
+	// HistoryLimit is the number of revisions of each key kept
+	// in memory for History, 0 disables the history
+	HistoryLimit int
+
+	// AuditLimit is the number of audit events kept in memory and in
+	// the snapshots, the oldest are dropped first. 0 keeps them all
+	AuditLimit int
+
+	// CompressionThreshold is the size in bytes above which the
+	// proposed values are compressed, 0 disables the compression
+	CompressionThreshold int
+
+	// DedupThreshold is the size in bytes above which the values
+	// are stored once by content and shared by the keys holding
+	// them. Writing a value the store already holds only sends its
+	// digest through the raft, 0 disables the deduplication
+	DedupThreshold int
+
+	// BatchWindow is the time during which the proposals made on
+	// the node are grouped into a single entry, trading a little
+	// latency for fewer entries under many concurrent writes. 0
+	// proposes every write as its own entry
+	BatchWindow time.Duration
+	batch       *batcher
+
+	// MaxEntrySize is the size in bytes above which a proposal
+	// is split into several entries, applied all at once with
+	// the last one. 0 uses the MaxSizePerMsg of the raft config
+	MaxEntrySize int
+
+	// ResolveInterval is the time between two resolutions of
+	// the hostnames of the members, a member whose hostname
+	// resolves to other IPs is dialed again. 0 disables it
+	ResolveInterval time.Duration
+
+	// DialOptions are used to dial the members in place of
+	// the default insecure connection, they must then set
+	// either grpc.WithInsecure or transport credentials
+	DialOptions []grpc.DialOption
+	// Dialer opens the connections to the members, so that
+	// they are reached through a proxy or a given network.
+	// The members are dialed over TCP if it is nil
+	Dialer Dialer
+
+	// RequireJoinToken refuses the nodes joining without a
+	// join token created by NewJoinToken, the tokens presented
+	// are checked either way. CACert is the CA certificate in DER
+	// form the members are signed with, its hash is given in the
+	// tokens so that the nodes joining can check the cluster
+	RequireJoinToken bool
+	CACert           []byte
+	joinTokens       *joinTokens
+
+	// VerifyPeerIdentity refuses the raft messages and the joins
+	// of the peers whose TLS certificate does not carry the NodeURI
+	// of the node they claim to be as a subject alternative name,
+	// or is not valid for the address of the member
+	VerifyPeerIdentity bool
+
+	// StatusDumpInterval is the time between two dumps of the
+	// raft status in JSON, 0 disables the dumps
+	StatusDumpInterval time.Duration
+	// StatusDumpWriter receives the status dumps one per
+	// line, they are logged if it is nil
+	StatusDumpWriter io.Writer
+
+	// DataDir is the directory the node keeps its files in, such
+	// as its applied index and its snapshots. Its free space is
+	// checked on every tick, under MinFreeSpace bytes the node
+	// refuses the writes and raises a NOSPACE alarm. An empty
+	// DataDir or a MinFreeSpace of 0 disables the checks
+	DataDir      string
+	MinFreeSpace uint64
+	disk         *diskSpace
+
+	// SnapshotCount is the number of applied entries after
+	// which a snapshot is taken, 0 disables the snapshots
+	SnapshotCount uint64
+
+	// JoinSnapshotEntries is the number of applied entries above
+	// which the leader sends a snapshot to a member as soon as it
+	// joins, instead of replicating the log to it. 0 leaves the
+	// raft find out by probing the member
+	JoinSnapshotEntries uint64
+
+	// IncrementalSnapshots sends members that fell behind only
+	// the keys written since their last replicated index
+	IncrementalSnapshots bool
+
+	// SnapshotStore receives the snapshots sent to the members,
+	// which are then only sent their location and digest through
+	// the raft and fetch them with their own SnapshotStore. The
+	// snapshots are streamed through the raft if it is nil
+	SnapshotStore SnapshotStore
+	offloads      *offloads
+
+	// QuiesceTicks is the number of ticks a leader waits with
+	// every member caught up before quiescing the raft, so that
+	// an idle cluster neither ticks nor sends regular heartbeats.
+	// 0 disables the quiescence
+	QuiesceTicks int
+	quiesce      *quiescence
+
+	appliedIndex  uint64
+	commitIndex   uint64
+	snapshotIndex uint64
+	confState     raftpb.ConfState
+
+	appliedStore AppliedStore
+	replayIndex  uint64
+	savedIndex   uint64
+
+	snapshotLock     sync.Mutex
+	fullSnapshots    map[uint64]bool
+	joinIndexes      map[uint64]uint64
+	snapshotThrottle *throttle
+	snapshotFunc     SnapshotFunc
+	restoreFunc      RestoreFunc
+
+	logger     *levelLogger
+	encryption encryption
+	validation validation
+
 	ticker    *time.Ticker
+	tickc     chan struct{}
+	tickStop  chan struct{}
+	tickDone  chan struct{}
+	forceChan chan chan []*protonpb.NodeInfo
+	resolvec  chan map[string][]string
 	stopChan  chan struct{}
 	pauseChan chan bool
 	pauseLock sync.RWMutex
@@ -75,17 +323,55 @@ type Node struct {
 // only channel to send event when an entry is committed
 // to the logs
 func NewNode(id uint64, addr string, cfg *raft.Config, apply ApplyCommand) (*Node, error) {
+	return newNode(id, addr, cfg, apply, []raft.Peer{{ID: id}})
+}
+
+// NewJoinNode generates a new Raft node that is meant to
+// join an existing raft cluster. Unlike NewNode, it does
+// not bootstrap a single member configuration: members
+// and log entries are received from the leader once the
+// node is added through JoinRaft
+func NewJoinNode(id uint64, addr string, cfg *raft.Config, apply ApplyCommand) (*Node, error) {
+	return newNode(id, addr, cfg, apply, nil)
+}
+
+func newNode(id uint64, addr string, cfg *raft.Config, apply ApplyCommand, peers []raft.Peer) (*Node, error) {
+	n, err := newNodeState(id, addr, cfg, apply, raft.NewMemoryStorage())
+	if err != nil {
+		return nil, err
+	}
+	// The first member bootstraps the cluster and gives it its id
+	if len(peers) > 0 {
+		n.setClusterID(peers[0].ID)
+	}
+	n.Node = raft.StartNode(n.Cfg, peers)
+	return n, nil
+}
+
+// newNodeState returns a node applying the entries persisted in
+// store, without the raft node driving it
+func newNodeState(id uint64, addr string, cfg *raft.Config, apply ApplyCommand, store *raft.MemoryStorage) (*Node, error) {
 	if cfg == nil {
 		cfg = DefaultNodeConfig()
 	}
 
-	store := raft.NewMemoryStorage()
-	peers := []raft.Peer{{ID: id}}
+	addr, err := NormalizeAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The level of the logger is changed while the node runs
+	base := cfg.Logger
+	if base == nil {
+		base = defaultLogger
+	}
+	logger := newLevelLogger(base)
 
 	n := &Node{
 		ID:      id,
 		Ctx:     context.TODO(),
 		Cluster: NewCluster(),
+		senders: newSenders(),
 		Store:   store,
 		Address: addr,
 		Cfg: &raft.Config{
@@ -95,28 +381,100 @@ func NewNode(id uint64, addr string, cfg *raft.Config, apply ApplyCommand) (*Nod
 			Storage:         store,
 			MaxSizePerMsg:   cfg.MaxSizePerMsg,
 			MaxInflightMsgs: cfg.MaxInflightMsgs,
-			Logger:          cfg.Logger,
+			CheckQuorum:     cfg.CheckQuorum,
+			PreVote:         cfg.PreVote,
+			Logger:          logger,
 		},
-		PStore:    make(map[string]string),
+		logger:    logger,
+		pstore:    make(map[string]string),
+		revisions: make(map[string]uint64),
+		expiries:  make(map[string]int64),
+		expiring:  make(map[string]time.Time),
+		owners:    make(map[string]uint64),
+		blobs:     make(map[string]*blob),
+		digests:   make(map[string]string),
+		nsQuotas:  make(map[string]Quota),
+		nsUsage:   make(map[string]usage),
+		alarms:    make(map[string]*protonpb.Alarm),
+		sessions:  make(map[uint64]*protonpb.Session),
+		latency:   newLatencyMetrics(),
+		proposals: &proposals{pending: make(map[uint64][]time.Time)},
+		waiters:   newWaiters(),
+
+		indexWaiters:  newIndexWaiters(),
+		txns:          newOutcomes(),
+		parts:         make(map[uint64][]*EntryPart),
+		subscriptions: newSubscriptions(),
+		history:       newHistory(),
+		clock:         &leaderClock{},
+		semaphores:    make(map[string]*protonpb.Semaphore),
+		released:      make(chan struct{}),
+		limiter:       newRateLimiter(),
+		joinTokens:    newJoinTokens(),
+		quiesce:       &quiescence{},
+		overload:      &overload{multiplier: 1},
+		disk:          &diskSpace{},
+		warmup:        &warmup{},
+		contacts:      newContacts(),
+		proposeQueue:  &proposalQueue{},
+		reads:         newReadIndexes(),
+		offloads:      &offloads{},
+		batch:         &batcher{},
+
+		fullSnapshots:    make(map[uint64]bool),
+		joinIndexes:      make(map[uint64]uint64),
+		snapshotThrottle: newThrottle(DefaultMaxSnapshotTransfers),
+
 		ticker:    time.NewTicker(time.Second),
+		tickc:     make(chan struct{}, 1),
+		applyc:    make(chan struct{}, 1),
+		tickStop:  make(chan struct{}),
+		tickDone:  make(chan struct{}),
+		forceChan: make(chan chan []*protonpb.NodeInfo),
+		standbyc:  make(chan *standbyBatch),
+		viewc:     make(chan chan *SnapshotView),
+		resolvec:  make(chan map[string][]string),
 		stopChan:  make(chan struct{}),
 		pauseChan: make(chan bool),
 		apply:     apply,
+
+		SlowApplyThreshold:  DefaultSlowApplyThreshold,
+		OverloadThreshold:   DefaultOverloadThreshold,
+		MaxTickMultiplier:   DefaultMaxTickMultiplier,
+		MinFreeSpace:        DefaultMinFreeSpace,
+		SnapshotCount:       DefaultSnapshotCount,
+		AuditLimit:          DefaultAuditLimit,
+		JoinSnapshotEntries: DefaultJoinSnapshotEntries,
+		ResolveInterval:     DefaultResolveInterval,
 	}
+	n.conns = newConnections(n.dialOptions)
+	n.replicated = newReplicatedLog(n)
+	n.observers = &observers{}
 
 	n.Cluster.AddPeer(
 		&Peer{
-			NodeInfo: &NodeInfo{
-				ID:   id,
-				Addr: addr,
+			NodeInfo: &protonpb.NodeInfo{
+				ID:      id,
+				Addr:    addr,
+				Version: Version,
 			},
 		},
 	)
-
-	n.Node = raft.StartNode(n.Cfg, peers)
 	return n, nil
 }
 
+// dialOptions returns the options the members are dialed with
+func (n *Node) dialOptions() []grpc.DialOption {
+	if n.Dialer == nil {
+		return n.DialOptions
+	}
+	opts := n.DialOptions
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithInsecure()}
+	}
+	return append(opts[:len(opts):len(opts)], grpc.WithDialer(n.Dialer))
+}
+
 // DefaultNodeConfig returns the default config for a
 // raft node that can be modified and customized
 func DefaultNodeConfig() *raft.Config {
@@ -144,38 +502,105 @@ func GenID(hostname string) uint64 {
 // messages received from other Raft nodes in
 // the cluster
 func (n *Node) Start() {
+	if n.ApplyWorkers > 1 && n.apply != nil {
+		n.applier = newApplier(n.ApplyWorkers, n.apply)
+	}
+
+	go n.tick()
+	go n.resolve()
+	go n.dumpStatus()
+	go n.readLoop()
+
 	for {
 		select {
-		case <-n.ticker.C:
-			n.Tick()
+		case <-n.tickc:
+			n.checkPriority()
+			for i := n.proposals.expire(time.Now().Add(-proposalExpiry)); i > 0; i-- {
+				n.observers.notify(func(o Observer) { o.OnProposalDropped(ErrProposalExpired) })
+			}
+			n.expireKeys()
+			n.expireSessions()
+			n.proposeSeal()
+			n.checkDiskSpace()
 
 		case rd := <-n.Ready():
+			ready := time.Now()
 			n.saveToStorage(rd.HardState, rd.Entries, rd.Snapshot)
+			failpoint(FailpointAfterPersist)
+			if rd.SoftState != nil {
+				n.Cluster.setLeader(rd.SoftState.Lead)
+			}
+			if !raft.IsEmptyHardState(rd.HardState) {
+				atomic.StoreUint64(&n.commitIndex, rd.HardState.Commit)
+			}
+			n.observeState(rd)
+			n.deliverReadStates(rd.ReadStates)
+			n.wakeOnReady(rd)
+			n.latency.Persist.Observe(time.Since(ready))
 			n.send(rd.Messages)
 			if !raft.IsEmptySnap(rd.Snapshot) {
+				// The entries committed before the snapshot are applied first
+				if len(n.applyQueue) > 0 {
+					n.applyQueued(0)
+				}
 				n.processSnapshot(rd.Snapshot)
 			}
-			for _, entry := range rd.CommittedEntries {
-				n.process(entry)
-				if entry.Type == raftpb.EntryConfChange {
-					var cc raftpb.ConfChange
-					err := cc.Unmarshal(entry.Data)
-					if err != nil {
-						log.Fatal("raft: Can't unmarshal configuration change")
-					}
-					switch cc.Type {
-					case raftpb.ConfChangeAddNode:
-						n.applyAddNode(cc)
-					case raftpb.ConfChangeRemoveNode:
-						n.applyRemoveNode(cc)
+			committed, cutover, err := unsealCommitted(rd.CommittedEntries, atomic.LoadUint64(&n.sealIndex))
+			if err != nil {
+				// Applying a corrupted entry is worse than stopping,
+				// the other members carry on without this one
+				log.Printf("raft: stopping node %x: %v", n.ID, err)
+				n.Stop()
+				continue
+			}
+			// The entries committed next are checked against the
+			// cutover before the ones of this batch are applied
+			atomic.CompareAndSwapUint64(&n.sealIndex, 0, cutover)
+			for _, entry := range committed {
+				if entry.Type == raftpb.EntryNormal {
+					if proposed, ok := n.proposals.done(entry.Data); ok {
+						n.latency.ProposeCommit.Observe(ready.Sub(proposed))
 					}
-					n.ApplyConfChange(cc)
 				}
 			}
+			n.queueApply(committed, ready)
+			n.applyQueued(n.ApplyBudget)
+			n.observeLoad(time.Since(ready))
 			n.Advance()
 
+		case <-n.applyc:
+			n.applyQueued(n.ApplyBudget)
+
+		case done := <-n.forceChan:
+			done <- n.forceNewCluster()
+
+		case changed := <-n.resolvec:
+			n.reconnect(changed)
+
+		case batch := <-n.standbyc:
+			n.applyStandby(batch.resp)
+			close(batch.done)
+
+		case done := <-n.viewc:
+			done <- n.copyStore()
+
 		case <-n.stopChan:
+			n.standbyLock.Lock()
+			if n.standby != nil {
+				close(n.standby.stop)
+				n.standby = nil
+			}
+			n.standbyLock.Unlock()
+			close(n.tickStop)
+			<-n.tickDone
 			n.Stop()
+			n.conns.closeAll()
+			n.senders.stopAll()
+			n.closeSubscriptions()
+			n.replicated.close()
+			if n.applier != nil {
+				n.applier.stop()
+			}
 			n.Node = nil
 			close(n.stopChan)
 			return
@@ -203,6 +628,86 @@ func (n *Node) Start() {
 	}
 }
 
+// tick advances the logical clock of the raft on its own
+// goroutine, so that a slow apply or send in the main loop
+// does not delay the elections and the heartbeats. The main
+// loop is notified of the ticks for its periodic checks
+func (n *Node) tick() {
+	defer close(n.tickDone)
+
+	for {
+		select {
+		case <-n.ticker.C:
+			// A paused node does not make any progress
+			if n.IsPaused() {
+				continue
+			}
+			if !n.quiesceTick() && n.overloadTick() {
+				n.Tick()
+			}
+			select {
+			case n.tickc <- struct{}{}:
+			default:
+			}
+		case <-n.tickStop:
+			n.ticker.Stop()
+			return
+		}
+	}
+}
+
+// resolve periodically looks for members whose hostname resolves
+// to other IPs, so that they are not reached at their previous
+// IP until the connection breaks. The lookups are made on their
+// own goroutine as they may block
+func (n *Node) resolve() {
+	if n.ResolveInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(n.ResolveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			changed := n.conns.resolved()
+			if len(changed) == 0 {
+				continue
+			}
+			select {
+			case n.resolvec <- changed:
+			case <-n.tickStop:
+				return
+			}
+		case <-n.tickStop:
+			return
+		}
+	}
+}
+
+// reconnect dials again the members whose hostname resolves
+// to other IPs. Called from the main loop
+func (n *Node) reconnect(changed map[string][]string) {
+	for addr, ips := range changed {
+		client, err := n.conns.replace(addr, ips, 2*time.Second)
+		if err != nil {
+			log.Printf("raft: can't reconnect to %s: %v", addr, err)
+			continue
+		}
+		if client == nil {
+			continue
+		}
+
+		for _, peer := range n.Cluster.Peers() {
+			if peer.Addr == addr && peer.Client != nil {
+				log.Printf("raft: %s of member %x resolves to %v, reconnecting", addr, peer.ID, ips)
+				n.Cluster.AddPeer(&Peer{NodeInfo: peer.NodeInfo, Client: client})
+			}
+		}
+	}
+}
+
 // Shutdown stops the raft node processing loop.
 // Calling Shutdown on an already stopped node
 // will result in a deadlock
@@ -210,6 +715,34 @@ func (n *Node) Shutdown() {
 	n.stopChan <- struct{}{}
 }
 
+// GracefulShutdown stops the node without cutting off its peers.
+// The Server of the node stops accepting RPCs and those in flight
+// are given timeout to complete, so that the connections of the
+// peers are closed cleanly rather than reset. The raft node keeps
+// stepping the messages received until the server stopped, then
+// it is shut down. Streams of changes are ended first as they
+// would otherwise hold the server until the timeout
+func (n *Node) GracefulShutdown(timeout time.Duration) {
+	if n.Server != nil {
+		n.closeSubscriptions()
+
+		stopped := make(chan struct{})
+		go func() {
+			n.Server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(timeout):
+			log.Printf("raft: RPCs of node %x still in flight after %v, closing connections", n.ID, timeout)
+			n.Server.Stop()
+			<-stopped
+		}
+	}
+	n.Shutdown()
+}
+
 // Pause pauses the raft node
 func (n *Node) Pause() {
 	n.pauseChan <- true
@@ -250,9 +783,94 @@ func (n *Node) Leader() uint64 {
 	return n.Node.Status().Lead
 }
 
+// Propose proposes data to be appended to the raft log after
+// checking that the node accepts writes and that it fits in
+// the quotas of the store
+func (n *Node) Propose(ctx context.Context, data []byte) error {
+	data, err := n.prepareProposal(data)
+	if err != nil {
+		return err
+	}
+	return n.propose(ctx, data)
+}
+
+// prepareProposal checks that data can be proposed and
+// returns it encrypted, and compressed if it is large enough
+func (n *Node) prepareProposal(data []byte) ([]byte, error) {
+	if n.IsStandby() {
+		return nil, ErrStandby
+	}
+
+	err := n.checkReadOnly(data)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = n.encryptProposal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.checkQuota(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.stampProposal(n.compressProposal(n.dedupProposal(data))), nil
+}
+
+// propose proposes prepared data to the raft
+func (n *Node) propose(ctx context.Context, data []byte) error {
+	limit := n.maxEntrySize()
+	if limit > 0 && len(data) > limit {
+		return n.proposeParts(ctx, data, limit)
+	}
+	if n.BatchWindow > 0 {
+		return n.proposeBatched(ctx, data, limit)
+	}
+	return n.proposeEntry(ctx, data)
+}
+
+// proposeEntry proposes prepared data as a single entry
+func (n *Node) proposeEntry(ctx context.Context, data []byte) error {
+	n.proposals.start(data)
+	err := n.proposeRaft(ctx, data)
+	if err != nil {
+		n.proposals.cancel(data)
+		n.observers.notify(func(o Observer) { o.OnProposalDropped(err) })
+	}
+	return err
+}
+
 // JoinRaft sends a configuration change to nodes to
 // add a new member to the raft cluster
-func (n *Node) JoinRaft(ctx context.Context, info *NodeInfo) (*JoinRaftResponse, error) {
+func (n *Node) JoinRaft(ctx context.Context, info *protonpb.NodeInfo) (*protonpb.JoinRaftResponse, error) {
+	addr, err := NormalizeAddr(info.Addr)
+	if err != nil {
+		return &protonpb.JoinRaftResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+	info.Addr = addr
+
+	err = n.checkPeerIdentity(ctx, info.ID, info.Addr)
+	if err == nil {
+		err = n.checkJoin(info)
+	}
+	if err == nil {
+		err = n.useJoinToken(ctx, info.Token)
+	}
+	if err != nil {
+		n.recordAudit(ctx, AuditMemberAdd, info.ID, err)
+		return &protonpb.JoinRaftResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+	// The token is not replicated with the member
+	info.Token = ""
+
 	meta, err := proto.Marshal(info)
 	if err != nil {
 		log.Fatal("Can't marshal node: ", info.ID)
@@ -266,31 +884,33 @@ func (n *Node) JoinRaft(ctx context.Context, info *NodeInfo) (*JoinRaftResponse,
 	}
 
 	err = n.ProposeConfChange(n.Ctx, confChange)
+	n.recordAudit(ctx, AuditMemberAdd, info.ID, err)
 	if err != nil {
-		return &JoinRaftResponse{
+		return &protonpb.JoinRaftResponse{
 			Success: false,
 			Error:   ErrConfChangeRefused.Error(),
 		}, nil
 	}
 
-	var nodes []*NodeInfo
-	for _, node := range n.Cluster.Peers() {
-		nodes = append(nodes, &NodeInfo{
-			ID:   node.ID,
-			Addr: node.Addr,
-		})
-	}
-
-	return &JoinRaftResponse{
+	return &protonpb.JoinRaftResponse{
 		Success: true,
-		Nodes:   nodes,
+		Nodes:   n.Cluster.Members(),
 		Error:   "",
 	}, nil
 }
 
 // LeaveRaft sends a configuration change for a node
 // that is willing to abandon its raft cluster membership
-func (n *Node) LeaveRaft(ctx context.Context, info *NodeInfo) (*LeaveRaftResponse, error) {
+func (n *Node) LeaveRaft(ctx context.Context, info *protonpb.NodeInfo) (*protonpb.LeaveRaftResponse, error) {
+	err := n.checkSender(ctx, info.ID)
+	if err != nil {
+		n.recordAudit(ctx, AuditMemberRemove, info.ID, err)
+		return &protonpb.LeaveRaftResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
 	confChange := raftpb.ConfChange{
 		ID:      info.ID,
 		Type:    raftpb.ConfChangeRemoveNode,
@@ -298,24 +918,106 @@ func (n *Node) LeaveRaft(ctx context.Context, info *NodeInfo) (*LeaveRaftRespons
 		Context: []byte(""),
 	}
 
-	err := n.ProposeConfChange(n.Ctx, confChange)
+	err = n.ProposeConfChange(n.Ctx, confChange)
+	n.recordAudit(ctx, AuditMemberRemove, info.ID, err)
 	if err != nil {
-		return &LeaveRaftResponse{
+		return &protonpb.LeaveRaftResponse{
 			Success: false,
 			Error:   ErrConfChangeRefused.Error(),
 		}, nil
 	}
 
-	return &LeaveRaftResponse{
+	return &protonpb.LeaveRaftResponse{
 		Success: true,
 		Error:   "",
 	}, nil
 }
 
+// UpdateMember sends a configuration change for a member
+// that came back with a new address, so that the others
+// reach it there without it leaving and joining again
+func (n *Node) UpdateMember(ctx context.Context, info *protonpb.NodeInfo) (*protonpb.UpdateMemberResponse, error) {
+	// The member must be valid for the address it moves to
+	addr, err := NormalizeAddr(info.Addr)
+	if err == nil {
+		err = n.checkPeerIdentity(ctx, info.ID, addr)
+	}
+	if err == nil {
+		err = n.updateMember(ctx, info)
+	}
+	if err != nil {
+		return &protonpb.UpdateMemberResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.UpdateMemberResponse{Success: true}, nil
+}
+
+// AdvertiseAddress updates the address recorded for the node in
+// the raft cluster with the one it was started with. It is meant
+// to be called by a member restarting with a new address
+func (n *Node) AdvertiseAddress(ctx context.Context) error {
+	return n.updateMember(ctx, n.Info())
+}
+
+// updateMember proposes the new address of a member
+func (n *Node) updateMember(ctx context.Context, info *protonpb.NodeInfo) error {
+	addr, err := NormalizeAddr(info.Addr)
+	if err != nil {
+		return err
+	}
+
+	member, ok := n.Cluster.Member(info.ID)
+	if !ok {
+		return ErrMemberNotFound
+	}
+	// The node always knows its own address, the others may not
+	if info.ID != n.ID && member.Addr == addr {
+		return nil
+	}
+
+	update := *member
+	update.Addr = addr
+	meta, err := proto.Marshal(&update)
+	if err != nil {
+		return err
+	}
+
+	err = n.ProposeConfChange(ctx, raftpb.ConfChange{
+		ID:      info.ID,
+		Type:    raftpb.ConfChangeUpdateNode,
+		NodeID:  info.ID,
+		Context: meta,
+	})
+	n.recordAudit(ctx, AuditMemberUpdate, info.ID, err)
+	if err != nil {
+		return ErrConfChangeRefused
+	}
+	return nil
+}
+
 // Send calls 'Step' which advances the raft state
 // machine with the received message
 func (n *Node) Send(ctx context.Context, msg *raftpb.Message) (*SendResponse, error) {
-	var err error
+	err := n.checkSender(ctx, msg.From)
+	if err == nil {
+		err = verifyEntries(msg, atomic.LoadUint64(&n.sealIndex))
+	}
+	if err == nil {
+		err = n.fetchSnapshot(ctx, msg)
+	}
+	if err == nil {
+		err = n.verifySnapshot(msg)
+	}
+	if err != nil {
+		return &SendResponse{Error: err.Error()}, nil
+	}
+
+	n.traceMessage("received", *msg)
+	n.receiveQuiesce(msg)
+	n.contacts.touch(msg.From)
 
 	if n.IsPaused() {
 		n.pauseLock.Lock()
@@ -328,46 +1030,176 @@ func (n *Node) Send(ctx context.Context, msg *raftpb.Message) (*SendResponse, er
 		}
 	}
 
-	return &SendResponse{Error: ""}, nil
+	resp := &SendResponse{Error: ""}
+	if n.Cluster.leaderID() == n.ID {
+		resp.LeaderTime = time.Now().UnixNano()
+		resp.LeaderCommit = atomic.LoadUint64(&n.commitIndex)
+	}
+	return resp, nil
 }
 
 // ListMembers lists the members in the raft cluster
-func (n *Node) ListMembers(ctx context.Context, req *ListMembersRequest) (*ListMembersResponse, error) {
-	var peers []*NodeInfo
-	for _, peer := range n.Cluster.Peers() {
-		peers = append(peers, peer.NodeInfo)
-	}
-
-	return &ListMembersResponse{Members: peers}, nil
+func (n *Node) ListMembers(ctx context.Context, req *protonpb.ListMembersRequest) (*protonpb.ListMembersResponse, error) {
+	return &protonpb.ListMembersResponse{
+		Members:  n.Cluster.Members(),
+		Statuses: n.MemberStatuses(),
+	}, nil
 }
 
 // Put proposes and puts a value in the raft cluster
-func (n *Node) PutObject(ctx context.Context, req *PutObjectRequest) (*PutObjectResponse, error) {
-	pair, err := EncodePair(req.Object.Key, req.Object.Value)
+func (n *Node) PutObject(ctx context.Context, req *protonpb.PutObjectRequest) (*protonpb.PutObjectResponse, error) {
+	err := validateNamespace(req.Namespace)
+	if err != nil {
+		return &protonpb.PutObjectResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if req.Namespace == "" && isReservedKey(req.Object.Key) {
+		return &protonpb.PutObjectResponse{
+			Success: false,
+			Error:   ErrReservedKey.Error(),
+		}, nil
+	}
+
+	if req.Ttl < 0 {
+		return &protonpb.PutObjectResponse{
+			Success: false,
+			Error:   ErrInvalidTTL.Error(),
+		}, nil
+	}
+
+	if req.Object.Session != 0 && !n.hasSession(req.Object.Session) {
+		return &protonpb.PutObjectResponse{
+			Success: false,
+			Error:   ErrSessionNotFound.Error(),
+		}, nil
+	}
+
+	err = n.checkRate(ctx)
 	if err != nil {
-		return &PutObjectResponse{
+		return &protonpb.PutObjectResponse{
 			Success: false,
 			Error:   err.Error(),
 		}, nil
 	}
 
+	object := &LogPair{
+		Key:     NamespacedKey(req.Namespace, req.Object.Key),
+		Value:   req.Object.Value,
+		Origin:  req.Object.Origin,
+		Session: req.Object.Session,
+		After:   req.After,
+	}
+	if req.Ttl > 0 {
+		object.Expires = n.deadline(time.Duration(req.Ttl))
+	}
+
+	pair, err := proto.Marshal(object)
+	if err != nil {
+		return &protonpb.PutObjectResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, proposeTimeout)
+	defer cancel()
+
 	// Propose the value to the raft
-	err = n.Propose(n.Ctx, pair)
+	c, err := n.proposeWait(ctx, pair)
 	if err != nil {
-		return &PutObjectResponse{
+		return &protonpb.PutObjectResponse{
 			Success: false,
 			Error:   err.Error(),
 		}, nil
 	}
 
-	return &PutObjectResponse{Success: true}, nil
+	return &protonpb.PutObjectResponse{
+		Success: true,
+		Index:   c.index,
+		Term:    c.term,
+		Hlc:     c.hlc,
+	}, nil
 }
 
 // ListObjects list the objects in the raft cluster
-func (n *Node) ListObjects(ctx context.Context, req *ListObjectsRequest) (*ListObjectsResponse, error) {
-	pairs := n.ListPairs()
+func (n *Node) ListObjects(ctx context.Context, req *protonpb.ListObjectsRequest) (*protonpb.ListObjectsResponse, error) {
+	err := validateNamespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !n.IsWarm() {
+		return nil, ErrWarmingUp
+	}
+
+	keys := n.ListNamespaceKeys(req.Namespace)
+	resp := &protonpb.ListObjectsResponse{Count: int64(len(keys))}
+	if req.CountOnly {
+		return resp, nil
+	}
+
+	// Large namespaces are listed in pages sorted by key
+	keys, resp.NextToken = pageKeys(keys, req.Token, req.Limit)
+
+	// Existence checks don't need the values
+	if req.KeysOnly {
+		for _, key := range keys {
+			resp.Objects = append(resp.Objects, &protonpb.Pair{Key: key})
+		}
+		return resp, nil
+	}
+
+	namespaced := make([]string, len(keys))
+	for i, key := range keys {
+		namespaced[i] = NamespacedKey(req.Namespace, key)
+	}
+	results, err := n.MultiGet(ctx, namespaced)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result.Found {
+			resp.Objects = append(resp.Objects, &protonpb.Pair{Key: keys[i], Value: result.Value})
+		}
+	}
+	return resp, nil
+}
+
+// GetObjects returns the values of many keys of a namespace in the raft cluster
+func (n *Node) GetObjects(ctx context.Context, req *protonpb.GetObjectsRequest) (*protonpb.GetObjectsResponse, error) {
+	err := validateNamespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !n.IsWarm() {
+		return nil, ErrWarmingUp
+	}
+	if req.Linearizable {
+		if err := n.LinearizableRead(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]string, len(req.Keys))
+	for i, key := range req.Keys {
+		keys[i] = NamespacedKey(req.Namespace, key)
+	}
+
+	results, err := n.MultiGet(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
 
-	return &ListObjectsResponse{Objects: pairs}, nil
+	// Keys are returned as requested, the reserved keyspace is hidden
+	for i, result := range results {
+		result.Key = req.Keys[i]
+		if req.Namespace == "" && isReservedKey(keys[i]) {
+			result.Value, result.Found, result.Revision = nil, false, 0
+		}
+	}
+	return &protonpb.GetObjectsResponse{Results: results}, nil
 }
 
 // RemoveNode removes a node from the raft cluster
@@ -380,25 +1212,50 @@ func (n *Node) RemoveNode(node *Peer) error {
 	}
 
 	err := n.ProposeConfChange(n.Ctx, confChange)
+	n.recordAudit(n.Ctx, AuditMemberRemove, node.ID, err)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// RegisterNode registers a new node on the cluster
-func (n *Node) RegisterNode(node *NodeInfo) error {
+// RegisterNode registers a new node on the cluster, the
+// connection is retried with an exponential backoff until
+// MaxRetryTime attempts are made or the context is done
+func (n *Node) RegisterNode(ctx context.Context, node *protonpb.NodeInfo) error {
 	var (
 		client *Raft
 		err    error
 	)
 
-	for i := 1; i <= MaxRetryTime; i++ {
-		client, err = GetRaftClient(node.Addr, 2*time.Second)
-		if err != nil {
-			if i == MaxRetryTime {
-				return ErrConnectionRefused
-			}
+	// Do not register yourself, the members returned
+	// by JoinRaft may already include the new node
+	if n.ID == node.ID {
+		return nil
+	}
+
+	old, exists := n.Cluster.Peers()[node.ID]
+	if exists && old.Client != nil && old.Addr == node.Addr {
+		// Keep the connection to the member
+		n.Cluster.AddPeer(&Peer{NodeInfo: node, Client: old.Client})
+		return nil
+	}
+
+	backoff := RetryBackoff
+	for i := 1; ; i++ {
+		client, err = n.conns.get(node.Addr, 2*time.Second)
+		if err == nil {
+			break
+		}
+		if i >= MaxRetryTime {
+			return ErrConnectionRefused
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 
@@ -409,19 +1266,46 @@ func (n *Node) RegisterNode(node *NodeInfo) error {
 		},
 	)
 
+	if exists && old.Client != nil {
+		n.conns.release(old.Addr)
+	}
+
 	return nil
 }
 
-// RegisterNodes registers a set of nodes in the cluster
-func (n *Node) RegisterNodes(nodes []*NodeInfo) (err error) {
+// RegisterNodes registers a set of nodes in the cluster. The nodes
+// are registered at once and it returns as soon as a quorum of them
+// is, so that a slow member does not hold up a join. The others keep
+// being registered in the background, and the members are registered
+// again anyway as the raft replicates their membership to the node
+func (n *Node) RegisterNodes(ctx context.Context, nodes []*protonpb.NodeInfo) error {
+	results := make(chan error, len(nodes))
 	for _, node := range nodes {
-		err = n.RegisterNode(node)
-		if err != nil {
-			return err
-		}
+		go func(node *protonpb.NodeInfo) {
+			err := n.RegisterNode(ctx, node)
+			if err != nil {
+				log.Printf("raft: can't register member %x at %s: %v", node.ID, node.Addr, err)
+			}
+			results <- err
+		}(node)
 	}
 
-	return nil
+	var err error
+	registered, quorum := 0, len(nodes)/2+1
+	for range nodes {
+		rerr := <-results
+		if rerr != nil {
+			if err == nil {
+				err = rerr
+			}
+			continue
+		}
+		registered++
+		if registered >= quorum {
+			return nil
+		}
+	}
+	return err
 }
 
 // UnregisterNode unregisters a node that has died or
@@ -432,55 +1316,141 @@ func (n *Node) UnregisterNode(id uint64) {
 		return
 	}
 
-	n.Cluster.Peers()[id].Client.Conn.Close()
+	peer, ok := n.Cluster.Peers()[id]
+	if !ok {
+		return
+	}
+
 	n.Cluster.RemovePeer(id)
+	n.senders.remove(id)
+	if peer.Client != nil {
+		n.conns.release(peer.Addr)
+	}
 }
 
-// Get returns a value from the PStore
+// Get returns a value from the store
 func (n *Node) Get(key string) string {
 	n.storeLock.RLock()
-	defer n.storeLock.RUnlock()
-	return n.PStore[key]
+	value, ok := n.pstore[key]
+	n.storeLock.RUnlock()
+	if !ok {
+		return ""
+	}
+	return string(n.decryptValue(key, []byte(value)))
 }
 
 // Put puts a value in the raft store
 func (n *Node) Put(key string, value string) {
+	n.put(key, value, "", 0)
+}
+
+// put puts a value in the raft store along with the index
+// of the entry that wrote it and the digest of a value stored
+// by content, it returns the new revision of the store and
+// the value it replaced if any
+func (n *Node) put(key string, value string, digest string, index uint64) (uint64, string, bool) {
 	n.storeLock.Lock()
 	defer n.storeLock.Unlock()
-	n.PStore[key] = value
+	old, exists := n.pstore[key]
+	n.account(key, old, exists, value)
+	if digest != "" {
+		value = n.ref(key, digest, value)
+	} else {
+		n.unref(key)
+	}
+	n.pstore[key] = value
+	n.revisions[key] = index
+	delete(n.expiries, key)
+	delete(n.owners, key)
+	n.revision++
+	return n.revision, old, exists
 }
 
-// List lists the pair in the store
-func (n *Node) ListPairs() []*Pair {
+// remove deletes a key from the raft store, it returns
+// the new revision of the store and the value removed
+func (n *Node) remove(key string, index uint64) (uint64, string, bool) {
 	n.storeLock.Lock()
 	defer n.storeLock.Unlock()
-	var pairs []*Pair
-	for k, v := range n.PStore {
-		pairs = append(pairs, &Pair{Key: k, Value: []byte(v)})
+	old, exists := n.pstore[key]
+	if !exists {
+		return n.revision, "", false
 	}
+	n.unaccount(key, old)
+	n.unref(key)
+	delete(n.pstore, key)
+	delete(n.revisions, key)
+	delete(n.expiries, key)
+	delete(n.owners, key)
+	n.revision++
+	return n.revision, old, true
+}
+
+// MultiGet returns the values of many keys read at once from the
+// store, along with whether each key was found, in the order of keys
+func (n *Node) MultiGet(ctx context.Context, keys []string) ([]*protonpb.GetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]*protonpb.GetResult, len(keys))
+	n.storeLock.RLock()
+	for i, key := range keys {
+		results[i] = &protonpb.GetResult{Key: key}
+		if value, ok := n.pstore[key]; ok {
+			results[i].Value, results[i].Found = []byte(value), true
+			results[i].Revision = n.revisions[key]
+		}
+	}
+	n.storeLock.RUnlock()
+
+	for _, result := range results {
+		if result.Found {
+			result.Value = n.decryptValue(result.Key, result.Value)
+		}
+	}
+	return results, nil
+}
+
+// List lists the pair in the store
+func (n *Node) ListPairs() []*protonpb.Pair {
+	var (
+		keys  []string
+		pairs []*protonpb.Pair
+	)
+
+	n.storeLock.RLock()
+	for k, v := range n.pstore {
+		keys = append(keys, k)
+		pairs = append(pairs, &protonpb.Pair{Key: k, Value: []byte(v)})
+	}
+	n.storeLock.RUnlock()
+
+	n.decryptPairs(keys, pairs)
 	return pairs
 }
 
 // StoreLength returns the length of the store
 func (n *Node) StoreLength() int {
-	n.storeLock.Lock()
-	defer n.storeLock.Unlock()
-	return len(n.PStore)
+	n.storeLock.RLock()
+	defer n.storeLock.RUnlock()
+	return len(n.pstore)
 }
 
 // applyAddNode is called when we receive a ConfChange
 // from a member in the raft cluster, this adds a new
 // node to the existing raft cluster
 func (n *Node) applyAddNode(conf raftpb.ConfChange) error {
-	peer := &NodeInfo{}
+	peer := &protonpb.NodeInfo{}
 	err := proto.Unmarshal(conf.Context, peer)
 	if err != nil {
 		return err
 	}
-	if n.ID != peer.ID {
-		n.RegisterNode(peer)
+	// The entries bootstrapping a node carry no member information
+	if peer.ID == raft.None || n.ID == peer.ID {
+		return nil
 	}
-	return nil
+	n.recordJoinIndex(peer)
+	return n.RegisterNode(n.Ctx, peer)
 }
 
 // applyRemoveNode is called when we receive a ConfChange
@@ -500,6 +1470,26 @@ func (n *Node) applyRemoveNode(conf raftpb.ConfChange) {
 	n.UnregisterNode(conf.NodeID)
 }
 
+// applyUpdateNode is called when we receive a ConfChange
+// updating the address of a member, which is then reached
+// at its new address
+func (n *Node) applyUpdateNode(conf raftpb.ConfChange) error {
+	peer := &protonpb.NodeInfo{}
+	err := proto.Unmarshal(conf.Context, peer)
+	if err != nil {
+		return err
+	}
+	// The node keeps the address it was started with
+	if n.ID == peer.ID {
+		n.SetLabels(peer.Labels)
+		return nil
+	}
+	if _, ok := n.Cluster.Member(peer.ID); !ok {
+		return nil
+	}
+	return n.RegisterNode(n.Ctx, peer)
+}
+
 // Saves a log entry to our Store
 func (n *Node) saveToStorage(hardState raftpb.HardState, entries []raftpb.Entry, snapshot raftpb.Snapshot) {
 	n.Store.Append(entries)
@@ -518,6 +1508,8 @@ func (n *Node) send(messages []raftpb.Message) {
 	peers := n.Cluster.Peers()
 
 	for _, m := range messages {
+		n.traceMessage("sending", m)
+
 		// Process locally
 		if m.To == n.ID {
 			n.Step(n.Ctx, m)
@@ -526,37 +1518,228 @@ func (n *Node) send(messages []raftpb.Message) {
 
 		// If node is an active raft member send the message
 		if peer, ok := peers[m.To]; ok {
-			_, err := peer.Client.Send(n.Ctx, &m)
-			if err != nil {
+			if m.Type == raftpb.MsgSnap {
+				go n.sendSnapshot(peer, m)
+				continue
+			}
+			if !n.senders.get(n, peer).enqueue(m) {
 				n.ReportUnreachable(peer.ID)
 			}
 		}
 	}
 }
 
+// applyEntry applies a committed entry and wakes up its waiters
+func (n *Node) applyEntry(entry raftpb.Entry, ready time.Time) {
+	// A promoted standby already applied the entries it fetched
+	if entry.Index > n.appliedIndex {
+		failpoint(FailpointBeforeApply)
+		n.process(entry)
+	}
+	atomic.StoreUint64(&n.appliedIndex, entry.Index)
+	if entry.Type == raftpb.EntryNormal {
+		n.waiters.trigger(entry, n.takeRejection(), n.HLC())
+	}
+	n.latency.CommitApply.Observe(time.Since(ready))
+	if entry.Type == raftpb.EntryConfChange {
+		var cc raftpb.ConfChange
+		err := cc.Unmarshal(entry.Data)
+		if err != nil {
+			// Every member skips the entry, which keeps them in
+			// agreement. The raft is told that the change is done
+			log.Printf("raft: ignoring configuration change %d that can't be decoded: %v", entry.Index, err)
+			n.ApplyConfChange(raftpb.ConfChange{NodeID: raft.None})
+			return
+		}
+		switch cc.Type {
+		case raftpb.ConfChangeAddNode:
+			err = n.applyAddNode(cc)
+			if err != nil {
+				log.Println("raft: can't register new member:", err)
+			}
+		case raftpb.ConfChangeRemoveNode:
+			n.applyRemoveNode(cc)
+		case raftpb.ConfChangeUpdateNode:
+			err = n.applyUpdateNode(cc)
+			if err != nil {
+				log.Println("raft: can't update member:", err)
+			}
+		}
+		n.learnClusterID(entry, cc)
+		n.confState = *n.ApplyConfChange(cc)
+		n.observers.notify(func(o Observer) { o.OnConfChange(cc) })
+		if cc.Type == raftpb.ConfChangeAddNode {
+			n.sendJoinSnapshot(cc.NodeID)
+		}
+	}
+}
+
 // Process a data entry and optionnally triggers an event
 // or a function handler after the entry is processed
 func (n *Node) process(entry raftpb.Entry) {
 	if entry.Type == raftpb.EntryNormal && entry.Data != nil {
-		pair := &Pair{}
+		pair := &LogPair{}
 		err := proto.Unmarshal(entry.Data, pair)
 		if err != nil {
-			log.Fatal("raft: Can't decode key and value sent through raft")
+			// Every member skips the entry, which keeps them in agreement
+			log.Printf("raft: ignoring entry %d that can't be decoded: %v", entry.Index, err)
+			return
 		}
+		// The writes of a transaction share the time of its entry
+		n.entryTime = pair.Timestamp
+		n.tickHLC(pair)
 
-		// Apply the command
-		if n.apply != nil {
-			n.apply(entry.Data)
+		// Internal cluster state is not exposed to the handler
+		if isSystemKey(pair.Key) {
+			n.processSystem(entry, pair)
+			return
 		}
 
-		// Put the value into the store
-		n.Put(pair.Key, string(pair.Value))
+		n.processPair(entry, pair, entry.Data, true)
 	}
 }
 
-// Process snapshot is not yet implemented but applies
-// a snapshot to handle node failures and restart
-func (n *Node) processSnapshot(snapshot raftpb.Snapshot) {
-	// TODO
-	panic(fmt.Sprintf("Applying snapshot on node %v is not implemented", n.ID))
+// processPair applies the write or the deletion of a key
+// by an entry, data is the encoded pair. The write is checked
+// by the validator unless it was already
+func (n *Node) processPair(entry raftpb.Entry, pair *LogPair, data []byte, validate bool) {
+	var err error
+	if pair.Delete {
+		n.processDelete(entry, pair, data)
+		return
+	}
+
+	// A key can't outlive its session
+	if pair.Session != 0 && !n.hasSession(pair.Session) {
+		log.Printf("raft: ignoring write of %s by session %x which ended", pair.Key, pair.Session)
+		return
+	}
+
+	// The store and the handler only see the original value
+	if pair.Compressed {
+		err = decompressPair(pair)
+		if err != nil {
+			log.Printf("raft: ignoring write of %s that can't be decompressed: %v", pair.Key, err)
+			return
+		}
+		data, err = proto.Marshal(pair)
+		if err != nil {
+			log.Fatal("raft: Can't encode decompressed key and value")
+		}
+	}
+
+	// A value stored by content may only carry its digest
+	if len(pair.Digest) > 0 {
+		if !n.resolveDigest(pair) {
+			return
+		}
+		data, err = proto.Marshal(pair)
+		if err != nil {
+			log.Fatal("raft: Can't encode key and value stored by content")
+		}
+	}
+
+	// Values are stored encrypted
+	value := string(pair.Value)
+	if n.decrypter() != nil {
+		pair.Value = n.decryptValue(pair.Key, pair.Value)
+		data, err = proto.Marshal(pair)
+		if err != nil {
+			log.Fatal("raft: Can't encode decrypted key and value")
+		}
+	}
+
+	// The application may reject the write, on every member alike
+	if validate && !n.validateWrite(entry, pair.Key, pair.Value) {
+		return
+	}
+
+	// Apply the command
+	n.applyHandler(entry, pair.Key, data)
+
+	// Put the value into the store
+	revision, old, exists := n.put(pair.Key, value, string(pair.Digest), entry.Index)
+	if pair.Expires != 0 {
+		n.setExpiry(pair.Key, pair.Expires)
+	}
+	if pair.Session != 0 {
+		n.setOwner(pair.Key, pair.Session)
+	}
+
+	change := &protonpb.Change{
+		Pair:         publicPair(pair),
+		Index:        entry.Index,
+		Term:         entry.Term,
+		Revision:     revision,
+		Type:         protonpb.ChangeType_CREATE,
+		ValueChanged: !exists || old != value,
+		Timestamp:    n.entryTime,
+		Hlc:          n.HLC(),
+	}
+	if exists {
+		change.Type = protonpb.ChangeType_UPDATE
+	}
+	n.recordHistory(change)
+	n.publish(change)
+}
+
+// processDelete applies the deletion of a key
+func (n *Node) processDelete(entry raftpb.Entry, pair *LogPair, data []byte) {
+	// An expiration does not delete a value written since
+	if pair.Expires != 0 && n.expiry(pair.Key) != pair.Expires {
+		return
+	}
+	n.deleteKey(entry, pair.Key, data)
+}
+
+// deleteKey removes a key from the store, data is
+// the deletion given to the apply handler
+func (n *Node) deleteKey(entry raftpb.Entry, key string, data []byte) {
+	revision, _, exists := n.remove(key, entry.Index)
+	if !exists {
+		return
+	}
+
+	n.applyHandler(entry, key, data)
+
+	change := &protonpb.Change{
+		Pair:         &protonpb.Pair{Key: key, Delete: true},
+		Index:        entry.Index,
+		Term:         entry.Term,
+		Revision:     revision,
+		Type:         protonpb.ChangeType_DELETE,
+		ValueChanged: true,
+		Timestamp:    n.entryTime,
+		Hlc:          n.HLC(),
+	}
+	n.recordHistory(change)
+	n.publish(change)
+}
+
+// processSystem applies an entry from the reserved keyspace
+func (n *Node) processSystem(entry raftpb.Entry, pair *LogPair) {
+	switch {
+	case strings.HasPrefix(pair.Key, auditPrefix):
+		n.applyAudit(pair)
+	case strings.HasPrefix(pair.Key, alarmPrefix):
+		n.applyAlarm(pair)
+	case strings.HasPrefix(pair.Key, sessionPrefix):
+		n.applySession(entry, pair)
+	case strings.HasPrefix(pair.Key, semaphorePrefix):
+		n.applySemaphore(entry, pair)
+	case strings.HasPrefix(pair.Key, ratePrefix):
+		n.applyRateLimit(pair)
+	case strings.HasPrefix(pair.Key, tokenPrefix):
+		n.applyJoinToken(pair)
+	case pair.Key == txnKey:
+		n.applyTxn(entry, pair)
+	case pair.Key == partKey:
+		n.applyPart(entry, pair)
+	case pair.Key == batchKey:
+		n.applyBatch(entry, pair)
+	case pair.Key == logKey:
+		n.applyLog(entry, pair)
+	case pair.Key == sealKey:
+		n.applySeal(entry)
+	}
 }
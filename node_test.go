@@ -4,12 +4,17 @@ import (
 	"io/ioutil"
 	"log"
 	"net"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/grpclog"
 
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
 	"github.com/coreos/etcd/raft"
 	"github.com/stretchr/testify/assert"
 )
@@ -34,6 +39,22 @@ func TestRaft(t *testing.T) {
 	testLeaderLeave(t)
 	testFollowerLeave(t)
 	testPauseFollower(t)
+	testAuditMembership(t)
+	testLeaderPriority(t)
+	testDrainLeader(t)
+	testProposeWait(t)
+	testWaitForIndex(t)
+	testFollowerLinearizableRead(t)
+	testForceNewCluster(t)
+	testTTL(t)
+	testSessions(t)
+	testSemaphore(t)
+	testSTM(t)
+	testStandby(t)
+	testUpdateMember(t)
+	testSplitProposal(t)
+	testGracefulShutdown(t)
+	testReplicatedLog(t)
 
 	// TODO
 	testSnapshot(t)
@@ -64,6 +85,10 @@ func newInitNode(t *testing.T, id uint64) *Node {
 }
 
 func newJoinNode(t *testing.T, id uint64, join string) *Node {
+	return newPriorityJoinNode(t, id, join, 0)
+}
+
+func newPriorityJoinNode(t *testing.T, id uint64, join string, priority uint64) *Node {
 	l, err := net.Listen("tcp", "127.0.0.1:0")
 	assert.NoError(t, err, "Can't bind to raft service port")
 	s := grpc.NewServer()
@@ -71,20 +96,21 @@ func newJoinNode(t *testing.T, id uint64, join string) *Node {
 	cfg := DefaultNodeConfig()
 	cfg.Logger = raftLogger
 
-	n, err := NewNode(id, l.Addr().String(), cfg, nil)
+	n, err := NewJoinNode(id, l.Addr().String(), cfg, nil)
 	assert.NoError(t, err, "Can't create raft node")
 	n.Listener = l
 	n.Server = s
+	n.SetPriority(priority)
 
 	go n.Start()
 
 	c, err := GetRaftClient(join, 100*time.Millisecond)
 	assert.NoError(t, err, "Can't initiate connection with existing raft")
 
-	resp, err := c.JoinRaft(n.Ctx, &NodeInfo{ID: id, Addr: l.Addr().String()})
+	resp, err := c.JoinRaft(n.Ctx, n.Info())
 	assert.NoError(t, err, "Can't join existing Raft")
 
-	err = n.RegisterNodes(resp.Nodes)
+	err = n.RegisterNodes(n.Ctx, resp.Nodes)
 	assert.NoError(t, err, "Can't add nodes to the local cluster list")
 
 	Register(s, n)
@@ -94,6 +120,27 @@ func newJoinNode(t *testing.T, id uint64, join string) *Node {
 	return n
 }
 
+func newStandbyNode(t *testing.T, id uint64, source string) *Node {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err, "Can't bind to raft service port")
+	s := grpc.NewServer()
+
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewJoinNode(id, l.Addr().String(), cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	n.Listener = l
+	n.Server = s
+
+	go n.Start()
+	n.StartStandby(source)
+
+	Register(s, n)
+	go s.Serve(l)
+	return n
+}
+
 func newRaftCluster(t *testing.T) map[int]*Node {
 	nodes := make(map[int]*Node, 0)
 
@@ -140,6 +187,11 @@ func testBootstrap(t *testing.T) {
 	assert.Equal(t, len(nodes[1].Cluster.Peers()), 3)
 	assert.Equal(t, len(nodes[2].Cluster.Peers()), 3)
 	assert.Equal(t, len(nodes[3].Cluster.Peers()), 3)
+
+	// A single connection is kept per member
+	assert.Equal(t, nodes[1].conns.len(), 2)
+	assert.Equal(t, nodes[2].conns.len(), 2)
+	assert.Equal(t, nodes[3].conns.len(), 2)
 }
 
 func testLeader(t *testing.T) {
@@ -329,7 +381,7 @@ func testFollowerLeave(t *testing.T) {
 	pair, err := EncodePair(key, value)
 	assert.NoError(t, err, "Can't encode key/value pair for test")
 
-	resp, err := nodes[5].LeaveRaft(nodes[5].Ctx, &NodeInfo{ID: nodes[5].ID})
+	resp, err := nodes[5].LeaveRaft(nodes[5].Ctx, &protonpb.NodeInfo{ID: nodes[5].ID})
 	assert.NoError(t, err, "Error sending message to leave the raft")
 	assert.Equal(t, resp.Error, "")
 
@@ -371,7 +423,7 @@ func testLeaderLeave(t *testing.T) {
 	assert.Equal(t, nodes[1].Leader(), nodes[1].ID)
 
 	// Try to leave the raft
-	resp, err := nodes[1].LeaveRaft(nodes[1].Ctx, &NodeInfo{ID: nodes[1].ID})
+	resp, err := nodes[1].LeaveRaft(nodes[1].Ctx, &protonpb.NodeInfo{ID: nodes[1].ID})
 	assert.NoError(t, err, "Error sending message to leave the raft")
 	assert.Equal(t, resp.Error, "")
 
@@ -439,6 +491,412 @@ func testPauseFollower(t *testing.T) {
 	assert.Equal(t, nodes[3].Get(key), string(value))
 }
 
+func testAuditMembership(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	// Every member should have recorded the two joins
+	for _, node := range nodes {
+		events := node.AuditEvents(0)
+		assert.Equal(t, len(events), 2)
+		for _, event := range events {
+			assert.Equal(t, event.Action, AuditMemberAdd)
+			assert.True(t, event.Success)
+		}
+	}
+
+	// Audit events are not part of the user store
+	assert.Equal(t, nodes[1].StoreLength(), 0)
+
+	resp, err := nodes[1].ListAuditEvents(nodes[1].Ctx, &protonpb.ListAuditEventsRequest{Limit: 1})
+	assert.NoError(t, err, "Can't list audit events")
+	assert.Equal(t, len(resp.Events), 1)
+	assert.Equal(t, resp.Events[0].Target, nodes[3].ID)
+}
+
+func testLeaderPriority(t *testing.T) {
+	nodes := newRaftCluster(t)
+	nodes[4] = newPriorityJoinNode(t, 4, nodes[1].Listener.Addr().String(), 10)
+	defer teardownCluster(t, nodes)
+
+	// Wait for the leadership to be transferred
+	time.Sleep(6 * time.Second)
+
+	// Node 4 has the highest priority and should lead
+	for _, node := range nodes {
+		assert.Equal(t, node.Leader(), nodes[4].ID)
+	}
+
+	events := nodes[1].AuditEvents(0)
+	assert.Equal(t, events[len(events)-1].Action, AuditLeaderTransfer)
+}
+
+func testDrainLeader(t *testing.T) {
+	nodes := newRaftCluster(t)
+	addRaftNode(t, nodes)
+	defer teardownCluster(t, nodes)
+
+	drained := nodes[1]
+	assert.True(t, drained.IsLeader())
+
+	ctx, cancel := context.WithTimeout(drained.Ctx, 20*time.Second)
+	defer cancel()
+	assert.NoError(t, drained.Drain(ctx))
+
+	// The raft loop of the drained node is already stopped
+	delete(nodes, 1)
+	drained.Server.Stop()
+	drained.Listener.Close()
+
+	time.Sleep(2 * time.Second)
+
+	for _, node := range nodes {
+		assert.NotEqual(t, node.Leader(), drained.ID)
+		assert.NotEqual(t, node.Leader(), uint64(raft.None))
+		_, ok := node.Cluster.Peers()[drained.ID]
+		assert.False(t, ok)
+	}
+}
+
+func testProposeWait(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err, "Can't encode KV pair")
+
+	ctx, cancel := context.WithTimeout(nodes[2].Ctx, 5*time.Second)
+	defer cancel()
+
+	// The value can be read on the follower as soon as the call returns
+	index, term, err := nodes[2].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+	assert.Equal(t, nodes[2].Get("foo"), "bar")
+	assert.True(t, index > 0)
+	assert.Equal(t, term, nodes[1].Status().Term)
+}
+
+func testWaitForIndex(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err, "Can't encode KV pair")
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 5*time.Second)
+	defer cancel()
+
+	index, _, err := nodes[1].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+	assert.True(t, nodes[1].CommitIndex() >= index)
+
+	// The followers apply the entry eventually
+	for _, id := range []int{2, 3} {
+		assert.NoError(t, nodes[id].WaitForIndex(ctx, index))
+		assert.True(t, nodes[id].AppliedIndex() >= index)
+		assert.Equal(t, nodes[id].Get("foo"), "bar")
+	}
+
+	// Waiting for an index far ahead times out
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, nodes[2].WaitForIndex(short, index+1000), context.DeadlineExceeded)
+}
+
+func testFollowerLinearizableRead(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err, "Can't encode KV pair")
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 10*time.Second)
+	defer cancel()
+
+	index, _, err := nodes[1].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+
+	// The followers get the read index from the leader and
+	// serve the read once they applied the write
+	for _, id := range []int{2, 3} {
+		assert.NoError(t, nodes[id].LinearizableRead(ctx))
+		assert.True(t, nodes[id].AppliedIndex() >= index)
+		assert.Equal(t, nodes[id].Get("foo"), "bar")
+		assert.Equal(t, nodes[id].ReadIndexStats().Forwarded, uint64(1))
+	}
+
+	// A follower has no read index to give
+	resp, err := nodes[2].LeaderReadIndex(ctx, &protonpb.LeaderReadIndexRequest{})
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+}
+
+func testTTL(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 10*time.Second)
+	defer cancel()
+
+	err := nodes[1].PutWithTTL(ctx, "foo", []byte("bar"), time.Second)
+	assert.NoError(t, err)
+
+	// The key is replicated, then expired by the leader
+	for _, id := range []int{1, 2, 3} {
+		node := nodes[id]
+		assert.NoError(t, poll(ctx, func() bool { return node.Get("foo") == "bar" }))
+	}
+	for _, id := range []int{1, 2, 3} {
+		node := nodes[id]
+		assert.NoError(t, poll(ctx, func() bool { return node.StoreLength() == 0 }))
+	}
+}
+
+func testSessions(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	ctx, cancel := context.WithTimeout(nodes[2].Ctx, 20*time.Second)
+	defer cancel()
+
+	session, err := nodes[2].NewSession(ctx, 2*time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, nodes[2].PutWithSession(ctx, "svc/node2", []byte("up"), session))
+
+	// Keepalives sent to a follower keep the key
+	for i := 0; i < 6; i++ {
+		assert.NoError(t, nodes[2].KeepAlive(ctx, session))
+		time.Sleep(500 * time.Millisecond)
+	}
+	for _, id := range []int{1, 2, 3} {
+		assert.Equal(t, nodes[id].Get("svc/node2"), "up")
+	}
+
+	// The key is deleted once the keepalives stop
+	for _, id := range []int{1, 2, 3} {
+		node := nodes[id]
+		assert.NoError(t, poll(ctx, func() bool { return node.Get("svc/node2") == "" && len(node.Sessions()) == 0 }))
+	}
+	assert.Equal(t, nodes[2].KeepAlive(ctx, session), ErrSessionNotFound)
+}
+
+func testSemaphore(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 20*time.Second)
+	defer cancel()
+
+	first, err := nodes[2].NewSession(ctx, time.Minute)
+	assert.NoError(t, err)
+	second, err := nodes[3].NewSession(ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, nodes[3].WaitForIndex(ctx, nodes[2].AppliedIndex()))
+
+	ticket, err := nodes[2].TryAcquire(ctx, "jobs", 2, 2, first)
+	assert.NoError(t, err)
+	assert.NoError(t, nodes[3].WaitForIndex(ctx, nodes[2].AppliedIndex()))
+	_, err = nodes[3].TryAcquire(ctx, "jobs", 1, 2, second)
+	assert.Equal(t, err, ErrSemaphoreTaken)
+
+	// A waiting holder gets the permits once they are released
+	acquired := make(chan error)
+	go func() {
+		_, err := nodes[3].Acquire(ctx, "jobs", 1, 2, second)
+		acquired <- err
+	}()
+	assert.NoError(t, nodes[2].Release(ctx, "jobs", ticket))
+	assert.NoError(t, <-acquired)
+
+	semaphores := nodes[1].Semaphores()
+	assert.NoError(t, poll(ctx, func() bool {
+		semaphores = nodes[1].Semaphores()
+		return len(semaphores) == 1 && used(semaphores[0]) == 1
+	}))
+	assert.Equal(t, semaphores[0].Holders[0].Session, second)
+}
+
+func testSTM(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 20*time.Second)
+	defer cancel()
+
+	// Concurrent increments conflict and are retried
+	// until each of them is applied exactly once
+	errs := make(chan error)
+	for _, id := range []int{1, 2, 3} {
+		c, err := GetRaftClient(nodes[id].Listener.Addr().String(), time.Second)
+		assert.NoError(t, err)
+		defer c.Conn.Close()
+
+		go func() {
+			for i := 0; i < 5; i++ {
+				err := RunSTM(ctx, c, "", func(s *STM) error {
+					value, _, err := s.Get("counter")
+					if err != nil {
+						return err
+					}
+					count, _ := strconv.Atoi(string(value))
+					s.Put("counter", []byte(strconv.Itoa(count+1)))
+					return nil
+				})
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	for range []int{1, 2, 3} {
+		assert.NoError(t, <-errs)
+	}
+
+	for _, id := range []int{1, 2, 3} {
+		node := nodes[id]
+		assert.NoError(t, poll(ctx, func() bool { return node.Get("counter") == "15" }))
+	}
+}
+
+func testStandby(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 20*time.Second)
+	defer cancel()
+
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err, "Can't encode KV pair")
+	_, _, err = nodes[1].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+
+	nodes[4] = newStandbyNode(t, 4, nodes[2].Listener.Addr().String())
+	standby := nodes[4]
+
+	// The standby replicates without being a member
+	assert.NoError(t, standby.WaitForIndex(ctx, nodes[1].AppliedIndex()))
+	assert.Equal(t, standby.Get("foo"), "bar")
+	assert.Equal(t, len(standby.Cluster.Peers()), 4)
+	assert.Equal(t, len(nodes[1].Cluster.Peers()), 3)
+	assert.Equal(t, standby.Propose(ctx, pair), ErrStandby)
+
+	pair, err = EncodePair("foo", []byte("baz"))
+	assert.NoError(t, err, "Can't encode KV pair")
+	_, _, err = nodes[1].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+	assert.NoError(t, standby.WaitForIndex(ctx, nodes[1].AppliedIndex()))
+	assert.Equal(t, standby.Get("foo"), "baz")
+
+	assert.NoError(t, standby.Promote(ctx))
+	assert.False(t, standby.IsStandby())
+	assert.Equal(t, standby.Promote(ctx), ErrNotStandby)
+	assert.NoError(t, poll(ctx, func() bool { return len(nodes[1].Cluster.Peers()) == 4 }))
+
+	// The promoted member commits with the others
+	pair, err = EncodePair("qux", []byte("quux"))
+	assert.NoError(t, err, "Can't encode KV pair")
+	_, _, err = standby.ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+	assert.Equal(t, standby.Get("foo"), "baz")
+	assert.NoError(t, poll(ctx, func() bool { return nodes[1].Get("qux") == "quux" }))
+	assert.Equal(t, standby.StoreLength(), nodes[1].StoreLength())
+}
+
+func testUpdateMember(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 10*time.Second)
+	defer cancel()
+
+	// Node 3 comes back on another address
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err, "Can't bind to raft service port")
+	defer l.Close()
+	go nodes[3].Server.Serve(l)
+	addr := l.Addr().String()
+
+	resp, err := nodes[2].UpdateMember(ctx, &protonpb.NodeInfo{ID: 4, Addr: addr})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Error, ErrMemberNotFound.Error())
+
+	resp, err = nodes[2].UpdateMember(ctx, &protonpb.NodeInfo{ID: 3, Addr: addr})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	for _, id := range []int{1, 2} {
+		node := nodes[id]
+		assert.NoError(t, poll(ctx, func() bool {
+			member, ok := node.Cluster.Member(3)
+			return ok && member.Addr == addr
+		}))
+	}
+
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err, "Can't encode KV pair")
+	_, _, err = nodes[1].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+	assert.NoError(t, poll(ctx, func() bool { return nodes[3].Get("foo") == "bar" }))
+}
+
+func testSplitProposal(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 10*time.Second)
+	defer cancel()
+
+	nodes[2].MaxEntrySize = 200
+	value := strings.Repeat("a", 1000)
+	pair, err := EncodePair("foo", []byte(value))
+	assert.NoError(t, err, "Can't encode KV pair")
+	_, _, err = nodes[2].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+
+	for _, id := range []int{1, 2, 3} {
+		node := nodes[id]
+		assert.NoError(t, poll(ctx, func() bool { return node.Get("foo") == value }))
+	}
+}
+
+func testForceNewCluster(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	// The quorum is lost for good
+	shutdownNode(nodes[2])
+	shutdownNode(nodes[3])
+	delete(nodes, 2)
+	delete(nodes, 3)
+
+	ctx, cancel := context.WithTimeout(nodes[1].Ctx, 5*time.Second)
+	defer cancel()
+
+	removed, err := nodes[1].ForceNewCluster(ctx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, len(removed), 2)
+	assert.Equal(t, len(nodes[1].Cluster.Peers()), 3)
+
+	removed, err = nodes[1].ForceNewCluster(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, len(removed), 2)
+	assert.Equal(t, removed[0].ID, uint64(2))
+	assert.Equal(t, len(nodes[1].Cluster.Peers()), 1)
+	assert.NoError(t, poll(ctx, func() bool {
+		events := nodes[1].AuditEvents(0)
+		return events[len(events)-1].Action == AuditForceNewCluster
+	}))
+
+	// The node commits alone
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err, "Can't encode KV pair")
+	_, _, err = nodes[1].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+	assert.Equal(t, nodes[1].Get("foo"), "bar")
+}
+
 func testSnapshot(t *testing.T) {
 	t.Skip()
 }
@@ -446,3 +904,76 @@ func testSnapshot(t *testing.T) {
 func testRecoverSnapshot(t *testing.T) {
 	t.Skip()
 }
+
+func testGracefulShutdown(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	client, err := GetRaftClient(nodes[3].Listener.Addr().String(), time.Second)
+	assert.NoError(t, err)
+	defer client.Conn.Close()
+
+	stream, err := client.StreamChanges(context.Background(), &protonpb.StreamChangesRequest{})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	_, _, err = nodes[1].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.NoError(t, err)
+
+	// The stream of changes does not hold the server until the timeout
+	stopped := time.Now()
+	nodes[3].GracefulShutdown(10 * time.Second)
+	assert.True(t, time.Since(stopped) < 5*time.Second)
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	nodes[3].Listener.Close()
+	delete(nodes, 3)
+
+	// The remaining members still form a quorum
+	pair, err = EncodePair("baz", []byte("qux"))
+	assert.NoError(t, err)
+	_, _, err = nodes[1].ProposeWait(ctx, pair)
+	assert.NoError(t, err)
+	assert.Equal(t, nodes[1].Get("baz"), "qux")
+}
+
+func testReplicatedLog(t *testing.T) {
+	nodes := newRaftCluster(t)
+	defer teardownCluster(t, nodes)
+
+	subs := make(map[int]<-chan *LogEntry)
+	for id, node := range nodes {
+		entries, cancel := node.ReplicatedLog().Subscribe(10)
+		defer cancel()
+		subs[id] = entries
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Every member applies the commands in the same order
+	var indexes []uint64
+	for _, cmd := range []string{"a", "b", "c"} {
+		index, term, err := nodes[2].ReplicatedLog().Propose(ctx, []byte(cmd))
+		assert.NoError(t, err)
+		assert.NotEqual(t, term, uint64(0))
+		indexes = append(indexes, index)
+	}
+
+	for _, entries := range subs {
+		for i, cmd := range []string{"a", "b", "c"} {
+			entry := <-entries
+			assert.Equal(t, entry.Index, indexes[i])
+			assert.Equal(t, string(entry.Data), cmd)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package proton
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+var (
+	// ErrProposalExpired is reported when a proposal was not committed within a minute, raft dropped it
+	ErrProposalExpired = errors.New("proposal was not committed and is considered dropped")
+)
+
+// Observer is notified of the state transitions of a node, so
+// that monitoring agents don't need to poll the status. The calls
+// are made from the main loop, except for the dropped proposals
+// which are reported by the caller of the proposal: an observer
+// must not block and must be safe for concurrent use
+type Observer interface {
+	// OnLeaderChange is called when the leader changes, with
+	// raft.None when the cluster has lost its leader
+	OnLeaderChange(previous, leader uint64)
+	// OnTermChange is called when the node enters a new term
+	OnTermChange(term uint64)
+	// OnSnapshot is called when a snapshot is taken by the
+	// node, or restored when it was received from the leader
+	OnSnapshot(metadata raftpb.SnapshotMetadata, received bool)
+	// OnConfChange is called once a configuration change is applied
+	OnConfChange(cc raftpb.ConfChange)
+	// OnProposalDropped is called when a proposal made on the
+	// node is refused, or expired without being committed
+	OnProposalDropped(err error)
+}
+
+// NopObserver ignores every notification, it is meant to be
+// embedded by observers only interested in some of them
+type NopObserver struct{}
+
+// OnLeaderChange does nothing
+func (NopObserver) OnLeaderChange(previous, leader uint64) {}
+
+// OnTermChange does nothing
+func (NopObserver) OnTermChange(term uint64) {}
+
+// OnSnapshot does nothing
+func (NopObserver) OnSnapshot(metadata raftpb.SnapshotMetadata, received bool) {}
+
+// OnConfChange does nothing
+func (NopObserver) OnConfChange(cc raftpb.ConfChange) {}
+
+// OnProposalDropped does nothing
+func (NopObserver) OnProposalDropped(err error) {}
+
+// observers holds the observers of a node
+type observers struct {
+	lock sync.RWMutex
+	list []Observer
+
+	// leader and term are the last ones notified,
+	// only accessed from the main loop
+	leader uint64
+	term   uint64
+}
+
+// AddObserver registers an observer of the state transitions of the node
+func (n *Node) AddObserver(o Observer) {
+	n.observers.lock.Lock()
+	n.observers.list = append(n.observers.list, o)
+	n.observers.lock.Unlock()
+}
+
+// notify calls fn on every observer
+func (o *observers) notify(fn func(Observer)) {
+	o.lock.RLock()
+	list := o.list
+	o.lock.RUnlock()
+
+	for _, observer := range list {
+		fn(observer)
+	}
+}
+
+// observeState notifies the changes of leader and term
+// found in a Ready. Called from the main loop
+func (n *Node) observeState(rd raft.Ready) {
+	o := n.observers
+	if rd.SoftState != nil && rd.SoftState.Lead != o.leader {
+		previous, leader := o.leader, rd.SoftState.Lead
+		o.leader = leader
+		o.notify(func(obs Observer) { obs.OnLeaderChange(previous, leader) })
+	}
+	if !raft.IsEmptyHardState(rd.HardState) && rd.HardState.Term > o.term {
+		term := rd.HardState.Term
+		o.term = term
+		o.notify(func(obs Observer) { obs.OnTermChange(term) })
+	}
+}
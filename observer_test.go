@@ -0,0 +1,50 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the notifications of an observer
+type recorder struct {
+	NopObserver
+	leaders []uint64
+	terms   []uint64
+	dropped []error
+}
+
+func (r *recorder) OnLeaderChange(previous, leader uint64) { r.leaders = append(r.leaders, leader) }
+func (r *recorder) OnTermChange(term uint64)               { r.terms = append(r.terms, term) }
+func (r *recorder) OnProposalDropped(err error)            { r.dropped = append(r.dropped, err) }
+
+func TestObserver(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	r := &recorder{}
+	n.AddObserver(r)
+
+	// Only the transitions are notified
+	n.observeState(raft.Ready{SoftState: &raft.SoftState{Lead: 2}, HardState: raftpb.HardState{Term: 3}})
+	n.observeState(raft.Ready{SoftState: &raft.SoftState{Lead: 2}, HardState: raftpb.HardState{Term: 3, Commit: 5}})
+	n.observeState(raft.Ready{SoftState: &raft.SoftState{Lead: raft.None}})
+	assert.Equal(t, r.leaders, []uint64{2, raft.None})
+	assert.Equal(t, r.terms, []uint64{3})
+
+	// Without a leader the proposal is never accepted
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	assert.Error(t, n.propose(ctx, pair))
+	assert.Equal(t, len(r.dropped), 1)
+
+	n.proposals.start(pair)
+	assert.Equal(t, n.proposals.expire(time.Now().Add(time.Second)), 1)
+}
@@ -0,0 +1,136 @@
+package proton
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultOverloadThreshold is the duration above which
+	// persisting and applying a batch of entries is an overload
+	DefaultOverloadThreshold = 500 * time.Millisecond
+
+	// DefaultMaxTickMultiplier is the number of times an overloaded
+	// node widens its election timeout at most
+	DefaultMaxTickMultiplier = 4
+
+	// overloadBatches is the number of overloaded batches in a
+	// row after which the election timeout is widened again
+	overloadBatches = 3
+)
+
+// overload tracks the adaptation of the raft clock of the node
+// to its load, an overloaded node ticks its raft every multiplier
+// ticks of its ticker
+type overload struct {
+	lock sync.Mutex
+	// slow is the number of overloaded batches in a row
+	slow int
+	// calm is the number of ticks since the last overloaded batch
+	calm int
+	// elapsed is the number of ticks since the last tick of the raft
+	elapsed    int
+	multiplier int
+
+	since       time.Time
+	activations uint64
+	skipped     uint64
+}
+
+// OverloadStatus reports whether the election timeout of
+// the node is widened because it is overloaded
+type OverloadStatus struct {
+	// Multiplier is the number of ticks per tick of the raft,
+	// it is 1 when the adaptation is not active
+	Multiplier int
+	// Since is the time the adaptation became active
+	Since time.Time
+	// Activations is the number of times the node was overloaded
+	Activations uint64
+	// SkippedTicks is the number of ticks the raft did not receive
+	SkippedTicks uint64
+}
+
+// Overload returns the adaptation of the node to its load
+func (n *Node) Overload() OverloadStatus {
+	o := n.overload
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return OverloadStatus{
+		Multiplier:   o.multiplier,
+		Since:        o.since,
+		Activations:  o.activations,
+		SkippedTicks: o.skipped,
+	}
+}
+
+// observeLoad reports the time spent persisting and applying a
+// batch of entries. The election timeout doubles every time the
+// node stays overloaded for overloadBatches batches in a row
+func (n *Node) observeLoad(took time.Duration) {
+	if n.OverloadThreshold <= 0 || n.MaxTickMultiplier <= 1 {
+		return
+	}
+
+	o := n.overload
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if took <= n.OverloadThreshold {
+		o.slow = 0
+		return
+	}
+	o.calm = 0
+	o.slow++
+	if o.slow < overloadBatches || o.multiplier >= n.MaxTickMultiplier {
+		return
+	}
+	o.slow = 0
+
+	if o.multiplier == 1 {
+		o.since = time.Now()
+		o.activations++
+	}
+	o.multiplier *= 2
+	if o.multiplier > n.MaxTickMultiplier {
+		o.multiplier = n.MaxTickMultiplier
+	}
+	n.Cfg.Logger.Warningf("raft: node is overloaded, batches took more than %v, election timeout widened %d times", n.OverloadThreshold, o.multiplier)
+}
+
+// overloadTick is called on every tick, it returns whether the
+// raft must be ticked. The election timeout is narrowed back by
+// half every election timeout without an overloaded batch. The
+// leader always ticks so that it keeps sending its heartbeats
+func (n *Node) overloadTick() bool {
+	o := n.overload
+	o.lock.Lock()
+	if o.multiplier > 1 {
+		o.calm++
+		if o.calm >= n.Cfg.ElectionTick {
+			o.calm = 0
+			o.multiplier /= 2
+			if o.multiplier == 1 {
+				o.since = time.Time{}
+				n.Cfg.Logger.Infof("raft: node is no longer overloaded, election timeout restored")
+			}
+		}
+	}
+	multiplier := o.multiplier
+	o.lock.Unlock()
+
+	if multiplier <= 1 || n.IsLeader() {
+		return true
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.elapsed++
+	if o.elapsed < multiplier {
+		o.skipped++
+		return false
+	}
+	o.elapsed = 0
+	return true
+}
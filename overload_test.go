@@ -0,0 +1,56 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverload(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	n.OverloadThreshold = 10 * time.Millisecond
+	n.MaxTickMultiplier = 4
+
+	// A single slow batch is not a sustained overload
+	n.observeLoad(time.Second)
+	n.observeLoad(time.Millisecond)
+	n.observeLoad(time.Second)
+	n.observeLoad(time.Second)
+	assert.Equal(t, n.Overload().Multiplier, 1)
+	assert.True(t, n.overloadTick())
+
+	n.observeLoad(time.Second)
+	overload := n.Overload()
+	assert.Equal(t, overload.Multiplier, 2)
+	assert.False(t, overload.Since.IsZero())
+	assert.Equal(t, overload.Activations, uint64(1))
+
+	for i := 0; i < 2*overloadBatches; i++ {
+		n.observeLoad(time.Second)
+	}
+	assert.Equal(t, n.Overload().Multiplier, 4)
+
+	// The raft of a follower is ticked every 4 ticks, until an election
+	// timeout without overload halves the multiplier
+	assert.False(t, n.overloadTick())
+	assert.False(t, n.overloadTick())
+	assert.True(t, n.overloadTick())
+	assert.Equal(t, n.Overload().Multiplier, 2)
+
+	assert.False(t, n.overloadTick())
+	assert.True(t, n.overloadTick())
+	assert.True(t, n.overloadTick())
+
+	overload = n.Overload()
+	assert.Equal(t, overload.Multiplier, 1)
+	assert.True(t, overload.Since.IsZero())
+	assert.Equal(t, overload.SkippedTicks, uint64(3))
+
+	n.OverloadThreshold = 0
+	for i := 0; i < overloadBatches; i++ {
+		n.observeLoad(time.Second)
+	}
+	assert.Equal(t, n.Overload().Multiplier, 1)
+}
@@ -0,0 +1,114 @@
+package proton
+
+import (
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+)
+
+const (
+	// AuditLeaderTransfer is recorded when the leadership is handed over to another member
+	AuditLeaderTransfer = "leader-transfer"
+)
+
+// SetPriority sets the election priority of the node. When
+// healthy, the member with the highest priority campaigns
+// first and the leadership is transferred to it
+func (n *Node) SetPriority(priority uint64) {
+	n.priorityLock.Lock()
+	n.priority = priority
+	n.priorityLock.Unlock()
+
+	if peer, ok := n.Cluster.Peers()[n.ID]; ok {
+		info := *peer.NodeInfo
+		info.Priority = priority
+		n.Cluster.AddPeer(&Peer{NodeInfo: &info, Client: peer.Client})
+	}
+}
+
+// Priority returns the election priority of the node
+func (n *Node) Priority() uint64 {
+	n.priorityLock.RLock()
+	defer n.priorityLock.RUnlock()
+	return n.priority
+}
+
+// Info returns the information to advertise when joining a raft cluster
+func (n *Node) Info() *protonpb.NodeInfo {
+	return &protonpb.NodeInfo{
+		ID:        n.ID,
+		Addr:      n.Address,
+		Priority:  n.Priority(),
+		ClusterID: n.ClusterID(),
+		Version:   Version,
+		Labels:    n.Labels(),
+	}
+}
+
+// preferredLeader returns the eligible member with the highest
+// priority if it is higher than ours, raft.None otherwise
+func (n *Node) preferredLeader(eligible func(id uint64) bool) uint64 {
+	best, priority := uint64(raft.None), n.Priority()
+	for id, peer := range n.Cluster.Peers() {
+		if id == n.ID || peer.Priority <= priority || !eligible(id) {
+			continue
+		}
+		best, priority = id, peer.Priority
+	}
+	return best
+}
+
+// outranked checks if another member should campaign before us,
+// ties are broken using the member ids
+func (n *Node) outranked() bool {
+	priority := n.Priority()
+	for id, peer := range n.Cluster.Peers() {
+		if id != n.ID && (peer.Priority > priority || (peer.Priority == priority && id > n.ID)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPriority is called on every tick: a leader hands over
+// the leadership to a healthy member with a higher priority,
+// and without a leader the member with the highest priority
+// campaigns without waiting for its election timeout
+func (n *Node) checkPriority() {
+	if n.Priority() == 0 && n.preferredLeader(func(uint64) bool { return true }) == raft.None {
+		// No priority configured in the cluster
+		return
+	}
+
+	// Give a previous campaign or transfer the time to complete
+	if time.Since(n.lastPriorityChange) < time.Duration(n.Cfg.ElectionTick)*time.Second {
+		return
+	}
+
+	status := n.Status()
+
+	switch {
+	case status.Lead == raft.None:
+		if n.Priority() > 0 && !n.outranked() {
+			n.lastPriorityChange = time.Now()
+			n.Campaign(n.Ctx)
+		}
+
+	case status.Lead == n.ID:
+		self := status.Progress[n.ID]
+		target := n.preferredLeader(func(id uint64) bool {
+			pr, ok := status.Progress[id]
+			return ok && pr.RecentActive && pr.Match >= self.Match
+		})
+		if target == raft.None {
+			return
+		}
+
+		n.lastPriorityChange = time.Now()
+		go func() {
+			n.recordAudit(n.Ctx, AuditLeaderTransfer, target, nil)
+			n.TransferLeadership(n.Ctx, n.ID, target)
+		}()
+	}
+}
@@ -0,0 +1,71 @@
+package proton
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+)
+
+// Progress returns the replication of every member as seen by the
+// leader, sorted by id, or nil if the node is not the leader. It
+// lets a scheduler tell the members that are caught up apart from
+// those catching up, such as not to restart the only member that
+// is up to date with the leader
+func (n *Node) Progress() []*protonpb.Progress {
+	return progress(n.Status())
+}
+
+// progress converts the progress of the members in a raft status
+func progress(status raft.Status) []*protonpb.Progress {
+	var members []*protonpb.Progress
+	for id, pr := range status.Progress {
+		members = append(members, &protonpb.Progress{
+			Id:              id,
+			Match:           pr.Match,
+			Next:            pr.Next,
+			State:           progressState(pr.State),
+			Paused:          pr.Paused,
+			RecentActive:    pr.RecentActive,
+			PendingSnapshot: pr.PendingSnapshot,
+		})
+	}
+	sort.Sort(progressByID(members))
+	return members
+}
+
+// progressState converts the replication state of a member
+func progressState(state raft.ProgressStateType) protonpb.ProgressState {
+	switch state {
+	case raft.ProgressStateReplicate:
+		return protonpb.ProgressState_REPLICATE
+	case raft.ProgressStateSnapshot:
+		return protonpb.ProgressState_SNAPSHOT
+	default:
+		return protonpb.ProgressState_PROBE
+	}
+}
+
+// RaftStatus returns the raft status of the node, along with the
+// progress of every member if it is the leader
+func (n *Node) RaftStatus(ctx context.Context, req *protonpb.RaftStatusRequest) (*protonpb.RaftStatusResponse, error) {
+	status := n.Status()
+	return &protonpb.RaftStatusResponse{
+		Id:             n.ID,
+		Term:           status.Term,
+		Leader:         status.Lead,
+		Commit:         status.Commit,
+		Applied:        n.AppliedIndex(),
+		Progress:       progress(status),
+		TickMultiplier: uint32(n.Overload().Multiplier),
+	}, nil
+}
+
+// progressByID sorts the progress of the members by id
+type progressByID []*protonpb.Progress
+
+func (p progressByID) Len() int           { return len(p) }
+func (p progressByID) Less(i, j int) bool { return p[i].Id < p[j].Id }
+func (p progressByID) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
@@ -0,0 +1,54 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestProgress(t *testing.T) {
+	status := raft.Status{Progress: map[uint64]raft.Progress{
+		3: {Match: 5, Next: 6, State: raft.ProgressStateSnapshot, PendingSnapshot: 9},
+		1: {Match: 10, Next: 11, State: raft.ProgressStateReplicate, RecentActive: true},
+		2: {Next: 4, Paused: true},
+	}}
+	assert.Equal(t, progress(status), []*protonpb.Progress{
+		{Id: 1, Match: 10, Next: 11, State: protonpb.ProgressState_REPLICATE, RecentActive: true},
+		{Id: 2, Next: 4, State: protonpb.ProgressState_PROBE, Paused: true},
+		{Id: 3, Match: 5, Next: 6, State: protonpb.ProgressState_SNAPSHOT, PendingSnapshot: 9},
+	})
+
+	// Only the leader knows the progress of the members
+	n := newQuotaNode(t)
+	defer n.Stop()
+	assert.Empty(t, n.Progress())
+}
+
+func TestStatusRPC(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	deadline := time.Now().Add(10 * time.Second)
+	for !n.IsLeader() && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	resp, err := n.RaftStatus(context.Background(), &protonpb.RaftStatusRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Id, uint64(1))
+	assert.Equal(t, resp.Leader, uint64(1))
+	assert.Equal(t, resp.Applied, resp.Commit)
+	if assert.Len(t, resp.Progress, 1) {
+		assert.Equal(t, resp.Progress[0].Match, resp.Commit)
+	}
+}
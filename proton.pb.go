@@ -9,17 +9,19 @@
 		proton.proto
 
 	It has these top-level messages:
-		JoinRaftResponse
-		LeaveRaftResponse
+		SnapshotChunk
 		SendResponse
-		PutObjectRequest
-		PutObjectResponse
-		ListObjectsRequest
-		ListObjectsResponse
-		ListMembersRequest
-		ListMembersResponse
-		NodeInfo
-		Pair
+		FetchEntriesRequest
+		FetchEntriesResponse
+		LogPair
+		StoreSnapshot
+		Blob
+		SnapshotData
+		SnapshotPointer
+		Txn
+		EntryPart
+		ProposalBatch
+		JoinToken
 */
 package proton
 
@@ -27,6 +29,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import raftpb "github.com/coreos/etcd/raft/raftpb"
+import proton_v1 "github.com/abronan/proton/protonpb/v1"
 
 // skipping weak import gogoproto "gogoproto"
 
@@ -42,899 +45,3642 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
-type JoinRaftResponse struct {
-	Success bool        `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error   string      `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	Nodes   []*NodeInfo `protobuf:"bytes,3,rep,name=nodes" json:"nodes,omitempty"`
+type SnapshotChunk struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 }
 
-func (m *JoinRaftResponse) Reset()         { *m = JoinRaftResponse{} }
-func (m *JoinRaftResponse) String() string { return proto.CompactTextString(m) }
-func (*JoinRaftResponse) ProtoMessage()    {}
+func (m *SnapshotChunk) Reset()         { *m = SnapshotChunk{} }
+func (m *SnapshotChunk) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunk) ProtoMessage()    {}
 
-func (m *JoinRaftResponse) GetNodes() []*NodeInfo {
+type SendResponse struct {
+	Success      bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error        string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	LeaderTime   int64  `protobuf:"varint,3,opt,name=leader_time,json=leaderTime,proto3" json:"leader_time,omitempty"`
+	LeaderCommit uint64 `protobuf:"varint,4,opt,name=leader_commit,json=leaderCommit,proto3" json:"leader_commit,omitempty"`
+}
+
+func (m *SendResponse) Reset()         { *m = SendResponse{} }
+func (m *SendResponse) String() string { return proto.CompactTextString(m) }
+func (*SendResponse) ProtoMessage()    {}
+
+type FetchEntriesRequest struct {
+	Index uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Id    uint64 `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (m *FetchEntriesRequest) Reset()         { *m = FetchEntriesRequest{} }
+func (m *FetchEntriesRequest) String() string { return proto.CompactTextString(m) }
+func (*FetchEntriesRequest) ProtoMessage()    {}
+
+type FetchEntriesResponse struct {
+	Success  bool             `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error    string           `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Entries  []*raftpb.Entry  `protobuf:"bytes,3,rep,name=entries" json:"entries,omitempty"`
+	Snapshot *raftpb.Snapshot `protobuf:"bytes,4,opt,name=snapshot" json:"snapshot,omitempty"`
+}
+
+func (m *FetchEntriesResponse) Reset()         { *m = FetchEntriesResponse{} }
+func (m *FetchEntriesResponse) String() string { return proto.CompactTextString(m) }
+func (*FetchEntriesResponse) ProtoMessage()    {}
+
+func (m *FetchEntriesResponse) GetEntries() []*raftpb.Entry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *FetchEntriesResponse) GetSnapshot() *raftpb.Snapshot {
 	if m != nil {
-		return m.Nodes
+		return m.Snapshot
 	}
 	return nil
 }
 
-type LeaveRaftResponse struct {
-	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+type LogPair struct {
+	Key        string                `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value      []byte                `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Origin     string                `protobuf:"bytes,3,opt,name=origin,proto3" json:"origin,omitempty"`
+	Compressed bool                  `protobuf:"varint,4,opt,name=compressed,proto3" json:"compressed,omitempty"`
+	Delete     bool                  `protobuf:"varint,5,opt,name=delete,proto3" json:"delete,omitempty"`
+	Expires    int64                 `protobuf:"varint,6,opt,name=expires,proto3" json:"expires,omitempty"`
+	Session    uint64                `protobuf:"varint,7,opt,name=session,proto3" json:"session,omitempty"`
+	Digest     []byte                `protobuf:"bytes,8,opt,name=digest,proto3" json:"digest,omitempty"`
+	Timestamp  int64                 `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	After      *proton_v1.HybridTime `protobuf:"bytes,10,opt,name=after" json:"after,omitempty"`
 }
 
-func (m *LeaveRaftResponse) Reset()         { *m = LeaveRaftResponse{} }
-func (m *LeaveRaftResponse) String() string { return proto.CompactTextString(m) }
-func (*LeaveRaftResponse) ProtoMessage()    {}
+func (m *LogPair) Reset()         { *m = LogPair{} }
+func (m *LogPair) String() string { return proto.CompactTextString(m) }
+func (*LogPair) ProtoMessage()    {}
 
-type SendResponse struct {
-	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+func (m *LogPair) GetAfter() *proton_v1.HybridTime {
+	if m != nil {
+		return m.After
+	}
+	return nil
 }
 
-func (m *SendResponse) Reset()         { *m = SendResponse{} }
-func (m *SendResponse) String() string { return proto.CompactTextString(m) }
-func (*SendResponse) ProtoMessage()    {}
+type StoreSnapshot struct {
+	Pairs      []*LogPair              `protobuf:"bytes,1,rep,name=pairs" json:"pairs,omitempty"`
+	Members    []*proton_v1.NodeInfo   `protobuf:"bytes,2,rep,name=members" json:"members,omitempty"`
+	Alarms     []*proton_v1.Alarm      `protobuf:"bytes,3,rep,name=alarms" json:"alarms,omitempty"`
+	Events     []*proton_v1.AuditEvent `protobuf:"bytes,4,rep,name=events" json:"events,omitempty"`
+	Revisions  []uint64                `protobuf:"varint,5,rep,packed,name=revisions" json:"revisions,omitempty"`
+	Since      uint64                  `protobuf:"varint,6,opt,name=since,proto3" json:"since,omitempty"`
+	Payload    []byte                  `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`
+	Revision   uint64                  `protobuf:"varint,8,opt,name=revision,proto3" json:"revision,omitempty"`
+	Expiries   []int64                 `protobuf:"varint,9,rep,packed,name=expiries" json:"expiries,omitempty"`
+	Keys       []string                `protobuf:"bytes,10,rep,name=keys" json:"keys,omitempty"`
+	Sessions   []*proton_v1.Session    `protobuf:"bytes,11,rep,name=sessions" json:"sessions,omitempty"`
+	Owners     []uint64                `protobuf:"varint,12,rep,packed,name=owners" json:"owners,omitempty"`
+	Semaphores []*proton_v1.Semaphore  `protobuf:"bytes,13,rep,name=semaphores" json:"semaphores,omitempty"`
+	Parts      []*EntryPart            `protobuf:"bytes,14,rep,name=parts" json:"parts,omitempty"`
+	RateLimits []*proton_v1.RateLimit  `protobuf:"bytes,15,rep,name=rate_limits,json=rateLimits" json:"rate_limits,omitempty"`
+	Blobs      []*Blob                 `protobuf:"bytes,16,rep,name=blobs" json:"blobs,omitempty"`
+	Hlc        *proton_v1.HybridTime   `protobuf:"bytes,17,opt,name=hlc" json:"hlc,omitempty"`
+	ClusterId  uint64                  `protobuf:"varint,18,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`
+	JoinTokens []*JoinToken            `protobuf:"bytes,19,rep,name=join_tokens,json=joinTokens" json:"join_tokens,omitempty"`
+	SealIndex  uint64                  `protobuf:"varint,20,opt,name=seal_index,json=sealIndex,proto3" json:"seal_index,omitempty"`
+}
+
+func (m *StoreSnapshot) Reset()         { *m = StoreSnapshot{} }
+func (m *StoreSnapshot) String() string { return proto.CompactTextString(m) }
+func (*StoreSnapshot) ProtoMessage()    {}
+
+func (m *StoreSnapshot) GetPairs() []*LogPair {
+	if m != nil {
+		return m.Pairs
+	}
+	return nil
+}
 
-type PutObjectRequest struct {
-	Object *Pair `protobuf:"bytes,1,opt,name=object" json:"object,omitempty"`
+func (m *StoreSnapshot) GetMembers() []*proton_v1.NodeInfo {
+	if m != nil {
+		return m.Members
+	}
+	return nil
 }
 
-func (m *PutObjectRequest) Reset()         { *m = PutObjectRequest{} }
-func (m *PutObjectRequest) String() string { return proto.CompactTextString(m) }
-func (*PutObjectRequest) ProtoMessage()    {}
+func (m *StoreSnapshot) GetAlarms() []*proton_v1.Alarm {
+	if m != nil {
+		return m.Alarms
+	}
+	return nil
+}
 
-func (m *PutObjectRequest) GetObject() *Pair {
+func (m *StoreSnapshot) GetEvents() []*proton_v1.AuditEvent {
 	if m != nil {
-		return m.Object
+		return m.Events
 	}
 	return nil
 }
 
-type PutObjectResponse struct {
-	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+func (m *StoreSnapshot) GetSessions() []*proton_v1.Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
 }
 
-func (m *PutObjectResponse) Reset()         { *m = PutObjectResponse{} }
-func (m *PutObjectResponse) String() string { return proto.CompactTextString(m) }
-func (*PutObjectResponse) ProtoMessage()    {}
+func (m *StoreSnapshot) GetSemaphores() []*proton_v1.Semaphore {
+	if m != nil {
+		return m.Semaphores
+	}
+	return nil
+}
 
-type ListObjectsRequest struct {
+func (m *StoreSnapshot) GetParts() []*EntryPart {
+	if m != nil {
+		return m.Parts
+	}
+	return nil
 }
 
-func (m *ListObjectsRequest) Reset()         { *m = ListObjectsRequest{} }
-func (m *ListObjectsRequest) String() string { return proto.CompactTextString(m) }
-func (*ListObjectsRequest) ProtoMessage()    {}
+func (m *StoreSnapshot) GetRateLimits() []*proton_v1.RateLimit {
+	if m != nil {
+		return m.RateLimits
+	}
+	return nil
+}
 
-type ListObjectsResponse struct {
-	Objects []*Pair `protobuf:"bytes,1,rep,name=objects" json:"objects,omitempty"`
+func (m *StoreSnapshot) GetBlobs() []*Blob {
+	if m != nil {
+		return m.Blobs
+	}
+	return nil
 }
 
-func (m *ListObjectsResponse) Reset()         { *m = ListObjectsResponse{} }
-func (m *ListObjectsResponse) String() string { return proto.CompactTextString(m) }
-func (*ListObjectsResponse) ProtoMessage()    {}
+func (m *StoreSnapshot) GetHlc() *proton_v1.HybridTime {
+	if m != nil {
+		return m.Hlc
+	}
+	return nil
+}
 
-func (m *ListObjectsResponse) GetObjects() []*Pair {
+func (m *StoreSnapshot) GetJoinTokens() []*JoinToken {
 	if m != nil {
-		return m.Objects
+		return m.JoinTokens
 	}
 	return nil
 }
 
-type ListMembersRequest struct {
+type Blob struct {
+	Digest []byte `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Value  []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 }
 
-func (m *ListMembersRequest) Reset()         { *m = ListMembersRequest{} }
-func (m *ListMembersRequest) String() string { return proto.CompactTextString(m) }
-func (*ListMembersRequest) ProtoMessage()    {}
+func (m *Blob) Reset()         { *m = Blob{} }
+func (m *Blob) String() string { return proto.CompactTextString(m) }
+func (*Blob) ProtoMessage()    {}
 
-type ListMembersResponse struct {
-	Members []*NodeInfo `protobuf:"bytes,1,rep,name=members" json:"members,omitempty"`
+type SnapshotData struct {
+	State    []byte           `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Checksum uint32           `protobuf:"varint,2,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	Pointer  *SnapshotPointer `protobuf:"bytes,3,opt,name=pointer" json:"pointer,omitempty"`
 }
 
-func (m *ListMembersResponse) Reset()         { *m = ListMembersResponse{} }
-func (m *ListMembersResponse) String() string { return proto.CompactTextString(m) }
-func (*ListMembersResponse) ProtoMessage()    {}
+func (m *SnapshotData) Reset()         { *m = SnapshotData{} }
+func (m *SnapshotData) String() string { return proto.CompactTextString(m) }
+func (*SnapshotData) ProtoMessage()    {}
 
-func (m *ListMembersResponse) GetMembers() []*NodeInfo {
+func (m *SnapshotData) GetPointer() *SnapshotPointer {
 	if m != nil {
-		return m.Members
+		return m.Pointer
+	}
+	return nil
+}
+
+type SnapshotPointer struct {
+	Location string `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Digest   []byte `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	Size_    uint64 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+}
+
+func (m *SnapshotPointer) Reset()         { *m = SnapshotPointer{} }
+func (m *SnapshotPointer) String() string { return proto.CompactTextString(m) }
+func (*SnapshotPointer) ProtoMessage()    {}
+
+type Txn struct {
+	Id       uint64               `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Compares []*proton_v1.Compare `protobuf:"bytes,2,rep,name=compares" json:"compares,omitempty"`
+	Writes   []*LogPair           `protobuf:"bytes,3,rep,name=writes" json:"writes,omitempty"`
+}
+
+func (m *Txn) Reset()         { *m = Txn{} }
+func (m *Txn) String() string { return proto.CompactTextString(m) }
+func (*Txn) ProtoMessage()    {}
+
+func (m *Txn) GetCompares() []*proton_v1.Compare {
+	if m != nil {
+		return m.Compares
+	}
+	return nil
+}
+
+func (m *Txn) GetWrites() []*LogPair {
+	if m != nil {
+		return m.Writes
 	}
 	return nil
 }
 
-type NodeInfo struct {
-	ID    uint64 `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Addr  string `protobuf:"bytes,2,opt,name=Addr,proto3" json:"Addr,omitempty"`
-	Port  string `protobuf:"bytes,3,opt,name=Port,proto3" json:"Port,omitempty"`
-	Error string `protobuf:"bytes,4,opt,name=Error,proto3" json:"Error,omitempty"`
+type EntryPart struct {
+	Id    uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Part  uint32 `protobuf:"varint,2,opt,name=part,proto3" json:"part,omitempty"`
+	Parts uint32 `protobuf:"varint,3,opt,name=parts,proto3" json:"parts,omitempty"`
+	Data  []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	Index uint64 `protobuf:"varint,5,opt,name=index,proto3" json:"index,omitempty"`
+}
+
+func (m *EntryPart) Reset()         { *m = EntryPart{} }
+func (m *EntryPart) String() string { return proto.CompactTextString(m) }
+func (*EntryPart) ProtoMessage()    {}
+
+type ProposalBatch struct {
+	Proposals [][]byte `protobuf:"bytes,1,rep,name=proposals" json:"proposals,omitempty"`
 }
 
-func (m *NodeInfo) Reset()         { *m = NodeInfo{} }
-func (m *NodeInfo) String() string { return proto.CompactTextString(m) }
-func (*NodeInfo) ProtoMessage()    {}
+func (m *ProposalBatch) Reset()         { *m = ProposalBatch{} }
+func (m *ProposalBatch) String() string { return proto.CompactTextString(m) }
+func (*ProposalBatch) ProtoMessage()    {}
 
-type Pair struct {
-	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+type JoinToken struct {
+	Id         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SecretHash []byte `protobuf:"bytes,2,opt,name=secret_hash,json=secretHash,proto3" json:"secret_hash,omitempty"`
+	Expires    int64  `protobuf:"varint,3,opt,name=expires,proto3" json:"expires,omitempty"`
 }
 
-func (m *Pair) Reset()         { *m = Pair{} }
-func (m *Pair) String() string { return proto.CompactTextString(m) }
-func (*Pair) ProtoMessage()    {}
+func (m *JoinToken) Reset()         { *m = JoinToken{} }
+func (m *JoinToken) String() string { return proto.CompactTextString(m) }
+func (*JoinToken) ProtoMessage()    {}
 
 func init() {
-	proto.RegisterType((*JoinRaftResponse)(nil), "proton.JoinRaftResponse")
-	proto.RegisterType((*LeaveRaftResponse)(nil), "proton.LeaveRaftResponse")
+	proto.RegisterType((*SnapshotChunk)(nil), "proton.SnapshotChunk")
 	proto.RegisterType((*SendResponse)(nil), "proton.SendResponse")
-	proto.RegisterType((*PutObjectRequest)(nil), "proton.PutObjectRequest")
-	proto.RegisterType((*PutObjectResponse)(nil), "proton.PutObjectResponse")
-	proto.RegisterType((*ListObjectsRequest)(nil), "proton.ListObjectsRequest")
-	proto.RegisterType((*ListObjectsResponse)(nil), "proton.ListObjectsResponse")
-	proto.RegisterType((*ListMembersRequest)(nil), "proton.ListMembersRequest")
-	proto.RegisterType((*ListMembersResponse)(nil), "proton.ListMembersResponse")
-	proto.RegisterType((*NodeInfo)(nil), "proton.NodeInfo")
-	proto.RegisterType((*Pair)(nil), "proton.Pair")
+	proto.RegisterType((*FetchEntriesRequest)(nil), "proton.FetchEntriesRequest")
+	proto.RegisterType((*FetchEntriesResponse)(nil), "proton.FetchEntriesResponse")
+	proto.RegisterType((*LogPair)(nil), "proton.LogPair")
+	proto.RegisterType((*StoreSnapshot)(nil), "proton.StoreSnapshot")
+	proto.RegisterType((*Blob)(nil), "proton.Blob")
+	proto.RegisterType((*SnapshotData)(nil), "proton.SnapshotData")
+	proto.RegisterType((*SnapshotPointer)(nil), "proton.SnapshotPointer")
+	proto.RegisterType((*Txn)(nil), "proton.Txn")
+	proto.RegisterType((*EntryPart)(nil), "proton.EntryPart")
+	proto.RegisterType((*ProposalBatch)(nil), "proton.ProposalBatch")
+	proto.RegisterType((*JoinToken)(nil), "proton.JoinToken")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ context.Context
 var _ grpc.ClientConn
 
-// Client API for Raft service
+// Client API for RaftTransport service
 
-type RaftClient interface {
-	JoinRaft(ctx context.Context, in *NodeInfo, opts ...grpc.CallOption) (*JoinRaftResponse, error)
-	LeaveRaft(ctx context.Context, in *NodeInfo, opts ...grpc.CallOption) (*LeaveRaftResponse, error)
+type RaftTransportClient interface {
 	Send(ctx context.Context, in *raftpb.Message, opts ...grpc.CallOption) (*SendResponse, error)
-	PutObject(ctx context.Context, in *PutObjectRequest, opts ...grpc.CallOption) (*PutObjectResponse, error)
-	ListObjects(ctx context.Context, in *ListObjectsRequest, opts ...grpc.CallOption) (*ListObjectsResponse, error)
-	ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error)
+	SendSnapshot(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_SendSnapshotClient, error)
 }
 
-type raftClient struct {
+type raftTransportClient struct {
 	cc *grpc.ClientConn
 }
 
-func NewRaftClient(cc *grpc.ClientConn) RaftClient {
-	return &raftClient{cc}
+func NewRaftTransportClient(cc *grpc.ClientConn) RaftTransportClient {
+	return &raftTransportClient{cc}
 }
 
-func (c *raftClient) JoinRaft(ctx context.Context, in *NodeInfo, opts ...grpc.CallOption) (*JoinRaftResponse, error) {
-	out := new(JoinRaftResponse)
-	err := grpc.Invoke(ctx, "/proton.Raft/JoinRaft", in, out, c.cc, opts...)
+func (c *raftTransportClient) Send(ctx context.Context, in *raftpb.Message, opts ...grpc.CallOption) (*SendResponse, error) {
+	out := new(SendResponse)
+	err := grpc.Invoke(ctx, "/proton.RaftTransport/Send", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *raftClient) LeaveRaft(ctx context.Context, in *NodeInfo, opts ...grpc.CallOption) (*LeaveRaftResponse, error) {
-	out := new(LeaveRaftResponse)
-	err := grpc.Invoke(ctx, "/proton.Raft/LeaveRaft", in, out, c.cc, opts...)
+func (c *raftTransportClient) SendSnapshot(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_SendSnapshotClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_RaftTransport_serviceDesc.Streams[0], c.cc, "/proton.RaftTransport/SendSnapshot", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &raftTransportSendSnapshotClient{stream}
+	return x, nil
 }
 
-func (c *raftClient) Send(ctx context.Context, in *raftpb.Message, opts ...grpc.CallOption) (*SendResponse, error) {
-	out := new(SendResponse)
-	err := grpc.Invoke(ctx, "/proton.Raft/Send", in, out, c.cc, opts...)
+type RaftTransport_SendSnapshotClient interface {
+	Send(*SnapshotChunk) error
+	CloseAndRecv() (*SendResponse, error)
+	grpc.ClientStream
+}
+
+type raftTransportSendSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *raftTransportSendSnapshotClient) Send(m *SnapshotChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *raftTransportSendSnapshotClient) CloseAndRecv() (*SendResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(SendResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for RaftTransport service
+
+type RaftTransportServer interface {
+	Send(context.Context, *raftpb.Message) (*SendResponse, error)
+	SendSnapshot(RaftTransport_SendSnapshotServer) error
+}
+
+func RegisterRaftTransportServer(s *grpc.Server, srv RaftTransportServer) {
+	s.RegisterService(&_RaftTransport_serviceDesc, srv)
+}
+
+func _RaftTransport_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(raftpb.Message)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(RaftTransportServer).Send(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *raftClient) PutObject(ctx context.Context, in *PutObjectRequest, opts ...grpc.CallOption) (*PutObjectResponse, error) {
-	out := new(PutObjectResponse)
-	err := grpc.Invoke(ctx, "/proton.Raft/PutObject", in, out, c.cc, opts...)
+func _RaftTransport_SendSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RaftTransportServer).SendSnapshot(&raftTransportSendSnapshotServer{stream})
+}
+
+type RaftTransport_SendSnapshotServer interface {
+	SendAndClose(*SendResponse) error
+	Recv() (*SnapshotChunk, error)
+	grpc.ServerStream
+}
+
+type raftTransportSendSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *raftTransportSendSnapshotServer) SendAndClose(m *SendResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *raftTransportSendSnapshotServer) Recv() (*SnapshotChunk, error) {
+	m := new(SnapshotChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _RaftTransport_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proton.RaftTransport",
+	HandlerType: (*RaftTransportServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Send",
+			Handler:    _RaftTransport_Send_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SendSnapshot",
+			Handler:       _RaftTransport_SendSnapshot_Handler,
+			ClientStreams: true,
+		},
+	},
+}
+
+// Client API for Cluster service
+
+type ClusterClient interface {
+	JoinRaft(ctx context.Context, in *proton_v1.NodeInfo, opts ...grpc.CallOption) (*proton_v1.JoinRaftResponse, error)
+	LeaveRaft(ctx context.Context, in *proton_v1.NodeInfo, opts ...grpc.CallOption) (*proton_v1.LeaveRaftResponse, error)
+	UpdateMember(ctx context.Context, in *proton_v1.NodeInfo, opts ...grpc.CallOption) (*proton_v1.UpdateMemberResponse, error)
+	LabelMember(ctx context.Context, in *proton_v1.LabelMemberRequest, opts ...grpc.CallOption) (*proton_v1.LabelMemberResponse, error)
+	CreateJoinToken(ctx context.Context, in *proton_v1.CreateJoinTokenRequest, opts ...grpc.CallOption) (*proton_v1.CreateJoinTokenResponse, error)
+	ListMembers(ctx context.Context, in *proton_v1.ListMembersRequest, opts ...grpc.CallOption) (*proton_v1.ListMembersResponse, error)
+	ListAuditEvents(ctx context.Context, in *proton_v1.ListAuditEventsRequest, opts ...grpc.CallOption) (*proton_v1.ListAuditEventsResponse, error)
+	ListAlarms(ctx context.Context, in *proton_v1.ListAlarmsRequest, opts ...grpc.CallOption) (*proton_v1.ListAlarmsResponse, error)
+	DisarmAlarm(ctx context.Context, in *proton_v1.Alarm, opts ...grpc.CallOption) (*proton_v1.DisarmAlarmResponse, error)
+	ToggleReadOnly(ctx context.Context, in *proton_v1.ToggleReadOnlyRequest, opts ...grpc.CallOption) (*proton_v1.ToggleReadOnlyResponse, error)
+	DrainNode(ctx context.Context, in *proton_v1.DrainNodeRequest, opts ...grpc.CallOption) (*proton_v1.DrainNodeResponse, error)
+	CheckLeader(ctx context.Context, in *proton_v1.CheckLeaderRequest, opts ...grpc.CallOption) (*proton_v1.CheckLeaderResponse, error)
+	RecoverCluster(ctx context.Context, in *proton_v1.RecoverClusterRequest, opts ...grpc.CallOption) (*proton_v1.RecoverClusterResponse, error)
+	FetchEntries(ctx context.Context, in *FetchEntriesRequest, opts ...grpc.CallOption) (*FetchEntriesResponse, error)
+	PromoteStandby(ctx context.Context, in *proton_v1.PromoteStandbyRequest, opts ...grpc.CallOption) (*proton_v1.PromoteStandbyResponse, error)
+	SetRateLimit(ctx context.Context, in *proton_v1.RateLimit, opts ...grpc.CallOption) (*proton_v1.SetRateLimitResponse, error)
+	ListRateLimits(ctx context.Context, in *proton_v1.ListRateLimitsRequest, opts ...grpc.CallOption) (*proton_v1.ListRateLimitsResponse, error)
+	RaftStatus(ctx context.Context, in *proton_v1.RaftStatusRequest, opts ...grpc.CallOption) (*proton_v1.RaftStatusResponse, error)
+	LeaderReadIndex(ctx context.Context, in *proton_v1.LeaderReadIndexRequest, opts ...grpc.CallOption) (*proton_v1.LeaderReadIndexResponse, error)
+	ChangeLogLevel(ctx context.Context, in *proton_v1.ChangeLogLevelRequest, opts ...grpc.CallOption) (*proton_v1.ChangeLogLevelResponse, error)
+}
+
+type clusterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewClusterClient(cc *grpc.ClientConn) ClusterClient {
+	return &clusterClient{cc}
+}
+
+func (c *clusterClient) JoinRaft(ctx context.Context, in *proton_v1.NodeInfo, opts ...grpc.CallOption) (*proton_v1.JoinRaftResponse, error) {
+	out := new(proton_v1.JoinRaftResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/JoinRaft", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *raftClient) ListObjects(ctx context.Context, in *ListObjectsRequest, opts ...grpc.CallOption) (*ListObjectsResponse, error) {
-	out := new(ListObjectsResponse)
-	err := grpc.Invoke(ctx, "/proton.Raft/ListObjects", in, out, c.cc, opts...)
+func (c *clusterClient) LeaveRaft(ctx context.Context, in *proton_v1.NodeInfo, opts ...grpc.CallOption) (*proton_v1.LeaveRaftResponse, error) {
+	out := new(proton_v1.LeaveRaftResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/LeaveRaft", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *raftClient) ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error) {
-	out := new(ListMembersResponse)
-	err := grpc.Invoke(ctx, "/proton.Raft/ListMembers", in, out, c.cc, opts...)
+func (c *clusterClient) UpdateMember(ctx context.Context, in *proton_v1.NodeInfo, opts ...grpc.CallOption) (*proton_v1.UpdateMemberResponse, error) {
+	out := new(proton_v1.UpdateMemberResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/UpdateMember", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// Server API for Raft service
-
-type RaftServer interface {
-	JoinRaft(context.Context, *NodeInfo) (*JoinRaftResponse, error)
-	LeaveRaft(context.Context, *NodeInfo) (*LeaveRaftResponse, error)
-	Send(context.Context, *raftpb.Message) (*SendResponse, error)
-	PutObject(context.Context, *PutObjectRequest) (*PutObjectResponse, error)
-	ListObjects(context.Context, *ListObjectsRequest) (*ListObjectsResponse, error)
-	ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error)
+func (c *clusterClient) LabelMember(ctx context.Context, in *proton_v1.LabelMemberRequest, opts ...grpc.CallOption) (*proton_v1.LabelMemberResponse, error) {
+	out := new(proton_v1.LabelMemberResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/LabelMember", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func RegisterRaftServer(s *grpc.Server, srv RaftServer) {
-	s.RegisterService(&_Raft_serviceDesc, srv)
+func (c *clusterClient) CreateJoinToken(ctx context.Context, in *proton_v1.CreateJoinTokenRequest, opts ...grpc.CallOption) (*proton_v1.CreateJoinTokenResponse, error) {
+	out := new(proton_v1.CreateJoinTokenResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/CreateJoinToken", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func _Raft_JoinRaft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(NodeInfo)
-	if err := dec(in); err != nil {
+func (c *clusterClient) ListMembers(ctx context.Context, in *proton_v1.ListMembersRequest, opts ...grpc.CallOption) (*proton_v1.ListMembersResponse, error) {
+	out := new(proton_v1.ListMembersResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/ListMembers", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(RaftServer).JoinRaft(ctx, in)
+	return out, nil
+}
+
+func (c *clusterClient) ListAuditEvents(ctx context.Context, in *proton_v1.ListAuditEventsRequest, opts ...grpc.CallOption) (*proton_v1.ListAuditEventsResponse, error) {
+	out := new(proton_v1.ListAuditEventsResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/ListAuditEvents", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _Raft_LeaveRaft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(NodeInfo)
-	if err := dec(in); err != nil {
+func (c *clusterClient) ListAlarms(ctx context.Context, in *proton_v1.ListAlarmsRequest, opts ...grpc.CallOption) (*proton_v1.ListAlarmsResponse, error) {
+	out := new(proton_v1.ListAlarmsResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/ListAlarms", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(RaftServer).LeaveRaft(ctx, in)
+	return out, nil
+}
+
+func (c *clusterClient) DisarmAlarm(ctx context.Context, in *proton_v1.Alarm, opts ...grpc.CallOption) (*proton_v1.DisarmAlarmResponse, error) {
+	out := new(proton_v1.DisarmAlarmResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/DisarmAlarm", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _Raft_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(raftpb.Message)
-	if err := dec(in); err != nil {
+func (c *clusterClient) ToggleReadOnly(ctx context.Context, in *proton_v1.ToggleReadOnlyRequest, opts ...grpc.CallOption) (*proton_v1.ToggleReadOnlyResponse, error) {
+	out := new(proton_v1.ToggleReadOnlyResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/ToggleReadOnly", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(RaftServer).Send(ctx, in)
+	return out, nil
+}
+
+func (c *clusterClient) DrainNode(ctx context.Context, in *proton_v1.DrainNodeRequest, opts ...grpc.CallOption) (*proton_v1.DrainNodeResponse, error) {
+	out := new(proton_v1.DrainNodeResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/DrainNode", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _Raft_PutObject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(PutObjectRequest)
-	if err := dec(in); err != nil {
+func (c *clusterClient) CheckLeader(ctx context.Context, in *proton_v1.CheckLeaderRequest, opts ...grpc.CallOption) (*proton_v1.CheckLeaderResponse, error) {
+	out := new(proton_v1.CheckLeaderResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/CheckLeader", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(RaftServer).PutObject(ctx, in)
+	return out, nil
+}
+
+func (c *clusterClient) RecoverCluster(ctx context.Context, in *proton_v1.RecoverClusterRequest, opts ...grpc.CallOption) (*proton_v1.RecoverClusterResponse, error) {
+	out := new(proton_v1.RecoverClusterResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/RecoverCluster", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _Raft_ListObjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(ListObjectsRequest)
-	if err := dec(in); err != nil {
+func (c *clusterClient) FetchEntries(ctx context.Context, in *FetchEntriesRequest, opts ...grpc.CallOption) (*FetchEntriesResponse, error) {
+	out := new(FetchEntriesResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/FetchEntries", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(RaftServer).ListObjects(ctx, in)
+	return out, nil
+}
+
+func (c *clusterClient) PromoteStandby(ctx context.Context, in *proton_v1.PromoteStandbyRequest, opts ...grpc.CallOption) (*proton_v1.PromoteStandbyResponse, error) {
+	out := new(proton_v1.PromoteStandbyResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/PromoteStandby", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _Raft_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(ListMembersRequest)
-	if err := dec(in); err != nil {
+func (c *clusterClient) SetRateLimit(ctx context.Context, in *proton_v1.RateLimit, opts ...grpc.CallOption) (*proton_v1.SetRateLimitResponse, error) {
+	out := new(proton_v1.SetRateLimitResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/SetRateLimit", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(RaftServer).ListMembers(ctx, in)
+	return out, nil
+}
+
+func (c *clusterClient) ListRateLimits(ctx context.Context, in *proton_v1.ListRateLimitsRequest, opts ...grpc.CallOption) (*proton_v1.ListRateLimitsResponse, error) {
+	out := new(proton_v1.ListRateLimitsResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/ListRateLimits", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-var _Raft_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "proton.Raft",
-	HandlerType: (*RaftServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "JoinRaft",
-			Handler:    _Raft_JoinRaft_Handler,
-		},
-		{
-			MethodName: "LeaveRaft",
-			Handler:    _Raft_LeaveRaft_Handler,
-		},
-		{
-			MethodName: "Send",
-			Handler:    _Raft_Send_Handler,
-		},
-		{
-			MethodName: "PutObject",
-			Handler:    _Raft_PutObject_Handler,
-		},
-		{
-			MethodName: "ListObjects",
-			Handler:    _Raft_ListObjects_Handler,
-		},
-		{
-			MethodName: "ListMembers",
-			Handler:    _Raft_ListMembers_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{},
+func (c *clusterClient) RaftStatus(ctx context.Context, in *proton_v1.RaftStatusRequest, opts ...grpc.CallOption) (*proton_v1.RaftStatusResponse, error) {
+	out := new(proton_v1.RaftStatusResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/RaftStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *JoinRaftResponse) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func (c *clusterClient) LeaderReadIndex(ctx context.Context, in *proton_v1.LeaderReadIndexRequest, opts ...grpc.CallOption) (*proton_v1.LeaderReadIndexResponse, error) {
+	out := new(proton_v1.LeaderReadIndexResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/LeaderReadIndex", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *JoinRaftResponse) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Success {
-		data[i] = 0x8
-		i++
-		if m.Success {
-			data[i] = 1
-		} else {
-			data[i] = 0
-		}
-		i++
-	}
-	if len(m.Error) > 0 {
-		data[i] = 0x12
-		i++
-		i = encodeVarintProton(data, i, uint64(len(m.Error)))
-		i += copy(data[i:], m.Error)
-	}
-	if len(m.Nodes) > 0 {
-		for _, msg := range m.Nodes {
-			data[i] = 0x1a
-			i++
-			i = encodeVarintProton(data, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(data[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+func (c *clusterClient) ChangeLogLevel(ctx context.Context, in *proton_v1.ChangeLogLevelRequest, opts ...grpc.CallOption) (*proton_v1.ChangeLogLevelResponse, error) {
+	out := new(proton_v1.ChangeLogLevelResponse)
+	err := grpc.Invoke(ctx, "/proton.Cluster/ChangeLogLevel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return i, nil
+	return out, nil
 }
 
-func (m *LeaveRaftResponse) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+// Server API for Cluster service
+
+type ClusterServer interface {
+	JoinRaft(context.Context, *proton_v1.NodeInfo) (*proton_v1.JoinRaftResponse, error)
+	LeaveRaft(context.Context, *proton_v1.NodeInfo) (*proton_v1.LeaveRaftResponse, error)
+	UpdateMember(context.Context, *proton_v1.NodeInfo) (*proton_v1.UpdateMemberResponse, error)
+	LabelMember(context.Context, *proton_v1.LabelMemberRequest) (*proton_v1.LabelMemberResponse, error)
+	CreateJoinToken(context.Context, *proton_v1.CreateJoinTokenRequest) (*proton_v1.CreateJoinTokenResponse, error)
+	ListMembers(context.Context, *proton_v1.ListMembersRequest) (*proton_v1.ListMembersResponse, error)
+	ListAuditEvents(context.Context, *proton_v1.ListAuditEventsRequest) (*proton_v1.ListAuditEventsResponse, error)
+	ListAlarms(context.Context, *proton_v1.ListAlarmsRequest) (*proton_v1.ListAlarmsResponse, error)
+	DisarmAlarm(context.Context, *proton_v1.Alarm) (*proton_v1.DisarmAlarmResponse, error)
+	ToggleReadOnly(context.Context, *proton_v1.ToggleReadOnlyRequest) (*proton_v1.ToggleReadOnlyResponse, error)
+	DrainNode(context.Context, *proton_v1.DrainNodeRequest) (*proton_v1.DrainNodeResponse, error)
+	CheckLeader(context.Context, *proton_v1.CheckLeaderRequest) (*proton_v1.CheckLeaderResponse, error)
+	RecoverCluster(context.Context, *proton_v1.RecoverClusterRequest) (*proton_v1.RecoverClusterResponse, error)
+	FetchEntries(context.Context, *FetchEntriesRequest) (*FetchEntriesResponse, error)
+	PromoteStandby(context.Context, *proton_v1.PromoteStandbyRequest) (*proton_v1.PromoteStandbyResponse, error)
+	SetRateLimit(context.Context, *proton_v1.RateLimit) (*proton_v1.SetRateLimitResponse, error)
+	ListRateLimits(context.Context, *proton_v1.ListRateLimitsRequest) (*proton_v1.ListRateLimitsResponse, error)
+	RaftStatus(context.Context, *proton_v1.RaftStatusRequest) (*proton_v1.RaftStatusResponse, error)
+	LeaderReadIndex(context.Context, *proton_v1.LeaderReadIndexRequest) (*proton_v1.LeaderReadIndexResponse, error)
+	ChangeLogLevel(context.Context, *proton_v1.ChangeLogLevelRequest) (*proton_v1.ChangeLogLevelResponse, error)
+}
+
+func RegisterClusterServer(s *grpc.Server, srv ClusterServer) {
+	s.RegisterService(&_Cluster_serviceDesc, srv)
+}
+
+func _Cluster_JoinRaft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.NodeInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).JoinRaft(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *LeaveRaftResponse) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Success {
-		data[i] = 0x8
-		i++
-		if m.Success {
-			data[i] = 1
-		} else {
-			data[i] = 0
-		}
-		i++
+func _Cluster_LeaveRaft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.NodeInfo)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	if len(m.Error) > 0 {
-		data[i] = 0x12
-		i++
-		i = encodeVarintProton(data, i, uint64(len(m.Error)))
-		i += copy(data[i:], m.Error)
+	out, err := srv.(ClusterServer).LeaveRaft(ctx, in)
+	if err != nil {
+		return nil, err
 	}
-	return i, nil
+	return out, nil
 }
 
-func (m *SendResponse) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_UpdateMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.NodeInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).UpdateMember(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *SendResponse) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Success {
-		data[i] = 0x8
-		i++
-		if m.Success {
-			data[i] = 1
-		} else {
-			data[i] = 0
-		}
-		i++
+func _Cluster_LabelMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.LabelMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	if len(m.Error) > 0 {
-		data[i] = 0x12
-		i++
-		i = encodeVarintProton(data, i, uint64(len(m.Error)))
-		i += copy(data[i:], m.Error)
+	out, err := srv.(ClusterServer).LabelMember(ctx, in)
+	if err != nil {
+		return nil, err
 	}
-	return i, nil
+	return out, nil
 }
 
-func (m *PutObjectRequest) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_CreateJoinToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.CreateJoinTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).CreateJoinToken(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *PutObjectRequest) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Object != nil {
-		data[i] = 0xa
-		i++
-		i = encodeVarintProton(data, i, uint64(m.Object.Size()))
-		n1, err := m.Object.MarshalTo(data[i:])
-		if err != nil {
-			return 0, err
-		}
-		i += n1
+func _Cluster_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.ListMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return i, nil
+	out, err := srv.(ClusterServer).ListMembers(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *PutObjectResponse) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).ListAuditEvents(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *PutObjectResponse) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Success {
-		data[i] = 0x8
-		i++
-		if m.Success {
-			data[i] = 1
-		} else {
-			data[i] = 0
-		}
-		i++
+func _Cluster_ListAlarms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.ListAlarmsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	if len(m.Error) > 0 {
-		data[i] = 0x12
-		i++
-		i = encodeVarintProton(data, i, uint64(len(m.Error)))
-		i += copy(data[i:], m.Error)
+	out, err := srv.(ClusterServer).ListAlarms(ctx, in)
+	if err != nil {
+		return nil, err
 	}
-	return i, nil
+	return out, nil
 }
 
-func (m *ListObjectsRequest) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_DisarmAlarm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.Alarm)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).DisarmAlarm(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *ListObjectsRequest) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	return i, nil
+func _Cluster_ToggleReadOnly_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.ToggleReadOnlyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).ToggleReadOnly(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *ListObjectsResponse) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_DrainNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.DrainNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).DrainNode(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *ListObjectsResponse) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Objects) > 0 {
-		for _, msg := range m.Objects {
-			data[i] = 0xa
-			i++
-			i = encodeVarintProton(data, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(data[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+func _Cluster_CheckLeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.CheckLeaderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return i, nil
+	out, err := srv.(ClusterServer).CheckLeader(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *ListMembersRequest) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_RecoverCluster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.RecoverClusterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).RecoverCluster(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *ListMembersRequest) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	return i, nil
+func _Cluster_FetchEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(FetchEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).FetchEntries(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *ListMembersResponse) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_PromoteStandby_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.PromoteStandbyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).PromoteStandby(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *ListMembersResponse) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Members) > 0 {
-		for _, msg := range m.Members {
-			data[i] = 0xa
-			i++
-			i = encodeVarintProton(data, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(data[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
-		}
+func _Cluster_SetRateLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.RateLimit)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return i, nil
+	out, err := srv.(ClusterServer).SetRateLimit(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *NodeInfo) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_ListRateLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.ListRateLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).ListRateLimits(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *NodeInfo) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.ID != 0 {
-		data[i] = 0x8
-		i++
-		i = encodeVarintProton(data, i, uint64(m.ID))
+func _Cluster_RaftStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.RaftStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	if len(m.Addr) > 0 {
-		data[i] = 0x12
-		i++
-		i = encodeVarintProton(data, i, uint64(len(m.Addr)))
-		i += copy(data[i:], m.Addr)
+	out, err := srv.(ClusterServer).RaftStatus(ctx, in)
+	if err != nil {
+		return nil, err
 	}
-	if len(m.Port) > 0 {
-		data[i] = 0x1a
-		i++
-		i = encodeVarintProton(data, i, uint64(len(m.Port)))
-		i += copy(data[i:], m.Port)
+	return out, nil
+}
+
+func _Cluster_LeaderReadIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.LeaderReadIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	if len(m.Error) > 0 {
-		data[i] = 0x22
-		i++
-		i = encodeVarintProton(data, i, uint64(len(m.Error)))
-		i += copy(data[i:], m.Error)
+	out, err := srv.(ClusterServer).LeaderReadIndex(ctx, in)
+	if err != nil {
+		return nil, err
 	}
-	return i, nil
+	return out, nil
 }
 
-func (m *Pair) Marshal() (data []byte, err error) {
-	size := m.Size()
-	data = make([]byte, size)
-	n, err := m.MarshalTo(data)
+func _Cluster_ChangeLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.ChangeLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ClusterServer).ChangeLogLevel(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	return data[:n], nil
+	return out, nil
 }
 
-func (m *Pair) MarshalTo(data []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Key) > 0 {
-		data[i] = 0xa
-		i++
-		i = encodeVarintProton(data, i, uint64(len(m.Key)))
-		i += copy(data[i:], m.Key)
-	}
-	if m.Value != nil {
-		if len(m.Value) > 0 {
-			data[i] = 0x12
-			i++
-			i = encodeVarintProton(data, i, uint64(len(m.Value)))
-			i += copy(data[i:], m.Value)
-		}
-	}
-	return i, nil
+var _Cluster_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proton.Cluster",
+	HandlerType: (*ClusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "JoinRaft",
+			Handler:    _Cluster_JoinRaft_Handler,
+		},
+		{
+			MethodName: "LeaveRaft",
+			Handler:    _Cluster_LeaveRaft_Handler,
+		},
+		{
+			MethodName: "UpdateMember",
+			Handler:    _Cluster_UpdateMember_Handler,
+		},
+		{
+			MethodName: "LabelMember",
+			Handler:    _Cluster_LabelMember_Handler,
+		},
+		{
+			MethodName: "CreateJoinToken",
+			Handler:    _Cluster_CreateJoinToken_Handler,
+		},
+		{
+			MethodName: "ListMembers",
+			Handler:    _Cluster_ListMembers_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _Cluster_ListAuditEvents_Handler,
+		},
+		{
+			MethodName: "ListAlarms",
+			Handler:    _Cluster_ListAlarms_Handler,
+		},
+		{
+			MethodName: "DisarmAlarm",
+			Handler:    _Cluster_DisarmAlarm_Handler,
+		},
+		{
+			MethodName: "ToggleReadOnly",
+			Handler:    _Cluster_ToggleReadOnly_Handler,
+		},
+		{
+			MethodName: "DrainNode",
+			Handler:    _Cluster_DrainNode_Handler,
+		},
+		{
+			MethodName: "CheckLeader",
+			Handler:    _Cluster_CheckLeader_Handler,
+		},
+		{
+			MethodName: "RecoverCluster",
+			Handler:    _Cluster_RecoverCluster_Handler,
+		},
+		{
+			MethodName: "FetchEntries",
+			Handler:    _Cluster_FetchEntries_Handler,
+		},
+		{
+			MethodName: "PromoteStandby",
+			Handler:    _Cluster_PromoteStandby_Handler,
+		},
+		{
+			MethodName: "SetRateLimit",
+			Handler:    _Cluster_SetRateLimit_Handler,
+		},
+		{
+			MethodName: "ListRateLimits",
+			Handler:    _Cluster_ListRateLimits_Handler,
+		},
+		{
+			MethodName: "RaftStatus",
+			Handler:    _Cluster_RaftStatus_Handler,
+		},
+		{
+			MethodName: "LeaderReadIndex",
+			Handler:    _Cluster_LeaderReadIndex_Handler,
+		},
+		{
+			MethodName: "ChangeLogLevel",
+			Handler:    _Cluster_ChangeLogLevel_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
 }
 
-func encodeFixed64Proton(data []byte, offset int, v uint64) int {
-	data[offset] = uint8(v)
-	data[offset+1] = uint8(v >> 8)
-	data[offset+2] = uint8(v >> 16)
-	data[offset+3] = uint8(v >> 24)
-	data[offset+4] = uint8(v >> 32)
-	data[offset+5] = uint8(v >> 40)
-	data[offset+6] = uint8(v >> 48)
-	data[offset+7] = uint8(v >> 56)
-	return offset + 8
+// Client API for KV service
+
+type KVClient interface {
+	PutObject(ctx context.Context, in *proton_v1.PutObjectRequest, opts ...grpc.CallOption) (*proton_v1.PutObjectResponse, error)
+	ListObjects(ctx context.Context, in *proton_v1.ListObjectsRequest, opts ...grpc.CallOption) (*proton_v1.ListObjectsResponse, error)
+	GetObjects(ctx context.Context, in *proton_v1.GetObjectsRequest, opts ...grpc.CallOption) (*proton_v1.GetObjectsResponse, error)
+	Txn(ctx context.Context, in *proton_v1.TxnRequest, opts ...grpc.CallOption) (*proton_v1.TxnResponse, error)
+	StreamChanges(ctx context.Context, in *proton_v1.StreamChangesRequest, opts ...grpc.CallOption) (KV_StreamChangesClient, error)
+	StreamChangeBatches(ctx context.Context, in *proton_v1.StreamChangesRequest, opts ...grpc.CallOption) (KV_StreamChangeBatchesClient, error)
+	GrantSession(ctx context.Context, in *proton_v1.GrantSessionRequest, opts ...grpc.CallOption) (*proton_v1.GrantSessionResponse, error)
+	KeepAliveSession(ctx context.Context, in *proton_v1.KeepAliveSessionRequest, opts ...grpc.CallOption) (*proton_v1.KeepAliveSessionResponse, error)
+	RevokeSession(ctx context.Context, in *proton_v1.RevokeSessionRequest, opts ...grpc.CallOption) (*proton_v1.RevokeSessionResponse, error)
+	AcquireSemaphore(ctx context.Context, in *proton_v1.AcquireSemaphoreRequest, opts ...grpc.CallOption) (*proton_v1.AcquireSemaphoreResponse, error)
+	ReleaseSemaphore(ctx context.Context, in *proton_v1.ReleaseSemaphoreRequest, opts ...grpc.CallOption) (*proton_v1.ReleaseSemaphoreResponse, error)
+	BulkLoad(ctx context.Context, opts ...grpc.CallOption) (KV_BulkLoadClient, error)
 }
-func encodeFixed32Proton(data []byte, offset int, v uint32) int {
-	data[offset] = uint8(v)
-	data[offset+1] = uint8(v >> 8)
-	data[offset+2] = uint8(v >> 16)
-	data[offset+3] = uint8(v >> 24)
-	return offset + 4
+
+type kVClient struct {
+	cc *grpc.ClientConn
 }
-func encodeVarintProton(data []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		data[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
-	}
-	data[offset] = uint8(v)
-	return offset + 1
+
+func NewKVClient(cc *grpc.ClientConn) KVClient {
+	return &kVClient{cc}
 }
-func (m *JoinRaftResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.Success {
-		n += 2
-	}
-	l = len(m.Error)
-	if l > 0 {
-		n += 1 + l + sovProton(uint64(l))
+
+func (c *kVClient) PutObject(ctx context.Context, in *proton_v1.PutObjectRequest, opts ...grpc.CallOption) (*proton_v1.PutObjectResponse, error) {
+	out := new(proton_v1.PutObjectResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/PutObject", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	if len(m.Nodes) > 0 {
-		for _, e := range m.Nodes {
-			l = e.Size()
-			n += 1 + l + sovProton(uint64(l))
-		}
+	return out, nil
+}
+
+func (c *kVClient) ListObjects(ctx context.Context, in *proton_v1.ListObjectsRequest, opts ...grpc.CallOption) (*proton_v1.ListObjectsResponse, error) {
+	out := new(proton_v1.ListObjectsResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/ListObjects", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return out, nil
 }
 
-func (m *LeaveRaftResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.Success {
-		n += 2
+func (c *kVClient) GetObjects(ctx context.Context, in *proton_v1.GetObjectsRequest, opts ...grpc.CallOption) (*proton_v1.GetObjectsResponse, error) {
+	out := new(proton_v1.GetObjectsResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/GetObjects", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	l = len(m.Error)
-	if l > 0 {
-		n += 1 + l + sovProton(uint64(l))
+	return out, nil
+}
+
+func (c *kVClient) Txn(ctx context.Context, in *proton_v1.TxnRequest, opts ...grpc.CallOption) (*proton_v1.TxnResponse, error) {
+	out := new(proton_v1.TxnResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/Txn", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return out, nil
 }
 
-func (m *SendResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.Success {
-		n += 2
+func (c *kVClient) StreamChanges(ctx context.Context, in *proton_v1.StreamChangesRequest, opts ...grpc.CallOption) (KV_StreamChangesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KV_serviceDesc.Streams[0], c.cc, "/proton.KV/StreamChanges", opts...)
+	if err != nil {
+		return nil, err
 	}
-	l = len(m.Error)
-	if l > 0 {
-		n += 1 + l + sovProton(uint64(l))
+	x := &kVStreamChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
 	}
-	return n
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (m *PutObjectRequest) Size() (n int) {
-	var l int
-	_ = l
-	if m.Object != nil {
-		l = m.Object.Size()
-		n += 1 + l + sovProton(uint64(l))
+type KV_StreamChangesClient interface {
+	Recv() (*proton_v1.Change, error)
+	grpc.ClientStream
+}
+
+type kVStreamChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *kVStreamChangesClient) Recv() (*proton_v1.Change, error) {
+	m := new(proton_v1.Change)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
 	}
-	return n
+	return m, nil
 }
 
-func (m *PutObjectResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.Success {
-		n += 2
+func (c *kVClient) StreamChangeBatches(ctx context.Context, in *proton_v1.StreamChangesRequest, opts ...grpc.CallOption) (KV_StreamChangeBatchesClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KV_serviceDesc.Streams[1], c.cc, "/proton.KV/StreamChangeBatches", opts...)
+	if err != nil {
+		return nil, err
 	}
-	l = len(m.Error)
-	if l > 0 {
-		n += 1 + l + sovProton(uint64(l))
+	x := &kVStreamChangeBatchesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
 	}
-	return n
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (m *ListObjectsRequest) Size() (n int) {
-	var l int
-	_ = l
-	return n
+type KV_StreamChangeBatchesClient interface {
+	Recv() (*proton_v1.ChangeBatch, error)
+	grpc.ClientStream
 }
 
-func (m *ListObjectsResponse) Size() (n int) {
-	var l int
-	_ = l
-	if len(m.Objects) > 0 {
-		for _, e := range m.Objects {
-			l = e.Size()
-			n += 1 + l + sovProton(uint64(l))
-		}
-	}
-	return n
+type kVStreamChangeBatchesClient struct {
+	grpc.ClientStream
 }
 
-func (m *ListMembersRequest) Size() (n int) {
-	var l int
-	_ = l
-	return n
+func (x *kVStreamChangeBatchesClient) Recv() (*proton_v1.ChangeBatch, error) {
+	m := new(proton_v1.ChangeBatch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func (m *ListMembersResponse) Size() (n int) {
-	var l int
-	_ = l
-	if len(m.Members) > 0 {
-		for _, e := range m.Members {
-			l = e.Size()
-			n += 1 + l + sovProton(uint64(l))
-		}
+func (c *kVClient) GrantSession(ctx context.Context, in *proton_v1.GrantSessionRequest, opts ...grpc.CallOption) (*proton_v1.GrantSessionResponse, error) {
+	out := new(proton_v1.GrantSessionResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/GrantSession", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return out, nil
 }
 
-func (m *NodeInfo) Size() (n int) {
-	var l int
-	_ = l
-	if m.ID != 0 {
-		n += 1 + sovProton(uint64(m.ID))
-	}
-	l = len(m.Addr)
-	if l > 0 {
-		n += 1 + l + sovProton(uint64(l))
+func (c *kVClient) KeepAliveSession(ctx context.Context, in *proton_v1.KeepAliveSessionRequest, opts ...grpc.CallOption) (*proton_v1.KeepAliveSessionResponse, error) {
+	out := new(proton_v1.KeepAliveSessionResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/KeepAliveSession", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	l = len(m.Port)
-	if l > 0 {
-		n += 1 + l + sovProton(uint64(l))
+	return out, nil
+}
+
+func (c *kVClient) RevokeSession(ctx context.Context, in *proton_v1.RevokeSessionRequest, opts ...grpc.CallOption) (*proton_v1.RevokeSessionResponse, error) {
+	out := new(proton_v1.RevokeSessionResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/RevokeSession", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	l = len(m.Error)
-	if l > 0 {
-		n += 1 + l + sovProton(uint64(l))
+	return out, nil
+}
+
+func (c *kVClient) AcquireSemaphore(ctx context.Context, in *proton_v1.AcquireSemaphoreRequest, opts ...grpc.CallOption) (*proton_v1.AcquireSemaphoreResponse, error) {
+	out := new(proton_v1.AcquireSemaphoreResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/AcquireSemaphore", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return out, nil
 }
 
-func (m *Pair) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Key)
-	if l > 0 {
-		n += 1 + l + sovProton(uint64(l))
+func (c *kVClient) ReleaseSemaphore(ctx context.Context, in *proton_v1.ReleaseSemaphoreRequest, opts ...grpc.CallOption) (*proton_v1.ReleaseSemaphoreResponse, error) {
+	out := new(proton_v1.ReleaseSemaphoreResponse)
+	err := grpc.Invoke(ctx, "/proton.KV/ReleaseSemaphore", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	if m.Value != nil {
-		l = len(m.Value)
-		if l > 0 {
-			n += 1 + l + sovProton(uint64(l))
-		}
+	return out, nil
+}
+
+func (c *kVClient) BulkLoad(ctx context.Context, opts ...grpc.CallOption) (KV_BulkLoadClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_KV_serviceDesc.Streams[2], c.cc, "/proton.KV/BulkLoad", opts...)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	x := &kVBulkLoadClient{stream}
+	return x, nil
 }
 
-func sovProton(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+type KV_BulkLoadClient interface {
+	Send(*proton_v1.BulkLoadRequest) error
+	Recv() (*proton_v1.BulkLoadProgress, error)
+	grpc.ClientStream
+}
+
+type kVBulkLoadClient struct {
+	grpc.ClientStream
+}
+
+func (x *kVBulkLoadClient) Send(m *proton_v1.BulkLoadRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *kVBulkLoadClient) Recv() (*proton_v1.BulkLoadProgress, error) {
+	m := new(proton_v1.BulkLoadProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
 	}
-	return n
+	return m, nil
 }
-func sozProton(x uint64) (n int) {
-	return sovProton(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+
+// Server API for KV service
+
+type KVServer interface {
+	PutObject(context.Context, *proton_v1.PutObjectRequest) (*proton_v1.PutObjectResponse, error)
+	ListObjects(context.Context, *proton_v1.ListObjectsRequest) (*proton_v1.ListObjectsResponse, error)
+	GetObjects(context.Context, *proton_v1.GetObjectsRequest) (*proton_v1.GetObjectsResponse, error)
+	Txn(context.Context, *proton_v1.TxnRequest) (*proton_v1.TxnResponse, error)
+	StreamChanges(*proton_v1.StreamChangesRequest, KV_StreamChangesServer) error
+	StreamChangeBatches(*proton_v1.StreamChangesRequest, KV_StreamChangeBatchesServer) error
+	GrantSession(context.Context, *proton_v1.GrantSessionRequest) (*proton_v1.GrantSessionResponse, error)
+	KeepAliveSession(context.Context, *proton_v1.KeepAliveSessionRequest) (*proton_v1.KeepAliveSessionResponse, error)
+	RevokeSession(context.Context, *proton_v1.RevokeSessionRequest) (*proton_v1.RevokeSessionResponse, error)
+	AcquireSemaphore(context.Context, *proton_v1.AcquireSemaphoreRequest) (*proton_v1.AcquireSemaphoreResponse, error)
+	ReleaseSemaphore(context.Context, *proton_v1.ReleaseSemaphoreRequest) (*proton_v1.ReleaseSemaphoreResponse, error)
+	BulkLoad(KV_BulkLoadServer) error
 }
-func (m *JoinRaftResponse) Unmarshal(data []byte) error {
-	l := len(data)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
+
+func RegisterKVServer(s *grpc.Server, srv KVServer) {
+	s.RegisterService(&_KV_serviceDesc, srv)
+}
+
+func _KV_PutObject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.PutObjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).PutObject(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_ListObjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.ListObjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).ListObjects(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_GetObjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.GetObjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).GetObjects(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_Txn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).Txn(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_StreamChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(proton_v1.StreamChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KVServer).StreamChanges(m, &kVStreamChangesServer{stream})
+}
+
+type KV_StreamChangesServer interface {
+	Send(*proton_v1.Change) error
+	grpc.ServerStream
+}
+
+type kVStreamChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *kVStreamChangesServer) Send(m *proton_v1.Change) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _KV_StreamChangeBatches_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(proton_v1.StreamChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KVServer).StreamChangeBatches(m, &kVStreamChangeBatchesServer{stream})
+}
+
+type KV_StreamChangeBatchesServer interface {
+	Send(*proton_v1.ChangeBatch) error
+	grpc.ServerStream
+}
+
+type kVStreamChangeBatchesServer struct {
+	grpc.ServerStream
+}
+
+func (x *kVStreamChangeBatchesServer) Send(m *proton_v1.ChangeBatch) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _KV_GrantSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.GrantSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).GrantSession(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_KeepAliveSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.KeepAliveSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).KeepAliveSession(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).RevokeSession(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_AcquireSemaphore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.AcquireSemaphoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).AcquireSemaphore(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_ReleaseSemaphore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(proton_v1.ReleaseSemaphoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(KVServer).ReleaseSemaphore(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _KV_BulkLoad_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(KVServer).BulkLoad(&kVBulkLoadServer{stream})
+}
+
+type KV_BulkLoadServer interface {
+	Send(*proton_v1.BulkLoadProgress) error
+	Recv() (*proton_v1.BulkLoadRequest, error)
+	grpc.ServerStream
+}
+
+type kVBulkLoadServer struct {
+	grpc.ServerStream
+}
+
+func (x *kVBulkLoadServer) Send(m *proton_v1.BulkLoadProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *kVBulkLoadServer) Recv() (*proton_v1.BulkLoadRequest, error) {
+	m := new(proton_v1.BulkLoadRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _KV_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proton.KV",
+	HandlerType: (*KVServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PutObject",
+			Handler:    _KV_PutObject_Handler,
+		},
+		{
+			MethodName: "ListObjects",
+			Handler:    _KV_ListObjects_Handler,
+		},
+		{
+			MethodName: "GetObjects",
+			Handler:    _KV_GetObjects_Handler,
+		},
+		{
+			MethodName: "Txn",
+			Handler:    _KV_Txn_Handler,
+		},
+		{
+			MethodName: "GrantSession",
+			Handler:    _KV_GrantSession_Handler,
+		},
+		{
+			MethodName: "KeepAliveSession",
+			Handler:    _KV_KeepAliveSession_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _KV_RevokeSession_Handler,
+		},
+		{
+			MethodName: "AcquireSemaphore",
+			Handler:    _KV_AcquireSemaphore_Handler,
+		},
+		{
+			MethodName: "ReleaseSemaphore",
+			Handler:    _KV_ReleaseSemaphore_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamChanges",
+			Handler:       _KV_StreamChanges_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamChangeBatches",
+			Handler:       _KV_StreamChangeBatches_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BulkLoad",
+			Handler:       _KV_BulkLoad_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
+
+func (m *SnapshotChunk) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *SnapshotChunk) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Data != nil {
+		if len(m.Data) > 0 {
+			data[i] = 0xa
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.Data)))
+			i += copy(data[i:], m.Data)
+		}
+	}
+	return i, nil
+}
+
+func (m *SendResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *SendResponse) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Success {
+		data[i] = 0x8
+		i++
+		if m.Success {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
+	if len(m.Error) > 0 {
+		data[i] = 0x12
+		i++
+		i = encodeVarintProton(data, i, uint64(len(m.Error)))
+		i += copy(data[i:], m.Error)
+	}
+	if m.LeaderTime != 0 {
+		data[i] = 0x18
+		i++
+		i = encodeVarintProton(data, i, uint64(m.LeaderTime))
+	}
+	if m.LeaderCommit != 0 {
+		data[i] = 0x20
+		i++
+		i = encodeVarintProton(data, i, uint64(m.LeaderCommit))
+	}
+	return i, nil
+}
+
+func (m *FetchEntriesRequest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *FetchEntriesRequest) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Index != 0 {
+		data[i] = 0x8
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Index))
+	}
+	if m.Id != 0 {
+		data[i] = 0x10
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Id))
+	}
+	return i, nil
+}
+
+func (m *FetchEntriesResponse) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *FetchEntriesResponse) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Success {
+		data[i] = 0x8
+		i++
+		if m.Success {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
+	if len(m.Error) > 0 {
+		data[i] = 0x12
+		i++
+		i = encodeVarintProton(data, i, uint64(len(m.Error)))
+		i += copy(data[i:], m.Error)
+	}
+	if len(m.Entries) > 0 {
+		for _, msg := range m.Entries {
+			data[i] = 0x1a
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Snapshot != nil {
+		data[i] = 0x22
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Snapshot.Size()))
+		n1, err := m.Snapshot.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	return i, nil
+}
+
+func (m *LogPair) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *LogPair) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		data[i] = 0xa
+		i++
+		i = encodeVarintProton(data, i, uint64(len(m.Key)))
+		i += copy(data[i:], m.Key)
+	}
+	if m.Value != nil {
+		if len(m.Value) > 0 {
+			data[i] = 0x12
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.Value)))
+			i += copy(data[i:], m.Value)
+		}
+	}
+	if len(m.Origin) > 0 {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintProton(data, i, uint64(len(m.Origin)))
+		i += copy(data[i:], m.Origin)
+	}
+	if m.Compressed {
+		data[i] = 0x20
+		i++
+		if m.Compressed {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
+	if m.Delete {
+		data[i] = 0x28
+		i++
+		if m.Delete {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
+	if m.Expires != 0 {
+		data[i] = 0x30
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Expires))
+	}
+	if m.Session != 0 {
+		data[i] = 0x38
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Session))
+	}
+	if m.Digest != nil {
+		if len(m.Digest) > 0 {
+			data[i] = 0x42
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.Digest)))
+			i += copy(data[i:], m.Digest)
+		}
+	}
+	if m.Timestamp != 0 {
+		data[i] = 0x48
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Timestamp))
+	}
+	if m.After != nil {
+		data[i] = 0x52
+		i++
+		i = encodeVarintProton(data, i, uint64(m.After.Size()))
+		n2, err := m.After.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	return i, nil
+}
+
+func (m *StoreSnapshot) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *StoreSnapshot) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Pairs) > 0 {
+		for _, msg := range m.Pairs {
+			data[i] = 0xa
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Members) > 0 {
+		for _, msg := range m.Members {
+			data[i] = 0x12
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Alarms) > 0 {
+		for _, msg := range m.Alarms {
+			data[i] = 0x1a
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Events) > 0 {
+		for _, msg := range m.Events {
+			data[i] = 0x22
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Revisions) > 0 {
+		data4 := make([]byte, len(m.Revisions)*10)
+		var j3 int
+		for _, num := range m.Revisions {
+			for num >= 1<<7 {
+				data4[j3] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j3++
+			}
+			data4[j3] = uint8(num)
+			j3++
+		}
+		data[i] = 0x2a
+		i++
+		i = encodeVarintProton(data, i, uint64(j3))
+		i += copy(data[i:], data4[:j3])
+	}
+	if m.Since != 0 {
+		data[i] = 0x30
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Since))
+	}
+	if m.Payload != nil {
+		if len(m.Payload) > 0 {
+			data[i] = 0x3a
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.Payload)))
+			i += copy(data[i:], m.Payload)
+		}
+	}
+	if m.Revision != 0 {
+		data[i] = 0x40
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Revision))
+	}
+	if len(m.Expiries) > 0 {
+		data6 := make([]byte, len(m.Expiries)*10)
+		var j5 int
+		for _, num1 := range m.Expiries {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				data6[j5] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j5++
+			}
+			data6[j5] = uint8(num)
+			j5++
+		}
+		data[i] = 0x4a
+		i++
+		i = encodeVarintProton(data, i, uint64(j5))
+		i += copy(data[i:], data6[:j5])
+	}
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			data[i] = 0x52
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				data[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			data[i] = uint8(l)
+			i++
+			i += copy(data[i:], s)
+		}
+	}
+	if len(m.Sessions) > 0 {
+		for _, msg := range m.Sessions {
+			data[i] = 0x5a
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Owners) > 0 {
+		data8 := make([]byte, len(m.Owners)*10)
+		var j7 int
+		for _, num := range m.Owners {
+			for num >= 1<<7 {
+				data8[j7] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j7++
+			}
+			data8[j7] = uint8(num)
+			j7++
+		}
+		data[i] = 0x62
+		i++
+		i = encodeVarintProton(data, i, uint64(j7))
+		i += copy(data[i:], data8[:j7])
+	}
+	if len(m.Semaphores) > 0 {
+		for _, msg := range m.Semaphores {
+			data[i] = 0x6a
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Parts) > 0 {
+		for _, msg := range m.Parts {
+			data[i] = 0x72
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.RateLimits) > 0 {
+		for _, msg := range m.RateLimits {
+			data[i] = 0x7a
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Blobs) > 0 {
+		for _, msg := range m.Blobs {
+			data[i] = 0x82
+			i++
+			data[i] = 0x1
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Hlc != nil {
+		data[i] = 0x8a
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Hlc.Size()))
+		n9, err := m.Hlc.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n9
+	}
+	if m.ClusterId != 0 {
+		data[i] = 0x90
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintProton(data, i, uint64(m.ClusterId))
+	}
+	if len(m.JoinTokens) > 0 {
+		for _, msg := range m.JoinTokens {
+			data[i] = 0x9a
+			i++
+			data[i] = 0x1
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.SealIndex != 0 {
+		data[i] = 0xa0
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintProton(data, i, uint64(m.SealIndex))
+	}
+	return i, nil
+}
+
+func (m *Blob) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *Blob) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Digest != nil {
+		if len(m.Digest) > 0 {
+			data[i] = 0xa
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.Digest)))
+			i += copy(data[i:], m.Digest)
+		}
+	}
+	if m.Value != nil {
+		if len(m.Value) > 0 {
+			data[i] = 0x12
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.Value)))
+			i += copy(data[i:], m.Value)
+		}
+	}
+	return i, nil
+}
+
+func (m *SnapshotData) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *SnapshotData) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.State != nil {
+		if len(m.State) > 0 {
+			data[i] = 0xa
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.State)))
+			i += copy(data[i:], m.State)
+		}
+	}
+	if m.Checksum != 0 {
+		data[i] = 0x10
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Checksum))
+	}
+	if m.Pointer != nil {
+		data[i] = 0x1a
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Pointer.Size()))
+		n10, err := m.Pointer.MarshalTo(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n10
+	}
+	return i, nil
+}
+
+func (m *SnapshotPointer) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *SnapshotPointer) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Location) > 0 {
+		data[i] = 0xa
+		i++
+		i = encodeVarintProton(data, i, uint64(len(m.Location)))
+		i += copy(data[i:], m.Location)
+	}
+	if m.Digest != nil {
+		if len(m.Digest) > 0 {
+			data[i] = 0x12
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.Digest)))
+			i += copy(data[i:], m.Digest)
+		}
+	}
+	if m.Size_ != 0 {
+		data[i] = 0x18
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Size_))
+	}
+	return i, nil
+}
+
+func (m *Txn) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *Txn) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Id != 0 {
+		data[i] = 0x8
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Id))
+	}
+	if len(m.Compares) > 0 {
+		for _, msg := range m.Compares {
+			data[i] = 0x12
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Writes) > 0 {
+		for _, msg := range m.Writes {
+			data[i] = 0x1a
+			i++
+			i = encodeVarintProton(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *EntryPart) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *EntryPart) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Id != 0 {
+		data[i] = 0x8
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Id))
+	}
+	if m.Part != 0 {
+		data[i] = 0x10
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Part))
+	}
+	if m.Parts != 0 {
+		data[i] = 0x18
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Parts))
+	}
+	if m.Data != nil {
+		if len(m.Data) > 0 {
+			data[i] = 0x22
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.Data)))
+			i += copy(data[i:], m.Data)
+		}
+	}
+	if m.Index != 0 {
+		data[i] = 0x28
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Index))
+	}
+	return i, nil
+}
+
+func (m *ProposalBatch) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *ProposalBatch) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Proposals) > 0 {
+		for _, b := range m.Proposals {
+			data[i] = 0xa
+			i++
+			i = encodeVarintProton(data, i, uint64(len(b)))
+			i += copy(data[i:], b)
+		}
+	}
+	return i, nil
+}
+
+func (m *JoinToken) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *JoinToken) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Id) > 0 {
+		data[i] = 0xa
+		i++
+		i = encodeVarintProton(data, i, uint64(len(m.Id)))
+		i += copy(data[i:], m.Id)
+	}
+	if m.SecretHash != nil {
+		if len(m.SecretHash) > 0 {
+			data[i] = 0x12
+			i++
+			i = encodeVarintProton(data, i, uint64(len(m.SecretHash)))
+			i += copy(data[i:], m.SecretHash)
+		}
+	}
+	if m.Expires != 0 {
+		data[i] = 0x18
+		i++
+		i = encodeVarintProton(data, i, uint64(m.Expires))
+	}
+	return i, nil
+}
+
+func encodeFixed64Proton(data []byte, offset int, v uint64) int {
+	data[offset] = uint8(v)
+	data[offset+1] = uint8(v >> 8)
+	data[offset+2] = uint8(v >> 16)
+	data[offset+3] = uint8(v >> 24)
+	data[offset+4] = uint8(v >> 32)
+	data[offset+5] = uint8(v >> 40)
+	data[offset+6] = uint8(v >> 48)
+	data[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Proton(data []byte, offset int, v uint32) int {
+	data[offset] = uint8(v)
+	data[offset+1] = uint8(v >> 8)
+	data[offset+2] = uint8(v >> 16)
+	data[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintProton(data []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		data[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	data[offset] = uint8(v)
+	return offset + 1
+}
+func (m *SnapshotChunk) Size() (n int) {
+	var l int
+	_ = l
+	if m.Data != nil {
+		l = len(m.Data)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *SendResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Success {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovProton(uint64(l))
+	}
+	if m.LeaderTime != 0 {
+		n += 1 + sovProton(uint64(m.LeaderTime))
+	}
+	if m.LeaderCommit != 0 {
+		n += 1 + sovProton(uint64(m.LeaderCommit))
+	}
+	return n
+}
+
+func (m *FetchEntriesRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Index != 0 {
+		n += 1 + sovProton(uint64(m.Index))
+	}
+	if m.Id != 0 {
+		n += 1 + sovProton(uint64(m.Id))
+	}
+	return n
+}
+
+func (m *FetchEntriesResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Success {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovProton(uint64(l))
+	}
+	if len(m.Entries) > 0 {
+		for _, e := range m.Entries {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Snapshot != nil {
+		l = m.Snapshot.Size()
+		n += 1 + l + sovProton(uint64(l))
+	}
+	return n
+}
+
+func (m *LogPair) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovProton(uint64(l))
+	}
+	if m.Value != nil {
+		l = len(m.Value)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	l = len(m.Origin)
+	if l > 0 {
+		n += 1 + l + sovProton(uint64(l))
+	}
+	if m.Compressed {
+		n += 2
+	}
+	if m.Delete {
+		n += 2
+	}
+	if m.Expires != 0 {
+		n += 1 + sovProton(uint64(m.Expires))
+	}
+	if m.Session != 0 {
+		n += 1 + sovProton(uint64(m.Session))
+	}
+	if m.Digest != nil {
+		l = len(m.Digest)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovProton(uint64(m.Timestamp))
+	}
+	if m.After != nil {
+		l = m.After.Size()
+		n += 1 + l + sovProton(uint64(l))
+	}
+	return n
+}
+
+func (m *StoreSnapshot) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Pairs) > 0 {
+		for _, e := range m.Pairs {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Members) > 0 {
+		for _, e := range m.Members {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Alarms) > 0 {
+		for _, e := range m.Alarms {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Events) > 0 {
+		for _, e := range m.Events {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Revisions) > 0 {
+		l = 0
+		for _, e := range m.Revisions {
+			l += sovProton(uint64(e))
+		}
+		n += 1 + sovProton(uint64(l)) + l
+	}
+	if m.Since != 0 {
+		n += 1 + sovProton(uint64(m.Since))
+	}
+	if m.Payload != nil {
+		l = len(m.Payload)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Revision != 0 {
+		n += 1 + sovProton(uint64(m.Revision))
+	}
+	if len(m.Expiries) > 0 {
+		l = 0
+		for _, e := range m.Expiries {
+			l += sovProton(uint64(e))
+		}
+		n += 1 + sovProton(uint64(l)) + l
+	}
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			l = len(s)
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Sessions) > 0 {
+		for _, e := range m.Sessions {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Owners) > 0 {
+		l = 0
+		for _, e := range m.Owners {
+			l += sovProton(uint64(e))
+		}
+		n += 1 + sovProton(uint64(l)) + l
+	}
+	if len(m.Semaphores) > 0 {
+		for _, e := range m.Semaphores {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Parts) > 0 {
+		for _, e := range m.Parts {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.RateLimits) > 0 {
+		for _, e := range m.RateLimits {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Blobs) > 0 {
+		for _, e := range m.Blobs {
+			l = e.Size()
+			n += 2 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Hlc != nil {
+		l = m.Hlc.Size()
+		n += 2 + l + sovProton(uint64(l))
+	}
+	if m.ClusterId != 0 {
+		n += 2 + sovProton(uint64(m.ClusterId))
+	}
+	if len(m.JoinTokens) > 0 {
+		for _, e := range m.JoinTokens {
+			l = e.Size()
+			n += 2 + l + sovProton(uint64(l))
+		}
+	}
+	if m.SealIndex != 0 {
+		n += 2 + sovProton(uint64(m.SealIndex))
+	}
+	return n
+}
+
+func (m *Blob) Size() (n int) {
+	var l int
+	_ = l
+	if m.Digest != nil {
+		l = len(m.Digest)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Value != nil {
+		l = len(m.Value)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *SnapshotData) Size() (n int) {
+	var l int
+	_ = l
+	if m.State != nil {
+		l = len(m.State)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Checksum != 0 {
+		n += 1 + sovProton(uint64(m.Checksum))
+	}
+	if m.Pointer != nil {
+		l = m.Pointer.Size()
+		n += 1 + l + sovProton(uint64(l))
+	}
+	return n
+}
+
+func (m *SnapshotPointer) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Location)
+	if l > 0 {
+		n += 1 + l + sovProton(uint64(l))
+	}
+	if m.Digest != nil {
+		l = len(m.Digest)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Size_ != 0 {
+		n += 1 + sovProton(uint64(m.Size_))
+	}
+	return n
+}
+
+func (m *Txn) Size() (n int) {
+	var l int
+	_ = l
+	if m.Id != 0 {
+		n += 1 + sovProton(uint64(m.Id))
+	}
+	if len(m.Compares) > 0 {
+		for _, e := range m.Compares {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if len(m.Writes) > 0 {
+		for _, e := range m.Writes {
+			l = e.Size()
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *EntryPart) Size() (n int) {
+	var l int
+	_ = l
+	if m.Id != 0 {
+		n += 1 + sovProton(uint64(m.Id))
+	}
+	if m.Part != 0 {
+		n += 1 + sovProton(uint64(m.Part))
+	}
+	if m.Parts != 0 {
+		n += 1 + sovProton(uint64(m.Parts))
+	}
+	if m.Data != nil {
+		l = len(m.Data)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Index != 0 {
+		n += 1 + sovProton(uint64(m.Index))
+	}
+	return n
+}
+
+func (m *ProposalBatch) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Proposals) > 0 {
+		for _, b := range m.Proposals {
+			l = len(b)
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *JoinToken) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovProton(uint64(l))
+	}
+	if m.SecretHash != nil {
+		l = len(m.SecretHash)
+		if l > 0 {
+			n += 1 + l + sovProton(uint64(l))
+		}
+	}
+	if m.Expires != 0 {
+		n += 1 + sovProton(uint64(m.Expires))
+	}
+	return n
+}
+
+func sovProton(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozProton(x uint64) (n int) {
+	return sovProton(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *SnapshotChunk) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProton
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SnapshotChunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SnapshotChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append([]byte{}, data[iNdEx:postIndex]...)
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProton(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProton
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SendResponse) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProton
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SendResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SendResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Success = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaderTime", wireType)
+			}
+			m.LeaderTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.LeaderTime |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaderCommit", wireType)
+			}
+			m.LeaderCommit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.LeaderCommit |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProton(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProton
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchEntriesRequest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProton
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchEntriesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchEntriesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Index |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			m.Id = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Id |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProton(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProton
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchEntriesResponse) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProton
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchEntriesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchEntriesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Success = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Entries", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Entries = append(m.Entries, &raftpb.Entry{})
+			if err := m.Entries[len(m.Entries)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Snapshot", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Snapshot == nil {
+				m.Snapshot = &raftpb.Snapshot{}
+			}
+			if err := m.Snapshot.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProton(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProton
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LogPair) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProton
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LogPair: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LogPair: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = append([]byte{}, data[iNdEx:postIndex]...)
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Origin", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Origin = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compressed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Compressed = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Delete", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Delete = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expires", wireType)
+			}
+			m.Expires = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Expires |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Session", wireType)
+			}
+			m.Session = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Session |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Digest", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Digest = append([]byte{}, data[iNdEx:postIndex]...)
+			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Timestamp |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field After", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.After == nil {
+				m.After = &proton_v1.HybridTime{}
+			}
+			if err := m.After.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProton(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthProton
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StoreSnapshot) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
 		for shift := uint(0); ; shift += 7 {
 			if shift >= 64 {
 				return ErrIntOverflowProton
 			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StoreSnapshot: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StoreSnapshot: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pairs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Pairs = append(m.Pairs, &LogPair{})
+			if err := m.Pairs[len(m.Pairs)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Members", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Members = append(m.Members, &proton_v1.NodeInfo{})
+			if err := m.Members[len(m.Members)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Alarms", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Alarms = append(m.Alarms, &proton_v1.Alarm{})
+			if err := m.Alarms[len(m.Alarms)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Events", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Events = append(m.Events, &proton_v1.AuditEvent{})
+			if err := m.Events[len(m.Events)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowProton
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := data[iNdEx]
+					iNdEx++
+					v |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Revisions = append(m.Revisions, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowProton
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := data[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthProton
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowProton
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := data[iNdEx]
+						iNdEx++
+						v |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Revisions = append(m.Revisions, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Revisions", wireType)
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Since", wireType)
 			}
-			b := data[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			m.Since = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Since |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: JoinRaftResponse: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: JoinRaftResponse: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Payload", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Payload = append([]byte{}, data[iNdEx:postIndex]...)
+			iNdEx = postIndex
+		case 8:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Revision", wireType)
 			}
-			var v int
+			m.Revision = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -944,15 +3690,76 @@ func (m *JoinRaftResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				v |= (int(b) & 0x7F) << shift
+				m.Revision |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Success = bool(v != 0)
-		case 2:
+		case 9:
+			if wireType == 0 {
+				var v int64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowProton
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := data[iNdEx]
+					iNdEx++
+					v |= (int64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Expiries = append(m.Expiries, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowProton
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := data[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthProton
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v int64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowProton
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := data[iNdEx]
+						iNdEx++
+						v |= (int64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Expiries = append(m.Expiries, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expiries", wireType)
+			}
+		case 10:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -977,11 +3784,11 @@ func (m *JoinRaftResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Error = string(data[iNdEx:postIndex])
+			m.Keys = append(m.Keys, string(data[iNdEx:postIndex]))
 			iNdEx = postIndex
-		case 3:
+		case 11:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Nodes", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Sessions", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1005,66 +3812,171 @@ func (m *JoinRaftResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Nodes = append(m.Nodes, &NodeInfo{})
-			if err := m.Nodes[len(m.Nodes)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+			m.Sessions = append(m.Sessions, &proton_v1.Session{})
+			if err := m.Sessions[len(m.Sessions)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipProton(data[iNdEx:])
-			if err != nil {
-				return err
+		case 12:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowProton
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := data[iNdEx]
+					iNdEx++
+					v |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Owners = append(m.Owners, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowProton
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := data[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthProton
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowProton
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := data[iNdEx]
+						iNdEx++
+						v |= (uint64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Owners = append(m.Owners, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owners", wireType)
 			}
-			if skippy < 0 {
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Semaphores", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
 				return ErrInvalidLengthProton
 			}
-			if (iNdEx + skippy) > l {
+			postIndex := iNdEx + msglen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *LeaveRaftResponse) Unmarshal(data []byte) error {
-	l := len(data)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowProton
+			m.Semaphores = append(m.Semaphores, &proton_v1.Semaphore{})
+			if err := m.Semaphores[len(m.Semaphores)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			if iNdEx >= l {
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Parts", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := data[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			m.Parts = append(m.Parts, &EntryPart{})
+			if err := m.Parts[len(m.Parts)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: LeaveRaftResponse: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LeaveRaftResponse: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RateLimits", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RateLimits = append(m.RateLimits, &proton_v1.RateLimit{})
+			if err := m.RateLimits[len(m.RateLimits)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			var v int
+			iNdEx = postIndex
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Blobs", wireType)
+			}
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1074,17 +3986,28 @@ func (m *LeaveRaftResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				v |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Success = bool(v != 0)
-		case 2:
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Blobs = append(m.Blobs, &Blob{})
+			if err := m.Blobs[len(m.Blobs)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 17:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Hlc", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1094,76 +4017,30 @@ func (m *LeaveRaftResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthProton
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Error = string(data[iNdEx:postIndex])
-			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipProton(data[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthProton
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *SendResponse) Unmarshal(data []byte) error {
-	l := len(data)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowProton
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			if m.Hlc == nil {
+				m.Hlc = &proton_v1.HybridTime{}
 			}
-			b := data[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			if err := m.Hlc.Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: SendResponse: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SendResponse: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			iNdEx = postIndex
+		case 18:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ClusterId", wireType)
 			}
-			var v int
+			m.ClusterId = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1173,17 +4050,16 @@ func (m *SendResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				v |= (int(b) & 0x7F) << shift
+				m.ClusterId |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Success = bool(v != 0)
-		case 2:
+		case 19:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field JoinTokens", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1193,76 +4069,28 @@ func (m *SendResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthProton
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Error = string(data[iNdEx:postIndex])
-			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipProton(data[iNdEx:])
-			if err != nil {
+			m.JoinTokens = append(m.JoinTokens, &JoinToken{})
+			if err := m.JoinTokens[len(m.JoinTokens)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthProton
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *PutObjectRequest) Unmarshal(data []byte) error {
-	l := len(data)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowProton
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := data[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: PutObjectRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PutObjectRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Object", wireType)
+			iNdEx = postIndex
+		case 20:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SealIndex", wireType)
 			}
-			var msglen int
+			m.SealIndex = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1272,25 +4100,11 @@ func (m *PutObjectRequest) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				m.SealIndex |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthProton
-			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.Object == nil {
-				m.Object = &Pair{}
-			}
-			if err := m.Object.Unmarshal(data[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipProton(data[iNdEx:])
@@ -1312,7 +4126,7 @@ func (m *PutObjectRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *PutObjectResponse) Unmarshal(data []byte) error {
+func (m *Blob) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1335,17 +4149,17 @@ func (m *PutObjectResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PutObjectResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: Blob: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PutObjectResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Blob: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Success", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Digest", wireType)
 			}
-			var v int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1355,17 +4169,25 @@ func (m *PutObjectResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				v |= (int(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Success = bool(v != 0)
+			if byteLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Digest = append([]byte{}, data[iNdEx:postIndex]...)
+			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1375,20 +4197,19 @@ func (m *PutObjectResponse) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthProton
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Error = string(data[iNdEx:postIndex])
+			m.Value = append([]byte{}, data[iNdEx:postIndex]...)
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1411,7 +4232,7 @@ func (m *PutObjectResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ListObjectsRequest) Unmarshal(data []byte) error {
+func (m *SnapshotData) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1434,65 +4255,62 @@ func (m *ListObjectsRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ListObjectsRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: SnapshotData: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ListObjectsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SnapshotData: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		default:
-			iNdEx = preIndex
-			skippy, err := skipProton(data[iNdEx:])
-			if err != nil {
-				return err
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
 			}
-			if skippy < 0 {
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
 				return ErrInvalidLengthProton
 			}
-			if (iNdEx + skippy) > l {
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *ListObjectsResponse) Unmarshal(data []byte) error {
-	l := len(data)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowProton
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			m.State = append([]byte{}, data[iNdEx:postIndex]...)
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Checksum", wireType)
 			}
-			b := data[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
+			m.Checksum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Checksum |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: ListObjectsResponse: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ListObjectsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Objects", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Pointer", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1516,8 +4334,10 @@ func (m *ListObjectsResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Objects = append(m.Objects, &Pair{})
-			if err := m.Objects[len(m.Objects)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+			if m.Pointer == nil {
+				m.Pointer = &SnapshotPointer{}
+			}
+			if err := m.Pointer.Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1542,7 +4362,7 @@ func (m *ListObjectsResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ListMembersRequest) Unmarshal(data []byte) error {
+func (m *SnapshotPointer) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1565,12 +4385,88 @@ func (m *ListMembersRequest) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ListMembersRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: SnapshotPointer: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ListMembersRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SnapshotPointer: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Location", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Location = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Digest", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Digest = append([]byte{}, data[iNdEx:postIndex]...)
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Size_", wireType)
+			}
+			m.Size_ = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Size_ |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipProton(data[iNdEx:])
@@ -1592,7 +4488,7 @@ func (m *ListMembersRequest) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *ListMembersResponse) Unmarshal(data []byte) error {
+func (m *Txn) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1615,15 +4511,34 @@ func (m *ListMembersResponse) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ListMembersResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: Txn: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ListMembersResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Txn: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			m.Id = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Id |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Members", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Compares", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1647,8 +4562,39 @@ func (m *ListMembersResponse) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Members = append(m.Members, &NodeInfo{})
-			if err := m.Members[len(m.Members)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+			m.Compares = append(m.Compares, &proton_v1.Compare{})
+			if err := m.Compares[len(m.Compares)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Writes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthProton
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Writes = append(m.Writes, &LogPair{})
+			if err := m.Writes[len(m.Writes)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1673,7 +4619,7 @@ func (m *ListMembersResponse) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *NodeInfo) Unmarshal(data []byte) error {
+func (m *EntryPart) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1696,17 +4642,17 @@ func (m *NodeInfo) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NodeInfo: wiretype end group for non-group")
+			return fmt.Errorf("proto: EntryPart: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NodeInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: EntryPart: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
 			}
-			m.ID = 0
+			m.Id = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1716,16 +4662,54 @@ func (m *NodeInfo) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				m.ID |= (uint64(b) & 0x7F) << shift
+				m.Id |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Part", wireType)
+			}
+			m.Part = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Part |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Parts", wireType)
+			}
+			m.Parts = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Parts |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Addr", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1735,26 +4719,25 @@ func (m *NodeInfo) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthProton
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Addr = string(data[iNdEx:postIndex])
+			m.Data = append([]byte{}, data[iNdEx:postIndex]...)
 			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Port", wireType)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
 			}
-			var stringLen uint64
+			m.Index = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1764,26 +4747,66 @@ func (m *NodeInfo) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.Index |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipProton(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthProton
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Port = string(data[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 4:
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProposalBatch) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowProton
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProposalBatch: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProposalBatch: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Proposals", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowProton
@@ -1793,20 +4816,20 @@ func (m *NodeInfo) Unmarshal(data []byte) error {
 				}
 				b := data[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthProton
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Error = string(data[iNdEx:postIndex])
+			m.Proposals = append(m.Proposals, make([]byte, postIndex-iNdEx))
+			copy(m.Proposals[len(m.Proposals)-1], data[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -1829,7 +4852,7 @@ func (m *NodeInfo) Unmarshal(data []byte) error {
 	}
 	return nil
 }
-func (m *Pair) Unmarshal(data []byte) error {
+func (m *JoinToken) Unmarshal(data []byte) error {
 	l := len(data)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1852,15 +4875,15 @@ func (m *Pair) Unmarshal(data []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Pair: wiretype end group for non-group")
+			return fmt.Errorf("proto: JoinToken: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Pair: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: JoinToken: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1885,11 +4908,11 @@ func (m *Pair) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Key = string(data[iNdEx:postIndex])
+			m.Id = string(data[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SecretHash", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -1913,8 +4936,27 @@ func (m *Pair) Unmarshal(data []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Value = append([]byte{}, data[iNdEx:postIndex]...)
+			m.SecretHash = append([]byte{}, data[iNdEx:postIndex]...)
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expires", wireType)
+			}
+			m.Expires = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowProton
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Expires |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipProton(data[iNdEx:])
@@ -0,0 +1,129 @@
+package proton
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// proposalClass is the priority class of a proposal waiting
+// for its turn to be handed over to the raft
+type proposalClass int
+
+const (
+	// clientProposal is the class of the writes of the clients
+	clientProposal proposalClass = iota
+	// internalProposal is the class of the proposals the node makes
+	// to maintain the cluster: the expirations of the keys and the
+	// sessions, the audit events, the alarms and the rate limits
+	internalProposal
+)
+
+const (
+	// internalBurst is the number of internal proposals handed over
+	// in a row while client proposals wait, so that a flood of
+	// internal proposals does not starve the clients either
+	internalBurst = 8
+)
+
+// proposalClassKey is the context key of the class of a proposal
+type proposalClassKey struct{}
+
+// withInternalPriority marks the proposals made with a context
+// as internal, they are handed over before the client ones
+func withInternalPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, proposalClassKey{}, internalProposal)
+}
+
+// classOf returns the class of the proposals made with a context
+func classOf(ctx context.Context) proposalClass {
+	class, ok := ctx.Value(proposalClassKey{}).(proposalClass)
+	if !ok {
+		return clientProposal
+	}
+	return class
+}
+
+// proposalQueue hands the proposals over to the raft one at a time.
+// The raft takes them in turn from a single channel, in no order,
+// so that a flood of client writes would otherwise hold up the
+// internal proposals waiting with them
+type proposalQueue struct {
+	lock sync.Mutex
+	busy bool
+	// burst is the number of internal proposals handed over in a row
+	burst   int
+	waiting [2][]chan struct{}
+}
+
+// acquire waits for the turn of a proposal of a class
+func (q *proposalQueue) acquire(ctx context.Context, class proposalClass) error {
+	q.lock.Lock()
+	if !q.busy {
+		q.busy = true
+		q.lock.Unlock()
+		return nil
+	}
+	turn := make(chan struct{}, 1)
+	q.waiting[class] = append(q.waiting[class], turn)
+	q.lock.Unlock()
+
+	select {
+	case <-turn:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.lock.Lock()
+	removed := q.remove(class, turn)
+	q.lock.Unlock()
+	// The turn was handed over meanwhile and is passed on
+	if !removed {
+		q.release()
+	}
+	return ctx.Err()
+}
+
+// release hands the turn over to the next proposal, an
+// internal one unless a burst of them was handed over
+func (q *proposalQueue) release() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	internal, client := q.waiting[internalProposal], q.waiting[clientProposal]
+	switch {
+	case len(internal) > 0 && (q.burst < internalBurst || len(client) == 0):
+		q.burst++
+		q.waiting[internalProposal] = internal[1:]
+		internal[0] <- struct{}{}
+	case len(client) > 0:
+		q.burst = 0
+		q.waiting[clientProposal] = client[1:]
+		client[0] <- struct{}{}
+	default:
+		q.busy = false
+	}
+}
+
+// remove takes a proposal whose context ended out of the queue.
+// Must be called with the lock held
+func (q *proposalQueue) remove(class proposalClass, turn chan struct{}) bool {
+	for i, c := range q.waiting[class] {
+		if c == turn {
+			q.waiting[class] = append(q.waiting[class][:i:i], q.waiting[class][i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// proposeRaft hands a proposal over to the raft once it is its
+// turn, along with its checksum
+func (n *Node) proposeRaft(ctx context.Context, data []byte) error {
+	err := n.proposeQueue.acquire(ctx, classOf(ctx))
+	if err != nil {
+		return err
+	}
+	defer n.proposeQueue.release()
+	return n.Node.Propose(ctx, sealEntry(data))
+}
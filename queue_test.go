@@ -0,0 +1,73 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// queued queues a proposal of a class and waits until it is
+// waiting, its id is sent to order once it is handed its turn
+func queued(t *testing.T, q *proposalQueue, class proposalClass, id int, order chan int) {
+	q.lock.Lock()
+	waiting := len(q.waiting[class])
+	q.lock.Unlock()
+
+	go func() {
+		assert.NoError(t, q.acquire(context.Background(), class))
+		order <- id
+		q.release()
+	}()
+	for i := 0; i < 100; i++ {
+		q.lock.Lock()
+		done := len(q.waiting[class]) > waiting
+		q.lock.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("proposal not queued")
+}
+
+func TestProposalQueue(t *testing.T) {
+	q := &proposalQueue{}
+	assert.NoError(t, q.acquire(context.Background(), clientProposal))
+
+	// The internal proposals go first, up to a burst
+	order := make(chan int, 20)
+	queued(t, q, clientProposal, 0, order)
+	for i := 1; i <= internalBurst+2; i++ {
+		queued(t, q, internalProposal, i, order)
+	}
+	q.release()
+
+	var got []int
+	for i := 0; i < internalBurst+3; i++ {
+		got = append(got, <-order)
+	}
+	expected := []int{1, 2, 3, 4, 5, 6, 7, 8, 0, 9, 10}
+	assert.Equal(t, got, expected)
+
+	q.lock.Lock()
+	assert.False(t, q.busy)
+	q.lock.Unlock()
+
+	// A proposal whose context ends leaves the queue
+	assert.NoError(t, q.acquire(context.Background(), clientProposal))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, q.acquire(ctx, internalProposal), context.DeadlineExceeded)
+	q.lock.Lock()
+	assert.Empty(t, q.waiting[internalProposal])
+	q.lock.Unlock()
+	q.release()
+	assert.NoError(t, q.acquire(context.Background(), clientProposal))
+	q.release()
+
+	assert.Equal(t, classOf(context.Background()), clientProposal)
+	assert.Equal(t, classOf(withInternalPriority(context.Background())), internalProposal)
+}
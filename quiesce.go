@@ -0,0 +1,201 @@
+package proton
+
+import (
+	"sync"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	// quiesceContext marks the heartbeats of a quiesced
+	// leader and their responses
+	quiesceContext = systemPrefix + "quiesce"
+
+	// quiesceTimeouts is the number of election timeouts after
+	// which a quiesced follower that heard nothing from the leader
+	// starts ticking again, and then campaigns
+	quiesceTimeouts = 2
+)
+
+// quiescence tracks whether the raft of the node is quiesced,
+// a quiesced node does not tick its raft
+type quiescence struct {
+	lock     sync.Mutex
+	quiesced bool
+	// idle is the number of ticks the leader spent with
+	// every member caught up
+	idle int
+	// elapsed is the number of ticks since the last quiesce
+	// heartbeat was sent by the leader or received by a follower
+	elapsed int
+	// acks holds the members that answered the last
+	// quiesce heartbeats of the leader
+	acks map[uint64]bool
+}
+
+// IsQuiesced checks if the raft of the node is quiesced
+func (n *Node) IsQuiesced() bool {
+	q := n.quiesce
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.quiesced
+}
+
+// caughtUp checks if every member of the raft
+// of a leader replicated the last entry
+func caughtUp(status raft.Status) bool {
+	if status.RaftState != raft.StateLeader {
+		return false
+	}
+	for _, pr := range status.Progress {
+		if pr.Match != status.Commit {
+			return false
+		}
+	}
+	return true
+}
+
+// quiesceTick is called on every tick, it returns whether the
+// raft is quiesced and must not be ticked. A leader quiesces
+// once every member has been caught up for QuiesceTicks: it then
+// stops ticking and sends quiesce heartbeats every half election
+// timeout in place of the regular ones. A follower stops ticking
+// on the first of these heartbeats. A leader that lost its quorum,
+// or a follower that no longer hears from the leader, ticks again
+func (n *Node) quiesceTick() bool {
+	if n.QuiesceTicks <= 0 {
+		return false
+	}
+
+	status := n.Status()
+	leader := status.RaftState == raft.StateLeader
+	interval := n.Cfg.ElectionTick / 2
+	if interval < 1 {
+		interval = 1
+	}
+
+	q := n.quiesce
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if !q.quiesced {
+		if caughtUp(status) && n.AppliedIndex() == status.Commit {
+			q.idle++
+		} else {
+			q.idle = 0
+		}
+		if q.idle < n.QuiesceTicks {
+			return false
+		}
+		q.quiesced = true
+		q.elapsed = 0
+		q.acks = make(map[uint64]bool)
+		n.sendQuiesce(status)
+		return true
+	}
+
+	q.elapsed++
+	if !leader {
+		if q.elapsed >= quiesceTimeouts*n.Cfg.ElectionTick {
+			q.wake()
+			return false
+		}
+		return true
+	}
+
+	if q.elapsed >= interval {
+		if len(q.acks)+1 <= len(status.Progress)/2 {
+			q.wake()
+			return false
+		}
+		q.elapsed = 0
+		q.acks = make(map[uint64]bool)
+		n.sendQuiesce(status)
+	}
+	return true
+}
+
+// wake makes the node tick its raft again.
+// Must be called with the lock held
+func (q *quiescence) wake() {
+	q.quiesced = false
+	q.idle = 0
+}
+
+// sendQuiesce sends a quiesce heartbeat to every member
+// of the raft of a leader
+func (n *Node) sendQuiesce(status raft.Status) {
+	peers := n.Cluster.Peers()
+	for id, pr := range status.Progress {
+		peer, ok := peers[id]
+		if id == n.ID || !ok {
+			continue
+		}
+
+		// A member is never told to commit past its log
+		commit := status.Commit
+		if pr.Match < commit {
+			commit = pr.Match
+		}
+		n.senders.get(n, peer).enqueue(raftpb.Message{
+			Type:    raftpb.MsgHeartbeat,
+			To:      id,
+			From:    n.ID,
+			Term:    status.Term,
+			Commit:  commit,
+			Context: []byte(quiesceContext),
+		})
+	}
+}
+
+// receiveQuiesce quiesces a follower receiving a quiesce heartbeat
+// and counts the answers of the members to the leader. Any other
+// message but the answer to a heartbeat wakes the node
+func (n *Node) receiveQuiesce(m *raftpb.Message) {
+	if n.QuiesceTicks <= 0 {
+		return
+	}
+
+	q := n.quiesce
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	quiesce := string(m.Context) == quiesceContext
+	switch {
+	case quiesce && m.Type == raftpb.MsgHeartbeat:
+		q.quiesced = true
+		q.elapsed = 0
+	case quiesce && m.Type == raftpb.MsgHeartbeatResp:
+		if q.acks != nil {
+			q.acks[m.From] = true
+		}
+	case m.Type == raftpb.MsgHeartbeatResp:
+		// The answers to the regular heartbeats keep the leader idle
+	default:
+		q.wake()
+	}
+}
+
+// wakeOnReady wakes the node when its raft has entries
+// to replicate or apply, or messages other than heartbeats
+func (n *Node) wakeOnReady(rd raft.Ready) {
+	if n.QuiesceTicks <= 0 {
+		return
+	}
+
+	active := rd.SoftState != nil || len(rd.Entries) > 0 || len(rd.CommittedEntries) > 0 || !raft.IsEmptySnap(rd.Snapshot)
+	for _, m := range rd.Messages {
+		if !isHeartbeat(m) {
+			active = true
+		}
+	}
+	if !active {
+		return
+	}
+
+	q := n.quiesce
+	q.lock.Lock()
+	q.wake()
+	q.lock.Unlock()
+}
@@ -0,0 +1,72 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestQuiesceFollower(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// Quiesce heartbeats are ignored unless enabled
+	heartbeat := &raftpb.Message{Type: raftpb.MsgHeartbeat, From: 2, Context: []byte(quiesceContext)}
+	n.receiveQuiesce(heartbeat)
+	assert.False(t, n.IsQuiesced())
+	assert.False(t, n.quiesceTick())
+
+	n.QuiesceTicks = 1
+	n.receiveQuiesce(heartbeat)
+	assert.True(t, n.IsQuiesced())
+
+	// Any other message wakes the follower
+	n.receiveQuiesce(&raftpb.Message{Type: raftpb.MsgApp, From: 2})
+	assert.False(t, n.IsQuiesced())
+
+	// A follower that no longer hears from the leader ticks again
+	n.receiveQuiesce(heartbeat)
+	for i := 1; i < quiesceTimeouts*n.Cfg.ElectionTick; i++ {
+		assert.True(t, n.quiesceTick())
+	}
+	assert.False(t, n.quiesceTick())
+	assert.False(t, n.IsQuiesced())
+}
+
+func TestQuiesceLeader(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	n.QuiesceTicks = 2
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+
+	quiesced := func() bool {
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			if n.IsQuiesced() {
+				return true
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		return false
+	}
+	assert.True(t, quiesced(), "Leader should quiesce once idle")
+
+	// A proposal wakes the leader until it is idle again
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	data, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	_, _, err = n.ProposeWait(ctx, data)
+	assert.NoError(t, err)
+	assert.False(t, n.IsQuiesced())
+	assert.True(t, quiesced(), "Leader should quiesce again once idle")
+}
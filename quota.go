@@ -0,0 +1,160 @@
+package proton
+
+import (
+	"errors"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+)
+
+var (
+	// ErrQuotaExceeded is thrown when a proposal would go beyond the quota of the cluster or of its namespace
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrNoSpace is thrown when a store went beyond its backend quota and the cluster only accepts reads
+	ErrNoSpace = errors.New("store is out of space, writes are refused")
+)
+
+// Quota limits the size of the store, a zero
+// value for a limit means there is no limit
+type Quota struct {
+	// MaxBytes is the maximum size of the keys and values
+	MaxBytes int64
+	// MaxKeys is the maximum number of keys
+	MaxKeys int64
+}
+
+// usage tracks the space used in the store
+type usage struct {
+	bytes int64
+	keys  int64
+}
+
+// exceeds checks if a usage is beyond the quota
+func (q Quota) exceeds(u usage) bool {
+	return (q.MaxBytes > 0 && u.bytes > q.MaxBytes) ||
+		(q.MaxKeys > 0 && u.keys > q.MaxKeys)
+}
+
+// SetQuota sets the quota of the whole cluster
+func (n *Node) SetQuota(q Quota) {
+	n.quotaLock.Lock()
+	n.quota = q
+	n.quotaLock.Unlock()
+}
+
+// SetNamespaceQuota sets the quota of a namespace
+func (n *Node) SetNamespaceQuota(namespace string, q Quota) {
+	n.quotaLock.Lock()
+	n.nsQuotas[namespace] = q
+	n.quotaLock.Unlock()
+}
+
+// SetBackendQuota sets the hard limit in bytes of the store.
+// When a proposal goes beyond it, a NOSPACE alarm is raised
+// and the cluster only accepts reads until it is disarmed
+func (n *Node) SetBackendQuota(bytes int64) {
+	n.quotaLock.Lock()
+	n.backendQuota = bytes
+	n.quotaLock.Unlock()
+}
+
+// IsNoSpace checks if the cluster stopped accepting writes
+// because a store went beyond its backend quota
+func (n *Node) IsNoSpace() bool {
+	return n.HasAlarm(protonpb.AlarmType_NOSPACE)
+}
+
+// StoreSize returns the size in bytes of the keys and values in the store
+func (n *Node) StoreSize() int64 {
+	n.storeLock.RLock()
+	defer n.storeLock.RUnlock()
+	return n.usage.bytes
+}
+
+// checkQuota checks a proposal against the quotas given
+// the current state of the store
+func (n *Node) checkQuota(data []byte) error {
+	pair := &LogPair{}
+	err := proto.Unmarshal(data, pair)
+	if err != nil || isSystemKey(pair.Key) {
+		// Let the apply side deal with it
+		return nil
+	}
+
+	err = n.checkAlarms()
+	if err != nil {
+		return err
+	}
+
+	n.quotaLock.RLock()
+	defer n.quotaLock.RUnlock()
+
+	ns, _ := SplitNamespacedKey(pair.Key)
+
+	n.storeLock.RLock()
+	old, exists := n.pstore[pair.Key]
+	delta := writeUsage(pair.Key, old, exists, string(pair.Value))
+	total := usage{bytes: n.usage.bytes + delta.bytes, keys: n.usage.keys + delta.keys}
+	nsUsage := n.nsUsage[ns]
+	n.storeLock.RUnlock()
+
+	if n.backendQuota > 0 && total.bytes > n.backendQuota {
+		n.raiseAlarm(protonpb.AlarmType_NOSPACE)
+		return ErrNoSpace
+	}
+
+	if n.quota.exceeds(total) {
+		return ErrQuotaExceeded
+	}
+
+	nsUsage.bytes += delta.bytes
+	nsUsage.keys += delta.keys
+	if q, ok := n.nsQuotas[ns]; ok && q.exceeds(nsUsage) {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// writeUsage returns the change of usage caused by writing
+// a value for a key, given the previous value if any
+func writeUsage(key string, old string, exists bool, value string) usage {
+	delta := usage{bytes: int64(len(value))}
+	if exists {
+		delta.bytes -= int64(len(old))
+	} else {
+		delta.bytes += int64(len(key))
+		delta.keys = 1
+	}
+	return delta
+}
+
+// unaccount updates the usage of the store after a key
+// is deleted. Must be called with the store lock held
+func (n *Node) unaccount(key string, old string) {
+	ns, _ := SplitNamespacedKey(key)
+	delta := usage{bytes: -int64(len(key) + len(old)), keys: -1}
+
+	n.usage.bytes += delta.bytes
+	n.usage.keys += delta.keys
+
+	u := n.nsUsage[ns]
+	u.bytes += delta.bytes
+	u.keys += delta.keys
+	n.nsUsage[ns] = u
+}
+
+// account updates the usage of the store after a key
+// is written. Must be called with the store lock held
+func (n *Node) account(key string, old string, exists bool, value string) {
+	ns, _ := SplitNamespacedKey(key)
+	delta := writeUsage(key, old, exists, value)
+
+	n.usage.bytes += delta.bytes
+	n.usage.keys += delta.keys
+
+	u := n.nsUsage[ns]
+	u.bytes += delta.bytes
+	u.keys += delta.keys
+	n.nsUsage[ns] = u
+}
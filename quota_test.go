@@ -0,0 +1,100 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func newQuotaNode(t *testing.T) *Node {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	return n
+}
+
+func TestQuota(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.Put("foo", "bar")
+	assert.Equal(t, n.StoreSize(), int64(6))
+
+	n.SetQuota(Quota{MaxKeys: 1})
+
+	// Overwriting an existing key does not add a new key
+	pair, err := EncodePair("foo", []byte("baz"))
+	assert.NoError(t, err)
+	assert.NoError(t, n.checkQuota(pair))
+
+	pair, err = EncodePair("foo2", []byte("bar"))
+	assert.NoError(t, err)
+	assert.Equal(t, n.checkQuota(pair), ErrQuotaExceeded)
+
+	n.SetQuota(Quota{})
+	n.SetNamespaceQuota("team", Quota{MaxBytes: 10})
+
+	pair, err = EncodePair(NamespacedKey("team", "key"), []byte("0123456789"))
+	assert.NoError(t, err)
+	assert.Equal(t, n.checkQuota(pair), ErrQuotaExceeded)
+
+	pair, err = EncodePair(NamespacedKey("other", "key"), []byte("0123456789"))
+	assert.NoError(t, err)
+	assert.NoError(t, n.checkQuota(pair))
+}
+
+func TestBackendQuota(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.Put("foo", "bar")
+	n.SetBackendQuota(10)
+
+	pair, err := EncodePair("foo2", []byte("bar"))
+	assert.NoError(t, err)
+	assert.Equal(t, n.checkQuota(pair), ErrNoSpace)
+
+	// Apply the alarm as if it was committed
+	alarm := &protonpb.Alarm{Member: n.ID, Type: protonpb.AlarmType_NOSPACE}
+	data, err := alarm.Marshal()
+	assert.NoError(t, err)
+	n.applyAlarm(&LogPair{Key: alarmKey(alarm), Value: data})
+	assert.True(t, n.IsNoSpace())
+	assert.Equal(t, len(n.Alarms()), 1)
+
+	// Every write is refused in the protective mode
+	n.SetBackendQuota(100)
+	pair, err = EncodePair("a", []byte("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, n.checkQuota(pair), ErrNoSpace)
+
+	// Disarm the alarm
+	n.applyAlarm(&LogPair{Key: alarmKey(alarm)})
+	assert.False(t, n.IsNoSpace())
+	assert.NoError(t, n.checkQuota(pair))
+}
+
+func TestReadOnly(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.SetReadOnly(true)
+	assert.True(t, n.IsReadOnly())
+
+	pair, err := EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	assert.Equal(t, n.Propose(n.Ctx, pair), ErrReadOnly)
+
+	// Internal cluster state is still written
+	pair, err = EncodePair(auditPrefix+"event", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, n.checkReadOnly(pair))
+
+	n.SetReadOnly(false)
+	pair, err = EncodePair("foo", []byte("bar"))
+	assert.NoError(t, err)
+	assert.NoError(t, n.checkReadOnly(pair))
+}
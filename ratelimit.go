@@ -0,0 +1,230 @@
+package proton
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// ratePrefix is the keyspace holding the rate limits of the clients
+	ratePrefix = systemPrefix + "ratelimit/"
+
+	// AnyClient is the identity whose rate limit applies
+	// to the clients that don't have their own
+	AnyClient = "*"
+)
+
+var (
+	// ErrRateLimited is thrown when a client writes faster than its rate limit
+	ErrRateLimited = errors.New("write rate limit exceeded")
+	// ErrInvalidRateLimit is thrown when setting a rate limit without identity or with a negative rate
+	ErrInvalidRateLimit = errors.New("rate limit needs an identity and a rate that is not negative")
+)
+
+// bucket holds the writes a client can make at once,
+// refilled at the rate of its limit
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take spends a write of the bucket if one is left
+func (b *bucket) take(limit *protonpb.RateLimit, now time.Time) bool {
+	burst := math.Max(float64(limit.Burst), 1)
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter holds the rate limits replicated in the
+// cluster along with the buckets of the clients
+type rateLimiter struct {
+	lock    sync.Mutex
+	limits  map[string]*protonpb.RateLimit
+	buckets map[string]*bucket
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limits:  make(map[string]*protonpb.RateLimit),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// LimitRate sets the write rate of the clients with an identity
+// on every member, a rate of 0 removes the limit. The clients are
+// identified by the common name of their certificate, or else by
+// the host of their address. Each member refills the buckets of
+// the clients writing to it, so that the limits hold without a
+// round trip to the leader on every write
+func (n *Node) LimitRate(ctx context.Context, limit *protonpb.RateLimit) error {
+	if limit.Identity == "" || limit.Rate < 0 {
+		return ErrInvalidRateLimit
+	}
+
+	var value []byte
+	if limit.Rate > 0 {
+		data, err := proto.Marshal(limit)
+		if err != nil {
+			return err
+		}
+		value = data
+	}
+
+	pair, err := EncodePair(ratePrefix+limit.Identity, value)
+	if err != nil {
+		return err
+	}
+	_, _, err = n.ProposeWait(withInternalPriority(ctx), pair)
+	return err
+}
+
+// RateLimits returns the rate limits of the clients
+func (n *Node) RateLimits() []*protonpb.RateLimit {
+	t := n.limiter
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var limits []*protonpb.RateLimit
+	for _, limit := range t.limits {
+		limits = append(limits, limit)
+	}
+	sort.Sort(rateLimitsByIdentity(limits))
+	return limits
+}
+
+// applyRateLimit sets or removes a committed rate limit
+func (n *Node) applyRateLimit(pair *LogPair) {
+	identity := strings.TrimPrefix(pair.Key, ratePrefix)
+
+	t := n.limiter
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// The buckets are filled again with the new limits
+	t.buckets = make(map[string]*bucket)
+	if len(pair.Value) == 0 {
+		delete(t.limits, identity)
+		return
+	}
+
+	limit := &protonpb.RateLimit{}
+	err := proto.Unmarshal(pair.Value, limit)
+	if err != nil {
+		log.Println("raft: can't decode rate limit:", err)
+		return
+	}
+	t.limits[identity] = limit
+}
+
+// restoreRateLimits replaces the rate limits with those of a snapshot
+func (n *Node) restoreRateLimits(limits []*protonpb.RateLimit) {
+	t := n.limiter
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.limits = make(map[string]*protonpb.RateLimit)
+	t.buckets = make(map[string]*bucket)
+	for _, limit := range limits {
+		t.limits[limit.Identity] = limit
+	}
+}
+
+// clientIdentity returns the common name of the certificate of the
+// client of an RPC, or else its host. Calls made without RPC have
+// no identity
+func clientIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+		return info.State.PeerCertificates[0].Subject.CommonName
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// checkRate spends a write of the client of an RPC,
+// ErrRateLimited is returned if it has none left
+func (n *Node) checkRate(ctx context.Context) error {
+	identity := clientIdentity(ctx)
+	if identity == "" {
+		return nil
+	}
+
+	t := n.limiter
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	limit, ok := t.limits[identity]
+	if !ok {
+		limit, ok = t.limits[AnyClient]
+	}
+	if !ok {
+		return nil
+	}
+
+	b, ok := t.buckets[identity]
+	if !ok {
+		b = &bucket{}
+		t.buckets[identity] = b
+	}
+	if !b.take(limit, time.Now()) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// SetRateLimit sets the write rate of clients of the raft cluster
+func (n *Node) SetRateLimit(ctx context.Context, req *protonpb.RateLimit) (*protonpb.SetRateLimitResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, proposeTimeout)
+	defer cancel()
+
+	err := n.LimitRate(ctx, req)
+	if err != nil {
+		return &protonpb.SetRateLimitResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.SetRateLimitResponse{Success: true}, nil
+}
+
+// ListRateLimits lists the rate limits of the clients of the raft cluster
+func (n *Node) ListRateLimits(ctx context.Context, req *protonpb.ListRateLimitsRequest) (*protonpb.ListRateLimitsResponse, error) {
+	return &protonpb.ListRateLimitsResponse{Limits: n.RateLimits()}, nil
+}
+
+// rateLimitsByIdentity sorts rate limits by identity
+type rateLimitsByIdentity []*protonpb.RateLimit
+
+func (r rateLimitsByIdentity) Len() int           { return len(r) }
+func (r rateLimitsByIdentity) Less(i, j int) bool { return r[i].Identity < r[j].Identity }
+func (r rateLimitsByIdentity) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
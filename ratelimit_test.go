@@ -0,0 +1,119 @@
+package proton
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func applyRateLimit(t *testing.T, n *Node, index uint64, limit *protonpb.RateLimit) {
+	value, err := proto.Marshal(limit)
+	assert.NoError(t, err)
+	applyProposal(t, n, index, &LogPair{Key: ratePrefix + limit.Identity, Value: value})
+}
+
+func clientContext(host string) context.Context {
+	addr := &net.TCPAddr{IP: net.ParseIP(host), Port: 4000}
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+}
+
+// bulkStream streams the requests of a bulk load from a client
+type bulkStream struct {
+	KV_BulkLoadServer
+	ctx  context.Context
+	reqs []*protonpb.BulkLoadRequest
+}
+
+func (s *bulkStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *bulkStream) Recv() (*protonpb.BulkLoadRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func TestBucket(t *testing.T) {
+	limit := &protonpb.RateLimit{Rate: 2, Burst: 3}
+	now := time.Now()
+
+	b := &bucket{}
+	for i := 0; i < 3; i++ {
+		assert.True(t, b.take(limit, now))
+	}
+	assert.False(t, b.take(limit, now))
+
+	// Refilled at the rate of the limit, up to the burst
+	assert.True(t, b.take(limit, now.Add(500*time.Millisecond)))
+	assert.False(t, b.take(limit, now.Add(500*time.Millisecond)))
+	for i := 0; i < 3; i++ {
+		assert.True(t, b.take(limit, now.Add(time.Minute)))
+	}
+	assert.False(t, b.take(limit, now.Add(time.Minute)))
+}
+
+func TestRateLimit(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	assert.Equal(t, clientIdentity(clientContext("10.0.0.1")), "10.0.0.1")
+	assert.Equal(t, clientIdentity(context.Background()), "")
+
+	applyRateLimit(t, n, 3, &protonpb.RateLimit{Identity: "10.0.0.1", Rate: 0.001, Burst: 2})
+	applyRateLimit(t, n, 4, &protonpb.RateLimit{Identity: AnyClient, Rate: 0.001, Burst: 1})
+	assert.Equal(t, n.RateLimits(), []*protonpb.RateLimit{
+		{Identity: AnyClient, Rate: 0.001, Burst: 1},
+		{Identity: "10.0.0.1", Rate: 0.001, Burst: 2},
+	})
+
+	limited := clientContext("10.0.0.1")
+	assert.NoError(t, n.checkRate(limited))
+	assert.NoError(t, n.checkRate(limited))
+	assert.Equal(t, n.checkRate(limited), ErrRateLimited)
+
+	// Other clients share the default limit but not its bucket
+	for _, host := range []string{"10.0.0.2", "10.0.0.3"} {
+		assert.NoError(t, n.checkRate(clientContext(host)))
+		assert.Equal(t, n.checkRate(clientContext(host)), ErrRateLimited)
+	}
+
+	// Calls made without RPC are never limited
+	assert.NoError(t, n.checkRate(context.Background()))
+
+	resp, err := n.PutObject(limited, &protonpb.PutObjectRequest{Object: &protonpb.Pair{Key: "foo"}})
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Equal(t, resp.Error, ErrRateLimited.Error())
+
+	// Nor can the client stream its writes through a bulk load
+	stream := &bulkStream{ctx: limited, reqs: []*protonpb.BulkLoadRequest{{Pairs: []*protonpb.Pair{{Key: "foo"}}}}}
+	assert.Equal(t, n.BulkLoad(stream), ErrRateLimited)
+
+	// Removing a limit falls back to the default one
+	applyProposal(t, n, 5, &LogPair{Key: ratePrefix + "10.0.0.1"})
+	assert.Len(t, n.RateLimits(), 1)
+	assert.NoError(t, n.checkRate(limited))
+	assert.Equal(t, n.checkRate(limited), ErrRateLimited)
+
+	// The limits are kept in snapshots
+	state := n.snapshotState()
+	applyProposal(t, n, 6, &LogPair{Key: ratePrefix + AnyClient})
+	assert.Empty(t, n.RateLimits())
+	n.restoreRateLimits(state.RateLimits)
+	assert.Equal(t, n.RateLimits(), []*protonpb.RateLimit{{Identity: AnyClient, Rate: 0.001, Burst: 1}})
+
+	assert.Equal(t, n.LimitRate(context.Background(), &protonpb.RateLimit{Rate: 1}), ErrInvalidRateLimit)
+	assert.Equal(t, n.LimitRate(context.Background(), &protonpb.RateLimit{Identity: "a", Rate: -1}), ErrInvalidRateLimit)
+}
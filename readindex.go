@@ -0,0 +1,290 @@
+package proton
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+	"golang.org/x/net/context"
+)
+
+const (
+	// readIndexTimeout bounds the time a round of linearizable
+	// reads waits for the leader to confirm the read index
+	readIndexTimeout = 5 * time.Second
+
+	// readIndexRetry is the interval after which the read index is
+	// requested again, the raft drops the requests made while there
+	// is no leader or before the leader committed in its term
+	readIndexRetry = 500 * time.Millisecond
+)
+
+var (
+	// ErrReadIndexTimeout is thrown when the leader did not confirm the read index of a linearizable read in time
+	ErrReadIndexTimeout = errors.New("timed out waiting for the read index of the leader")
+	// ErrReadStopped is thrown when the node stopped before a linearizable read was served
+	ErrReadStopped = errors.New("node stopped before the read was served")
+)
+
+// readBatch is a round of linearizable reads, all the reads
+// waiting for the same round are served by a single read index
+type readBatch struct {
+	done  chan struct{}
+	index uint64
+	err   error
+}
+
+// readIndexes groups the concurrent linearizable reads so that the
+// leader confirms its leadership to a quorum once per round instead
+// of once per read. A follower asks the leader for the read index
+// of its rounds, which the leader serves along with its own reads
+type readIndexes struct {
+	lock sync.Mutex
+	// next is the round collecting the reads arriving while
+	// another one is in flight
+	next      *readBatch
+	id        uint64
+	rounds    uint64
+	reads     uint64
+	forwarded uint64
+
+	notify chan struct{}
+	states chan raft.ReadState
+}
+
+func newReadIndexes() *readIndexes {
+	return &readIndexes{
+		notify: make(chan struct{}, 1),
+		states: make(chan raft.ReadState, 1),
+	}
+}
+
+// ReadIndexStats reports the number of linearizable reads
+// served and the number of read index rounds they took
+type ReadIndexStats struct {
+	Reads  uint64
+	Rounds uint64
+	// Forwarded is the number of rounds whose read
+	// index was given by the leader to this follower
+	Forwarded uint64
+}
+
+// ReadIndexStats returns the batching of the linearizable reads of the node
+func (n *Node) ReadIndexStats() ReadIndexStats {
+	r := n.reads
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return ReadIndexStats{Reads: r.reads, Rounds: r.rounds, Forwarded: r.forwarded}
+}
+
+// LinearizableRead waits until the store of the node reflects every
+// write committed before it was called, the read that follows can't
+// return a stale value even on a follower or a deposed leader. The
+// reads made at once share a single read index round of the leader,
+// a follower then serves them from its own store
+func (n *Node) LinearizableRead(ctx context.Context) error {
+	index, err := n.confirmReadIndex(ctx)
+	if err != nil {
+		return err
+	}
+	return n.WaitForIndex(ctx, index)
+}
+
+// confirmReadIndex returns the commit index confirmed by the
+// leader in the next round, once every write committed before
+// the call is at or below it
+func (n *Node) confirmReadIndex(ctx context.Context) (uint64, error) {
+	r := n.reads
+	r.lock.Lock()
+	b := r.next
+	if b == nil {
+		b = &readBatch{done: make(chan struct{})}
+		r.next = b
+	}
+	r.reads++
+	r.lock.Unlock()
+
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-b.done:
+		return b.index, b.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// readLoop serves the rounds of linearizable reads one at a time,
+// the reads arriving during a round wait for the next one
+func (n *Node) readLoop() {
+	r := n.reads
+	for {
+		select {
+		case <-r.notify:
+		case <-n.tickStop:
+			r.lock.Lock()
+			b := r.next
+			r.next = nil
+			r.lock.Unlock()
+			if b != nil {
+				b.err = ErrReadStopped
+				close(b.done)
+			}
+			return
+		}
+
+		r.lock.Lock()
+		b := r.next
+		r.next = nil
+		if b == nil {
+			r.lock.Unlock()
+			continue
+		}
+		r.id++
+		r.rounds++
+		id := r.id
+		r.lock.Unlock()
+
+		ctx, cancel := context.WithTimeout(n.Ctx, readIndexTimeout)
+		index, err := n.roundIndex(ctx, id)
+		cancel()
+		if err == context.DeadlineExceeded {
+			err = ErrReadIndexTimeout
+		}
+		b.index, b.err = index, err
+		close(b.done)
+	}
+}
+
+// roundIndex returns the read index of a round. A follower asks
+// the leader for it through the connection they share, so that the
+// rounds of the followers are batched on the leader with its own,
+// and falls back on the raft when the leader can't be reached
+func (n *Node) roundIndex(ctx context.Context, id uint64) (uint64, error) {
+	if !n.IsLeader() {
+		index, err := n.leaderReadIndex(ctx)
+		if err == nil {
+			n.reads.lock.Lock()
+			n.reads.forwarded++
+			n.reads.lock.Unlock()
+			return index, nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+	}
+	return n.readIndex(ctx, id)
+}
+
+// leaderReadIndex asks the leader for its read index
+func (n *Node) leaderReadIndex(ctx context.Context) (uint64, error) {
+	leader, ok := n.Cluster.Peers()[n.Leader()]
+	if !ok || leader.ID == n.ID || leader.Client == nil {
+		return 0, ErrNoLeader
+	}
+
+	resp, err := leader.Client.LeaderReadIndex(ctx, &protonpb.LeaderReadIndexRequest{})
+	if err != nil {
+		return 0, err
+	}
+	if !resp.Success {
+		return 0, errors.New(resp.Error)
+	}
+	return resp.Index, nil
+}
+
+// LeaderReadIndex gives a follower the read index of the next round
+// of the leader, the follower serves the read from its own store
+// once it applied the entries up to it instead of going through the
+// leader with the values
+func (n *Node) LeaderReadIndex(ctx context.Context, req *protonpb.LeaderReadIndexRequest) (*protonpb.LeaderReadIndexResponse, error) {
+	if !n.IsLeader() {
+		return &protonpb.LeaderReadIndexResponse{
+			Success: false,
+			Error:   n.notLeader().Error(),
+		}, nil
+	}
+
+	index, err := n.confirmReadIndex(ctx)
+	if err != nil {
+		return &protonpb.LeaderReadIndexResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.LeaderReadIndexResponse{Success: true, Index: index}, nil
+}
+
+// readIndex requests the read index of a round and waits for the
+// leader to confirm it. The round is identified by its id so that
+// the confirmation of an earlier round that timed out is ignored
+func (n *Node) readIndex(ctx context.Context, id uint64) (uint64, error) {
+	rctx := make([]byte, 8)
+	binary.BigEndian.PutUint64(rctx, id)
+
+	retry := time.NewTicker(readIndexRetry)
+	defer retry.Stop()
+
+	for {
+		err := n.Node.ReadIndex(ctx, rctx)
+		if err != nil {
+			if err == raft.ErrStopped {
+				return 0, ErrReadStopped
+			}
+			return 0, err
+		}
+
+		index, ok, err := n.awaitReadState(ctx, retry.C, id)
+		if ok || err != nil {
+			return index, err
+		}
+	}
+}
+
+// awaitReadState waits for the read index of a round until it
+// is time to request it again
+func (n *Node) awaitReadState(ctx context.Context, retry <-chan time.Time, id uint64) (uint64, bool, error) {
+	for {
+		select {
+		case rs := <-n.reads.states:
+			if len(rs.RequestCtx) == 8 && binary.BigEndian.Uint64(rs.RequestCtx) == id {
+				return rs.Index, true, nil
+			}
+		case <-retry:
+			return 0, false, nil
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		case <-n.tickStop:
+			return 0, false, ErrReadStopped
+		}
+	}
+}
+
+// deliverReadStates hands the read index confirmed by the leader
+// over to the round in flight, only the last one matters since
+// there is a single round at a time
+func (n *Node) deliverReadStates(states []raft.ReadState) {
+	if len(states) == 0 {
+		return
+	}
+	rs := states[len(states)-1]
+	select {
+	case n.reads.states <- rs:
+	default:
+		select {
+		case <-n.reads.states:
+		default:
+		}
+		select {
+		case n.reads.states <- rs:
+		default:
+		}
+	}
+}
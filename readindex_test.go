@@ -0,0 +1,66 @@
+package proton
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestLinearizableRead(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, n.LinearizableRead(ctx))
+
+	// The concurrent reads share the rounds of read index
+	reads := 64
+	var wg sync.WaitGroup
+	errs := make(chan error, reads)
+	for i := 0; i < reads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- n.LinearizableRead(ctx)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	stats := n.ReadIndexStats()
+	assert.Equal(t, stats.Reads, uint64(reads+1))
+	assert.True(t, stats.Rounds < stats.Reads)
+	assert.True(t, n.AppliedIndex() > 0)
+}
+
+func TestDeliverReadStates(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// Only the last read index confirmed is kept
+	n.deliverReadStates(nil)
+	n.deliverReadStates([]raft.ReadState{{Index: 1}, {Index: 2}})
+	n.deliverReadStates([]raft.ReadState{{Index: 3}})
+	rs := <-n.reads.states
+	assert.Equal(t, rs.Index, uint64(3))
+	assert.Len(t, n.reads.states, 0)
+}
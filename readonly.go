@@ -0,0 +1,66 @@
+package proton
+
+import (
+	"errors"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// AuditReadOnlyEnable is recorded when a node enters the read-only mode
+	AuditReadOnlyEnable = "read-only-enable"
+	// AuditReadOnlyDisable is recorded when a node leaves the read-only mode
+	AuditReadOnlyDisable = "read-only-disable"
+)
+
+var (
+	// ErrReadOnly is thrown when a proposal is made on a node in read-only mode
+	ErrReadOnly = errors.New("node is in read-only mode, proposals are refused")
+)
+
+// SetReadOnly toggles the read-only mode of the node. In read-only
+// mode new proposals are rejected with ErrReadOnly, while the node
+// keeps serving reads and taking part in the raft
+func (n *Node) SetReadOnly(readOnly bool) {
+	n.readOnlyLock.Lock()
+	n.readOnly = readOnly
+	n.readOnlyLock.Unlock()
+}
+
+// IsReadOnly checks if the node is in read-only mode
+func (n *Node) IsReadOnly() bool {
+	n.readOnlyLock.RLock()
+	defer n.readOnlyLock.RUnlock()
+	return n.readOnly
+}
+
+// checkReadOnly rejects a proposal if the node is in read-only
+// mode, the internal cluster state can still be written
+func (n *Node) checkReadOnly(data []byte) error {
+	if !n.IsReadOnly() {
+		return nil
+	}
+
+	pair := &LogPair{}
+	err := proto.Unmarshal(data, pair)
+	if err == nil && isSystemKey(pair.Key) {
+		return nil
+	}
+	return ErrReadOnly
+}
+
+// ToggleReadOnly toggles the read-only mode of a node in the raft cluster
+func (n *Node) ToggleReadOnly(ctx context.Context, req *protonpb.ToggleReadOnlyRequest) (*protonpb.ToggleReadOnlyResponse, error) {
+	n.SetReadOnly(req.ReadOnly)
+
+	action := AuditReadOnlyDisable
+	if req.ReadOnly {
+		action = AuditReadOnlyEnable
+	}
+	n.recordAudit(ctx, action, n.ID, nil)
+
+	return &protonpb.ToggleReadOnlyResponse{Success: true}, nil
+}
@@ -0,0 +1,141 @@
+package proton
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	// AuditForceNewCluster is recorded when a node is forced to be the single member of a new cluster
+	AuditForceNewCluster = "force-new-cluster"
+)
+
+var (
+	// ErrNodeStopped is thrown when an operation needs the raft loop of a stopped node
+	ErrNodeStopped = errors.New("raft node is stopped")
+)
+
+// ForceNewCluster turns the node into the single member of a new
+// cluster, keeping its store. It is meant to recover from the
+// permanent loss of the quorum: the other members are removed
+// without their agreement and must never be restarted with their
+// current state. With dryRun, the members that would be removed
+// are returned and nothing is changed
+func (n *Node) ForceNewCluster(ctx context.Context, dryRun bool) ([]*protonpb.NodeInfo, error) {
+	if dryRun {
+		var removed []*protonpb.NodeInfo
+		for _, member := range n.Cluster.Members() {
+			if member.ID != n.ID {
+				removed = append(removed, member)
+			}
+		}
+		return removed, nil
+	}
+
+	log.Printf("raft: WARNING forcing %x to be the single member of a new cluster, the other members must be wiped before they rejoin", n.ID)
+
+	done := make(chan []*protonpb.NodeInfo, 1)
+	select {
+	case n.forceChan <- done:
+	case <-n.tickDone:
+		return nil, ErrNodeStopped
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	removed := <-done
+
+	// The node is now alone and elects itself
+	n.Campaign(ctx)
+	err := poll(ctx, n.IsLeader)
+	// Recorded before the removals, which may not all make it
+	n.recordAudit(ctx, AuditForceNewCluster, n.ID, err)
+	if err != nil {
+		return removed, err
+	}
+
+	return removed, n.proposeRemovals(ctx, removed)
+}
+
+// proposeRemovals records the removals of the members in the log so
+// that the snapshots carry the new configuration, the removals that
+// did not get into the log are named in the error
+func (n *Node) proposeRemovals(ctx context.Context, removed []*protonpb.NodeInfo) error {
+	for i, member := range removed {
+		err := n.ProposeConfChange(ctx, raftpb.ConfChange{
+			ID:     member.ID,
+			Type:   raftpb.ConfChangeRemoveNode,
+			NodeID: member.ID,
+		})
+		if err != nil {
+			ids := make([]string, 0, len(removed)-i)
+			for _, member := range removed[i:] {
+				ids = append(ids, fmt.Sprintf("%x", member.ID))
+			}
+			return fmt.Errorf("removal of %s not recorded in the log: %v", strings.Join(ids, ", "), err)
+		}
+	}
+	return nil
+}
+
+// forceNewCluster removes every other member from the local raft
+// configuration without going through the log, which cannot commit
+// anything without the quorum. It is called from the main loop
+func (n *Node) forceNewCluster() []*protonpb.NodeInfo {
+	ids := make(map[uint64]bool)
+	for _, id := range n.confState.Nodes {
+		ids[id] = true
+	}
+	for id := range n.Cluster.Peers() {
+		ids[id] = true
+	}
+
+	// The members are removed in a stable order, the
+	// same as the removals are then proposed in
+	var removed []*protonpb.NodeInfo
+	for id := range ids {
+		if id == n.ID {
+			continue
+		}
+		member, ok := n.Cluster.Member(id)
+		if !ok {
+			member = &protonpb.NodeInfo{ID: id}
+		}
+		removed = append(removed, member)
+	}
+	sort.Sort(membersByID(removed))
+
+	for _, member := range removed {
+		log.Printf("raft: WARNING removing %x from the cluster of %x", member.ID, n.ID)
+		n.confState = *n.ApplyConfChange(raftpb.ConfChange{
+			Type:   raftpb.ConfChangeRemoveNode,
+			NodeID: member.ID,
+		})
+		n.UnregisterNode(member.ID)
+	}
+	return removed
+}
+
+// RecoverCluster forces a node of the raft cluster to be the single member of a new cluster
+func (n *Node) RecoverCluster(ctx context.Context, req *protonpb.RecoverClusterRequest) (*protonpb.RecoverClusterResponse, error) {
+	removed, err := n.ForceNewCluster(ctx, req.DryRun)
+	if err != nil {
+		return &protonpb.RecoverClusterResponse{
+			Success: false,
+			Error:   err.Error(),
+			Removed: removed,
+		}, nil
+	}
+
+	return &protonpb.RecoverClusterResponse{
+		Success: true,
+		Removed: removed,
+	}, nil
+}
@@ -0,0 +1,21 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProposeRemovals(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	assert.NoError(t, n.proposeRemovals(n.Ctx, nil))
+
+	// The removals left out of the log are reported
+	n.Stop()
+	removed := []*protonpb.NodeInfo{{ID: 0x2}, {ID: 0x1a}}
+	err := n.proposeRemovals(n.Ctx, removed)
+	assert.EqualError(t, err, "removal of 2, 1a not recorded in the log: "+raft.ErrStopped.Error())
+}
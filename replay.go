@@ -0,0 +1,141 @@
+package proton
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+var (
+	// ErrAppliedCorrupt is thrown when the saved applied index does not match its checksum
+	ErrAppliedCorrupt = errors.New("applied index checksum mismatch")
+)
+
+// AppliedStore saves the index of the last entry given to the
+// apply handler. It may be implemented by the application to
+// save the index along with its state, in the same transaction,
+// so that no entry is ever applied twice
+type AppliedStore interface {
+	// LoadApplied returns the index saved, 0 if there is none
+	LoadApplied() (uint64, error)
+	// SaveApplied saves the index of the last applied entry
+	SaveApplied(index uint64) error
+}
+
+// NewAppliedFile returns an AppliedStore saving the
+// applied index in a file, replaced atomically
+func NewAppliedFile(path string) AppliedStore {
+	return &appliedFile{path: path}
+}
+
+// appliedFile saves the applied index in a file
+// along with the checksum of the index
+type appliedFile struct {
+	path string
+}
+
+// LoadApplied reads the index saved in the file
+func (f *appliedFile) LoadApplied() (uint64, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) != 12 || crc32.Checksum(data[:8], crcTable) != binary.BigEndian.Uint32(data[8:]) {
+		return 0, ErrAppliedCorrupt
+	}
+	return binary.BigEndian.Uint64(data[:8]), nil
+}
+
+// SaveApplied writes the index to a temporary file
+// synced to disk, then renames it over the file
+func (f *appliedFile) SaveApplied(index uint64) error {
+	data := make([]byte, 12)
+	binary.BigEndian.PutUint64(data[:8], index)
+	binary.BigEndian.PutUint32(data[8:], crc32.Checksum(data[:8], crcTable))
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// SetAppliedStore sets where the index of the last entry given
+// to the apply handler is saved. When the node replays the
+// log, the entries and the snapshots up to the saved index are
+// no longer given to the apply handler and the restore function,
+// so that a state machine kept on disk never applies them twice.
+// Must be called before the node is started
+func (n *Node) SetAppliedStore(store AppliedStore) error {
+	index, err := store.LoadApplied()
+	if err != nil {
+		return err
+	}
+	n.appliedStore = store
+	n.replayIndex = index
+	n.savedIndex = index
+	n.warmup.raise(index)
+	return nil
+}
+
+// applyHandler gives an entry to the apply handler,
+// unless it was applied before the node restarted
+func (n *Node) applyHandler(entry raftpb.Entry, key string, data []byte) {
+	if n.apply == nil || entry.Index <= n.replayIndex {
+		return
+	}
+	if n.applier != nil {
+		n.applier.dispatch(key, data)
+		return
+	}
+	n.apply(data)
+}
+
+// saveApplied saves the applied index once the entries given to
+// the handler are applied. Called from the main loop
+func (n *Node) saveApplied() {
+	if n.appliedStore == nil || n.appliedIndex <= n.savedIndex {
+		return
+	}
+
+	// The file is replaced atomically so a full disk leaves the
+	// previous index in place, it is saved again once writes are
+	// refused rather than stopping the node
+	err := n.appliedStore.SaveApplied(n.appliedIndex)
+	if err != nil && isNoSpaceError(err) {
+		n.Cfg.Logger.Warningf("raft: can't save applied index on node %v, the disk is full: %v", n.ID, err)
+		n.raiseAlarm(protonpb.AlarmType_NOSPACE)
+		return
+	}
+	// Applying the entries again is worse than stopping
+	if err != nil {
+		log.Fatalf("raft: can't save applied index on node %v: %v", n.ID, err)
+	}
+	n.savedIndex = n.appliedIndex
+	if n.savedIndex > n.replayIndex {
+		n.replayIndex = n.savedIndex
+	}
+}
@@ -0,0 +1,49 @@
+package proton
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppliedStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proton-applied")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "applied")
+	store := NewAppliedFile(path)
+	index, err := store.LoadApplied()
+	assert.NoError(t, err)
+	assert.Equal(t, index, uint64(0))
+	assert.NoError(t, store.SaveApplied(4))
+
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	var applied []string
+	n.apply = func(data interface{}) { applied = append(applied, string(data.([]byte))) }
+	assert.NoError(t, n.SetAppliedStore(store))
+
+	// The entries applied before the restart are not applied again
+	applyPair(t, n, 4, "foo", "bar")
+	assert.Empty(t, applied)
+	assert.Equal(t, n.Get("foo"), "bar")
+
+	applyPair(t, n, 5, "baz", "qux")
+	assert.Equal(t, len(applied), 1)
+
+	n.appliedIndex = 5
+	n.saveApplied()
+	index, err = NewAppliedFile(path).LoadApplied()
+	assert.NoError(t, err)
+	assert.Equal(t, index, uint64(5))
+
+	// A corrupted index is not trusted
+	assert.NoError(t, ioutil.WriteFile(path, []byte("garbage-data"), 0600))
+	_, err = store.LoadApplied()
+	assert.Equal(t, err, ErrAppliedCorrupt)
+}
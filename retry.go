@@ -0,0 +1,220 @@
+package proton
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// DefaultRetryAttempts is the number of times a request is sent at most
+	DefaultRetryAttempts = 5
+
+	// DefaultRetryBackoff is the time to wait before the first
+	// retry of a request, doubled after each retry
+	DefaultRetryBackoff = 50 * time.Millisecond
+
+	// DefaultMaxRetryBackoff caps the time to wait between retries
+	DefaultMaxRetryBackoff = 2 * time.Second
+
+	// DefaultRetryBudget is the fraction of the requests that can be retried
+	DefaultRetryBudget = 0.2
+
+	// retryMaxTokens caps the retries saved up while the requests
+	// succeed, to bound a burst of retries when the cluster fails
+	retryMaxTokens = 10
+)
+
+// ErrorClass tells whether a request that failed can be sent again
+type ErrorClass int
+
+const (
+	// PermanentError is the class of the errors a retry can't
+	// fix, like an invalid request or a refused permission
+	PermanentError ErrorClass = iota
+	// RetryableError is the class of the errors of a cluster going
+	// through a change, like an election or a restarting member
+	RetryableError
+)
+
+// retryableErrors are the errors of the nodes thrown while the
+// cluster recovers, matched by their description through gRPC
+var retryableErrors = []error{
+	ErrNotLeader,
+	ErrNoLeader,
+	ErrWarmingUp,
+	ErrReadIndexTimeout,
+	ErrProposalExpired,
+	ErrRateLimited,
+	ErrConnectionRefused,
+}
+
+// ClassifyError tells whether a request failing with an error can be
+// retried, locally or through gRPC. A member that is unavailable or
+// not the leader is retryable, an invalid argument or a permission
+// denied is not, nor is the end of the context of the request
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return PermanentError
+	}
+
+	switch grpc.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return RetryableError
+	case codes.Unknown:
+		// The handlers return plain errors, known by their description
+		desc := grpc.ErrorDesc(err)
+		for _, retryable := range retryableErrors {
+			if strings.HasPrefix(desc, retryable.Error()) {
+				return RetryableError
+			}
+		}
+	}
+	return PermanentError
+}
+
+// IsRetryable checks if a request failing with an error can be retried
+func IsRetryable(err error) bool {
+	return ClassifyError(err) == RetryableError
+}
+
+// RetryStats reports the retries of the requests made with a policy
+type RetryStats struct {
+	// Requests is the number of requests made
+	Requests uint64
+	// Attempts is the number of times the requests were sent
+	Attempts uint64
+	// Retries is the number of attempts after the first
+	Retries uint64
+	// BudgetExhausted is the number of retryable failures
+	// not retried because the budget was spent
+	BudgetExhausted uint64
+	// DeadlineExhausted is the number of retryable failures not
+	// retried because the deadline was too close for the backoff
+	DeadlineExhausted uint64
+}
+
+// RetryPolicy retries the requests to the cluster failing with a
+// retryable error, waiting an exponential backoff between attempts.
+// A request is not retried past the deadline of its context, and the
+// retries are limited to a fraction of the requests so that a failing
+// cluster is not overloaded by the clients retrying all at once
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent at most
+	MaxAttempts int
+	// Backoff is the time to wait before the first retry
+	Backoff time.Duration
+	// MaxBackoff caps the time to wait between retries
+	MaxBackoff time.Duration
+	// Budget is the fraction of the requests that can be retried
+	Budget float64
+	// Classify tells which errors are retried, ClassifyError if nil
+	Classify func(error) ErrorClass
+
+	lock   sync.Mutex
+	tokens float64
+	stats  RetryStats
+}
+
+// NewRetryPolicy returns a retry policy with the default settings
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DefaultRetryAttempts,
+		Backoff:     DefaultRetryBackoff,
+		MaxBackoff:  DefaultMaxRetryBackoff,
+		Budget:      DefaultRetryBudget,
+		tokens:      retryMaxTokens,
+	}
+}
+
+// Stats returns the retries of the requests made with the policy
+func (p *RetryPolicy) Stats() RetryStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.stats
+}
+
+// Do sends a request until it succeeds, fails with an error that
+// is not retryable or runs out of attempts, budget or time. It
+// returns the error of the last attempt
+func (p *RetryPolicy) Do(ctx context.Context, call func(context.Context) error) error {
+	p.lock.Lock()
+	p.stats.Requests++
+	p.tokens += p.Budget
+	if p.tokens > retryMaxTokens {
+		p.tokens = retryMaxTokens
+	}
+	p.lock.Unlock()
+
+	classify := p.Classify
+	if classify == nil {
+		classify = ClassifyError
+	}
+
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		p.count(func(s *RetryStats) { s.Attempts++ })
+		err := call(ctx)
+		if err == nil || attempt >= p.MaxAttempts || classify(err) != RetryableError {
+			return err
+		}
+
+		wait := jitter(backoff)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			p.count(func(s *RetryStats) { s.DeadlineExhausted++ })
+			return err
+		}
+		if !p.spend() {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// spend takes a retry from the budget
+func (p *RetryPolicy) spend() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.tokens < 1 {
+		p.stats.BudgetExhausted++
+		return false
+	}
+	p.tokens--
+	p.stats.Retries++
+	return true
+}
+
+// count updates the stats of the policy
+func (p *RetryPolicy) count(update func(*RetryStats)) {
+	p.lock.Lock()
+	update(&p.stats)
+	p.lock.Unlock()
+}
+
+// jitter spreads a backoff over its upper half so that
+// the clients failing at once don't retry at once
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 1 {
+		return backoff
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
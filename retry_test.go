@@ -0,0 +1,121 @@
+package proton
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestClassifyError(t *testing.T) {
+	retryable := []error{
+		ErrNoLeader,
+		&NotLeaderError{LeaderID: 2, LeaderAddr: "10.0.0.2:4242"},
+		grpc.Errorf(codes.Unknown, "%s", (&NotLeaderError{}).Error()),
+		grpc.Errorf(codes.Unknown, "%s", ErrWarmingUp.Error()),
+		grpc.Errorf(codes.Unavailable, "transport is closing"),
+		grpc.Errorf(codes.ResourceExhausted, "too many streams"),
+	}
+	for _, err := range retryable {
+		assert.Equal(t, RetryableError, ClassifyError(err), err.Error())
+	}
+
+	permanent := []error{
+		nil,
+		ErrInvalidNamespace,
+		errors.New("something else went wrong"),
+		context.Canceled,
+		grpc.Errorf(codes.Unknown, "%s", ErrQuotaExceeded.Error()),
+		grpc.Errorf(codes.InvalidArgument, "bad request"),
+		grpc.Errorf(codes.PermissionDenied, "not allowed"),
+		grpc.Errorf(codes.DeadlineExceeded, "too late"),
+	}
+	for _, err := range permanent {
+		assert.Equal(t, PermanentError, ClassifyError(err))
+	}
+	assert.True(t, IsRetryable(ErrNoLeader))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	p := NewRetryPolicy()
+	p.Backoff = time.Millisecond
+	ctx := context.Background()
+
+	// A retryable error is retried until the request succeeds
+	calls := 0
+	err := p.Do(ctx, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return ErrNoLeader
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, calls, 3)
+
+	// A permanent error is returned at once
+	calls = 0
+	err = p.Do(ctx, func(context.Context) error {
+		calls++
+		return ErrInvalidNamespace
+	})
+	assert.Equal(t, err, ErrInvalidNamespace)
+	assert.Equal(t, calls, 1)
+
+	// The attempts are bounded
+	calls = 0
+	err = p.Do(ctx, func(context.Context) error {
+		calls++
+		return ErrNoLeader
+	})
+	assert.Equal(t, err, ErrNoLeader)
+	assert.Equal(t, calls, DefaultRetryAttempts)
+
+	stats := p.Stats()
+	assert.Equal(t, stats.Requests, uint64(3))
+	assert.Equal(t, stats.Attempts, uint64(3+1+DefaultRetryAttempts))
+	assert.Equal(t, stats.Retries, uint64(2+DefaultRetryAttempts-1))
+}
+
+func TestRetryDeadline(t *testing.T) {
+	p := NewRetryPolicy()
+	p.Backoff = time.Second
+
+	// A backoff past the deadline is not waited for
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	calls := 0
+	start := time.Now()
+	err := p.Do(ctx, func(context.Context) error {
+		calls++
+		return ErrNoLeader
+	})
+	assert.Equal(t, err, ErrNoLeader)
+	assert.Equal(t, calls, 1)
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+	assert.Equal(t, p.Stats().DeadlineExhausted, uint64(1))
+}
+
+func TestRetryBudget(t *testing.T) {
+	p := NewRetryPolicy()
+	p.Backoff = 0
+	p.Budget = 0
+	ctx := context.Background()
+
+	// The saved up retries are spent, then the requests fail at once
+	calls := 0
+	for i := 0; i < 5; i++ {
+		p.Do(ctx, func(context.Context) error {
+			calls++
+			return ErrNoLeader
+		})
+	}
+	stats := p.Stats()
+	assert.Equal(t, stats.Retries, uint64(retryMaxTokens))
+	assert.Equal(t, calls, 5+retryMaxTokens)
+	assert.True(t, stats.BudgetExhausted > 0)
+}
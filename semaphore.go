@@ -0,0 +1,276 @@
+package proton
+
+import (
+	"errors"
+	"log"
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// semaphorePrefix is the keyspace holding the semaphores
+	semaphorePrefix = systemPrefix + "semaphore/"
+)
+
+var (
+	// ErrTooManyPermits is thrown when acquiring no permit or more permits than the semaphore has
+	ErrTooManyPermits = errors.New("permits must be between 1 and the limit of the semaphore")
+	// ErrSemaphoreLimit is thrown when acquiring a semaphore with another limit than its holders
+	ErrSemaphoreLimit = errors.New("semaphore is held with a different limit")
+	// ErrSemaphoreTaken is thrown when the permits of a semaphore are taken
+	ErrSemaphoreTaken = errors.New("not enough permits available")
+)
+
+// Acquire takes permits out of the limit of a named semaphore, it
+// waits for the permits to be released if they are taken. The
+// permits are given back when the session ends, so that a crashed
+// holder does not keep them. The waiters are not served in order
+func (n *Node) Acquire(ctx context.Context, name string, permits, limit, session uint64) (uint64, error) {
+	for {
+		// Registered before trying so that a release is not missed
+		n.semaphoreLock.RLock()
+		released := n.released
+		n.semaphoreLock.RUnlock()
+
+		ticket, err := n.TryAcquire(ctx, name, permits, limit, session)
+		if err != ErrSemaphoreTaken {
+			return ticket, err
+		}
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// TryAcquire takes permits out of the limit of a named semaphore,
+// ErrSemaphoreTaken is returned if they are not available. The
+// semaphore is created by its first holder with the given limit
+func (n *Node) TryAcquire(ctx context.Context, name string, permits, limit, session uint64) (uint64, error) {
+	if permits == 0 || permits > limit {
+		return 0, ErrTooManyPermits
+	}
+	if !n.hasSession(session) {
+		return 0, ErrSessionNotFound
+	}
+
+	ticket, err := randomID()
+	if err != nil {
+		return 0, err
+	}
+	holder := &protonpb.SemaphoreHolder{Ticket: ticket, Session: session, Permits: permits}
+
+	err = n.proposeSemaphore(ctx, &protonpb.SemaphoreRequest{Name: name, Limit: limit, Holder: holder})
+	if err != nil {
+		return 0, err
+	}
+
+	// The permits are granted when the request is applied
+	n.semaphoreLock.RLock()
+	defer n.semaphoreLock.RUnlock()
+
+	semaphore, ok := n.semaphores[name]
+	if ok && semaphore.Limit != limit {
+		return 0, ErrSemaphoreLimit
+	}
+	if !ok || holderIndex(semaphore, ticket) < 0 {
+		return 0, ErrSemaphoreTaken
+	}
+	return ticket, nil
+}
+
+// Release gives back the permits taken with a ticket
+func (n *Node) Release(ctx context.Context, name string, ticket uint64) error {
+	return n.proposeSemaphore(ctx, &protonpb.SemaphoreRequest{
+		Name:    name,
+		Holder:  &protonpb.SemaphoreHolder{Ticket: ticket},
+		Release: true,
+	})
+}
+
+// proposeSemaphore replicates a request on a semaphore
+// and waits for it to be applied on this node
+func (n *Node) proposeSemaphore(ctx context.Context, req *protonpb.SemaphoreRequest) error {
+	value, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	data, err := EncodePair(semaphorePrefix+req.Name, value)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = n.ProposeWait(ctx, data)
+	return err
+}
+
+// Semaphores returns the semaphores that have holders
+func (n *Node) Semaphores() []*protonpb.Semaphore {
+	n.semaphoreLock.RLock()
+	defer n.semaphoreLock.RUnlock()
+
+	var semaphores []*protonpb.Semaphore
+	for _, semaphore := range n.semaphores {
+		semaphores = append(semaphores, semaphore)
+	}
+	sort.Sort(semaphoresByName(semaphores))
+	return semaphores
+}
+
+// holderIndex returns the position of a ticket in the holders of a semaphore
+func holderIndex(semaphore *protonpb.Semaphore, ticket uint64) int {
+	for i, holder := range semaphore.Holders {
+		if holder.Ticket == ticket {
+			return i
+		}
+	}
+	return -1
+}
+
+// used returns the number of permits taken from a semaphore
+func used(semaphore *protonpb.Semaphore) uint64 {
+	var permits uint64
+	for _, holder := range semaphore.Holders {
+		permits += holder.Permits
+	}
+	return permits
+}
+
+// applySemaphore grants or releases permits of a semaphore. The
+// outcome only depends on the log, so that every member agrees
+// on the holders and their fencing tokens
+func (n *Node) applySemaphore(entry raftpb.Entry, pair *LogPair) {
+	req := &protonpb.SemaphoreRequest{}
+	err := proto.Unmarshal(pair.Value, req)
+	if err != nil || req.Holder == nil {
+		log.Println("raft: can't decode semaphore request:", err)
+		return
+	}
+
+	n.semaphoreLock.Lock()
+	defer n.semaphoreLock.Unlock()
+
+	semaphore, ok := n.semaphores[req.Name]
+	if req.Release {
+		if !ok {
+			return
+		}
+		if holderIndex(semaphore, req.Holder.Ticket) < 0 {
+			return
+		}
+		n.semaphores[req.Name] = removeHolders(semaphore, func(h *protonpb.SemaphoreHolder) bool {
+			return h.Ticket == req.Holder.Ticket
+		})
+		n.notifyRelease()
+		return
+	}
+
+	if !n.hasSession(req.Holder.Session) {
+		return
+	}
+	if !ok {
+		semaphore = &protonpb.Semaphore{Name: req.Name, Limit: req.Limit}
+	}
+	if semaphore.Limit != req.Limit || used(semaphore)+req.Holder.Permits > semaphore.Limit {
+		return
+	}
+
+	// The entry orders the holders, so that a holder whose permits
+	// were taken over has a lower token than the new one
+	holder := *req.Holder
+	holder.Token = &protonpb.FencingToken{Term: entry.Term, Index: entry.Index}
+
+	// Semaphores are copied on write as they are returned to readers
+	holders := append([]*protonpb.SemaphoreHolder(nil), semaphore.Holders...)
+	n.semaphores[req.Name] = &protonpb.Semaphore{
+		Name:    semaphore.Name,
+		Limit:   semaphore.Limit,
+		Holders: append(holders, &holder),
+	}
+}
+
+// removeHolders returns a copy of a semaphore without the holders
+// matching remove, or nil if no holder is left
+func removeHolders(semaphore *protonpb.Semaphore, remove func(*protonpb.SemaphoreHolder) bool) *protonpb.Semaphore {
+	var holders []*protonpb.SemaphoreHolder
+	for _, holder := range semaphore.Holders {
+		if !remove(holder) {
+			holders = append(holders, holder)
+		}
+	}
+	if len(holders) == 0 {
+		return nil
+	}
+	return &protonpb.Semaphore{Name: semaphore.Name, Limit: semaphore.Limit, Holders: holders}
+}
+
+// releaseSession gives back the permits held by a session that ended
+func (n *Node) releaseSession(session uint64) {
+	n.semaphoreLock.Lock()
+	defer n.semaphoreLock.Unlock()
+
+	for name, semaphore := range n.semaphores {
+		n.semaphores[name] = removeHolders(semaphore, func(h *protonpb.SemaphoreHolder) bool {
+			return h.Session == session
+		})
+	}
+	n.notifyRelease()
+}
+
+// notifyRelease wakes up the callers waiting for permits.
+// Must be called with the semaphore lock held
+func (n *Node) notifyRelease() {
+	for name, semaphore := range n.semaphores {
+		if semaphore == nil {
+			delete(n.semaphores, name)
+		}
+	}
+	close(n.released)
+	n.released = make(chan struct{})
+}
+
+// AcquireSemaphore takes permits of a semaphore of the raft cluster
+func (n *Node) AcquireSemaphore(ctx context.Context, req *protonpb.AcquireSemaphoreRequest) (*protonpb.AcquireSemaphoreResponse, error) {
+	acquire := n.TryAcquire
+	if req.Wait {
+		acquire = n.Acquire
+	}
+
+	ticket, err := acquire(ctx, req.Name, req.Permits, req.Limit, req.Session)
+	if err != nil {
+		return &protonpb.AcquireSemaphoreResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	token, _ := n.SemaphoreToken(req.Name, ticket)
+	return &protonpb.AcquireSemaphoreResponse{Success: true, Ticket: ticket, Token: token}, nil
+}
+
+// ReleaseSemaphore gives back permits of a semaphore of the raft cluster
+func (n *Node) ReleaseSemaphore(ctx context.Context, req *protonpb.ReleaseSemaphoreRequest) (*protonpb.ReleaseSemaphoreResponse, error) {
+	err := n.Release(ctx, req.Name, req.Ticket)
+	if err != nil {
+		return &protonpb.ReleaseSemaphoreResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.ReleaseSemaphoreResponse{Success: true}, nil
+}
+
+// semaphoresByName sorts semaphores by name
+type semaphoresByName []*protonpb.Semaphore
+
+func (s semaphoresByName) Len() int           { return len(s) }
+func (s semaphoresByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+func (s semaphoresByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,77 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func applySemaphoreRequest(t *testing.T, n *Node, index uint64, req *protonpb.SemaphoreRequest) {
+	value, err := proto.Marshal(req)
+	assert.NoError(t, err)
+	applyProposal(t, n, index, &LogPair{Key: semaphorePrefix + req.Name, Value: value})
+}
+
+func TestSemaphore(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	for i, id := range []uint64{1, 2} {
+		value, err := proto.Marshal(&protonpb.Session{Id: id, Ttl: 1000})
+		assert.NoError(t, err)
+		applyProposal(t, n, uint64(i+3), &LogPair{Key: sessionKey(id), Value: value})
+	}
+
+	acquire := func(index, ticket, session, permits uint64) {
+		applySemaphoreRequest(t, n, index, &protonpb.SemaphoreRequest{
+			Name:   "jobs",
+			Limit:  3,
+			Holder: &protonpb.SemaphoreHolder{Ticket: ticket, Session: session, Permits: permits},
+		})
+	}
+
+	acquire(5, 10, 1, 2)
+	acquire(6, 11, 2, 2)
+	acquire(7, 12, 2, 1)
+	assert.Equal(t, n.Semaphores(), []*protonpb.Semaphore{{
+		Name:  "jobs",
+		Limit: 3,
+		Holders: []*protonpb.SemaphoreHolder{
+			{Ticket: 10, Session: 1, Permits: 2, Token: &protonpb.FencingToken{Term: 1, Index: 5}},
+			{Ticket: 12, Session: 2, Permits: 1, Token: &protonpb.FencingToken{Term: 1, Index: 7}},
+		},
+	}})
+
+	// A holder with another limit is refused
+	applySemaphoreRequest(t, n, 8, &protonpb.SemaphoreRequest{
+		Name:   "jobs",
+		Limit:  10,
+		Holder: &protonpb.SemaphoreHolder{Ticket: 13, Session: 1, Permits: 1},
+	})
+	assert.Len(t, n.Semaphores()[0].Holders, 2)
+
+	released := n.released
+	applySemaphoreRequest(t, n, 9, &protonpb.SemaphoreRequest{
+		Name:    "jobs",
+		Holder:  &protonpb.SemaphoreHolder{Ticket: 10},
+		Release: true,
+	})
+	<-released
+	assert.Equal(t, used(n.Semaphores()[0]), uint64(1))
+
+	restored := newQuotaNode(t)
+	defer restored.Stop()
+	restored.restore(n.snapshotState())
+	assert.Equal(t, restored.Semaphores(), n.Semaphores())
+
+	// The permits of a session are released when it ends
+	applyProposal(t, n, 10, &LogPair{Key: sessionKey(2)})
+	assert.Empty(t, n.Semaphores())
+
+	_, err := n.TryAcquire(n.Ctx, "jobs", 4, 3, 1)
+	assert.Equal(t, err, ErrTooManyPermits)
+	_, err = n.TryAcquire(n.Ctx, "jobs", 1, 3, 2)
+	assert.Equal(t, err, ErrSessionNotFound)
+}
@@ -0,0 +1,166 @@
+package proton
+
+import (
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	// DefaultSendBufferSize is the number of messages queued
+	// for a member before new ones are dropped
+	DefaultSendBufferSize = 4096
+
+	// heartbeatBufferSize is the number of heartbeats queued for
+	// a member, only the most recent ones are worth sending
+	heartbeatBufferSize = 1
+)
+
+// sender queues the raft messages sent to a member so that a
+// slow member never blocks the main loop. Heartbeats have their
+// own buffer and goroutine, so they are sent even while large
+// appends are in flight and do not trigger an election
+type sender struct {
+	node *Node
+	peer *Peer
+
+	heartbeats chan raftpb.Message
+	messages   chan raftpb.Message
+	stop       chan struct{}
+	wg         sync.WaitGroup
+}
+
+func newSender(n *Node, peer *Peer, size int) *sender {
+	return &sender{
+		node:       n,
+		peer:       peer,
+		heartbeats: make(chan raftpb.Message, heartbeatBufferSize),
+		messages:   make(chan raftpb.Message, size),
+		stop:       make(chan struct{}),
+	}
+}
+
+// isHeartbeat checks if a message is a heartbeat or its response
+func isHeartbeat(m raftpb.Message) bool {
+	return m.Type == raftpb.MsgHeartbeat || m.Type == raftpb.MsgHeartbeatResp
+}
+
+// enqueue queues a message for the member, it returns false
+// if the message was dropped because the member is too slow.
+// A queued heartbeat is replaced by a newer one
+func (s *sender) enqueue(m raftpb.Message) bool {
+	if isHeartbeat(m) {
+		for {
+			select {
+			case s.heartbeats <- m:
+				return true
+			default:
+			}
+			select {
+			case <-s.heartbeats:
+			default:
+			}
+		}
+	}
+
+	select {
+	case s.messages <- m:
+		return true
+	default:
+		return false
+	}
+}
+
+// start sends the heartbeats and the other
+// messages from two separate goroutines
+func (s *sender) start() {
+	s.wg.Add(2)
+	go s.run(s.heartbeats)
+	go s.run(s.messages)
+}
+
+// run sends the messages of a queue until the sender is stopped
+func (s *sender) run(queue <-chan raftpb.Message) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case m := <-queue:
+			s.deliver(m)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// deliver sends a message to the member
+func (s *sender) deliver(m raftpb.Message) {
+	failpoint(FailpointBeforeSend)
+	sent := time.Now()
+	resp, err := s.peer.Client.Send(s.node.Ctx, &m)
+	if err != nil {
+		s.node.ReportUnreachable(s.peer.ID)
+		return
+	}
+	s.node.contacts.touch(s.peer.ID)
+	if resp.LeaderTime != 0 {
+		s.node.clock.observe(s.peer.ID, sent, time.Now(), resp.LeaderTime)
+		s.node.observeLeaderCommit(resp.LeaderCommit)
+	}
+}
+
+// senders holds the sender of each member
+type senders struct {
+	lock    sync.Mutex
+	senders map[uint64]*sender
+}
+
+func newSenders() *senders {
+	return &senders{senders: make(map[uint64]*sender)}
+}
+
+// get returns the sender of a member, starting one if there is
+// none yet or if the member was registered with a new client
+func (s *senders) get(n *Node, peer *Peer) *sender {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sd, ok := s.senders[peer.ID]
+	if ok && sd.peer.Client == peer.Client {
+		return sd
+	}
+	if ok {
+		close(sd.stop)
+	}
+
+	sd = newSender(n, peer, DefaultSendBufferSize)
+	s.senders[peer.ID] = sd
+	sd.start()
+	return sd
+}
+
+// remove stops the sender of a member, the
+// messages still queued are dropped
+func (s *senders) remove(id uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if sd, ok := s.senders[id]; ok {
+		close(sd.stop)
+		delete(s.senders, id)
+	}
+}
+
+// stopAll stops every sender and waits for
+// the messages in flight to be sent
+func (s *senders) stopAll() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for id, sd := range s.senders {
+		close(sd.stop)
+		sd.wg.Wait()
+		delete(s.senders, id)
+	}
+}
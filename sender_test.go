@@ -0,0 +1,76 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSenderEnqueue(t *testing.T) {
+	s := newSender(nil, &Peer{}, 2)
+
+	// Only the latest heartbeat is kept
+	for i := uint64(1); i <= 3; i++ {
+		assert.True(t, s.enqueue(raftpb.Message{Type: raftpb.MsgHeartbeat, Commit: i}))
+	}
+	assert.Equal(t, len(s.heartbeats), 1)
+	assert.Equal(t, (<-s.heartbeats).Commit, uint64(3))
+
+	// Appends are dropped once the buffer is full
+	assert.True(t, s.enqueue(raftpb.Message{Type: raftpb.MsgApp}))
+	assert.True(t, s.enqueue(raftpb.Message{Type: raftpb.MsgApp}))
+	assert.False(t, s.enqueue(raftpb.Message{Type: raftpb.MsgApp}))
+
+	// Heartbeats are still queued when appends are backed up
+	assert.True(t, s.enqueue(raftpb.Message{Type: raftpb.MsgHeartbeatResp}))
+	assert.Equal(t, len(s.heartbeats), 1)
+	assert.Equal(t, len(s.messages), 2)
+}
+
+// blockingTransport blocks the appends until
+// released and records the heartbeats it sends
+type blockingTransport struct {
+	RaftTransportClient
+	release    chan struct{}
+	heartbeats chan raftpb.Message
+}
+
+func (b *blockingTransport) Send(ctx context.Context, m *raftpb.Message, opts ...grpc.CallOption) (*SendResponse, error) {
+	if isHeartbeat(*m) {
+		b.heartbeats <- *m
+	} else {
+		<-b.release
+	}
+	return &SendResponse{Success: true}, nil
+}
+
+func TestSenderHeartbeats(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	transport := &blockingTransport{
+		release:    make(chan struct{}),
+		heartbeats: make(chan raftpb.Message, 1),
+	}
+	s := newSender(n, &Peer{NodeInfo: &protonpb.NodeInfo{ID: 2}, Client: &Raft{RaftTransportClient: transport}}, DefaultSendBufferSize)
+	s.start()
+
+	// The heartbeat goes through while an append is in flight
+	assert.True(t, s.enqueue(raftpb.Message{Type: raftpb.MsgApp}))
+	assert.True(t, s.enqueue(raftpb.Message{Type: raftpb.MsgHeartbeat}))
+	select {
+	case <-transport.heartbeats:
+	case <-time.After(time.Second):
+		t.Fatal("heartbeat blocked behind an append")
+	}
+
+	close(transport.release)
+	close(s.stop)
+	s.wg.Wait()
+}
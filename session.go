@@ -0,0 +1,327 @@
+package proton
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// sessionPrefix is the keyspace holding the client sessions
+	sessionPrefix = systemPrefix + "session/"
+)
+
+var (
+	// ErrSessionNotFound is thrown when using a session that ended or never existed
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// randomID returns a random identifier for a session or a ticket
+func randomID() (uint64, error) {
+	var b [8]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// sessionKey returns the key of a session in the reserved keyspace
+func sessionKey(id uint64) string {
+	return fmt.Sprintf("%s%016x", sessionPrefix, id)
+}
+
+// NewSession starts a client session that ends if no keepalive
+// is received for ttl. The keys written with the session are
+// deleted on every member when it ends. The sessions are checked
+// on every tick of the leader, a new leader gives every session a
+// full ttl to reach it
+func (n *Node) NewSession(ctx context.Context, ttl time.Duration) (uint64, error) {
+	if ttl <= 0 {
+		return 0, ErrInvalidTTL
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return 0, err
+	}
+	session := &protonpb.Session{Id: id, Ttl: int64(ttl)}
+
+	value, err := proto.Marshal(session)
+	if err != nil {
+		return 0, err
+	}
+	data, err := EncodePair(sessionKey(session.Id), value)
+	if err != nil {
+		return 0, err
+	}
+
+	_, _, err = n.ProposeWait(ctx, data)
+	if err != nil {
+		return 0, err
+	}
+	return session.Id, nil
+}
+
+// KeepAlive resets the ttl of a session. The deadlines of the
+// sessions are only tracked by the leader, which is sent the
+// keepalive if this node is a follower
+func (n *Node) KeepAlive(ctx context.Context, id uint64) error {
+	if !n.IsLeader() {
+		peer, ok := n.Cluster.Peers()[n.Leader()]
+		if !ok || peer.ID == n.ID {
+			return ErrNoLeader
+		}
+		resp, err := peer.Client.KeepAliveSession(ctx, &protonpb.KeepAliveSessionRequest{Id: id})
+		if err != nil {
+			return err
+		}
+		if !resp.Success {
+			return errors.New(resp.Error)
+		}
+		return nil
+	}
+
+	n.sessionLock.Lock()
+	defer n.sessionLock.Unlock()
+
+	session, ok := n.sessions[id]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	if n.keepalives == nil {
+		n.keepalives = make(map[uint64]time.Time)
+	}
+	n.keepalives[id] = time.Now().Add(time.Duration(session.Ttl))
+	return nil
+}
+
+// CloseSession ends a session, its keys are deleted on every member
+func (n *Node) CloseSession(ctx context.Context, id uint64) error {
+	if !n.hasSession(id) {
+		return ErrSessionNotFound
+	}
+	return n.proposeSessionEnd(ctx, id)
+}
+
+// proposeSessionEnd replicates the end of a session
+func (n *Node) proposeSessionEnd(ctx context.Context, id uint64) error {
+	data, err := EncodePair(sessionKey(id), nil)
+	if err != nil {
+		return err
+	}
+	return n.proposeRaft(ctx, n.stampProposal(data))
+}
+
+// PutWithSession proposes a value that is deleted
+// once the session ends
+func (n *Node) PutWithSession(ctx context.Context, key string, value []byte, session uint64) error {
+	if !n.hasSession(session) {
+		return ErrSessionNotFound
+	}
+
+	data, err := proto.Marshal(&LogPair{
+		Key:     key,
+		Value:   value,
+		Session: session,
+	})
+	if err != nil {
+		return err
+	}
+	return n.Propose(ctx, data)
+}
+
+// hasSession checks if a session is active
+func (n *Node) hasSession(id uint64) bool {
+	n.sessionLock.RLock()
+	defer n.sessionLock.RUnlock()
+	_, ok := n.sessions[id]
+	return ok
+}
+
+// Sessions returns the active sessions
+func (n *Node) Sessions() []*protonpb.Session {
+	n.sessionLock.RLock()
+	defer n.sessionLock.RUnlock()
+
+	var sessions []*protonpb.Session
+	for _, session := range n.sessions {
+		sessions = append(sessions, session)
+	}
+	sort.Sort(sessionsByID(sessions))
+	return sessions
+}
+
+// setOwner attaches a key of the store to a session
+func (n *Node) setOwner(key string, session uint64) {
+	n.storeLock.Lock()
+	n.owners[key] = session
+	n.storeLock.Unlock()
+}
+
+// sessionKeys returns the keys of the store owned by a session
+func (n *Node) sessionKeys(id uint64) []string {
+	n.storeLock.RLock()
+	defer n.storeLock.RUnlock()
+
+	var keys []string
+	for key, owner := range n.owners {
+		if owner == id {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// applySession starts or ends a committed session,
+// the keys of a session that ended are deleted
+func (n *Node) applySession(entry raftpb.Entry, pair *LogPair) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(pair.Key, sessionPrefix), 16, 64)
+	if err != nil {
+		log.Println("raft: can't decode session id:", err)
+		return
+	}
+
+	if len(pair.Value) == 0 {
+		n.sessionLock.Lock()
+		delete(n.sessions, id)
+		delete(n.keepalives, id)
+		n.sessionLock.Unlock()
+
+		n.releaseSession(id)
+
+		for _, key := range n.sessionKeys(id) {
+			data, err := proto.Marshal(&LogPair{Key: key, Delete: true})
+			if err != nil {
+				log.Println("raft: can't encode deletion of", key, ":", err)
+				continue
+			}
+			n.deleteKey(entry, key, data)
+		}
+		return
+	}
+
+	session := &protonpb.Session{}
+	err = proto.Unmarshal(pair.Value, session)
+	if err != nil {
+		log.Println("raft: can't decode session:", err)
+		return
+	}
+
+	n.sessionLock.Lock()
+	n.sessions[id] = session
+	n.sessionLock.Unlock()
+}
+
+// expireSessions ends the sessions that missed their keepalive.
+// Only the leader tracks the deadlines, a node becoming leader
+// gives every session a full ttl. Called from the main loop on
+// every tick
+func (n *Node) expireSessions() {
+	leader := n.IsLeader()
+	now := time.Now()
+
+	n.sessionLock.Lock()
+	if !leader {
+		n.keepalives = nil
+		n.sessionLock.Unlock()
+		return
+	}
+
+	keepalives := make(map[uint64]time.Time, len(n.sessions))
+	var expired []uint64
+	for id, session := range n.sessions {
+		deadline, ok := n.keepalives[id]
+		if !ok {
+			deadline = now.Add(time.Duration(session.Ttl))
+		}
+		if now.After(deadline) {
+			expired = append(expired, id)
+			// Retried if the end of the session is not applied
+			deadline = now.Add(expiryRetry)
+		}
+		keepalives[id] = deadline
+	}
+	n.keepalives = keepalives
+	n.sessionLock.Unlock()
+
+	if len(expired) == 0 {
+		return
+	}
+
+	// Proposing blocks while there is no leader, which
+	// must not hold up the main loop
+	go func() {
+		ctx, cancel := context.WithTimeout(withInternalPriority(n.Ctx), proposeTimeout)
+		defer cancel()
+		for _, id := range expired {
+			err := n.proposeSessionEnd(ctx, id)
+			if err != nil {
+				log.Println("raft: can't propose end of session:", err)
+				return
+			}
+		}
+	}()
+}
+
+// GrantSession starts a client session in the raft cluster
+func (n *Node) GrantSession(ctx context.Context, req *protonpb.GrantSessionRequest) (*protonpb.GrantSessionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, proposeTimeout)
+	defer cancel()
+
+	id, err := n.NewSession(ctx, time.Duration(req.Ttl))
+	if err != nil {
+		return &protonpb.GrantSessionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.GrantSessionResponse{Success: true, Id: id}, nil
+}
+
+// KeepAliveSession resets the ttl of a session of the raft cluster
+func (n *Node) KeepAliveSession(ctx context.Context, req *protonpb.KeepAliveSessionRequest) (*protonpb.KeepAliveSessionResponse, error) {
+	err := n.KeepAlive(ctx, req.Id)
+	if err != nil {
+		return &protonpb.KeepAliveSessionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.KeepAliveSessionResponse{Success: true}, nil
+}
+
+// RevokeSession ends a session of the raft cluster and deletes its keys
+func (n *Node) RevokeSession(ctx context.Context, req *protonpb.RevokeSessionRequest) (*protonpb.RevokeSessionResponse, error) {
+	err := n.CloseSession(ctx, req.Id)
+	if err != nil {
+		return &protonpb.RevokeSessionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.RevokeSessionResponse{Success: true}, nil
+}
+
+// sessionsByID sorts sessions by id
+type sessionsByID []*protonpb.Session
+
+func (s sessionsByID) Len() int           { return len(s) }
+func (s sessionsByID) Less(i, j int) bool { return s[i].Id < s[j].Id }
+func (s sessionsByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
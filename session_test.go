@@ -0,0 +1,52 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	value, err := proto.Marshal(&protonpb.Session{Id: 42, Ttl: 1000})
+	assert.NoError(t, err)
+	applyProposal(t, n, 3, &LogPair{Key: sessionKey(42), Value: value})
+	assert.Equal(t, n.Sessions(), []*protonpb.Session{{Id: 42, Ttl: 1000}})
+
+	applyProposal(t, n, 4, &LogPair{Key: "svc/a", Value: []byte("a"), Session: 42})
+	applyProposal(t, n, 5, &LogPair{Key: "svc/b", Value: []byte("b"), Session: 42})
+	applyPair(t, n, 6, "svc/c", "c")
+
+	// Writes of an unknown session are ignored
+	applyProposal(t, n, 7, &LogPair{Key: "svc/d", Value: []byte("d"), Session: 7})
+	assert.Equal(t, n.Get("svc/d"), "")
+
+	// Writing a key without the session detaches it
+	applyPair(t, n, 8, "svc/b", "kept")
+	assert.Equal(t, n.sessionKeys(42), []string{"svc/a"})
+
+	restored := newQuotaNode(t)
+	defer restored.Stop()
+	restored.restore(n.snapshotState())
+	assert.Equal(t, restored.Sessions(), n.Sessions())
+	assert.Equal(t, restored.sessionKeys(42), []string{"svc/a"})
+
+	changes, cancel := n.Subscribe("svc/", 10)
+	defer cancel()
+
+	// The keys of a session are deleted when it ends
+	applyProposal(t, n, 9, &LogPair{Key: sessionKey(42)})
+	assert.Empty(t, n.Sessions())
+	assert.Equal(t, n.Get("svc/a"), "")
+	assert.Equal(t, n.Get("svc/b"), "kept")
+	assert.Equal(t, n.Get("svc/c"), "c")
+
+	change := <-changes
+	assert.Equal(t, change.Type, protonpb.ChangeType_DELETE)
+	assert.Equal(t, change.Pair.Key, "svc/a")
+	assert.Equal(t, n.KeepAlive(n.Ctx, 42), ErrNoLeader)
+}
@@ -0,0 +1,528 @@
+package proton
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+var (
+	// ErrSimulatedMemberExists is thrown when adding a member to a simulation with an id already taken
+	ErrSimulatedMemberExists = errors.New("member already exists in the simulation")
+)
+
+// SimulationConfig is the cluster and the network model of a simulation
+type SimulationConfig struct {
+	// Members is the number of members the cluster starts with,
+	// their ids go from 1 to Members
+	Members int
+	// Seed is the seed of the faults and latencies of the
+	// network. Raft draws its election timeouts on its own,
+	// runs with the same seed may elect other leaders
+	Seed int64
+	// MinLatency and MaxLatency bound the number of ticks
+	// a message takes to be delivered
+	MinLatency int
+	MaxLatency int
+	// DropRate is the probability of a message being lost
+	DropRate float64
+	// Raft is the config of the members, DefaultNodeConfig if nil
+	Raft *raft.Config
+	// Apply is the handler of the entries applied by the members
+	Apply ApplyCommand
+}
+
+// simMember is a member of a simulation, its store
+// is kept when it crashes as if it was on disk
+type simMember struct {
+	node    *Node
+	raw     *raft.RawNode
+	crashed bool
+	removed bool
+}
+
+// simMessage is a message in flight, delivered at the tick at
+type simMessage struct {
+	at  uint64
+	seq uint64
+	msg raftpb.Message
+}
+
+// simNetwork holds the messages in flight by delivery tick
+type simNetwork []*simMessage
+
+func (q simNetwork) Len() int { return len(q) }
+func (q simNetwork) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	return q[i].seq < q[j].seq
+}
+func (q simNetwork) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *simNetwork) Push(x interface{}) { *q = append(*q, x.(*simMessage)) }
+func (q *simNetwork) Pop() interface{} {
+	old := *q
+	m := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return m
+}
+
+// Simulation runs the members of a cluster in a single process
+// on virtual time: raft is driven tick by tick and the messages
+// go through a simulated network with latency, losses and
+// partitions. The members apply the entries as a node would, so
+// that replication and membership changes can be tested many
+// times over with random faults, much faster than on the wall
+// clock. A simulation is not safe for concurrent use
+type Simulation struct {
+	cfg       SimulationConfig
+	rand      *rand.Rand
+	now       uint64
+	seq       uint64
+	members   map[uint64]*simMember
+	network   simNetwork
+	partition map[uint64]int
+
+	// leaders holds the leader seen at every term and applied the
+	// first entry applied at every index, to check that members agree
+	leaders   map[uint64]uint64
+	applied   map[uint64]raftpb.Entry
+	violation error
+}
+
+// NewSimulation starts a simulated cluster
+func NewSimulation(cfg SimulationConfig) (*Simulation, error) {
+	if cfg.Raft == nil {
+		cfg.Raft = DefaultNodeConfig()
+	}
+	if cfg.MaxLatency < cfg.MinLatency {
+		cfg.MaxLatency = cfg.MinLatency
+	}
+
+	s := &Simulation{
+		cfg:     cfg,
+		rand:    rand.New(rand.NewSource(cfg.Seed)),
+		members: make(map[uint64]*simMember),
+		leaders: make(map[uint64]uint64),
+		applied: make(map[uint64]raftpb.Entry),
+	}
+
+	var peers []raft.Peer
+	for id := uint64(1); id <= uint64(cfg.Members); id++ {
+		peers = append(peers, raft.Peer{ID: id})
+	}
+	for _, peer := range peers {
+		err := s.start(peer.ID, raft.NewMemoryStorage(), peers)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// start runs a member on the entries persisted in store
+func (s *Simulation) start(id uint64, store *raft.MemoryStorage, peers []raft.Peer) error {
+	n, err := newNodeState(id, fmt.Sprintf("member-%x:4000", id), s.cfg.Raft, s.cfg.Apply, store)
+	if err != nil {
+		return err
+	}
+	n.ticker.Stop()
+
+	snapshot, err := store.Snapshot()
+	if err != nil {
+		return err
+	}
+	if !raft.IsEmptySnap(snapshot) {
+		n.processSnapshot(snapshot)
+	}
+
+	raw, err := raft.NewRawNode(n.Cfg, peers)
+	if err != nil {
+		return err
+	}
+
+	removed := false
+	if m, ok := s.members[id]; ok {
+		removed = m.removed
+	}
+	s.members[id] = &simMember{node: n, raw: raw, removed: removed}
+	return nil
+}
+
+// Now returns the number of ticks elapsed
+func (s *Simulation) Now() uint64 {
+	return s.now
+}
+
+// Rand returns the source of randomness of the network, to
+// draw the faults of a test from the seed of the simulation
+func (s *Simulation) Rand() *rand.Rand {
+	return s.rand
+}
+
+// Members returns the ids of the members that did not crash
+// and were not removed, in ascending order
+func (s *Simulation) Members() []uint64 {
+	var ids []uint64
+	for id, m := range s.members {
+		if !m.crashed && !m.removed {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(idsAscending(ids))
+	return ids
+}
+
+// Member returns the node of a member to read its state
+func (s *Simulation) Member(id uint64) *Node {
+	m, ok := s.members[id]
+	if !ok {
+		return nil
+	}
+	return m.node
+}
+
+// Leader returns the leader with the highest term, 0 if none
+func (s *Simulation) Leader() uint64 {
+	var leader, term uint64
+	for _, id := range s.Members() {
+		status := s.members[id].raw.Status()
+		if status.RaftState == raft.StateLeader && status.Term > term {
+			leader, term = id, status.Term
+		}
+	}
+	return leader
+}
+
+// Run advances the simulation by a number of ticks
+func (s *Simulation) Run(ticks int) {
+	for i := 0; i < ticks; i++ {
+		s.Tick()
+	}
+}
+
+// Tick advances the simulation by one tick: every member ticks,
+// then the messages due are delivered and the members handle
+// their ready state until nothing is left to do for this tick
+func (s *Simulation) Tick() {
+	s.now++
+	for _, id := range s.Members() {
+		s.members[id].raw.Tick()
+	}
+	s.settle()
+}
+
+// settle delivers the messages due and handles the
+// ready state of the members until they are idle
+func (s *Simulation) settle() {
+	for {
+		progressed := s.deliver()
+		for _, id := range s.Members() {
+			m := s.members[id]
+			for m.raw.HasReady() && !m.removed {
+				s.handle(id, m)
+				progressed = true
+			}
+		}
+		s.checkLeaders()
+		if !progressed {
+			return
+		}
+	}
+}
+
+// Put proposes a write to the leader, the proposal is lost
+// if the leader is deposed before it is committed
+func (s *Simulation) Put(key string, value string) error {
+	leader := s.Leader()
+	if leader == 0 {
+		return ErrNoLeader
+	}
+	n := s.members[leader].node
+
+	data, err := proto.Marshal(&LogPair{Key: key, Value: []byte(value)})
+	if err != nil {
+		return err
+	}
+	data, err = n.prepareProposal(data)
+	if err != nil {
+		return err
+	}
+	err = s.members[leader].raw.Propose(data)
+	s.settle()
+	return err
+}
+
+// AddMember proposes to add a member to the cluster, it starts
+// empty and catches up once the change is applied
+func (s *Simulation) AddMember(id uint64) error {
+	if _, ok := s.members[id]; ok {
+		return ErrSimulatedMemberExists
+	}
+	err := s.proposeConfChange(raftpb.ConfChange{Type: raftpb.ConfChangeAddNode, NodeID: id})
+	if err != nil {
+		return err
+	}
+	return s.start(id, raft.NewMemoryStorage(), nil)
+}
+
+// RemoveMember proposes to remove a member from the cluster,
+// it stops once the change is applied by any member
+func (s *Simulation) RemoveMember(id uint64) error {
+	if _, ok := s.members[id]; !ok {
+		return ErrMemberNotFound
+	}
+	return s.proposeConfChange(raftpb.ConfChange{Type: raftpb.ConfChangeRemoveNode, NodeID: id})
+}
+
+// proposeConfChange proposes a configuration change to the leader
+func (s *Simulation) proposeConfChange(cc raftpb.ConfChange) error {
+	leader := s.Leader()
+	if leader == 0 {
+		return ErrNoLeader
+	}
+	err := s.members[leader].raw.ProposeConfChange(cc)
+	s.settle()
+	return err
+}
+
+// Crash stops a member, its store is kept for a restart
+// and the messages sent to it are lost
+func (s *Simulation) Crash(id uint64) {
+	if m, ok := s.members[id]; ok {
+		m.crashed = true
+	}
+}
+
+// Restart starts a crashed member again with the entries and
+// the snapshot it persisted, its applied state is rebuilt
+func (s *Simulation) Restart(id uint64) error {
+	m, ok := s.members[id]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if !m.crashed {
+		return nil
+	}
+	return s.start(id, m.node.Store, nil)
+}
+
+// Partition splits the network into groups of members that can
+// only reach each other, the members missing from the groups
+// are isolated. It replaces the previous partition
+func (s *Simulation) Partition(groups ...[]uint64) {
+	s.partition = make(map[uint64]int)
+	for i, group := range groups {
+		for _, id := range group {
+			s.partition[id] = i + 1
+		}
+	}
+}
+
+// Heal removes the partition of the network
+func (s *Simulation) Heal() {
+	s.partition = nil
+}
+
+// reachable checks if a message from a member can get to another
+func (s *Simulation) reachable(from, to uint64) bool {
+	if s.partition == nil {
+		return true
+	}
+	group, ok := s.partition[from]
+	return ok && s.partition[to] == group
+}
+
+// send puts a message in flight or drops it
+func (s *Simulation) send(m raftpb.Message) {
+	if !s.reachable(m.From, m.To) || s.rand.Float64() < s.cfg.DropRate {
+		s.drop(m)
+		return
+	}
+
+	latency := s.cfg.MinLatency
+	if s.cfg.MaxLatency > s.cfg.MinLatency {
+		latency += s.rand.Intn(s.cfg.MaxLatency - s.cfg.MinLatency + 1)
+	}
+	s.seq++
+	heap.Push(&s.network, &simMessage{at: s.now + uint64(latency), seq: s.seq, msg: m})
+}
+
+// drop loses a message, the sender of a snapshot is told it failed
+func (s *Simulation) drop(m raftpb.Message) {
+	sender, ok := s.members[m.From]
+	if !ok || sender.crashed {
+		return
+	}
+	sender.raw.ReportUnreachable(m.To)
+	if m.Type == raftpb.MsgSnap {
+		sender.raw.ReportSnapshot(m.To, raft.SnapshotFailure)
+	}
+}
+
+// deliver steps the members with the messages due, it
+// returns if any message was delivered
+func (s *Simulation) deliver() bool {
+	delivered := false
+	for len(s.network) > 0 && s.network[0].at <= s.now {
+		m := heap.Pop(&s.network).(*simMessage).msg
+
+		to, ok := s.members[m.To]
+		if !ok || to.crashed || to.removed || !s.reachable(m.From, m.To) {
+			s.drop(m)
+			continue
+		}
+		to.raw.Step(m)
+		delivered = true
+
+		if m.Type == raftpb.MsgSnap {
+			if sender, ok := s.members[m.From]; ok && !sender.crashed {
+				sender.raw.ReportSnapshot(m.To, raft.SnapshotFinish)
+			}
+		}
+	}
+	return delivered
+}
+
+// handle persists, sends and applies the ready state of a member
+// the way the main loop of a node does
+func (s *Simulation) handle(id uint64, m *simMember) {
+	n := m.node
+	rd := m.raw.Ready()
+
+	n.saveToStorage(rd.HardState, rd.Entries, rd.Snapshot)
+	for _, msg := range rd.Messages {
+		s.send(msg)
+	}
+	if !raft.IsEmptySnap(rd.Snapshot) {
+		n.processSnapshot(rd.Snapshot)
+	}
+
+	for _, entry := range rd.CommittedEntries {
+		s.checkApplied(id, entry)
+		n.process(entry)
+		atomic.StoreUint64(&n.appliedIndex, entry.Index)
+
+		if entry.Type == raftpb.EntryConfChange {
+			var cc raftpb.ConfChange
+			err := cc.Unmarshal(entry.Data)
+			if err != nil {
+				s.fail(fmt.Errorf("member %x can't decode configuration change at %d: %v", id, entry.Index, err))
+				continue
+			}
+			n.confState = *m.raw.ApplyConfChange(cc)
+			if removed, ok := s.members[cc.NodeID]; ok && cc.Type == raftpb.ConfChangeRemoveNode {
+				removed.removed = true
+			}
+		}
+	}
+	m.raw.Advance(rd)
+
+	if n.appliedIndex-n.snapshotIndex >= n.SnapshotCount {
+		err := n.createSnapshot()
+		if err != nil {
+			s.fail(fmt.Errorf("member %x can't create snapshot: %v", id, err))
+		}
+	}
+}
+
+// checkApplied checks that an entry is the same as the one
+// applied at its index by the other members
+func (s *Simulation) checkApplied(id uint64, entry raftpb.Entry) {
+	first, ok := s.applied[entry.Index]
+	if !ok {
+		s.applied[entry.Index] = entry
+		return
+	}
+	if first.Term != entry.Term || first.Type != entry.Type || !bytes.Equal(first.Data, entry.Data) {
+		s.fail(fmt.Errorf("member %x applied entry %d of term %d, another member applied it at term %d", id, entry.Index, entry.Term, first.Term))
+	}
+}
+
+// checkLeaders checks that no two members lead in the same term
+func (s *Simulation) checkLeaders() {
+	for _, id := range s.Members() {
+		status := s.members[id].raw.Status()
+		if status.RaftState != raft.StateLeader {
+			continue
+		}
+		leader, ok := s.leaders[status.Term]
+		if !ok {
+			s.leaders[status.Term] = id
+		} else if leader != id {
+			s.fail(fmt.Errorf("members %x and %x both lead term %d", leader, id, status.Term))
+		}
+	}
+}
+
+// fail records the first safety violation of the simulation
+func (s *Simulation) fail(err error) {
+	if s.violation == nil {
+		s.violation = fmt.Errorf("tick %d: %v", s.now, err)
+	}
+}
+
+// Check returns the first safety violation seen since the
+// simulation started: two leaders in the same term, or two
+// members applying different entries at the same index
+func (s *Simulation) Check() error {
+	return s.violation
+}
+
+// Converged checks if there is a leader and every member of
+// its configuration that did not crash applied the whole log of
+// the leader, so that they hold the same keys and values
+func (s *Simulation) Converged() bool {
+	leader := s.Leader()
+	if leader == 0 {
+		return false
+	}
+	first := s.members[leader].node
+	status := s.members[leader].raw.Status()
+	if first.AppliedIndex() != status.Progress[leader].Match {
+		return false
+	}
+
+	var ids []uint64
+	for _, id := range first.confState.Nodes {
+		if m, ok := s.members[id]; ok && !m.crashed && id != leader {
+			ids = append(ids, id)
+		}
+	}
+
+	pairs := make(map[string]string)
+	for _, pair := range first.ListPairs() {
+		pairs[pair.Key] = string(pair.Value)
+	}
+	for _, id := range ids {
+		n := s.members[id].node
+		if n.AppliedIndex() != first.AppliedIndex() {
+			return false
+		}
+		other := n.ListPairs()
+		if len(other) != len(pairs) {
+			return false
+		}
+		for _, pair := range other {
+			if value, ok := pairs[pair.Key]; !ok || value != string(pair.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// idsAscending sorts ids in ascending order
+type idsAscending []uint64
+
+func (ids idsAscending) Len() int           { return len(ids) }
+func (ids idsAscending) Less(i, j int) bool { return ids[i] < ids[j] }
+func (ids idsAscending) Swap(i, j int)      { ids[i], ids[j] = ids[j], ids[i] }
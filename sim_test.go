@@ -0,0 +1,162 @@
+package proton
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSimulation(t *testing.T, seed int64) *Simulation {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+	cfg.ElectionTick = 10
+	// A removed member that campaigned can leave a member on a higher
+	// term than the leader, which only learns of it with CheckQuorum
+	cfg.CheckQuorum = true
+
+	s, err := NewSimulation(SimulationConfig{
+		Members:    5,
+		Seed:       seed,
+		MinLatency: 0,
+		MaxLatency: 2,
+		DropRate:   0.05,
+		Raft:       cfg,
+	})
+	assert.NoError(t, err)
+	return s
+}
+
+// waitLeader runs a simulation until it elects a leader
+func waitLeader(s *Simulation) uint64 {
+	for i := 0; i < 1000 && s.Leader() == 0; i++ {
+		s.Tick()
+	}
+	return s.Leader()
+}
+
+// waitConverged runs a simulation until its members converge
+func waitConverged(s *Simulation) bool {
+	for i := 0; i < 5000; i++ {
+		if s.Converged() {
+			return true
+		}
+		s.Tick()
+	}
+	return false
+}
+
+func TestSimulationReplicates(t *testing.T) {
+	s := newSimulation(t, 1)
+	assert.NotZero(t, waitLeader(s))
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, s.Put(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)))
+	}
+	assert.True(t, waitConverged(s))
+	assert.NoError(t, s.Check())
+
+	for _, id := range s.Members() {
+		assert.Equal(t, s.Member(id).Get("key9"), "value9")
+	}
+}
+
+func TestSimulationPartition(t *testing.T) {
+	s := newSimulation(t, 7)
+	leader := waitLeader(s)
+	assert.NotZero(t, leader)
+
+	// The leader ends up in the minority
+	var majority []uint64
+	for _, id := range s.Members() {
+		if id != leader {
+			majority = append(majority, id)
+		}
+	}
+	s.Partition([]uint64{leader}, majority)
+	s.Run(200)
+	assert.NotEqual(t, s.Leader(), leader)
+
+	assert.NoError(t, s.Put("key", "value"))
+	s.Heal()
+	assert.True(t, waitConverged(s))
+	assert.Equal(t, s.Member(leader).Get("key"), "value")
+	assert.NoError(t, s.Check())
+}
+
+func TestSimulationMembership(t *testing.T) {
+	s := newSimulation(t, 3)
+	waitLeader(s)
+
+	assert.NoError(t, s.AddMember(6))
+	assert.Equal(t, s.AddMember(6), ErrSimulatedMemberExists)
+	assert.NoError(t, s.Put("key", "value"))
+	assert.True(t, waitConverged(s))
+	assert.Equal(t, s.Member(6).Get("key"), "value")
+
+	assert.NoError(t, s.RemoveMember(1))
+	assert.True(t, waitConverged(s))
+	assert.NotContains(t, s.Members(), uint64(1))
+	assert.Equal(t, s.Member(s.Leader()).confState.Nodes, []uint64{2, 3, 4, 5, 6})
+	assert.NoError(t, s.Check())
+}
+
+// TestSimulationProperties runs random writes, partitions, crashes
+// and membership changes, the members must never disagree and
+// must converge once the faults are gone
+func TestSimulationProperties(t *testing.T) {
+	seeds := 200
+	if testing.Short() {
+		seeds = 20
+	}
+
+	for seed := 0; seed < seeds; seed++ {
+		s := newSimulation(t, int64(seed))
+		r := s.Rand()
+		next := uint64(6)
+
+		for step := 0; step < 200; step++ {
+			members := s.Members()
+			switch op := r.Intn(100); {
+			case op < 60:
+				s.Put(fmt.Sprintf("key%d", r.Intn(20)), fmt.Sprintf("%d-%d", seed, step))
+			case op < 70 && len(members) > 0:
+				r.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+				cut := r.Intn(len(members))
+				s.Partition(members[:cut], members[cut:])
+			case op < 80:
+				s.Heal()
+			case op < 85 && len(members) > 0:
+				s.Crash(members[r.Intn(len(members))])
+			case op < 90:
+				for id := uint64(1); id < next; id++ {
+					s.Restart(id)
+				}
+			case op < 95:
+				if s.AddMember(next) != ErrNoLeader {
+					next++
+				}
+			case len(members) > 3 && len(s.Member(members[0]).confState.Nodes) > 3:
+				// A cluster of 2 can't elect a leader if the member left
+				// does not learn that the removal of the other is committed
+				s.RemoveMember(members[r.Intn(len(members))])
+			}
+			s.Run(1 + r.Intn(5))
+
+			if err := s.Check(); err != nil {
+				t.Fatalf("seed %d, step %d: %v", seed, step, err)
+			}
+		}
+
+		s.Heal()
+		for id := uint64(1); id < next; id++ {
+			s.Restart(id)
+		}
+		if !waitConverged(s) {
+			t.Fatalf("seed %d: members did not converge", seed)
+		}
+		if err := s.Check(); err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+	}
+}
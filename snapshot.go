@@ -0,0 +1,600 @@
+package proton
+
+import (
+	"errors"
+	"hash/crc32"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// DefaultSnapshotCount is the number of applied
+	// entries after which a snapshot is taken
+	DefaultSnapshotCount = 10000
+
+	// snapshotCatchUpEntries is the number of entries kept in
+	// the log after a snapshot, so that slow followers can
+	// catch up without receiving the whole snapshot
+	snapshotCatchUpEntries = 5000
+
+	// DefaultMaxSnapshotTransfers is the number of snapshots
+	// that can be sent to members at the same time
+	DefaultMaxSnapshotTransfers = 1
+
+	// snapshotChunkSize is the size of the chunks a snapshot is
+	// sent in when the bandwidth of the transfers is capped
+	snapshotChunkSize = 64 << 10
+)
+
+var (
+	// ErrSnapshotCorrupt is thrown when the checksum of a snapshot does not match its payload
+	ErrSnapshotCorrupt = errors.New("snapshot checksum mismatch, refusing to load a corrupt snapshot")
+	// ErrSnapshotAhead is thrown when an incremental snapshot starts after the applied state of a member
+	ErrSnapshotAhead = errors.New("incremental snapshot is ahead of the applied state")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// SnapshotFunc returns the state the application derived from the
+// committed entries, to be saved along with the store in a snapshot
+type SnapshotFunc func() ([]byte, error)
+
+// RestoreFunc restores the state of the application from
+// the payload of a snapshot sent by the leader
+type RestoreFunc func([]byte) error
+
+// SetSnapshotHandlers sets the functions saving and restoring
+// the state of the application in snapshots. The snapshot
+// function is called from the main loop, once every entry up
+// to the snapshot index went through the apply handler
+func (n *Node) SetSnapshotHandlers(snapshot SnapshotFunc, restore RestoreFunc) {
+	n.snapshotLock.Lock()
+	n.snapshotFunc = snapshot
+	n.restoreFunc = restore
+	n.snapshotLock.Unlock()
+}
+
+// encodeSnapshot encodes the state of a node along
+// with the checksum of the payload
+func encodeSnapshot(state *StoreSnapshot) ([]byte, error) {
+	data, err := proto.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(&SnapshotData{
+		State:    data,
+		Checksum: crc32.Checksum(data, crcTable),
+	})
+}
+
+// decodeSnapshot verifies the checksum of a snapshot
+// payload and decodes the state it holds
+func decodeSnapshot(data []byte) (*StoreSnapshot, error) {
+	snapshot := &SnapshotData{}
+	err := proto.Unmarshal(data, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if crc32.Checksum(snapshot.State, crcTable) != snapshot.Checksum {
+		return nil, ErrSnapshotCorrupt
+	}
+
+	state := &StoreSnapshot{}
+	err = proto.Unmarshal(snapshot.State, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// snapshotState returns the state of the node to save in a snapshot
+func (n *Node) snapshotState() *StoreSnapshot {
+	state := &StoreSnapshot{
+		Alarms:     n.Alarms(),
+		Events:     n.AuditEvents(0),
+		Sessions:   n.Sessions(),
+		Semaphores: n.Semaphores(),
+		Parts:      n.pendingParts(),
+		RateLimits: n.RateLimits(),
+		Hlc:        n.HLC(),
+		ClusterId:  n.ClusterID(),
+		JoinTokens: n.JoinTokens(),
+		SealIndex:  atomic.LoadUint64(&n.sealIndex),
+	}
+
+	n.storeLock.RLock()
+	for k, v := range n.pstore {
+		if digest, ok := n.digests[k]; ok {
+			state.Pairs = append(state.Pairs, &LogPair{Key: k, Digest: []byte(digest)})
+		} else {
+			state.Pairs = append(state.Pairs, &LogPair{Key: k, Value: []byte(v)})
+		}
+		state.Revisions = append(state.Revisions, n.revisions[k])
+		state.Expiries = append(state.Expiries, n.expiries[k])
+		state.Owners = append(state.Owners, n.owners[k])
+	}
+	state.Blobs = n.snapshotBlobs()
+	state.Revision = n.revision
+	n.storeLock.RUnlock()
+
+	state.Members = n.Cluster.Members()
+	return state
+}
+
+// maybeSnapshot takes a snapshot once enough entries were
+// applied since the last one. Called from the main loop
+// so that the state matches the applied index
+func (n *Node) maybeSnapshot() {
+	if n.SnapshotCount == 0 || n.appliedIndex-n.snapshotIndex < n.SnapshotCount {
+		return
+	}
+
+	err := n.createSnapshot()
+	if err != nil {
+		log.Println("raft: can't create snapshot:", err)
+	}
+}
+
+// snapshotData returns the encoded snapshot of the
+// store along with the state of the application
+func (n *Node) snapshotData() ([]byte, error) {
+	state := n.snapshotState()
+
+	n.snapshotLock.Lock()
+	snapshot := n.snapshotFunc
+	n.snapshotLock.Unlock()
+	if snapshot != nil {
+		payload, err := snapshot()
+		if err != nil {
+			return nil, err
+		}
+		state.Payload = payload
+	}
+
+	return encodeSnapshot(state)
+}
+
+// createSnapshot takes a snapshot at the last applied index and compacts the log
+func (n *Node) createSnapshot() error {
+	data, err := n.snapshotData()
+	if err != nil {
+		return err
+	}
+	failpoint(FailpointDuringSnapshot)
+
+	snapshot, err := n.Store.CreateSnapshot(n.appliedIndex, &n.confState, data)
+	if err != nil {
+		return err
+	}
+	n.snapshotIndex = n.appliedIndex
+	n.compactHistory()
+	n.observers.notify(func(o Observer) { o.OnSnapshot(snapshot.Metadata, false) })
+
+	if n.appliedIndex > snapshotCatchUpEntries {
+		err = n.Store.Compact(n.appliedIndex - snapshotCatchUpEntries)
+		if err != nil && err != raft.ErrCompacted {
+			return err
+		}
+	}
+	return nil
+}
+
+// processSnapshot restores the state of the node from a
+// snapshot sent by the leader. Snapshots are verified when
+// received, a corrupt one at this point is fatal
+func (n *Node) processSnapshot(snapshot raftpb.Snapshot) {
+	state, err := decodeSnapshot(snapshot.Data)
+	if err != nil {
+		log.Fatalf("raft: can't restore snapshot on node %v: %v", n.ID, err)
+	}
+
+	n.restore(state)
+	n.resetHistory()
+
+	n.snapshotLock.Lock()
+	restore := n.restoreFunc
+	n.snapshotLock.Unlock()
+	// The application state is already past the snapshot
+	if restore != nil && snapshot.Metadata.Index > n.replayIndex {
+		err = restore(state.Payload)
+		if err != nil {
+			log.Fatalf("raft: can't restore application state on node %v: %v", n.ID, err)
+		}
+	}
+
+	atomic.StoreUint64(&n.appliedIndex, snapshot.Metadata.Index)
+	n.snapshotIndex = snapshot.Metadata.Index
+	n.confState = snapshot.Metadata.ConfState
+	n.observers.notify(func(o Observer) { o.OnSnapshot(snapshot.Metadata, true) })
+}
+
+// restore replaces the state of the node with the one of
+// a snapshot, an incremental snapshot only overwrites the
+// keys that were written since its base index
+func (n *Node) restore(state *StoreSnapshot) {
+	n.storeLock.Lock()
+	if state.Since == 0 {
+		n.pstore = make(map[string]string)
+		n.revisions = make(map[string]uint64)
+		n.expiries = make(map[string]int64)
+		n.owners = make(map[string]uint64)
+		n.digests = make(map[string]string)
+		n.usage = usage{}
+		n.nsUsage = make(map[string]usage)
+	} else if len(state.Keys) > 0 {
+		// Keys deleted since the last replicated index
+		keys := make(map[string]bool, len(state.Keys))
+		for _, key := range state.Keys {
+			keys[key] = true
+		}
+		for key, old := range n.pstore {
+			if !keys[key] {
+				n.unaccount(key, old)
+				delete(n.pstore, key)
+				delete(n.revisions, key)
+				delete(n.expiries, key)
+				delete(n.owners, key)
+				delete(n.digests, key)
+			}
+		}
+	}
+	n.revision = state.Revision
+	blobs := blobsByDigest(state.Blobs)
+	for i, pair := range state.Pairs {
+		value := string(pair.Value)
+		delete(n.digests, pair.Key)
+		if len(pair.Digest) > 0 {
+			value = string(blobs[string(pair.Digest)])
+			n.digests[pair.Key] = string(pair.Digest)
+		}
+		old, exists := n.pstore[pair.Key]
+		n.account(pair.Key, old, exists, value)
+		n.pstore[pair.Key] = value
+		if i < len(state.Revisions) {
+			n.revisions[pair.Key] = state.Revisions[i]
+		}
+		delete(n.expiries, pair.Key)
+		if i < len(state.Expiries) && state.Expiries[i] != 0 {
+			n.expiries[pair.Key] = state.Expiries[i]
+		}
+		delete(n.owners, pair.Key)
+		if i < len(state.Owners) && state.Owners[i] != 0 {
+			n.owners[pair.Key] = state.Owners[i]
+		}
+	}
+	n.restoreBlobs()
+	n.storeLock.Unlock()
+
+	n.alarmLock.Lock()
+	n.alarms = make(map[string]*protonpb.Alarm)
+	for _, alarm := range state.Alarms {
+		n.alarms[alarmKey(alarm)] = alarm
+	}
+	n.alarmLock.Unlock()
+
+	n.auditLock.Lock()
+	n.audit = n.trimAudit(state.Events)
+	n.auditLock.Unlock()
+
+	n.sessionLock.Lock()
+	n.sessions = make(map[uint64]*protonpb.Session)
+	for _, session := range state.Sessions {
+		n.sessions[session.Id] = session
+	}
+	n.sessionLock.Unlock()
+
+	n.restoreParts(state.Parts)
+
+	n.semaphoreLock.Lock()
+	n.semaphores = make(map[string]*protonpb.Semaphore)
+	for _, semaphore := range state.Semaphores {
+		n.semaphores[semaphore.Name] = semaphore
+	}
+	n.notifyRelease()
+	n.semaphoreLock.Unlock()
+
+	n.restoreRateLimits(state.RateLimits)
+	n.restoreJoinTokens(state.JoinTokens)
+	n.setHLC(state.Hlc)
+	n.setClusterID(state.ClusterId)
+	atomic.CompareAndSwapUint64(&n.sealIndex, 0, state.SealIndex)
+
+	peers := n.Cluster.Peers()
+	members := make(map[uint64]bool)
+	for _, member := range state.Members {
+		members[member.ID] = true
+		if peer, ok := peers[member.ID]; ok && peer.Addr == member.Addr || member.ID == n.ID || member.ID == raft.None {
+			continue
+		}
+		err := n.RegisterNode(n.Ctx, member)
+		if err != nil {
+			log.Println("raft: can't register member from snapshot:", err)
+		}
+	}
+	for id := range peers {
+		if id != n.ID && !members[id] {
+			n.UnregisterNode(id)
+		}
+	}
+}
+
+// verifySnapshot checks the integrity of a snapshot received
+// from the leader before it is handed over to raft
+func (n *Node) verifySnapshot(msg *raftpb.Message) error {
+	if msg.Type != raftpb.MsgSnap {
+		return nil
+	}
+
+	state, err := decodeSnapshot(msg.Snapshot.Data)
+	if err == nil && state.Since > atomic.LoadUint64(&n.appliedIndex) {
+		err = ErrSnapshotAhead
+	}
+	if err != nil {
+		log.Printf("raft: rejected snapshot at index %d from %x: %v", msg.Snapshot.Metadata.Index, msg.From, err)
+	}
+	return err
+}
+
+// incrementalSnapshot reduces a snapshot sent to a member to the
+// keys written since the last index replicated on the member. The
+// full snapshot is sent if history is missing or a previous attempt
+// failed
+func (n *Node) incrementalSnapshot(m *raftpb.Message) {
+	n.snapshotLock.Lock()
+	full := n.fullSnapshots[m.To]
+	n.snapshotLock.Unlock()
+	if !n.IncrementalSnapshots || full {
+		return
+	}
+
+	pr, ok := n.Status().Progress[m.To]
+	if !ok {
+		return
+	}
+	since := pr.Match
+	if since == 0 {
+		// A promoted standby already has the state up to its join
+		since = n.joinIndex(m.To, m.Snapshot.Metadata.Index)
+	}
+	if since == 0 {
+		return
+	}
+
+	state, err := decodeSnapshot(m.Snapshot.Data)
+	if err != nil || len(state.Revisions) != len(state.Pairs) {
+		return
+	}
+
+	data, err := encodeSnapshot(deltaSnapshot(state, since))
+	if err != nil {
+		return
+	}
+	m.Snapshot.Data = data
+}
+
+// deltaSnapshot returns the part of a snapshot written after an
+// index, along with every key to remove the ones deleted since
+func deltaSnapshot(state *StoreSnapshot, since uint64) *StoreSnapshot {
+	// Without keys there is nothing to save and
+	// nothing that tells the deleted keys apart
+	if len(state.Pairs) == 0 {
+		return state
+	}
+
+	delta := &StoreSnapshot{
+		Members:    state.Members,
+		Alarms:     state.Alarms,
+		Events:     state.Events,
+		Sessions:   state.Sessions,
+		Semaphores: state.Semaphores,
+		Parts:      state.Parts,
+		RateLimits: state.RateLimits,
+		Hlc:        state.Hlc,
+		ClusterId:  state.ClusterId,
+		JoinTokens: state.JoinTokens,
+		SealIndex:  state.SealIndex,
+		Since:      since,
+		Payload:    state.Payload,
+		Revision:   state.Revision,
+	}
+	blobs := blobsByDigest(state.Blobs)
+	for i, pair := range state.Pairs {
+		delta.Keys = append(delta.Keys, pair.Key)
+		if state.Revisions[i] > since {
+			delta.Pairs = append(delta.Pairs, pair)
+			// Only the values stored by content of the pairs sent
+			if value, ok := blobs[string(pair.Digest)]; ok {
+				delta.Blobs = append(delta.Blobs, &Blob{Digest: pair.Digest, Value: value})
+				delete(blobs, string(pair.Digest))
+			}
+			delta.Revisions = append(delta.Revisions, state.Revisions[i])
+			if i < len(state.Expiries) {
+				delta.Expiries = append(delta.Expiries, state.Expiries[i])
+			}
+			if i < len(state.Owners) {
+				delta.Owners = append(delta.Owners, state.Owners[i])
+			}
+		}
+	}
+	return delta
+}
+
+// reportSnapshot reports the outcome of sending a snapshot to a member
+func (n *Node) reportSnapshot(to uint64, resp *SendResponse, err error) {
+	n.snapshotLock.Lock()
+	defer n.snapshotLock.Unlock()
+
+	if err == nil && resp.Error == "" {
+		delete(n.fullSnapshots, to)
+		delete(n.joinIndexes, to)
+		n.ReportSnapshot(to, raft.SnapshotFinish)
+		return
+	}
+
+	// Fall back to a full snapshot on the next attempt
+	n.fullSnapshots[to] = true
+
+	if err == nil {
+		err = errors.New(resp.Error)
+	}
+	log.Printf("raft: snapshot to %x failed: %v", to, err)
+	n.ReportSnapshot(to, raft.SnapshotFailure)
+}
+
+// sendSnapshot sends a snapshot to a member without blocking
+// the main loop, within the limits of the snapshot throttle
+func (n *Node) sendSnapshot(peer *Peer, m raftpb.Message) {
+	n.incrementalSnapshot(&m)
+
+	release := n.snapshotThrottle.acquire()
+	defer release()
+
+	err := n.offloadSnapshot(&m)
+	if err != nil {
+		n.reportSnapshot(peer.ID, nil, err)
+		return
+	}
+
+	failpoint(FailpointBeforeSend)
+	var resp *SendResponse
+	if n.snapshotThrottle.limited() {
+		resp, err = n.streamSnapshot(peer, &m)
+	} else {
+		resp, err = peer.Client.Send(n.Ctx, &m)
+	}
+	if err != nil {
+		n.ReportUnreachable(peer.ID)
+	}
+	n.reportSnapshot(peer.ID, resp, err)
+}
+
+// streamSnapshot sends the message of a snapshot in chunks, each
+// waiting for its share of the bandwidth before it is sent
+func (n *Node) streamSnapshot(peer *Peer, m *raftpb.Message) (*SendResponse, error) {
+	data, err := m.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := peer.Client.SendSnapshot(n.Ctx)
+	if err != nil {
+		return nil, err
+	}
+	for len(data) > 0 {
+		size := snapshotChunkSize
+		if size > len(data) {
+			size = len(data)
+		}
+		time.Sleep(n.snapshotThrottle.reserve(size))
+		err = stream.Send(&SnapshotChunk{Data: data[:size]})
+		if err != nil {
+			return nil, err
+		}
+		data = data[size:]
+	}
+	return stream.CloseAndRecv()
+}
+
+// SendSnapshot receives the chunks of a raft message carrying
+// a snapshot and steps it once it is whole, as Send does
+func (n *Node) SendSnapshot(stream RaftTransport_SendSnapshotServer) error {
+	var data []byte
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data = append(data, chunk.Data...)
+	}
+
+	var m raftpb.Message
+	err := m.Unmarshal(data)
+	if err != nil {
+		return stream.SendAndClose(&SendResponse{Error: err.Error()})
+	}
+	resp, err := n.Send(stream.Context(), &m)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// SetSnapshotLimits caps the bandwidth in bytes per second used to
+// send snapshots and the number of concurrent transfers, so that a
+// large snapshot does not starve the replication. The snapshots are
+// then streamed in chunks, each sent once the bandwidth allows it.
+// A zero bandwidth means there is no limit
+func (n *Node) SetSnapshotLimits(bandwidth int64, transfers int) {
+	if transfers < 1 {
+		transfers = 1
+	}
+
+	t := n.snapshotThrottle
+	t.lock.Lock()
+	t.bandwidth = bandwidth
+	t.slots = make(chan struct{}, transfers)
+	t.lock.Unlock()
+}
+
+// throttle limits the bandwidth and the
+// concurrency of snapshot transfers
+type throttle struct {
+	lock      sync.Mutex
+	bandwidth int64
+	slots     chan struct{}
+	next      time.Time
+}
+
+func newThrottle(transfers int) *throttle {
+	return &throttle{slots: make(chan struct{}, transfers)}
+}
+
+// acquire waits for a transfer slot, it returns
+// a function releasing the slot
+func (t *throttle) acquire() func() {
+	t.lock.Lock()
+	slots := t.slots
+	t.lock.Unlock()
+
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+// limited checks if the bandwidth of the transfers is capped
+func (t *throttle) limited() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.bandwidth > 0
+}
+
+// reserve books the bandwidth to send size bytes and returns the
+// time to wait before sending them, which is the time taken to
+// send them along with the bytes booked before at the bandwidth
+func (t *throttle) reserve(size int) time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.bandwidth <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	t.next = t.next.Add(time.Duration(int64(size) * int64(time.Second) / t.bandwidth))
+	return t.next.Sub(now)
+}
@@ -0,0 +1,271 @@
+package proton
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestSnapshotChecksum(t *testing.T) {
+	state := &StoreSnapshot{Pairs: []*LogPair{{Key: "foo", Value: []byte("bar")}}}
+
+	data, err := encodeSnapshot(state)
+	assert.NoError(t, err)
+
+	decoded, err := decodeSnapshot(data)
+	assert.NoError(t, err)
+	assert.Equal(t, decoded.Pairs[0].Key, "foo")
+
+	// Flip a byte of the payload
+	snapshot := &SnapshotData{}
+	assert.NoError(t, proto.Unmarshal(data, snapshot))
+	snapshot.State[len(snapshot.State)-1] ^= 0xff
+	data, err = proto.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	_, err = decodeSnapshot(data)
+	assert.Equal(t, err, ErrSnapshotCorrupt)
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.Put("foo", "bar")
+	n.Put(NamespacedKey("team", "key"), "value")
+	alarm := &protonpb.Alarm{Member: 1, Type: protonpb.AlarmType_NOSPACE}
+	value, err := proto.Marshal(alarm)
+	assert.NoError(t, err)
+	n.applyAlarm(&LogPair{Key: alarmKey(alarm), Value: value})
+
+	data, err := encodeSnapshot(n.snapshotState())
+	assert.NoError(t, err)
+	state, err := decodeSnapshot(data)
+	assert.NoError(t, err)
+
+	restored := newQuotaNode(t)
+	defer restored.Stop()
+	restored.Put("stale", "value")
+	restored.restore(state)
+
+	assert.Equal(t, restored.StoreLength(), 2)
+	assert.Equal(t, restored.Get("foo"), "bar")
+	assert.Equal(t, restored.Get("stale"), "")
+	assert.Equal(t, restored.StoreSize(), n.StoreSize())
+	assert.True(t, restored.IsNoSpace())
+}
+
+func TestIncrementalSnapshot(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.put("foo", "bar", "", 1)
+	n.put("baz", "qux", "", 2)
+	n.put("foo", "updated", "", 3)
+
+	delta := deltaSnapshot(n.snapshotState(), 2)
+	assert.Equal(t, len(delta.Pairs), 1)
+	assert.Equal(t, delta.Pairs[0].Key, "foo")
+
+	// A member at index 2 only receives the keys written after it
+	follower := newQuotaNode(t)
+	defer follower.Stop()
+	follower.put("foo", "bar", "", 1)
+	follower.put("baz", "qux", "", 2)
+	follower.restore(delta)
+
+	assert.Equal(t, follower.Get("foo"), "updated")
+	assert.Equal(t, follower.Get("baz"), "qux")
+	assert.Equal(t, follower.StoreSize(), n.StoreSize())
+
+	// Keys deleted since are removed from the member
+	n.remove("baz", 4)
+	follower.restore(deltaSnapshot(n.snapshotState(), 3))
+	assert.Equal(t, follower.StoreLength(), 1)
+	assert.Equal(t, follower.StoreSize(), n.StoreSize())
+}
+
+func TestThrottle(t *testing.T) {
+	th := newThrottle(1)
+	assert.False(t, th.limited())
+	assert.Equal(t, th.reserve(1000), time.Duration(0))
+
+	// Every chunk waits for its own share of the bandwidth
+	th.bandwidth = 1000
+	assert.True(t, th.limited())
+	wait := th.reserve(1000)
+	assert.True(t, wait > 900*time.Millisecond && wait <= time.Second)
+
+	// The next chunk also waits for the bandwidth of the previous one
+	wait = th.reserve(500)
+	assert.True(t, wait > 1400*time.Millisecond && wait <= 1500*time.Millisecond)
+	wait = th.reserve(0)
+	assert.True(t, wait > 1400*time.Millisecond && wait <= 1500*time.Millisecond)
+}
+
+// chunkTransport records the chunks of the snapshots it streams
+type chunkTransport struct {
+	RaftTransportClient
+	chunks int
+	data   []byte
+}
+
+func (c *chunkTransport) SendSnapshot(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_SendSnapshotClient, error) {
+	return &chunkStream{transport: c}, nil
+}
+
+type chunkStream struct {
+	grpc.ClientStream
+	transport *chunkTransport
+}
+
+func (s *chunkStream) Send(chunk *SnapshotChunk) error {
+	s.transport.chunks++
+	s.transport.data = append(s.transport.data, chunk.Data...)
+	return nil
+}
+
+func (s *chunkStream) CloseAndRecv() (*SendResponse, error) {
+	return &SendResponse{}, nil
+}
+
+func TestStreamSnapshotBandwidth(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	n.SetSnapshotLimits(100<<10, 1)
+
+	transport := &chunkTransport{}
+	peer := &Peer{NodeInfo: &protonpb.NodeInfo{ID: 2}, Client: &Raft{RaftTransportClient: transport}}
+	m := raftpb.Message{Type: raftpb.MsgSnap, To: 2, Snapshot: raftpb.Snapshot{Data: make([]byte, 200<<10)}}
+
+	// Two seconds worth of bandwidth take two seconds to send
+	start := time.Now()
+	resp, err := n.streamSnapshot(peer, &m)
+	took := time.Since(start)
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Error, "")
+	assert.True(t, took >= 1900*time.Millisecond, took.String())
+	assert.True(t, took < 3*time.Second, took.String())
+
+	// The chunks make up the message
+	assert.True(t, transport.chunks > 1)
+	var sent raftpb.Message
+	assert.NoError(t, sent.Unmarshal(transport.data))
+	assert.Equal(t, len(sent.Snapshot.Data), len(m.Snapshot.Data))
+}
+
+func TestSnapshotHandlers(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	n.Put("foo", "bar")
+	n.SetSnapshotHandlers(func() ([]byte, error) { return []byte("derived"), nil }, nil)
+
+	data, err := n.snapshotData()
+	assert.NoError(t, err)
+
+	var restored []byte
+	follower := newQuotaNode(t)
+	defer follower.Stop()
+	follower.SetSnapshotHandlers(nil, func(payload []byte) error {
+		restored = payload
+		return nil
+	})
+
+	snapshot := raftpb.Snapshot{Data: data}
+	snapshot.Metadata.Index = 5
+	follower.processSnapshot(snapshot)
+
+	assert.Equal(t, string(restored), "derived")
+	assert.Equal(t, follower.Get("foo"), "bar")
+	assert.Equal(t, follower.appliedIndex, uint64(5))
+}
+
+func TestEtcdSnapshot(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.Put("foo", "bar")
+	n.Put(NamespacedKey("team", "key"), "value")
+
+	var buf bytes.Buffer
+	assert.NoError(t, n.ExportEtcdSnapshot(n.Ctx, &buf))
+
+	pairs, err := ReadEtcdSnapshot(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, len(pairs), 2)
+	assert.Equal(t, pairs[0].Key, NamespacedKey("team", "key"))
+	assert.Equal(t, string(pairs[1].Value), "bar")
+
+	// The hash of the database is verified
+	data := buf.Bytes()
+	data[len(data)-1] ^= 0xff
+	_, err = ReadEtcdSnapshot(bytes.NewReader(data))
+	assert.Equal(t, err, ErrEtcdSnapshotHash)
+}
+
+func TestEtcdSnapshotAudit(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	var buf bytes.Buffer
+	assert.NoError(t, n.ExportEtcdSnapshot(n.Ctx, &buf))
+	assert.NoError(t, n.ImportEtcdSnapshot(n.Ctx, bytes.NewReader(buf.Bytes())))
+	assert.Error(t, n.ImportEtcdSnapshot(n.Ctx, bytes.NewReader(nil)))
+	for i := 0; i < 100 && len(n.AuditEvents(0)) < 3; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	events := n.AuditEvents(0)
+	assert.Len(t, events, 3)
+	assert.Equal(t, events[0].Action, AuditEtcdExport)
+	assert.True(t, events[0].Success)
+	assert.Equal(t, events[1].Action, AuditEtcdImport)
+	assert.True(t, events[1].Success)
+	// The failed imports are recorded as well
+	assert.Equal(t, events[2].Action, AuditEtcdImport)
+	assert.False(t, events[2].Success)
+}
+
+func TestSendSnapshotStream(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+	defer server.Stop()
+	RegisterTransport(server, n)
+	go server.Serve(l)
+
+	client, err := GetRaftClient(l.Addr().String(), 2*time.Second)
+	assert.NoError(t, err)
+	defer client.Conn.Close()
+
+	// The chunks are put back together before the message is read
+	stream, err := client.SendSnapshot(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&SnapshotChunk{Data: []byte{0xff}}))
+	assert.NoError(t, stream.Send(&SnapshotChunk{Data: []byte{0xff}}))
+	resp, err := stream.CloseAndRecv()
+	assert.NoError(t, err)
+	assert.NotEqual(t, resp.Error, "")
+}
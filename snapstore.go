@@ -0,0 +1,224 @@
+package proton
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// snapshotStoreTimeout is the time given to write
+	// or fetch a snapshot from a snapshot store
+	snapshotStoreTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrNoSnapshotStore is thrown when a member receives the location of a snapshot without a snapshot store to fetch it from
+	ErrNoSnapshotStore = errors.New("snapshot sent by location but no snapshot store is set")
+	// ErrSnapshotMismatch is thrown when a snapshot fetched from a snapshot store does not match its digest
+	ErrSnapshotMismatch = errors.New("snapshot fetched does not match the digest it was sent with")
+)
+
+// SnapshotStore holds the snapshots sent to the members out of
+// band, such as a bucket of an object storage, so that a large
+// snapshot is not streamed through the raft transport
+type SnapshotStore interface {
+	// Put saves a snapshot under a name and returns
+	// the location the members fetch it from
+	Put(ctx context.Context, name string, data []byte) (string, error)
+	// Get fetches the snapshot saved at a location
+	Get(ctx context.Context, location string) ([]byte, error)
+}
+
+// NewDirSnapshotStore returns a SnapshotStore writing the
+// snapshots to a directory shared by the members
+func NewDirSnapshotStore(dir string) SnapshotStore {
+	return &dirSnapshotStore{dir: dir}
+}
+
+// dirSnapshotStore holds the snapshots in a directory
+type dirSnapshotStore struct {
+	dir string
+}
+
+// Put writes a snapshot to a temporary file which is
+// then renamed, so that it is never read partially. It
+// is not written if it does not fit on the disk
+func (s *dirSnapshotStore) Put(ctx context.Context, name string, data []byte) (string, error) {
+	err := ensureSpace(s.dir, uint64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(s.dir, name)
+	tmp, err := ioutil.TempFile(s.dir, name)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp.Name(), path)
+}
+
+// Get reads a snapshot from its file
+func (s *dirSnapshotStore) Get(ctx context.Context, location string) ([]byte, error) {
+	return ioutil.ReadFile(location)
+}
+
+// NewHTTPSnapshotStore returns a SnapshotStore uploading the
+// snapshots with a PUT under a base URL, such as a bucket of an
+// object storage, and fetching them with a GET. The default
+// client is used if client is nil
+func NewHTTPSnapshotStore(base string, client *http.Client) SnapshotStore {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpSnapshotStore{base: strings.TrimSuffix(base, "/"), client: client}
+}
+
+// httpSnapshotStore holds the snapshots behind an HTTP endpoint
+type httpSnapshotStore struct {
+	base   string
+	client *http.Client
+}
+
+// Put uploads a snapshot to the base URL
+func (s *httpSnapshotStore) Put(ctx context.Context, name string, data []byte) (string, error) {
+	location := s.base + "/" + name
+	req, err := http.NewRequest("PUT", location, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := ctxhttp.Do(ctx, s.client, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("can't upload snapshot to %s: %s", location, resp.Status)
+	}
+	return location, nil
+}
+
+// Get downloads a snapshot
+func (s *httpSnapshotStore) Get(ctx context.Context, location string) ([]byte, error) {
+	resp, err := ctxhttp.Get(ctx, s.client, location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't download snapshot from %s: %s", location, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// offloads keeps the locations of the snapshots written to
+// the snapshot store, so that a snapshot sent to several
+// members is only written once
+type offloads struct {
+	lock      sync.Mutex
+	pointers  map[string]*SnapshotPointer
+	lastIndex uint64
+}
+
+// offloadSnapshot writes the data of a snapshot sent to a member
+// to the snapshot store, the message then only carries its
+// location and digest. The data is left as is without a store
+func (n *Node) offloadSnapshot(m *raftpb.Message) error {
+	store := n.SnapshotStore
+	if store == nil {
+		return nil
+	}
+
+	digest := sha256.Sum256(m.Snapshot.Data)
+	name := fmt.Sprintf("snapshot-%016x-%016x-%x", m.Snapshot.Metadata.Term, m.Snapshot.Metadata.Index, digest[:8])
+
+	o := n.offloads
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	// Only the locations of the last snapshot are kept
+	if m.Snapshot.Metadata.Index != o.lastIndex {
+		o.pointers = make(map[string]*SnapshotPointer)
+		o.lastIndex = m.Snapshot.Metadata.Index
+	}
+
+	pointer, ok := o.pointers[name]
+	if !ok {
+		ctx, cancel := context.WithTimeout(n.Ctx, snapshotStoreTimeout)
+		defer cancel()
+
+		location, err := store.Put(ctx, name, m.Snapshot.Data)
+		if err != nil {
+			return err
+		}
+		pointer = &SnapshotPointer{Location: location, Digest: digest[:], Size_: uint64(len(m.Snapshot.Data))}
+		o.pointers[name] = pointer
+	}
+
+	data, err := proto.Marshal(&SnapshotData{Pointer: pointer})
+	if err != nil {
+		return err
+	}
+	m.Snapshot.Data = data
+	return nil
+}
+
+// fetchSnapshot replaces the location of a snapshot received
+// from the leader with its data, fetched from the snapshot store
+func (n *Node) fetchSnapshot(ctx context.Context, m *raftpb.Message) error {
+	if m.Type != raftpb.MsgSnap {
+		return nil
+	}
+
+	snapshot := &SnapshotData{}
+	err := proto.Unmarshal(m.Snapshot.Data, snapshot)
+	if err != nil || snapshot.Pointer == nil {
+		// Left to the verification of the snapshot
+		return nil
+	}
+
+	store := n.SnapshotStore
+	if store == nil {
+		return ErrNoSnapshotStore
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, snapshotStoreTimeout)
+	defer cancel()
+
+	data, err := store.Get(ctx, snapshot.Pointer.Location)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(data)
+	if uint64(len(data)) != snapshot.Pointer.Size_ || !bytes.Equal(digest[:], snapshot.Pointer.Digest) {
+		return ErrSnapshotMismatch
+	}
+	m.Snapshot.Data = data
+	return nil
+}
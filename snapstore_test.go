@@ -0,0 +1,109 @@
+package proton
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+// countingStore counts the snapshots written to a store
+type countingStore struct {
+	SnapshotStore
+	puts int
+}
+
+func (s *countingStore) Put(ctx context.Context, name string, data []byte) (string, error) {
+	s.puts++
+	return s.SnapshotStore.Put(ctx, name, data)
+}
+
+func TestSnapshotStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proton-snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	n := newQuotaNode(t)
+	defer n.Stop()
+	n.Put("foo", "bar")
+	store := &countingStore{SnapshotStore: NewDirSnapshotStore(dir)}
+	n.SnapshotStore = store
+
+	data, err := n.snapshotData()
+	assert.NoError(t, err)
+	snapshot := raftpb.Snapshot{Data: data, Metadata: raftpb.SnapshotMetadata{Index: 10, Term: 2}}
+
+	// The members are sent the location of a snapshot written once
+	var sent []raftpb.Message
+	for to := uint64(2); to <= 3; to++ {
+		m := raftpb.Message{Type: raftpb.MsgSnap, To: to, Snapshot: snapshot}
+		assert.NoError(t, n.offloadSnapshot(&m))
+		assert.NotEqual(t, m.Snapshot.Data, data)
+		sent = append(sent, m)
+	}
+	assert.Equal(t, store.puts, 1)
+
+	follower := newQuotaNode(t)
+	defer follower.Stop()
+	m := sent[0]
+	assert.Equal(t, follower.fetchSnapshot(context.Background(), &m), ErrNoSnapshotStore)
+
+	follower.SnapshotStore = NewDirSnapshotStore(dir)
+	assert.NoError(t, follower.fetchSnapshot(context.Background(), &m))
+	assert.Equal(t, m.Snapshot.Data, data)
+	assert.NoError(t, follower.verifySnapshot(&m))
+
+	// A snapshot sent with its data is left as is
+	assert.NoError(t, follower.fetchSnapshot(context.Background(), &m))
+	assert.Equal(t, m.Snapshot.Data, data)
+
+	// A snapshot changed in the store is refused
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.NoError(t, ioutil.WriteFile(dir+"/"+files[0].Name(), []byte("corrupt"), 0600))
+	m = sent[1]
+	assert.Equal(t, follower.fetchSnapshot(context.Background(), &m), ErrSnapshotMismatch)
+}
+
+func TestHTTPSnapshotStore(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		objects = make(map[string][]byte)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch r.Method {
+		case "PUT":
+			data, _ := ioutil.ReadAll(r.Body)
+			objects[r.URL.Path] = data
+		case "GET":
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	store := NewHTTPSnapshotStore(server.URL+"/bucket/", nil)
+	location, err := store.Put(context.Background(), "snapshot", []byte("data"))
+	assert.NoError(t, err)
+	assert.Equal(t, location, server.URL+"/bucket/snapshot")
+
+	data, err := store.Get(context.Background(), location)
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), "data")
+
+	_, err = store.Get(context.Background(), server.URL+"/bucket/missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package proton
+
+import (
+	"bytes"
+	"log"
+
+	"golang.org/x/net/context"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// partKey is the key of the parts of split proposals in the reserved keyspace
+	partKey = systemPrefix + "part"
+
+	// partOverhead is the room left in a part for
+	// the encoding of the part and of its pair
+	partOverhead = 64
+
+	// partsWindow is the number of entries after which the parts
+	// of a proposal that was not entirely committed are dropped
+	partsWindow = 1000
+)
+
+// maxEntrySize returns the size above which proposals are split
+func (n *Node) maxEntrySize() int {
+	if n.MaxEntrySize > 0 {
+		return n.MaxEntrySize
+	}
+	return int(n.Cfg.MaxSizePerMsg)
+}
+
+// proposeParts proposes data too large for a single entry as
+// several parts. Nothing is applied until the last part is
+// committed, so that a proposal failing halfway has no effect
+func (n *Node) proposeParts(ctx context.Context, data []byte, limit int) error {
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+
+	parts, err := splitProposal(id, data, limit)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		err = n.proposeRaft(ctx, part)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitProposal returns the encoded parts of data
+// that fit in entries of limit bytes
+func splitProposal(id uint64, data []byte, limit int) ([][]byte, error) {
+	size := limit - partOverhead
+	if size < 1 {
+		size = 1
+	}
+	count := (len(data) + size - 1) / size
+
+	var parts [][]byte
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(data) {
+			end = len(data)
+		}
+		value, err := proto.Marshal(&EntryPart{
+			Id:    id,
+			Part:  uint32(i),
+			Parts: uint32(count),
+			Data:  data[i*size : end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		part, err := EncodePair(partKey, value)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// applyPart keeps the part of a split proposal, the
+// proposal is applied once its last part is committed.
+// Parts missing or out of order drop the proposal on
+// every member alike
+func (n *Node) applyPart(entry raftpb.Entry, pair *LogPair) {
+	part := &EntryPart{}
+	err := proto.Unmarshal(pair.Value, part)
+	if err != nil {
+		log.Println("raft: can't decode part of a proposal:", err)
+		return
+	}
+	part.Index = entry.Index
+
+	n.partsLock.Lock()
+	for id, parts := range n.parts {
+		if entry.Index-parts[0].Index > partsWindow {
+			delete(n.parts, id)
+		}
+	}
+
+	parts := n.parts[part.Id]
+	if part.Part == 0 {
+		parts = nil
+	}
+	if int(part.Part) != len(parts) || part.Part >= part.Parts {
+		delete(n.parts, part.Id)
+		n.partsLock.Unlock()
+		return
+	}
+	parts = append(parts, part)
+	if len(parts) < int(part.Parts) {
+		n.parts[part.Id] = parts
+		n.partsLock.Unlock()
+		return
+	}
+	delete(n.parts, part.Id)
+	n.partsLock.Unlock()
+
+	chunks := make([][]byte, len(parts))
+	for i, p := range parts {
+		chunks[i] = p.Data
+	}
+	entry.Data = bytes.Join(chunks, nil)
+
+	n.process(entry)
+	n.waiters.trigger(entry, n.takeRejection(), n.HLC())
+}
+
+// pendingParts returns the parts of the proposals not entirely applied
+func (n *Node) pendingParts() []*EntryPart {
+	n.partsLock.Lock()
+	defer n.partsLock.Unlock()
+
+	var pending []*EntryPart
+	for _, parts := range n.parts {
+		pending = append(pending, parts...)
+	}
+	return pending
+}
+
+// restoreParts replaces the parts of the proposals not entirely applied
+func (n *Node) restoreParts(pending []*EntryPart) {
+	n.partsLock.Lock()
+	defer n.partsLock.Unlock()
+
+	n.parts = make(map[uint64][]*EntryPart)
+	for _, part := range pending {
+		n.parts[part.Id] = append(n.parts[part.Id], part)
+	}
+}
@@ -0,0 +1,71 @@
+package proton
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitProposal(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	value := bytes.Repeat([]byte("a"), 1000)
+	data, err := proto.Marshal(&protonpb.Pair{Key: "foo", Value: value})
+	assert.NoError(t, err)
+
+	parts, err := splitProposal(1, data, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, len(parts), 8)
+	for _, part := range parts {
+		assert.True(t, len(part) <= 200)
+	}
+
+	// Nothing is applied until the last part
+	index := uint64(3)
+	for _, part := range parts[:len(parts)-1] {
+		n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: index, Term: 1, Data: part})
+		index++
+	}
+	assert.Equal(t, n.Get("foo"), "")
+	assert.Equal(t, len(n.pendingParts()), 7)
+
+	// The pending parts are carried by snapshots
+	state := n.snapshotState()
+	n.restore(&StoreSnapshot{})
+	assert.Empty(t, n.pendingParts())
+	n.restore(state)
+
+	ch := n.waiters.register(data)
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: index, Term: 1, Data: parts[len(parts)-1]})
+	assert.Equal(t, n.Get("foo"), string(value))
+	assert.Equal(t, (<-ch).index, index)
+	assert.Empty(t, n.pendingParts())
+
+	// A missing part drops the proposal
+	parts, err = splitProposal(2, data, 200)
+	assert.NoError(t, err)
+	for i, part := range parts {
+		if i == 3 {
+			continue
+		}
+		index++
+		n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: index, Term: 1, Data: part})
+	}
+	assert.Equal(t, n.StoreLength(), 1)
+	assert.Empty(t, n.pendingParts())
+
+	// So does a proposal that never completes
+	parts, err = splitProposal(3, data, 200)
+	assert.NoError(t, err)
+	index++
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: index, Term: 1, Data: parts[0]})
+	applyPair(t, n, index+partsWindow+1, "bar", "baz")
+	index += partsWindow + 2
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: index, Term: 1, Data: parts[1]})
+	assert.Empty(t, n.pendingParts())
+}
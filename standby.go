@@ -0,0 +1,417 @@
+package proton
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	// AuditPromote is recorded when a standby is promoted to a member
+	AuditPromote = "promote"
+
+	// standbyInterval is the time a standby waits before
+	// fetching again once it caught up or its source failed
+	standbyInterval = 100 * time.Millisecond
+
+	// maxFetchSize is the maximum size in bytes of the
+	// entries sent to a standby in a single response
+	maxFetchSize = 1 << 20
+)
+
+var (
+	// ErrStandby is thrown when a proposal is made on a standby
+	ErrStandby = errors.New("node is a standby, proposals are refused until it is promoted")
+	// ErrNotStandby is thrown when promoting a node that is not a standby
+	ErrNotStandby = errors.New("node is not a standby")
+	// ErrSnapshotIncomplete is thrown when a standby needs a snapshot and the member only has an incremental one
+	ErrSnapshotIncomplete = errors.New("no full snapshot to send to a standby")
+)
+
+// standby is the replication of a standby, stop
+// ends it and done is closed once it ended
+type standby struct {
+	addr string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// standbyBatch holds fetched entries to apply in the
+// main loop, done is closed once they are applied
+type standbyBatch struct {
+	resp *FetchEntriesResponse
+	done chan struct{}
+}
+
+// StartStandby makes the node a warm standby of the cluster
+// of the member at addr. A standby applies every entry committed
+// in the cluster without being a member: it neither votes nor
+// counts in the quorum. It serves reads and refuses proposals
+// until Promote turns it into a member. The node must be created
+// with NewJoinNode and started, with the encryption key of the
+// cluster if its proposals are encrypted
+func (n *Node) StartStandby(addr string) {
+	n.standbyLock.Lock()
+	defer n.standbyLock.Unlock()
+
+	if n.standby != nil {
+		return
+	}
+	n.standby = &standby{
+		addr: addr,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go n.follow(n.standby)
+}
+
+// IsStandby checks if the node is a standby
+func (n *Node) IsStandby() bool {
+	n.standbyLock.RLock()
+	defer n.standbyLock.RUnlock()
+	return n.standby != nil
+}
+
+// Promote turns a standby into a member of the cluster it
+// follows. As its store is already up to date, it is only sent
+// the entries committed since it last fetched. If they were
+// compacted, a leader with IncrementalSnapshots only sends the
+// keys written since. The standby keeps following the cluster
+// if it can't join
+func (n *Node) Promote(ctx context.Context) error {
+	n.standbyLock.Lock()
+	s := n.standby
+	if s == nil {
+		n.standbyLock.Unlock()
+		return ErrNotStandby
+	}
+	close(s.stop)
+	<-s.done
+
+	err := n.joinCluster(ctx, s.addr)
+	if err != nil {
+		n.standby = &standby{
+			addr: s.addr,
+			stop: make(chan struct{}),
+			done: make(chan struct{}),
+		}
+		go n.follow(n.standby)
+	} else {
+		n.standby = nil
+	}
+	n.standbyLock.Unlock()
+
+	if err != nil {
+		return err
+	}
+	n.recordAudit(ctx, AuditPromote, n.ID, nil)
+	return nil
+}
+
+// joinCluster adds the node to the raft through the member
+// at addr, or through another known member if it fails
+func (n *Node) joinCluster(ctx context.Context, addr string) error {
+	info := n.Info()
+	info.Applied = n.AppliedIndex()
+
+	var err error
+	for _, addr := range n.sources(addr) {
+		var nodes []*protonpb.NodeInfo
+		nodes, err = n.joinThrough(ctx, addr, info)
+		if err == nil {
+			return n.RegisterNodes(ctx, nodes)
+		}
+	}
+	return err
+}
+
+// joinThrough asks the member at addr to add the node to the
+// raft, it returns the members of the cluster
+func (n *Node) joinThrough(ctx context.Context, addr string, info *protonpb.NodeInfo) ([]*protonpb.NodeInfo, error) {
+	client, err := n.conns.get(addr, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer n.conns.release(addr)
+
+	resp, err := client.JoinRaft(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Nodes, nil
+}
+
+// sources returns the addresses a standby can fetch from,
+// starting with addr and then the members it knows of
+func (n *Node) sources(addr string) []string {
+	addrs := []string{addr}
+	for _, member := range n.Cluster.Members() {
+		if member.ID != n.ID && member.Addr != addr {
+			addrs = append(addrs, member.Addr)
+		}
+	}
+	return addrs
+}
+
+// follow fetches the entries committed in the cluster and hands
+// them over to the main loop. The standby moves on to the next
+// member it knows of when its source fails
+func (n *Node) follow(s *standby) {
+	defer close(s.done)
+
+	addr := s.addr
+	registered := false
+	for {
+		// The members that bootstrapped the cluster
+		// are not found in the configuration changes
+		var err error
+		if !registered {
+			err = n.registerMembers(addr)
+			registered = err == nil
+		}
+
+		var resp *FetchEntriesResponse
+		if err == nil {
+			resp, err = n.fetch(addr)
+		}
+		if err != nil {
+			log.Printf("raft: standby %x can't follow %s: %v", n.ID, addr, err)
+			sources := n.sources(s.addr)
+			for i := range sources {
+				if sources[i] == addr {
+					addr = sources[(i+1)%len(sources)]
+					break
+				}
+			}
+		}
+
+		if err == nil && (len(resp.Entries) > 0 || resp.Snapshot != nil) {
+			batch := &standbyBatch{resp: resp, done: make(chan struct{})}
+			select {
+			case n.standbyc <- batch:
+				<-batch.done
+				continue
+			case <-s.stop:
+				return
+			}
+		}
+
+		select {
+		case <-time.After(standbyInterval):
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// registerMembers registers the members known by the member at addr
+func (n *Node) registerMembers(addr string) error {
+	client, err := n.conns.get(addr, 2*time.Second)
+	if err != nil {
+		return err
+	}
+	defer n.conns.release(addr)
+
+	ctx, cancel := context.WithTimeout(n.Ctx, proposeTimeout)
+	defer cancel()
+
+	resp, err := client.ListMembers(ctx, &protonpb.ListMembersRequest{})
+	if err != nil {
+		return err
+	}
+	return n.RegisterNodes(ctx, resp.Members)
+}
+
+// fetch returns the entries applied by the member at addr
+// since the last index applied by the standby
+func (n *Node) fetch(addr string) (*FetchEntriesResponse, error) {
+	client, err := n.conns.get(addr, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer n.conns.release(addr)
+
+	ctx, cancel := context.WithTimeout(n.Ctx, proposeTimeout)
+	defer cancel()
+
+	resp, err := client.FetchEntries(ctx, &FetchEntriesRequest{Index: n.AppliedIndex(), Id: n.ID})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// applyStandby applies the entries fetched by a standby, the
+// configuration changes only update the members it knows of.
+// Called from the main loop
+func (n *Node) applyStandby(resp *FetchEntriesResponse) {
+	if resp.Snapshot != nil && resp.Snapshot.Metadata.Index > n.appliedIndex {
+		n.processSnapshot(*resp.Snapshot)
+	}
+
+	for _, entry := range resp.Entries {
+		if entry.Index != n.appliedIndex+1 {
+			continue
+		}
+		if entry.Type == raftpb.EntryNormal {
+			data, err := unsealEntry(entry.Data, sealedAt(atomic.LoadUint64(&n.sealIndex), entry.Index))
+			if err != nil {
+				// The entries are fetched again from the applied index
+				log.Printf("raft: standby fetched corrupted entry %d: %v", entry.Index, err)
+				break
+			}
+			unsealed := *entry
+			unsealed.Data = data
+			entry = &unsealed
+		}
+		failpoint(FailpointBeforeApply)
+		n.process(*entry)
+		// No proposer waits on a standby
+		n.takeRejection()
+		atomic.StoreUint64(&n.appliedIndex, entry.Index)
+
+		if entry.Type == raftpb.EntryConfChange {
+			var cc raftpb.ConfChange
+			err := cc.Unmarshal(entry.Data)
+			if err != nil {
+				log.Printf("raft: ignoring configuration change %d that can't be decoded: %v", entry.Index, err)
+				continue
+			}
+			switch cc.Type {
+			case raftpb.ConfChangeAddNode:
+				err = n.applyAddNode(cc)
+				if err != nil {
+					log.Println("raft: can't register new member:", err)
+				}
+			case raftpb.ConfChangeRemoveNode:
+				n.UnregisterNode(cc.NodeID)
+			case raftpb.ConfChangeUpdateNode:
+				err = n.applyUpdateNode(cc)
+				if err != nil {
+					log.Println("raft: can't update member:", err)
+				}
+			}
+		}
+	}
+	n.waitApply()
+	n.indexWaiters.trigger(n.appliedIndex)
+	n.saveApplied()
+}
+
+// entriesSince returns the entries applied after an index,
+// or the last snapshot if some of them were compacted
+func (n *Node) entriesSince(index uint64) ([]*raftpb.Entry, *raftpb.Snapshot, error) {
+	applied := n.AppliedIndex()
+	if index >= applied {
+		return nil, nil, nil
+	}
+
+	first, err := n.Store.FirstIndex()
+	if err != nil {
+		return nil, nil, err
+	}
+	if index+1 < first {
+		snapshot, err := n.Store.Snapshot()
+		if err != nil {
+			return nil, nil, err
+		}
+		state, err := decodeSnapshot(snapshot.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if state.Since != 0 {
+			return nil, nil, ErrSnapshotIncomplete
+		}
+		return nil, &snapshot, nil
+	}
+
+	ents, err := n.Store.Entries(index+1, applied+1, maxFetchSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := make([]*raftpb.Entry, len(ents))
+	for i := range ents {
+		entries[i] = &ents[i]
+	}
+	return entries, nil, nil
+}
+
+// recordJoinIndex keeps the index applied by a standby joining
+// as a member, so that it is sent an incremental snapshot
+func (n *Node) recordJoinIndex(info *protonpb.NodeInfo) {
+	if info.Applied == 0 {
+		return
+	}
+	n.snapshotLock.Lock()
+	n.joinIndexes[info.ID] = info.Applied
+	n.snapshotLock.Unlock()
+}
+
+// joinIndex returns the index applied by a standby that joined,
+// if a snapshot at index can be reduced to the keys written since
+func (n *Node) joinIndex(id uint64, index uint64) uint64 {
+	n.snapshotLock.Lock()
+	defer n.snapshotLock.Unlock()
+
+	applied := n.joinIndexes[id]
+	if applied > index {
+		return 0
+	}
+	return applied
+}
+
+// FetchEntries returns the entries applied by the node to a standby
+func (n *Node) FetchEntries(ctx context.Context, req *FetchEntriesRequest) (*FetchEntriesResponse, error) {
+	if n.IsStandby() {
+		return &FetchEntriesResponse{
+			Success: false,
+			Error:   ErrStandby.Error(),
+		}, nil
+	}
+	err := n.checkSender(ctx, req.Id)
+	if err != nil {
+		return &FetchEntriesResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	entries, snapshot, err := n.entriesSince(req.Index)
+	if err != nil {
+		return &FetchEntriesResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &FetchEntriesResponse{
+		Success:  true,
+		Entries:  entries,
+		Snapshot: snapshot,
+	}, nil
+}
+
+// PromoteStandby turns a standby into a member of the raft cluster
+func (n *Node) PromoteStandby(ctx context.Context, req *protonpb.PromoteStandbyRequest) (*protonpb.PromoteStandbyResponse, error) {
+	err := n.Promote(ctx)
+	if err != nil {
+		return &protonpb.PromoteStandbyResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.PromoteStandbyResponse{Success: true}, nil
+}
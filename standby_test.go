@@ -0,0 +1,50 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntriesSince(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	for i := uint64(1); i <= 5; i++ {
+		data, err := EncodePair("foo", []byte("bar"))
+		assert.NoError(t, err)
+		assert.NoError(t, n.Store.Append([]raftpb.Entry{{Index: i, Term: 1, Data: data}}))
+	}
+	n.appliedIndex = 5
+
+	entries, snapshot, err := n.entriesSince(2)
+	assert.NoError(t, err)
+	assert.Nil(t, snapshot)
+	assert.Equal(t, len(entries), 3)
+	assert.Equal(t, entries[0].Index, uint64(3))
+
+	entries, snapshot, err = n.entriesSince(5)
+	assert.NoError(t, err)
+	assert.Nil(t, snapshot)
+	assert.Empty(t, entries)
+
+	// The standby is sent the snapshot once the entries are compacted
+	assert.NoError(t, n.createSnapshot())
+	assert.NoError(t, n.Store.Compact(4))
+	entries, snapshot, err = n.entriesSince(2)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, snapshot.Metadata.Index, uint64(5))
+
+	entries, _, err = n.entriesSince(4)
+	assert.NoError(t, err)
+	assert.Equal(t, len(entries), 1)
+
+	// A standby is not a source
+	n.StartStandby("127.0.0.1:0")
+	resp, err := n.FetchEntries(n.Ctx, &FetchEntriesRequest{Index: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Error, ErrStandby.Error())
+	close(n.standby.stop)
+}
@@ -0,0 +1,117 @@
+package proton
+
+import (
+	"errors"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+)
+
+// STM is a software transactional memory over a namespace of the
+// store of a cluster. The keys read are recorded along with their
+// revision, the writes are buffered and only sent on commit
+type STM struct {
+	ctx       context.Context
+	client    KVClient
+	namespace string
+
+	reads  map[string]*protonpb.GetResult
+	writes map[string]*protonpb.Pair
+	order  []string
+}
+
+// Get returns the value of a key, as written by the
+// transaction or else as read from the cluster
+func (s *STM) Get(key string) ([]byte, bool, error) {
+	if write, ok := s.writes[key]; ok {
+		return write.Value, !write.Delete, nil
+	}
+	if read, ok := s.reads[key]; ok {
+		return read.Value, read.Found, nil
+	}
+
+	resp, err := s.client.GetObjects(s.ctx, &protonpb.GetObjectsRequest{
+		Keys:      []string{key},
+		Namespace: s.namespace,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(resp.Results) != 1 {
+		return nil, false, errors.New("unexpected number of results")
+	}
+
+	read := resp.Results[0]
+	s.reads[key] = read
+	return read.Value, read.Found, nil
+}
+
+// Put writes a value when the transaction commits
+func (s *STM) Put(key string, value []byte) {
+	s.write(&protonpb.Pair{Key: key, Value: value})
+}
+
+// Delete deletes a key when the transaction commits
+func (s *STM) Delete(key string) {
+	s.write(&protonpb.Pair{Key: key, Delete: true})
+}
+
+func (s *STM) write(pair *protonpb.Pair) {
+	if _, ok := s.writes[pair.Key]; !ok {
+		s.order = append(s.order, pair.Key)
+	}
+	s.writes[pair.Key] = pair
+}
+
+// commit sends the writes of the transaction, which are
+// applied only if none of the keys read were written since
+func (s *STM) commit() (bool, error) {
+	req := &protonpb.TxnRequest{Namespace: s.namespace}
+	for key, read := range s.reads {
+		req.Compares = append(req.Compares, &protonpb.Compare{Key: key, Revision: read.Revision})
+	}
+	for _, key := range s.order {
+		req.Writes = append(req.Writes, s.writes[key])
+	}
+
+	resp, err := s.client.Txn(s.ctx, req)
+	if err != nil {
+		return false, err
+	}
+	if !resp.Success {
+		return false, errors.New(resp.Error)
+	}
+	return resp.Committed, nil
+}
+
+// RunSTM runs apply in a transaction over a namespace of the
+// cluster, and runs it again from scratch until no key it read
+// was written by someone else before its writes are committed.
+// apply may run many times and must only act through the STM.
+// An error returned by apply aborts the transaction
+func RunSTM(ctx context.Context, client KVClient, namespace string, apply func(*STM) error) error {
+	for {
+		s := &STM{
+			ctx:       ctx,
+			client:    client,
+			namespace: namespace,
+			reads:     make(map[string]*protonpb.GetResult),
+			writes:    make(map[string]*protonpb.Pair),
+		}
+
+		err := apply(s)
+		if err != nil {
+			return err
+		}
+
+		committed, err := s.commit()
+		if err != nil || committed {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
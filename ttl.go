@@ -0,0 +1,129 @@
+package proton
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// expiryRetry is the time after which the leader proposes
+	// again the expiration of a key that is still in the store
+	expiryRetry = 5 * time.Second
+)
+
+var (
+	// ErrInvalidTTL is thrown when a key is written with a ttl that is not positive
+	ErrInvalidTTL = errors.New("ttl must be positive")
+)
+
+// deadline returns the time in unix nanoseconds at which a ttl
+// elapses on the clock of the leader, which expires the keys, so
+// that a write received by a follower expires after its ttl
+// whatever the offset of the clock of the follower
+func (n *Node) deadline(ttl time.Duration) int64 {
+	return n.LeaderTime().Add(ttl).UnixNano()
+}
+
+// PutWithTTL proposes a value that is deleted once ttl elapsed.
+// The deadline is replicated along with the value so that the
+// next leader expires the key if the current one goes away.
+// Writing the key again without a ttl cancels the expiration
+func (n *Node) PutWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+
+	data, err := proto.Marshal(&LogPair{
+		Key:     key,
+		Value:   value,
+		Expires: n.deadline(ttl),
+	})
+	if err != nil {
+		return err
+	}
+	return n.Propose(ctx, data)
+}
+
+// setExpiry sets the deadline of a key of the store
+func (n *Node) setExpiry(key string, expires int64) {
+	n.storeLock.Lock()
+	n.expiries[key] = expires
+	n.storeLock.Unlock()
+}
+
+// expiry returns the deadline of a key of the store, 0 if it has none
+func (n *Node) expiry(key string) int64 {
+	n.storeLock.RLock()
+	defer n.storeLock.RUnlock()
+	return n.expiries[key]
+}
+
+// dueExpirations returns the deletions of the keys
+// whose deadline is before now
+func (n *Node) dueExpirations(now time.Time) []*LogPair {
+	n.storeLock.RLock()
+	defer n.storeLock.RUnlock()
+
+	var due []*LogPair
+	for key, expires := range n.expiries {
+		if expires <= now.UnixNano() {
+			due = append(due, &LogPair{Key: key, Delete: true, Expires: expires})
+		}
+	}
+	return due
+}
+
+// expireKeys proposes the deletion of the expired keys. Only the
+// leader expires keys, against its own clock. Called from the
+// main loop on every tick
+func (n *Node) expireKeys() {
+	if !n.IsLeader() {
+		if len(n.expiring) > 0 {
+			n.expiring = make(map[string]time.Time)
+		}
+		return
+	}
+
+	now := time.Now()
+	expiring := make(map[string]time.Time)
+	var proposals [][]byte
+	for _, pair := range n.dueExpirations(now) {
+		// Wait for a proposed expiration to be applied
+		if proposed, ok := n.expiring[pair.Key]; ok && now.Sub(proposed) < expiryRetry {
+			expiring[pair.Key] = proposed
+			continue
+		}
+		expiring[pair.Key] = now
+
+		data, err := proto.Marshal(pair)
+		if err != nil {
+			log.Println("raft: can't encode expiration:", err)
+			continue
+		}
+		proposals = append(proposals, data)
+	}
+	n.expiring = expiring
+
+	if len(proposals) == 0 {
+		return
+	}
+
+	// Proposing blocks while there is no leader, which
+	// must not hold up the main loop
+	go func() {
+		ctx, cancel := context.WithTimeout(withInternalPriority(n.Ctx), proposeTimeout)
+		defer cancel()
+		for _, data := range proposals {
+			err := n.proposeRaft(ctx, n.stampProposal(data))
+			if err != nil {
+				log.Println("raft: can't propose expiration:", err)
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,107 @@
+package proton
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func applyProposal(t *testing.T, n *Node, index uint64, pair *LogPair) {
+	data, err := proto.Marshal(pair)
+	assert.NoError(t, err)
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: index, Term: 1, Data: data})
+}
+
+func TestExpiration(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	assert.Equal(t, n.PutWithTTL(context.Background(), "foo", nil, 0), ErrInvalidTTL)
+
+	changes, cancel := n.Subscribe("", 10)
+	defer cancel()
+
+	expires := time.Now().Add(time.Minute).UnixNano()
+	applyProposal(t, n, 3, &LogPair{Key: "foo", Value: []byte("bar"), Expires: expires})
+	applyPair(t, n, 4, "baz", "qux")
+	<-changes
+	<-changes
+
+	assert.Empty(t, n.dueExpirations(time.Now()))
+	due := n.dueExpirations(time.Unix(0, expires))
+	assert.Equal(t, due, []*LogPair{{Key: "foo", Delete: true, Expires: expires}})
+
+	applyProposal(t, n, 5, due[0])
+	assert.Equal(t, n.Get("foo"), "")
+	assert.Equal(t, n.StoreLength(), 1)
+	assert.Equal(t, n.StoreSize(), int64(6))
+
+	change := <-changes
+	assert.Equal(t, change.Type, protonpb.ChangeType_DELETE)
+	assert.Equal(t, change.Pair.Key, "foo")
+
+	// Writing a key again cancels its expiration
+	applyProposal(t, n, 6, &LogPair{Key: "foo", Value: []byte("bar"), Expires: expires})
+	applyPair(t, n, 7, "foo", "kept")
+	assert.Empty(t, n.dueExpirations(time.Unix(0, expires)))
+	applyProposal(t, n, 8, due[0])
+	assert.Equal(t, n.Get("foo"), "kept")
+}
+
+func TestDeadlineLeaderClock(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// A follower with a clock an hour behind the leader sets
+	// the deadline on the clock of the leader
+	n.Cluster.setLeader(2)
+	n.clock.observe(2, time.Now(), time.Now(), time.Now().Add(time.Hour).UnixNano())
+	expires := time.Unix(0, n.deadline(time.Minute))
+	assert.WithinDuration(t, expires, time.Now().Add(time.Hour+time.Minute), time.Second)
+
+	n.Cluster.setLeader(1)
+	expires = time.Unix(0, n.deadline(time.Minute))
+	assert.WithinDuration(t, expires, time.Now().Add(time.Minute), time.Second)
+}
+
+func TestExpirationTimestamp(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	changes, cancel := n.Subscribe("foo", 10)
+	defer cancel()
+
+	ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+	defer done()
+	assert.NoError(t, n.PutWithTTL(ctx, "foo", []byte("bar"), 100*time.Millisecond))
+	put := <-changes
+	assert.Equal(t, put.Type, protonpb.ChangeType_CREATE)
+
+	// The expiration carries the time of the leader that proposed it,
+	// the same on every member
+	select {
+	case change := <-changes:
+		assert.Equal(t, change.Type, protonpb.ChangeType_DELETE)
+		assert.True(t, change.Timestamp >= put.Timestamp+int64(100*time.Millisecond))
+		assert.WithinDuration(t, time.Unix(0, change.Timestamp), time.Now(), time.Second)
+	case <-time.After(5 * time.Second):
+		t.Fatal("key did not expire")
+	}
+}
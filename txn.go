@@ -0,0 +1,259 @@
+package proton
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// txnKey is the key of the transactions in the reserved keyspace
+	txnKey = systemPrefix + "txn"
+)
+
+var (
+	// ErrDuplicateWrite is thrown when a transaction writes a key more than once
+	ErrDuplicateWrite = errors.New("transaction writes a key more than once")
+)
+
+// outcomes keeps track of the callers waiting
+// for the outcome of their transactions
+type outcomes struct {
+	lock    sync.Mutex
+	pending map[uint64]chan bool
+}
+
+func newOutcomes() *outcomes {
+	return &outcomes{pending: make(map[uint64]chan bool)}
+}
+
+// register returns a channel receiving the outcome of a transaction
+func (o *outcomes) register(id uint64) chan bool {
+	ch := make(chan bool, 1)
+	o.lock.Lock()
+	o.pending[id] = ch
+	o.lock.Unlock()
+	return ch
+}
+
+// cancel forgets a transaction
+func (o *outcomes) cancel(id uint64) {
+	o.lock.Lock()
+	delete(o.pending, id)
+	o.lock.Unlock()
+}
+
+// done sends the outcome of a transaction proposed by this node
+func (o *outcomes) done(id uint64, committed bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if ch, ok := o.pending[id]; ok {
+		ch <- committed
+		delete(o.pending, id)
+	}
+}
+
+// Commit applies writes only if every key was last written by the
+// entry at the index of its compare, or does not exist for a
+// revision of 0. It returns if the writes were applied, along with
+// the index of the transaction. The writes are applied one after
+// the other, a reader may see a part of them until the last one.
+// Each key is written once, two writes of a key would share the
+// revision of the transaction so that a reader of the first one
+// could not tell it was overwritten
+func (n *Node) Commit(ctx context.Context, compares []*protonpb.Compare, writes []*LogPair) (bool, uint64, error) {
+	written := make(map[string]bool, len(writes))
+	for _, write := range writes {
+		if written[write.Key] {
+			return false, 0, ErrDuplicateWrite
+		}
+		written[write.Key] = true
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return false, 0, err
+	}
+
+	txn := &Txn{Id: id, Compares: compares}
+	for _, write := range writes {
+		data, err := proto.Marshal(write)
+		if err != nil {
+			return false, 0, err
+		}
+
+		// Each write is checked and encoded as if it was proposed
+		data, err = n.prepareProposal(data)
+		if err != nil {
+			return false, 0, err
+		}
+
+		pair := &LogPair{}
+		err = proto.Unmarshal(data, pair)
+		if err != nil {
+			return false, 0, err
+		}
+		txn.Writes = append(txn.Writes, pair)
+	}
+
+	value, err := proto.Marshal(txn)
+	if err != nil {
+		return false, 0, err
+	}
+	// The clock moves with the entry of the transaction, past
+	// the latest event its writes depend on
+	entry := &LogPair{Key: txnKey, Value: value}
+	for _, write := range writes {
+		if write.After != nil && (entry.After == nil || CompareHybridTime(write.After, entry.After) > 0) {
+			entry.After = write.After
+		}
+	}
+	data, err := proto.Marshal(entry)
+	if err != nil {
+		return false, 0, err
+	}
+
+	outcome := n.txns.register(id)
+	defer n.txns.cancel(id)
+
+	index, _, err := n.ProposeWait(ctx, data)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// The outcome is known once the entry is applied
+	select {
+	case committed := <-outcome:
+		return committed, index, nil
+	default:
+		return false, index, nil
+	}
+}
+
+// compare checks that the keys were last written at the given revisions
+func (n *Node) compare(compares []*protonpb.Compare) bool {
+	n.storeLock.RLock()
+	defer n.storeLock.RUnlock()
+
+	for _, c := range compares {
+		_, exists := n.pstore[c.Key]
+		if (!exists && c.Revision != 0) || (exists && n.revisions[c.Key] != c.Revision) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyTxn applies the writes of a transaction if its compares hold
+func (n *Node) applyTxn(entry raftpb.Entry, pair *LogPair) {
+	if pair.Compressed {
+		err := decompressPair(pair)
+		if err != nil {
+			log.Println("raft: ignoring transaction that can't be decompressed:", err)
+			return
+		}
+	}
+
+	txn := &Txn{}
+	err := proto.Unmarshal(pair.Value, txn)
+	if err != nil {
+		log.Println("raft: can't decode transaction:", err)
+		return
+	}
+
+	committed := n.compare(txn.Compares) && n.validateTxn(entry, txn.Writes)
+	if committed {
+		for _, write := range txn.Writes {
+			if isSystemKey(write.Key) {
+				continue
+			}
+			data, err := proto.Marshal(write)
+			if err != nil {
+				log.Fatal("raft: Can't encode key and value of a transaction")
+			}
+			n.processPair(entry, write, data, false)
+		}
+	}
+	n.txns.done(txn.Id, committed)
+}
+
+// Txn applies writes to a namespace of the raft cluster if the compares hold
+func (n *Node) Txn(ctx context.Context, req *protonpb.TxnRequest) (*protonpb.TxnResponse, error) {
+	err := validateNamespace(req.Namespace)
+	if err != nil {
+		return &protonpb.TxnResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if req.Ttl < 0 {
+		return &protonpb.TxnResponse{
+			Success: false,
+			Error:   ErrInvalidTTL.Error(),
+		}, nil
+	}
+
+	err = n.checkRate(ctx)
+	if err != nil {
+		return &protonpb.TxnResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	var (
+		compares []*protonpb.Compare
+		writes   []*LogPair
+	)
+	for _, c := range req.Compares {
+		compares = append(compares, &protonpb.Compare{
+			Key:      NamespacedKey(req.Namespace, c.Key),
+			Revision: c.Revision,
+		})
+	}
+	for _, w := range req.Writes {
+		if req.Namespace == "" && isReservedKey(w.Key) {
+			return &protonpb.TxnResponse{
+				Success: false,
+				Error:   ErrReservedKey.Error(),
+			}, nil
+		}
+		write := &LogPair{
+			Key:     NamespacedKey(req.Namespace, w.Key),
+			Value:   w.Value,
+			Origin:  w.Origin,
+			Delete:  w.Delete,
+			Session: w.Session,
+			After:   req.After,
+		}
+		if req.Ttl > 0 && !w.Delete {
+			write.Expires = n.deadline(time.Duration(req.Ttl))
+		}
+		writes = append(writes, write)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, proposeTimeout)
+	defer cancel()
+
+	committed, index, err := n.Commit(ctx, compares, writes)
+	if err != nil {
+		return &protonpb.TxnResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protonpb.TxnResponse{
+		Success:   true,
+		Committed: committed,
+		Index:     index,
+	}, nil
+}
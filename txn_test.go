@@ -0,0 +1,72 @@
+package proton
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func applyTxn(t *testing.T, n *Node, index uint64, txn *Txn) bool {
+	value, err := proto.Marshal(txn)
+	assert.NoError(t, err)
+
+	outcome := n.txns.register(txn.Id)
+	defer n.txns.cancel(txn.Id)
+	applyProposal(t, n, index, &LogPair{Key: txnKey, Value: value})
+	return <-outcome
+}
+
+func TestTxn(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	applyPair(t, n, 3, "foo", "bar")
+
+	// A stale revision aborts every write
+	committed := applyTxn(t, n, 4, &Txn{
+		Id:       1,
+		Compares: []*protonpb.Compare{{Key: "foo", Revision: 2}},
+		Writes:   []*LogPair{{Key: "foo", Value: []byte("baz")}, {Key: "qux", Value: []byte("quux")}},
+	})
+	assert.False(t, committed)
+	assert.Equal(t, n.Get("foo"), "bar")
+	assert.Equal(t, n.Get("qux"), "")
+
+	committed = applyTxn(t, n, 5, &Txn{
+		Id: 2,
+		Compares: []*protonpb.Compare{
+			{Key: "foo", Revision: 3},
+			{Key: "qux", Revision: 0},
+		},
+		Writes: []*LogPair{{Key: "foo", Delete: true}, {Key: "qux", Value: []byte("quux")}},
+	})
+	assert.True(t, committed)
+	assert.Equal(t, n.Get("foo"), "")
+	assert.Equal(t, n.Get("qux"), "quux")
+
+	results, err := n.MultiGet(context.Background(), []string{"qux"})
+	assert.NoError(t, err)
+	assert.Equal(t, results[0].Revision, uint64(5))
+
+	// The reserved keyspace is never written by a transaction
+	committed = applyTxn(t, n, 6, &Txn{
+		Id:     3,
+		Writes: []*LogPair{{Key: sessionKey(1), Value: []byte("x")}},
+	})
+	assert.True(t, committed)
+	assert.Empty(t, n.Sessions())
+}
+
+func TestTxnDuplicateWrite(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	// Two writes of a key would share the revision of the transaction
+	writes := []*LogPair{{Key: "foo", Value: []byte("bar")}, {Key: "foo", Value: []byte("baz")}}
+	_, _, err := n.Commit(context.Background(), nil, writes)
+	assert.Equal(t, err, ErrDuplicateWrite)
+}
@@ -2,8 +2,10 @@ package proton
 
 import (
 	"errors"
+	"net"
 	"time"
 
+	"github.com/abronan/proton/protonpb/v1"
 	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
 )
@@ -12,31 +14,102 @@ const (
 	// MaxRetryTime is the number of time we try to initiate
 	// a grpc connection to a remote raft member
 	MaxRetryTime = 3
+
+	// RetryBackoff is the time to wait before the second attempt
+	// to connect to a raft member, doubled after each attempt
+	RetryBackoff = 100 * time.Millisecond
+
+	// DefaultMaxConcurrentStreams is the number of streams a client
+	// connection carries at once to a server, the next ones wait
+	DefaultMaxConcurrentStreams = 256
 )
 
-// Raft represents a connection to a raft member
+// Dialer opens the network connection to a raft member, it
+// can route the traffic through a proxy or a given interface
+type Dialer func(addr string, timeout time.Duration) (net.Conn, error)
+
+// Raft represents a connection to a raft member,
+// exposing the clients of each of its services
 type Raft struct {
-	RaftClient
+	RaftTransportClient
+	ClusterClient
+	KVClient
 	Conn *grpc.ClientConn
 }
 
 // GetRaftClient returns a raft client object to communicate
-// with other raft members
-func GetRaftClient(addr string, timeout time.Duration) (*Raft, error) {
-	conn, err := getClientConn(addr, "tcp", timeout)
+// with other raft members. The connection is insecure unless
+// options are given, which must then set the transport security
+func GetRaftClient(addr string, timeout time.Duration, opts ...grpc.DialOption) (*Raft, error) {
+	addr, err := NormalizeAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := getClientConn(addr, "tcp", timeout, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Raft{
-		RaftClient: NewRaftClient(conn),
-		Conn:       conn,
+		RaftTransportClient: NewRaftTransportClient(conn),
+		ClusterClient:       NewClusterClient(conn),
+		KVClient:            NewKVClient(conn),
+		Conn:                conn,
 	}, nil
 }
 
+// NewServer returns a grpc server to register the services of a
+// node on. A member keeps a single connection to each other member,
+// shared by the transport, the cluster and the KV services, so the
+// streams it carries at once are bounded by the server rather than
+// by the number of connections. Options given after the limit of
+// DefaultMaxConcurrentStreams streams override it
+func NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.MaxConcurrentStreams(DefaultMaxConcurrentStreams)}, opts...)
+	return grpc.NewServer(opts...)
+}
+
+// NormalizeAddr checks that an address is a host and a port and
+// returns it in canonical form, so that the same member is always
+// known by the same address. IPv6 literals must be between brackets
+func NormalizeAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" || port == "" {
+		return "", ErrInvalidAddress
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Listen binds every address, so that a node can be served on
+// both its IPv4 and IPv6 addresses. The listeners already bound
+// are closed if an address can't be bound
+func Listen(addrs ...string) ([]net.Listener, error) {
+	var listeners []net.Listener
+	for _, addr := range addrs {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
 // getClientConn returns a grpc client connection
-func getClientConn(addr string, protocol string, timeout time.Duration) (*grpc.ClientConn, error) {
-	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithTimeout(timeout))
+func getClientConn(addr string, protocol string, timeout time.Duration, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithInsecure()}
+	}
+	opts = append(opts[:len(opts):len(opts)], grpc.WithTimeout(timeout))
+
+	conn, err := grpc.Dial(addr, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +120,7 @@ func getClientConn(addr string, protocol string, timeout time.Duration) (*grpc.C
 // EncodePair returns a protobuf encoded key/value pair to be sent through raft
 func EncodePair(key string, value []byte) ([]byte, error) {
 	k := proto.String(key)
-	pair := &Pair{
+	pair := &LogPair{
 		Key:   *k,
 		Value: value,
 	}
@@ -58,7 +131,39 @@ func EncodePair(key string, value []byte) ([]byte, error) {
 	return data, nil
 }
 
-// Register registers the node raft server
+// publicPair returns the fields of a pair of the log that are
+// exposed to the clients, without the envelope set by the node
+func publicPair(pair *LogPair) *protonpb.Pair {
+	return &protonpb.Pair{
+		Key:     pair.Key,
+		Value:   pair.Value,
+		Origin:  pair.Origin,
+		Delete:  pair.Delete,
+		Session: pair.Session,
+	}
+}
+
+// Register registers every service of the node on
+// the same grpc server
 func Register(server *grpc.Server, node *Node) {
-	RegisterRaftServer(server, node)
+	RegisterTransport(server, node)
+	RegisterCluster(server, node)
+	RegisterKV(server, node)
+}
+
+// RegisterTransport registers the service carrying the raft
+// messages, it has to be served on the address of the node
+func RegisterTransport(server *grpc.Server, node *Node) {
+	RegisterRaftTransportServer(server, node)
+}
+
+// RegisterCluster registers the membership and administration
+// service of the node
+func RegisterCluster(server *grpc.Server, node *Node) {
+	RegisterClusterServer(server, node)
+}
+
+// RegisterKV registers the key/value service of the node
+func RegisterKV(server *grpc.Server, node *Node) {
+	RegisterKVServer(server, node)
 }
@@ -0,0 +1,42 @@
+package proton
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAddr(t *testing.T) {
+	for addr, expected := range map[string]string{
+		"127.0.0.1:4242":         "127.0.0.1:4242",
+		"proton-1:4242":          "proton-1:4242",
+		"[::1]:4242":             "[::1]:4242",
+		"[0:0:0:0:0:0:0:1]:4242": "[::1]:4242",
+		"[fe80::1%eth0]:4242":    "[fe80::1%eth0]:4242",
+		"[2001:DB8::0:1]:4242":   "[2001:db8::1]:4242",
+	} {
+		normalized, err := NormalizeAddr(addr)
+		assert.NoError(t, err)
+		assert.Equal(t, normalized, expected)
+	}
+
+	for _, addr := range []string{"::1:4242", "127.0.0.1", ":4242", "[::1]"} {
+		_, err := NormalizeAddr(addr)
+		assert.Equal(t, err, ErrInvalidAddress)
+	}
+}
+
+func TestListen(t *testing.T) {
+	listeners, err := Listen("127.0.0.1:0", "127.0.0.1:0")
+	assert.NoError(t, err)
+	assert.Equal(t, len(listeners), 2)
+
+	// The listeners bound are closed when an address fails
+	addr := listeners[0].Addr().String()
+	_, err = Listen("127.0.0.1:0", addr)
+	assert.Error(t, err)
+
+	for _, l := range listeners {
+		l.Close()
+	}
+}
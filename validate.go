@@ -0,0 +1,112 @@
+package proton
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+// Validator checks a write of the application as it is applied,
+// an error rejects it: the key is left untouched, the apply handler
+// is not called and the proposer is returned an ApplicationError
+type Validator func(key string, value []byte) error
+
+// ApplicationError is returned to the proposer of
+// a write that the validator rejected
+type ApplicationError struct {
+	Index  uint64
+	Key    string
+	Reason string
+}
+
+func (e *ApplicationError) Error() string {
+	return fmt.Sprintf("write of %s at index %d rejected: %s", e.Key, e.Index, e.Reason)
+}
+
+// validation holds the validator of the writes
+// and the rejection of the entry being applied
+type validation struct {
+	lock      sync.RWMutex
+	validate  Validator
+	rejection error
+}
+
+// SetValidator sets the function checking the writes as they
+// are applied. It runs on every member, so that they all reject
+// the same writes: it must only depend on the key, the value and
+// the store. Deletions and the reserved keyspace are not checked.
+// Every member must use the same validator
+func (n *Node) SetValidator(validate Validator) {
+	n.validation.lock.Lock()
+	n.validation.validate = validate
+	n.validation.lock.Unlock()
+}
+
+// validator returns the function checking the writes, if any
+func (n *Node) validator() Validator {
+	n.validation.lock.RLock()
+	defer n.validation.lock.RUnlock()
+	return n.validation.validate
+}
+
+// validateWrite checks the plaintext value of a write, a
+// rejection is kept for the proposer. Called from the main loop
+func (n *Node) validateWrite(entry raftpb.Entry, key string, value []byte) bool {
+	validate := n.validator()
+	if validate == nil {
+		return true
+	}
+
+	err := validate(key, value)
+	if err == nil {
+		return true
+	}
+	n.validation.rejection = &ApplicationError{Index: entry.Index, Key: key, Reason: err.Error()}
+	return false
+}
+
+// takeRejection returns the rejection of the entry
+// last applied, if any. Called from the main loop
+func (n *Node) takeRejection() error {
+	err := n.validation.rejection
+	n.validation.rejection = nil
+	return err
+}
+
+// validateTxn checks every write of a transaction before
+// any is applied, so that a rejection leaves all the keys
+// untouched. Called from the main loop
+func (n *Node) validateTxn(entry raftpb.Entry, writes []*LogPair) bool {
+	if n.validator() == nil {
+		return true
+	}
+
+	for _, write := range writes {
+		if isSystemKey(write.Key) || write.Delete {
+			continue
+		}
+		// A value that can't be decoded is ignored when applied
+		value, ok := n.plainValue(write)
+		if ok && !n.validateWrite(entry, write.Key, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// plainValue returns the value of a pair as it was proposed,
+// before it was compressed, stored by content or encrypted
+func (n *Node) plainValue(pair *LogPair) ([]byte, bool) {
+	p := *pair
+	if p.Compressed {
+		err := decompressPair(&p)
+		if err != nil {
+			return nil, false
+		}
+	}
+	if len(p.Digest) > 0 && !n.resolveDigest(&p) {
+		return nil, false
+	}
+	return n.decryptValue(p.Key, p.Value), true
+}
@@ -0,0 +1,120 @@
+package proton
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/stretchr/testify/assert"
+)
+
+// rejectPrefix rejects the values starting with "bad"
+func rejectPrefix(key string, value []byte) error {
+	if strings.HasPrefix(string(value), "bad") {
+		return errors.New("value is bad")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	var applied []string
+	n.apply = func(data interface{}) { applied = append(applied, string(data.([]byte))) }
+	n.SetValidator(rejectPrefix)
+
+	applyPair(t, n, 1, "foo", "good")
+	assert.Nil(t, n.takeRejection())
+
+	applyPair(t, n, 2, "foo", "bad value")
+	assert.Equal(t, n.Get("foo"), "good")
+	assert.Len(t, applied, 1)
+
+	err := n.takeRejection()
+	assert.Equal(t, err, &ApplicationError{Index: 2, Key: "foo", Reason: "value is bad"})
+	assert.Nil(t, n.takeRejection())
+
+	// Deletions are not checked
+	applyProposal(t, n, 3, &LogPair{Key: "foo", Delete: true})
+	assert.Nil(t, n.takeRejection())
+	assert.Equal(t, n.Get("foo"), "")
+}
+
+func TestValidatorCompressed(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.SetValidator(rejectPrefix)
+	n.CompressionThreshold = 16
+
+	data, err := EncodePair("foo", []byte("bad"+strings.Repeat(" value", 20)))
+	assert.NoError(t, err)
+	n.process(raftpb.Entry{Type: raftpb.EntryNormal, Index: 1, Term: 1, Data: n.compressProposal(data)})
+	assert.Equal(t, n.StoreLength(), 0)
+	assert.IsType(t, n.takeRejection(), &ApplicationError{})
+}
+
+func TestValidatorTxn(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+
+	n.SetValidator(rejectPrefix)
+
+	// A rejected write aborts the whole transaction
+	committed := applyTxn(t, n, 1, &Txn{
+		Id:     1,
+		Writes: []*LogPair{{Key: "foo", Value: []byte("good")}, {Key: "bar", Value: []byte("bad")}},
+	})
+	assert.False(t, committed)
+	assert.Equal(t, n.StoreLength(), 0)
+	assert.Equal(t, n.takeRejection(), &ApplicationError{Index: 1, Key: "bar", Reason: "value is bad"})
+
+	committed = applyTxn(t, n, 2, &Txn{
+		Id:     2,
+		Writes: []*LogPair{{Key: "foo", Value: []byte("good")}},
+	})
+	assert.True(t, committed)
+	assert.Equal(t, n.Get("foo"), "good")
+	assert.Nil(t, n.takeRejection())
+}
+
+func TestValidatorProposer(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	n.SetValidator(rejectPrefix)
+	defer n.Shutdown()
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	data, err := EncodePair("foo", []byte("bad"))
+	assert.NoError(t, err)
+	index, _, err := n.ProposeWait(ctx, data)
+	assert.Equal(t, err, &ApplicationError{Index: index, Key: "foo", Reason: "value is bad"})
+
+	resp, err := n.PutObject(ctx, &protonpb.PutObjectRequest{Object: &protonpb.Pair{Key: "foo", Value: []byte("bad")}})
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.Error, "value is bad")
+
+	resp, err = n.PutObject(ctx, &protonpb.PutObjectRequest{Object: &protonpb.Pair{Key: "foo", Value: []byte("good")}})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, n.Get("foo"), "good")
+}
@@ -0,0 +1,110 @@
+package proton
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+// SnapshotView is an immutable copy of the store at an applied
+// index, to scan every key without holding up the writes. The
+// keys are iterated in order. A view is not safe for concurrent use
+type SnapshotView struct {
+	node  *Node
+	index uint64
+	keys  []viewKey
+	pos   int
+}
+
+// viewKey is a key of the store copied in a view
+type viewKey struct {
+	key      string
+	value    string
+	revision uint64
+}
+
+// SnapshotView returns a view of the store once the entries being
+// applied are done, so that it never holds a part of an entry
+// such as some of the writes of a transaction. The store is copied
+// from the main loop, the values are shared as they are immutable
+func (n *Node) SnapshotView(ctx context.Context) (*SnapshotView, error) {
+	done := make(chan *SnapshotView, 1)
+	select {
+	case n.viewc <- done:
+	case <-n.tickDone:
+		return nil, ErrNodeStopped
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	v := <-done
+
+	sort.Sort(viewKeys(v.keys))
+	return v, nil
+}
+
+// copyStore copies the store in a view. Called from the main loop
+func (n *Node) copyStore() *SnapshotView {
+	n.storeLock.RLock()
+	defer n.storeLock.RUnlock()
+
+	v := &SnapshotView{
+		node:  n,
+		index: n.appliedIndex,
+		keys:  make([]viewKey, 0, len(n.pstore)),
+		pos:   -1,
+	}
+	for key, value := range n.pstore {
+		v.keys = append(v.keys, viewKey{key: key, value: value, revision: n.revisions[key]})
+	}
+	return v
+}
+
+// Index returns the index applied to the store when the view was taken
+func (v *SnapshotView) Index() uint64 {
+	return v.index
+}
+
+// Len returns the number of keys in the view
+func (v *SnapshotView) Len() int {
+	return len(v.keys)
+}
+
+// Seek moves the view before the first key greater
+// than or equal to key, Next moves to that key
+func (v *SnapshotView) Seek(key string) {
+	v.pos = sort.Search(len(v.keys), func(i int) bool { return v.keys[i].key >= key }) - 1
+}
+
+// Next moves to the next key, it returns false once
+// every key was iterated
+func (v *SnapshotView) Next() bool {
+	if v.pos < len(v.keys) {
+		v.pos++
+	}
+	return v.pos < len(v.keys)
+}
+
+// Key returns the current key
+func (v *SnapshotView) Key() string {
+	return v.keys[v.pos].key
+}
+
+// Value returns the value of the current key, decrypted
+// if the values of the store are encrypted
+func (v *SnapshotView) Value() []byte {
+	k := v.keys[v.pos]
+	return v.node.decryptValue(k.key, []byte(k.value))
+}
+
+// Revision returns the index of the entry that last
+// wrote the current key
+func (v *SnapshotView) Revision() uint64 {
+	return v.keys[v.pos].revision
+}
+
+// viewKeys sorts the keys of a view
+type viewKeys []viewKey
+
+func (k viewKeys) Len() int           { return len(k) }
+func (k viewKeys) Less(i, j int) bool { return k[i].key < k[j].key }
+func (k viewKeys) Swap(i, j int)      { k[i], k[j] = k[j], k[i] }
@@ -0,0 +1,71 @@
+package proton
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotView(t *testing.T) {
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+
+	n, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+
+	n.Campaign(n.Ctx)
+	go n.Start()
+	for i := 0; i < 100 && !n.IsLeader(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, n.IsLeader())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	put := func(key, value string) uint64 {
+		data, err := EncodePair(key, []byte(value))
+		assert.NoError(t, err)
+		index, _, err := n.ProposeWait(ctx, data)
+		assert.NoError(t, err)
+		return index
+	}
+	for i := 3; i > 0; i-- {
+		put(fmt.Sprintf("foo/%d", i), "old")
+	}
+	revision := put("bar", "old")
+
+	view, err := n.SnapshotView(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, view.Index(), n.AppliedIndex())
+	assert.Equal(t, view.Len(), 4)
+
+	// Later writes are not seen by the view
+	put("foo/1", "new")
+	put("foo/4", "new")
+
+	var keys []string
+	for view.Next() {
+		keys = append(keys, view.Key())
+		assert.Equal(t, string(view.Value()), "old")
+	}
+	assert.Equal(t, keys, []string{"bar", "foo/1", "foo/2", "foo/3"})
+	assert.False(t, view.Next())
+
+	view.Seek("foo/2")
+	assert.True(t, view.Next())
+	assert.Equal(t, view.Key(), "foo/2")
+	view.Seek("bar")
+	assert.True(t, view.Next())
+	assert.Equal(t, view.Revision(), revision)
+	view.Seek("zzz")
+	assert.False(t, view.Next())
+
+	n.Shutdown()
+	_, err = n.SnapshotView(ctx)
+	assert.Equal(t, err, ErrNodeStopped)
+}
@@ -0,0 +1,195 @@
+package proton
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+const (
+	// proposeTimeout bounds the time a client waits for
+	// its proposal to be committed and applied
+	proposeTimeout = 10 * time.Second
+)
+
+// commit is the position of an entry in the raft log and its
+// hybrid logical time, err is the rejection of the entry by the
+// validator
+type commit struct {
+	index uint64
+	term  uint64
+	hlc   *protonpb.HybridTime
+	err   error
+}
+
+// waiters keeps track of the local proposals waiting
+// for their entry to be committed and applied
+type waiters struct {
+	lock    sync.Mutex
+	pending map[uint64][]chan commit
+}
+
+func newWaiters() *waiters {
+	return &waiters{pending: make(map[uint64][]chan commit)}
+}
+
+// register returns a channel receiving the position
+// of the entry once it is applied
+func (w *waiters) register(data []byte) chan commit {
+	ch := make(chan commit, 1)
+	h := proposalHash(data)
+	w.lock.Lock()
+	w.pending[h] = append(w.pending[h], ch)
+	w.lock.Unlock()
+	return ch
+}
+
+// cancel forgets a waiter that stopped waiting
+func (w *waiters) cancel(data []byte, ch chan commit) {
+	h := proposalHash(data)
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	chans := w.pending[h]
+	for i, c := range chans {
+		if c == ch {
+			chans = append(chans[:i], chans[i+1:]...)
+			break
+		}
+	}
+	if len(chans) == 0 {
+		delete(w.pending, h)
+	} else {
+		w.pending[h] = chans
+	}
+}
+
+// trigger notifies the oldest waiter of an applied entry,
+// identical proposals are matched in order
+func (w *waiters) trigger(entry raftpb.Entry, err error, hlc *protonpb.HybridTime) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.pending) == 0 {
+		return
+	}
+
+	h := proposalHash(entry.Data)
+	chans, ok := w.pending[h]
+	if !ok {
+		return
+	}
+	chans[0] <- commit{index: entry.Index, term: entry.Term, hlc: hlc, err: err}
+	if len(chans) == 1 {
+		delete(w.pending, h)
+	} else {
+		w.pending[h] = chans[1:]
+	}
+}
+
+// ProposeWait proposes data to be appended to the raft log and
+// waits for the entry to be applied on this node. It returns the
+// index and the term at which the entry was committed, to be used
+// for read-after-write waits or as a cursor of the changes, along
+// with an ApplicationError if the validator rejected the write
+func (n *Node) ProposeWait(ctx context.Context, data []byte) (index uint64, term uint64, err error) {
+	c, err := n.proposeWait(ctx, data)
+	return c.index, c.term, err
+}
+
+// proposeWait proposes data and waits for the entry to
+// be applied on this node, it returns where it committed
+func (n *Node) proposeWait(ctx context.Context, data []byte) (commit, error) {
+	data, err := n.prepareProposal(data)
+	if err != nil {
+		return commit{}, err
+	}
+	return n.proposePrepared(ctx, data)
+}
+
+// proposePrepared proposes prepared data and
+// waits for the entry to be applied on this node
+func (n *Node) proposePrepared(ctx context.Context, data []byte) (commit, error) {
+	ch := n.waiters.register(data)
+	err := n.propose(ctx, data)
+	if err != nil {
+		n.waiters.cancel(data, ch)
+		return commit{}, err
+	}
+
+	select {
+	case c := <-ch:
+		return c, c.err
+	case <-ctx.Done():
+		n.waiters.cancel(data, ch)
+		return commit{}, ctx.Err()
+	}
+}
+
+// indexWaiters keeps track of the callers waiting
+// for an index to be applied on this node
+type indexWaiters struct {
+	lock    sync.Mutex
+	applied uint64
+	pending map[uint64][]chan struct{}
+}
+
+func newIndexWaiters() *indexWaiters {
+	return &indexWaiters{pending: make(map[uint64][]chan struct{})}
+}
+
+// wait returns a channel closed once index is applied
+func (w *indexWaiters) wait(index uint64) <-chan struct{} {
+	ch := make(chan struct{})
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if index <= w.applied {
+		close(ch)
+		return ch
+	}
+	w.pending[index] = append(w.pending[index], ch)
+	return ch
+}
+
+// trigger notifies the waiters of every index up to applied
+func (w *indexWaiters) trigger(applied uint64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.applied = applied
+	for index, chans := range w.pending {
+		if index > applied {
+			continue
+		}
+		for _, ch := range chans {
+			close(ch)
+		}
+		delete(w.pending, index)
+	}
+}
+
+// AppliedIndex returns the index of the last
+// entry applied to the store of this node
+func (n *Node) AppliedIndex() uint64 {
+	return atomic.LoadUint64(&n.appliedIndex)
+}
+
+// CommitIndex returns the index of the last entry
+// known to be committed in the raft log
+func (n *Node) CommitIndex() uint64 {
+	return atomic.LoadUint64(&n.commitIndex)
+}
+
+// WaitForIndex waits for the entry at index to be applied on
+// this node, so that external actions such as invalidating a
+// cache can be coordinated with the replication
+func (n *Node) WaitForIndex(ctx context.Context, index uint64) error {
+	select {
+	case <-n.indexWaiters.wait(index):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
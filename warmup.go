@@ -0,0 +1,82 @@
+package proton
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrWarmingUp is thrown when reading from a node that did not yet apply
+	// the entries committed before it started
+	ErrWarmingUp = errors.New("node is applying the entries committed before it started")
+)
+
+// warmup holds the index a started node applies before it serves
+// the reads, which is known from its AppliedStore or the leader
+type warmup struct {
+	lock   sync.Mutex
+	target uint64
+	known  bool
+	// leader is set once the commit index of the leader is known
+	leader bool
+	warm   bool
+}
+
+// raise makes the node apply up to an index before serving reads
+func (w *warmup) raise(index uint64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if index == 0 {
+		return
+	}
+	if index > w.target {
+		w.target = index
+	}
+	w.known = true
+}
+
+// observeLeaderCommit is given the commit index of the leader
+// in its responses. Only the first one is kept: it covers every
+// entry committed before the node started, and the later ones
+// would keep a busy node from ever serving the reads
+func (n *Node) observeLeaderCommit(index uint64) {
+	w := n.warmup
+	w.lock.Lock()
+	leader := w.leader
+	w.leader = true
+	w.lock.Unlock()
+
+	if !leader {
+		w.raise(index)
+	}
+}
+
+// IsWarm checks if the node serves the client reads, which is once
+// it applied the index saved before it restarted or the commit
+// index of the leader when it first heard from it. The leader
+// applies its own commit index. Always true without WarmupReads
+func (n *Node) IsWarm() bool {
+	if !n.WarmupReads {
+		return true
+	}
+
+	w := n.warmup
+	w.lock.Lock()
+	warm, known := w.warm, w.known
+	w.lock.Unlock()
+	if warm {
+		return true
+	}
+	if !known && n.IsLeader() {
+		w.raise(atomic.LoadUint64(&n.commitIndex))
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.known && n.AppliedIndex() >= w.target {
+		w.warm = true
+	}
+	return w.warm
+}
@@ -0,0 +1,70 @@
+package proton
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/abronan/proton/protonpb/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarmup(t *testing.T) {
+	n := newQuotaNode(t)
+	defer n.Stop()
+	assert.True(t, n.IsWarm())
+
+	// A follower waits until it heard from the leader
+	n.WarmupReads = true
+	assert.False(t, n.IsWarm())
+	_, err := n.GetObjects(context.Background(), &protonpb.GetObjectsRequest{Keys: []string{"foo"}})
+	assert.Equal(t, err, ErrWarmingUp)
+	_, err = n.ListObjects(context.Background(), &protonpb.ListObjectsRequest{})
+	assert.Equal(t, err, ErrWarmingUp)
+
+	n.observeLeaderCommit(5)
+	n.observeLeaderCommit(10)
+	atomic.StoreUint64(&n.appliedIndex, 4)
+	assert.False(t, n.IsWarm())
+	atomic.StoreUint64(&n.appliedIndex, 5)
+	assert.True(t, n.IsWarm())
+	_, err = n.GetObjects(context.Background(), &protonpb.GetObjectsRequest{Keys: []string{"foo"}})
+	assert.NoError(t, err)
+
+	// The node stays warm if it falls behind later
+	n.observeLeaderCommit(100)
+	assert.True(t, n.IsWarm())
+
+	// The index saved before the restart is known right away
+	dir, err := ioutil.TempDir("", "proton-warmup")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	applied := NewAppliedFile(filepath.Join(dir, "applied"))
+	assert.NoError(t, applied.SaveApplied(7))
+	restarted := newQuotaNode(t)
+	defer restarted.Stop()
+	restarted.WarmupReads = true
+	assert.NoError(t, restarted.SetAppliedStore(applied))
+	atomic.StoreUint64(&restarted.appliedIndex, 7)
+	assert.True(t, restarted.IsWarm())
+
+	cfg := DefaultNodeConfig()
+	cfg.Logger = raftLogger
+	leader, err := NewNode(1, "127.0.0.1:0", cfg, nil)
+	assert.NoError(t, err, "Can't create raft node")
+	defer leader.Shutdown()
+	leader.WarmupReads = true
+
+	leader.Campaign(leader.Ctx)
+	go leader.Start()
+	for i := 0; i < 100 && !leader.IsWarm(); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.True(t, leader.IsLeader())
+	assert.True(t, leader.IsWarm())
+}